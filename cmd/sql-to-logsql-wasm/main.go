@@ -0,0 +1,58 @@
+//go:build js && wasm
+
+// Command sql-to-logsql-wasm exposes the SQL to LogsQL translator to
+// JavaScript, so the web UI can show live LogsQL as the user types without a
+// round-trip to the server.
+//
+// Build with:
+//
+//	GOOS=js GOARCH=wasm go build -o sql-to-logsql.wasm ./cmd/sql-to-logsql-wasm
+//
+// Once loaded, a global translateSQLToLogsQL(sql, tables) function is
+// available; see wrapper.js for a usage example.
+package main
+
+import (
+	"syscall/js"
+
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/sqltologsql"
+)
+
+func main() {
+	js.Global().Set("translateSQLToLogsQL", js.FuncOf(translate))
+	// Keep the Go runtime alive so the exported function stays callable.
+	select {}
+}
+
+// translate is the JS entry point. args[0] is the SQL string; the optional
+// args[1] is an object mapping table names to LogsQL expressions. It returns
+// {logsql: string, warnings: []string, error: string}.
+func translate(_ js.Value, args []js.Value) any {
+	result := map[string]any{"logsql": "", "warnings": []any{}, "error": ""}
+	if len(args) == 0 || args[0].Type() != js.TypeString {
+		result["error"] = "translateSQLToLogsQL expects a SQL string argument"
+		return result
+	}
+	opts := sqltologsql.Options{}
+	if len(args) > 1 && args[1].Type() == js.TypeObject {
+		tables := make(map[string]string)
+		keys := js.Global().Get("Object").Call("keys", args[1])
+		for i := 0; i < keys.Length(); i++ {
+			name := keys.Index(i).String()
+			tables[name] = args[1].Get(name).String()
+		}
+		opts.Tables = tables
+	}
+	query, warnings, err := sqltologsql.Translate(args[0].String(), opts)
+	if err != nil {
+		result["error"] = err.Error()
+		return result
+	}
+	messages := make([]any, 0, len(warnings))
+	for _, w := range warnings {
+		messages = append(messages, w.Message)
+	}
+	result["logsql"] = query
+	result["warnings"] = messages
+	return result
+}
@@ -0,0 +1,17 @@
+// Thin wrapper around the sql-to-logsql WASM module.
+//
+// Usage:
+//
+//   import { loadTranslator } from './wrapper.js';
+//   const translate = await loadTranslator('/sql-to-logsql.wasm');
+//   const { logsql, warnings, error } = translate("SELECT * FROM logs", { logs: '*' });
+//
+// Requires wasm_exec.js from the Go distribution to be loaded first
+// ($(go env GOROOT)/misc/wasm/wasm_exec.js).
+
+export async function loadTranslator(wasmURL) {
+  const go = new Go();
+  const result = await WebAssembly.instantiateStreaming(fetch(wasmURL), go.importObject);
+  go.run(result.instance);
+  return (sql, tables) => globalThis.translateSQLToLogsQL(sql, tables || {});
+}
@@ -0,0 +1,168 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/sql/lexer"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/sql/token"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/vlogs"
+)
+
+// completionFunctions lists the function names the translator supports, for
+// editor autocompletion.
+var completionFunctions = []string{
+	"ANY_VALUE", "APPROX_COUNT_DISTINCT", "AVG", "COALESCE", "CONCAT",
+	"COUNT", "DATE_TRUNC", "FIRST", "LAST", "LEFT", "LENGTH", "LOWER",
+	"LPAD", "LTRIM", "MAX", "MIN", "NOW", "REPLACE", "RIGHT", "RPAD",
+	"RTRIM", "SUBSTRING", "SUM", "TRIM", "UPPER",
+}
+
+type completionItem struct {
+	Text string `json:"text"`
+	Kind string `json:"kind"`
+}
+
+type completeResponse struct {
+	Suggestions []completionItem `json:"suggestions"`
+}
+
+// handleComplete serves GET /api/v1/complete?sql=...&pos=..., returning the
+// candidate keywords, table and view names, field names and functions at the
+// cursor. Field names come from the schema cache, so suggestions stay cheap
+// even while the user types.
+func (s *Server) handleComplete(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	sqlText := r.URL.Query().Get("sql")
+	pos := parseNonNegativeInt(r.URL.Query().Get("pos"), len(sqlText))
+	if pos > len(sqlText) {
+		pos = len(sqlText)
+	}
+	prefix, context := completionContext(sqlText, pos)
+
+	var items []completionItem
+	add := func(kind string, names []string) {
+		for _, name := range names {
+			if prefix != "" && !strings.HasPrefix(strings.ToLower(name), strings.ToLower(prefix)) {
+				continue
+			}
+			items = append(items, completionItem{Text: name, Kind: kind})
+		}
+	}
+	switch context {
+	case completeTables:
+		add("table", s.sp.TableStore().ListTables())
+		add("view", s.listViewNames())
+	case completeStatements:
+		add("keyword", token.Keywords())
+	default:
+		add("field", s.cachedFieldNames(r, sqlText))
+		add("function", completionFunctions)
+		add("keyword", token.Keywords())
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Kind != items[j].Kind {
+			return items[i].Kind < items[j].Kind
+		}
+		return items[i].Text < items[j].Text
+	})
+	writeJSON(w, http.StatusOK, completeResponse{Suggestions: items})
+}
+
+const (
+	completeStatements = "statements"
+	completeTables     = "tables"
+	completeExprs      = "expressions"
+)
+
+// completionContext returns the partial identifier ending at pos and the
+// completion context implied by the token before it.
+func completionContext(sqlText string, pos int) (string, string) {
+	start := pos
+	for start > 0 && isCompletionIdentByte(sqlText[start-1]) {
+		start--
+	}
+	prefix := sqlText[start:pos]
+
+	l := lexer.New(sqlText[:start])
+	last := token.Token{Type: token.EOF}
+	for {
+		tok := l.NextToken()
+		if tok.Type == token.EOF {
+			break
+		}
+		last = tok
+	}
+	switch last.Type {
+	case token.EOF, token.SEMICOLON:
+		return prefix, completeStatements
+	case token.FROM, token.JOIN, token.INTO:
+		return prefix, completeTables
+	default:
+		return prefix, completeExprs
+	}
+}
+
+func isCompletionIdentByte(ch byte) bool {
+	return ch == '_' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || (ch >= '0' && ch <= '9')
+}
+
+// cachedFieldNames collects field names for every configured table the SQL
+// mentions. Lookups go through the schema cache; tables that cannot be
+// resolved right now are skipped rather than delaying the suggestion.
+func (s *Server) cachedFieldNames(r *http.Request, sqlText string) []string {
+	mentioned := make(map[string]struct{})
+	l := lexer.New(sqlText)
+	for {
+		tok := l.NextToken()
+		if tok.Type == token.EOF {
+			break
+		}
+		if tok.Type != token.IDENT {
+			continue
+		}
+		name := strings.ToLower(tok.Literal)
+		if _, ok := s.sp.TableStore().GetTableQuery(name); ok {
+			mentioned[name] = struct{}{}
+		}
+	}
+	seen := make(map[string]struct{})
+	var names []string
+	for table := range mentioned {
+		fields, err := s.tableFieldNames(r.Context(), table, vlogs.EndpointConfig{
+			ClientLabel: requestClientLabel(r, ""),
+		})
+		if err != nil {
+			continue
+		}
+		for _, field := range fields {
+			if _, ok := seen[field]; ok {
+				continue
+			}
+			seen[field] = struct{}{}
+			names = append(names, field)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// listViewNames merges the names from the persistent view store, ignoring
+// load errors since autocompletion is best-effort.
+func (s *Server) listViewNames() []string {
+	vs := s.sp.ViewStore()
+	if vs == nil {
+		return nil
+	}
+	names, err := vs.ListViews()
+	if err != nil {
+		return nil
+	}
+	sort.Strings(names)
+	return names
+}
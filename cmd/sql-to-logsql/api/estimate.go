@@ -0,0 +1,135 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/logsql"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/sql/parser"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/store/viewstore"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/vlogs"
+)
+
+type estimateResponse struct {
+	LogsQL string `json:"logsql"`
+	// Rows is the number of log entries matched by the filter part of the
+	// translated query, before any pipes are applied.
+	Rows  uint64 `json:"rows"`
+	Start string `json:"start,omitempty"`
+	End   string `json:"end,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// handleEstimate translates the SQL query and runs only its filter part
+// against the VictoriaLogs hits API, so clients can warn about expensive
+// queries before executing them.
+func (s *Server) handleEstimate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	defer r.Body.Close()
+
+	var req queryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("ERROR: failed to decode request: %v", err)
+		writeJSON(w, http.StatusBadRequest, estimateResponse{Error: "invalid request payload"})
+		return
+	}
+
+	sqlText := strings.TrimSpace(req.SQL)
+	if sqlText == "" {
+		writeJSON(w, http.StatusBadRequest, estimateResponse{Error: "sql query is required"})
+		return
+	}
+	sqlText, err := substituteVariables(sqlText, req.Variables)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, estimateResponse{Error: err.Error()})
+		return
+	}
+	sqlText = expandTimeMacros(sqlText, strings.TrimSpace(req.From), strings.TrimSpace(req.To), req.IntervalMs)
+
+	statement, _, _, err := processQuery(sqlText, s.sp, s.rewriters)
+	if err != nil {
+		log.Printf("ERROR: query processing failed: %v", err)
+		var te *logsql.TranslationError
+		var ve *viewstore.StoreError
+		var se *parser.SyntaxError
+		if errors.As(err, &te) {
+			writeJSON(w, te.Code, estimateResponse{Error: te.Message})
+		} else if errors.As(err, &ve) {
+			writeJSON(w, ve.Code, estimateResponse{Error: ve.Message})
+		} else if errors.As(err, &se) {
+			writeJSON(w, http.StatusBadRequest, estimateResponse{Error: err.Error()})
+		} else {
+			writeJSON(w, http.StatusInternalServerError, estimateResponse{Error: "query processing failed"})
+		}
+		return
+	}
+	if statement.Kind != logsql.StatementTypeSelect || statement.LogsQL == "" {
+		writeJSON(w, http.StatusBadRequest, estimateResponse{Error: "only SELECT statements can be estimated"})
+		return
+	}
+
+	filter := filterPart(statement.LogsQL)
+	estimate, err := s.api.GetHits(r.Context(), filter, vlogs.EndpointConfig{
+		Endpoint:    req.Endpoint,
+		BearerToken: req.BearerToken,
+		ClientLabel: requestClientLabel(r, ""),
+	})
+	if err != nil {
+		log.Printf("ERROR: estimate failed: %v", err)
+		var ae *vlogs.APIError
+		if errors.As(err, &ae) {
+			writeJSON(w, ae.Code, estimateResponse{LogsQL: filter, Error: ae.Message})
+		} else {
+			writeJSON(w, http.StatusInternalServerError, estimateResponse{LogsQL: filter, Error: "estimate failed"})
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, estimateResponse{
+		LogsQL: filter,
+		Rows:   estimate.Rows,
+		Start:  estimate.Start,
+		End:    estimate.End,
+	})
+}
+
+// filterPart returns everything before the first top-level pipe in query,
+// ignoring pipes inside quoted strings and parentheses.
+func filterPart(query string) string {
+	depth := 0
+	var quote rune
+	for i := 0; i < len(query); i++ {
+		ch := query[i]
+		if quote != 0 {
+			if ch == '\\' {
+				i++
+				continue
+			}
+			if rune(ch) == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch ch {
+		case '"', '\'', '`':
+			quote = rune(ch)
+		case '(':
+			depth++
+		case ')':
+			if depth > 0 {
+				depth--
+			}
+		case '|':
+			if depth == 0 {
+				return strings.TrimSpace(query[:i])
+			}
+		}
+	}
+	return strings.TrimSpace(query)
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandleEstimate(t *testing.T) {
+	srv, err := NewServer(Config{Endpoint: "http://victoria", Tables: map[string]string{"logs": "*"}})
+	if err != nil {
+		t.Fatalf("NewServer error: %v", err)
+	}
+	srv.setHTTPClient(&http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path != "/select/logsql/hits" {
+				t.Fatalf("unexpected path: %s", req.URL.Path)
+			}
+			if err := req.ParseForm(); err != nil {
+				t.Fatalf("failed to parse form: %v", err)
+			}
+			if got := req.Form.Get("query"); got != "level:error" {
+				t.Fatalf("unexpected query sent: %q", got)
+			}
+			body := `{"hits":[{"fields":{},"timestamps":["2024-01-01T00:00:00Z","2024-01-02T00:00:00Z"],"values":[3,4],"total":7}]}`
+			resp := &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewBufferString(body)),
+				Header:     make(http.Header),
+			}
+			return resp, nil
+		}),
+	})
+
+	reqBody := `{"sql":"SELECT * FROM logs WHERE level = 'error' ORDER BY _time LIMIT 10"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/estimate", strings.NewReader(reqBody))
+	rr := httptest.NewRecorder()
+	srv.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp estimateResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid json response: %v", err)
+	}
+	if resp.LogsQL != "level:error" {
+		t.Fatalf("unexpected logsql: %q", resp.LogsQL)
+	}
+	if resp.Rows != 7 {
+		t.Fatalf("unexpected row estimate: %d", resp.Rows)
+	}
+	if resp.Start != "2024-01-01T00:00:00Z" || resp.End != "2024-01-02T00:00:00Z" {
+		t.Fatalf("unexpected time span: %q .. %q", resp.Start, resp.End)
+	}
+}
+
+func TestHandleEstimateRejectsNonSelect(t *testing.T) {
+	srv, err := NewServer(Config{Endpoint: "http://victoria", Tables: map[string]string{"logs": "*"}})
+	if err != nil {
+		t.Fatalf("NewServer error: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/estimate", strings.NewReader(`{"sql":"SHOW TABLES"}`))
+	rr := httptest.NewRecorder()
+	srv.ServeHTTP(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
@@ -0,0 +1,325 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/logsql"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/vlogs"
+)
+
+// Result export runs a query asynchronously and streams the full, unlimited
+// result to a destination instead of buffering it into the HTTP response.
+// This serves compliance extracts that are too large for an interactive
+// response: the POST returns a job ID immediately and progress is polled via
+// /api/v1/export/{id}.
+
+// exportSpec is the export block of a query request.
+type exportSpec struct {
+	// Format is "jsonl" (the raw VictoriaLogs stream) or "csv".
+	Format string `json:"format"`
+	// Destination is a path relative to the configured export directory.
+	// Object storage buckets are expected to be mounted under it; native
+	// s3:// support would pull in a full SDK dependency.
+	Destination string `json:"destination"`
+}
+
+type exportJob struct {
+	ID          string `json:"id"`
+	Status      string `json:"status"`
+	Format      string `json:"format"`
+	Destination string `json:"destination"`
+	Rows        int64  `json:"rows"`
+	Bytes       int64  `json:"bytes"`
+	Error       string `json:"error,omitempty"`
+	StartedAt   string `json:"startedAt"`
+	FinishedAt  string `json:"finishedAt,omitempty"`
+}
+
+const (
+	exportStatusRunning   = "running"
+	exportStatusCompleted = "completed"
+	exportStatusFailed    = "failed"
+)
+
+type exportJobs struct {
+	mu   sync.Mutex
+	jobs map[string]*exportJob
+}
+
+func newExportJobID() string {
+	var buf [8]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		return "exp_fallback"
+	}
+	return "exp_" + hex.EncodeToString(buf[:])
+}
+
+func (e *exportJobs) add(job *exportJob) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.jobs == nil {
+		e.jobs = make(map[string]*exportJob)
+	}
+	e.jobs[job.ID] = job
+}
+
+// update applies fn to a job under the lock; progress fields are only ever
+// mutated through here.
+func (e *exportJobs) update(id string, fn func(*exportJob)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if job, ok := e.jobs[id]; ok {
+		fn(job)
+	}
+}
+
+func (e *exportJobs) get(id string) (exportJob, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	job, ok := e.jobs[id]
+	if !ok {
+		return exportJob{}, false
+	}
+	return *job, true
+}
+
+func (e *exportJobs) list() []exportJob {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	out := make([]exportJob, 0, len(e.jobs))
+	for _, job := range e.jobs {
+		out = append(out, *job)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].StartedAt > out[j].StartedAt })
+	return out
+}
+
+// startExport validates the spec, registers a job and launches the streaming
+// goroutine. The caller has already translated and masked the statement.
+func (s *Server) startExport(spec *exportSpec, statement *logsql.StatementInfo, ec vlogs.EndpointConfig) (string, error) {
+	format := strings.ToLower(strings.TrimSpace(spec.Format))
+	switch format {
+	case "", "jsonl":
+		format = "jsonl"
+	case "csv":
+	default:
+		return "", fmt.Errorf("unsupported export format %q (supported: jsonl, csv)", spec.Format)
+	}
+	path, err := s.exportDestinationPath(spec.Destination)
+	if err != nil {
+		return "", err
+	}
+	if statement.Kind != logsql.StatementTypeSelect || statement.LogsQL == "" {
+		return "", fmt.Errorf("only SELECT statements can be exported")
+	}
+
+	job := &exportJob{
+		ID:          newExportJobID(),
+		Status:      exportStatusRunning,
+		Format:      format,
+		Destination: spec.Destination,
+		StartedAt:   historyTimestamp(time.Now()),
+	}
+	s.exports.add(job)
+	go s.runExport(job.ID, statement.LogsQL, format, path, ec)
+	return job.ID, nil
+}
+
+func (s *Server) runExport(id, logsQL, format, path string, ec vlogs.EndpointConfig) {
+	fail := func(err error) {
+		s.exports.update(id, func(job *exportJob) {
+			job.Status = exportStatusFailed
+			job.Error = err.Error()
+			job.FinishedAt = historyTimestamp(time.Now())
+		})
+	}
+
+	stream, err := s.api.QueryStream(context.Background(), logsQL, ec)
+	if err != nil {
+		fail(err)
+		return
+	}
+	defer stream.Close()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		fail(fmt.Errorf("failed to create destination directory: %w", err))
+		return
+	}
+	out, err := os.Create(path)
+	if err != nil {
+		fail(fmt.Errorf("failed to create destination file: %w", err))
+		return
+	}
+
+	var rows, bytes int64
+	switch format {
+	case "csv":
+		rows, bytes, err = exportCSV(out, stream)
+	default:
+		rows, bytes, err = exportJSONL(out, stream)
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		fail(err)
+		return
+	}
+	s.exports.update(id, func(job *exportJob) {
+		job.Status = exportStatusCompleted
+		job.Rows = rows
+		job.Bytes = bytes
+		job.FinishedAt = historyTimestamp(time.Now())
+	})
+}
+
+// exportJSONL copies the stream verbatim, counting rows by newline.
+func exportJSONL(out io.Writer, in io.Reader) (int64, int64, error) {
+	var rows, bytes int64
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	w := bufio.NewWriter(out)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		n, err := w.Write(line)
+		if err != nil {
+			return rows, bytes, err
+		}
+		if err := w.WriteByte('\n'); err != nil {
+			return rows, bytes, err
+		}
+		rows++
+		bytes += int64(n) + 1
+	}
+	if err := scanner.Err(); err != nil {
+		return rows, bytes, err
+	}
+	return rows, bytes, w.Flush()
+}
+
+// exportCSV converts the JSONL stream to CSV. The header is fixed from the
+// sorted keys of the first row; later rows may omit columns but cannot
+// introduce new ones without being silently truncated, which matches the
+// tabular contract of a CSV extract.
+func exportCSV(out io.Writer, in io.Reader) (int64, int64, error) {
+	var rows int64
+	counter := &countingWriter{w: out}
+	cw := csv.NewWriter(counter)
+	var columns []string
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var record map[string]any
+		if err := json.Unmarshal(line, &record); err != nil {
+			return rows, counter.n, fmt.Errorf("failed to parse result row: %w", err)
+		}
+		if columns == nil {
+			columns = make([]string, 0, len(record))
+			for name := range record {
+				columns = append(columns, name)
+			}
+			sort.Strings(columns)
+			if err := cw.Write(columns); err != nil {
+				return rows, counter.n, err
+			}
+		}
+		values := make([]string, len(columns))
+		for i, name := range columns {
+			if v, ok := record[name]; ok && v != nil {
+				values[i] = fmt.Sprintf("%v", v)
+			}
+		}
+		if err := cw.Write(values); err != nil {
+			return rows, counter.n, err
+		}
+		rows++
+	}
+	if err := scanner.Err(); err != nil {
+		return rows, counter.n, err
+	}
+	cw.Flush()
+	return rows, counter.n, cw.Error()
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// exportDestinationPath resolves a destination to a file path inside the
+// configured export directory. Destinations never name filesystem locations
+// directly: confining them to exportDir keeps a query from overwriting
+// arbitrary files the server process can write to.
+func (s *Server) exportDestinationPath(dest string) (string, error) {
+	dest = strings.TrimSpace(dest)
+	if dest == "" {
+		return "", fmt.Errorf("export destination is required")
+	}
+	if s.exportDir == "" {
+		return "", fmt.Errorf("exports are disabled: no exportDir is configured")
+	}
+	if strings.HasPrefix(dest, "s3://") || strings.HasPrefix(dest, "gs://") {
+		return "", fmt.Errorf("object storage destinations are not supported directly; mount the bucket under the export directory and use a relative destination")
+	}
+	path := strings.TrimPrefix(dest, "file://")
+	if path == dest && strings.Contains(dest, "://") {
+		return "", fmt.Errorf("unsupported export destination %q; use a path relative to the export directory", dest)
+	}
+	if filepath.IsAbs(path) {
+		return "", fmt.Errorf("export destination must be relative to the configured export directory")
+	}
+	root := filepath.Clean(s.exportDir)
+	full := filepath.Join(root, path)
+	if full == root || !strings.HasPrefix(full, root+string(filepath.Separator)) {
+		return "", fmt.Errorf("export destination %q escapes the export directory", dest)
+	}
+	return full, nil
+}
+
+// handleExportStatus serves GET /api/v1/export for the job list and
+// /api/v1/export/{id} for a single job.
+func (s *Server) handleExportStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	id := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/api/v1/export"), "/")
+	if id == "" {
+		writeJSON(w, http.StatusOK, s.exports.list())
+		return
+	}
+	job, ok := s.exports.get(id)
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": fmt.Sprintf("unknown export job %q", id)})
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
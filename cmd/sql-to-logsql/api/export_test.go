@@ -0,0 +1,142 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func exportServer(t *testing.T, payload, exportDir string) *Server {
+	t.Helper()
+	srv, err := NewServer(Config{Endpoint: "http://victoria", Tables: map[string]string{"logs": "*"}, ExportDir: exportDir})
+	if err != nil {
+		t.Fatalf("NewServer error: %v", err)
+	}
+	srv.setHTTPClient(&http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			resp := &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewBufferString(payload)),
+				Header:     make(http.Header),
+			}
+			resp.Header.Set("Content-Type", "application/json")
+			return resp, nil
+		}),
+	})
+	return srv
+}
+
+func waitForExport(t *testing.T, srv *Server, id string) exportJob {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		job, ok := srv.exports.get(id)
+		if !ok {
+			t.Fatalf("export job %q disappeared", id)
+		}
+		if job.Status != exportStatusRunning {
+			return job
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("export job %q did not finish in time", id)
+	return exportJob{}
+}
+
+func TestExportToFile(t *testing.T) {
+	payload := `{"level":"error","user":"alice"}` + "\n" + `{"level":"error","user":"bob"}` + "\n"
+	dir := t.TempDir()
+	srv := exportServer(t, payload, dir)
+	dest := filepath.Join(dir, "out.csv")
+
+	rr := postJSON(t, srv, "/api/v1/sql-to-logsql", map[string]any{
+		"sql":    "SELECT * FROM logs WHERE level = 'error'",
+		"export": map[string]string{"format": "csv", "destination": "out.csv"},
+	})
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("expected status 202, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp struct {
+		JobID string `json:"jobId"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil || resp.JobID == "" {
+		t.Fatalf("invalid export response: %v %s", err, rr.Body.String())
+	}
+
+	job := waitForExport(t, srv, resp.JobID)
+	if job.Status != exportStatusCompleted {
+		t.Fatalf("export failed: %+v", job)
+	}
+	if job.Rows != 2 {
+		t.Fatalf("expected 2 rows, got %d", job.Rows)
+	}
+	data, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatalf("failed to read export file: %v", err)
+	}
+	want := "level,user\nerror,alice\nerror,bob\n"
+	if string(data) != want {
+		t.Fatalf("unexpected export content:\nexpected: %q\n  actual: %q", want, string(data))
+	}
+}
+
+func TestExportStatusEndpoint(t *testing.T) {
+	payload := `{"level":"error"}` + "\n"
+	srv := exportServer(t, payload, t.TempDir())
+
+	rr := postJSON(t, srv, "/api/v1/sql-to-logsql", map[string]any{
+		"sql":    "SELECT * FROM logs",
+		"export": map[string]string{"destination": "out.jsonl"},
+	})
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("expected status 202, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp struct {
+		JobID string `json:"jobId"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid export response: %v", err)
+	}
+	waitForExport(t, srv, resp.JobID)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/export/"+resp.JobID, nil)
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), exportStatusCompleted) {
+		t.Fatalf("unexpected status response %d: %s", rec.Code, rec.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/export", nil)
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), resp.JobID) {
+		t.Fatalf("unexpected list response %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestExportDestinationValidation(t *testing.T) {
+	srv := &Server{exportDir: "/data/exports"}
+	if _, err := srv.exportDestinationPath("s3://bucket/path"); err == nil || !strings.Contains(err.Error(), "mount the bucket") {
+		t.Fatalf("expected object storage rejection, got %v", err)
+	}
+	if _, err := srv.exportDestinationPath("file:///tmp/out.csv"); err == nil {
+		t.Fatal("expected absolute path rejection")
+	}
+	if _, err := srv.exportDestinationPath("../../etc/passwd"); err == nil {
+		t.Fatal("expected traversal rejection")
+	}
+	if _, err := (&Server{}).exportDestinationPath("out.csv"); err == nil || !strings.Contains(err.Error(), "disabled") {
+		t.Fatalf("expected exports to be disabled without an exportDir, got %v", err)
+	}
+	path, err := srv.exportDestinationPath("reports/out.csv")
+	if err != nil || path != "/data/exports/reports/out.csv" {
+		t.Fatalf("unexpected result: %q %v", path, err)
+	}
+}
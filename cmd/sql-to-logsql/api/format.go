@@ -0,0 +1,65 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/sql/lexer"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/sql/parser"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/sql/render"
+)
+
+type formatRequest struct {
+	SQL string `json:"sql"`
+}
+
+type formatResponse struct {
+	SQL   string `json:"sql"`
+	Error string `json:"error,omitempty"`
+}
+
+// handleFormat parses the submitted SQL and returns the canonical rendering
+// produced by the render package. Useful for stored view hygiene and diffs:
+// semantically equal statements format to the same text.
+func (s *Server) handleFormat(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	defer r.Body.Close()
+
+	var req formatRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, formatResponse{Error: "invalid request payload"})
+		return
+	}
+	sqlText := strings.TrimSpace(req.SQL)
+	if sqlText == "" {
+		writeJSON(w, http.StatusBadRequest, formatResponse{Error: "sql query is required"})
+		return
+	}
+	formatted, err := formatSQL(sqlText)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, formatResponse{Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, formatResponse{SQL: formatted})
+}
+
+// formatSQL parses sql and renders it back in canonical form.
+func formatSQL(sql string) (string, error) {
+	l := lexer.New(sql)
+	p := parser.New(l)
+	stmt := p.ParseStatement()
+	if stmt == nil {
+		return "", errors.New("no statement parsed")
+	}
+	if perrs := p.Errors(); len(perrs) > 0 {
+		return "", fmt.Errorf("parse errors: %w", errors.Join(perrs...))
+	}
+	return render.Render(stmt)
+}
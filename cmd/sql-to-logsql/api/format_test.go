@@ -0,0 +1,47 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandleFormat(t *testing.T) {
+	srv, err := NewServer(Config{Tables: map[string]string{"logs": "*"}})
+	if err != nil {
+		t.Fatalf("NewServer error: %v", err)
+	}
+
+	body := `{"sql":"select   level,count(*) As total from logs group by level"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/format", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+	srv.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp formatResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid json response: %v", err)
+	}
+	want := "SELECT level, count(*) AS total FROM logs GROUP BY level"
+	if resp.SQL != want {
+		t.Fatalf("unexpected formatted SQL: %q, want %q", resp.SQL, want)
+	}
+}
+
+func TestHandleFormatSyntaxError(t *testing.T) {
+	srv, err := NewServer(Config{Tables: map[string]string{"logs": "*"}})
+	if err != nil {
+		t.Fatalf("NewServer error: %v", err)
+	}
+
+	body := `{"sql":"SELECT FROM WHERE"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/format", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+	srv.ServeHTTP(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
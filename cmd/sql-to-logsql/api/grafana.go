@@ -0,0 +1,191 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/logsql"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/vlogs"
+)
+
+// The Grafana adapter accepts JSON datasource queries (SQL plus a time range
+// and interval), injects the time range into the translated LogsQL and
+// returns the result as a data frame, so the Infinity datasource or a thin
+// plugin can chart VictoriaLogs data without knowing LogsQL.
+
+type grafanaQueryRequest struct {
+	SQL         string            `json:"sql"`
+	Variables   map[string]string `json:"variables,omitempty"`
+	From        json.RawMessage   `json:"from,omitempty"`
+	To          json.RawMessage   `json:"to,omitempty"`
+	IntervalMs  int64             `json:"intervalMs,omitempty"`
+	Endpoint    string            `json:"endpoint,omitempty"`
+	BearerToken string            `json:"bearerToken,omitempty"`
+}
+
+type grafanaField struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+type grafanaFrame struct {
+	Schema struct {
+		Fields []grafanaField `json:"fields"`
+	} `json:"schema"`
+	Data struct {
+		Values [][]any `json:"values"`
+	} `json:"data"`
+}
+
+type grafanaQueryResponse struct {
+	LogsQL string         `json:"logsql"`
+	Frames []grafanaFrame `json:"frames,omitempty"`
+	Error  string         `json:"error,omitempty"`
+}
+
+func (s *Server) handleGrafanaQuery(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	defer r.Body.Close()
+
+	var req grafanaQueryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, grafanaQueryResponse{Error: "invalid request payload"})
+		return
+	}
+	sqlText := strings.TrimSpace(req.SQL)
+	if sqlText == "" {
+		writeJSON(w, http.StatusBadRequest, grafanaQueryResponse{Error: "sql query is required"})
+		return
+	}
+	from, err := grafanaTime(req.From)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, grafanaQueryResponse{Error: fmt.Sprintf("invalid from value: %v", err)})
+		return
+	}
+	to, err := grafanaTime(req.To)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, grafanaQueryResponse{Error: fmt.Sprintf("invalid to value: %v", err)})
+		return
+	}
+
+	sqlText, err = substituteVariables(sqlText, req.Variables)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, grafanaQueryResponse{Error: err.Error()})
+		return
+	}
+	sqlText = expandTimeMacros(sqlText, from, to, req.IntervalMs)
+
+	statement, tables, hashes, err := processQuery(sqlText, s.sp, s.rewriters)
+	if err != nil {
+		log.Printf("ERROR: grafana query processing failed: %v", err)
+		writeJSON(w, http.StatusBadRequest, grafanaQueryResponse{Error: err.Error()})
+		return
+	}
+	if statement.LogsQL != "" {
+		statement.LogsQL = logsql.AddTimeFilter(statement.LogsQL, from, to)
+		if fields := s.maskedFields(r, req.BearerToken, tables); len(fields) > 0 {
+			statement.LogsQL = logsql.MaskFields(statement.LogsQL, fields)
+		}
+	}
+
+	resp := grafanaQueryResponse{LogsQL: statement.LogsQL}
+	data := []byte(statement.Data)
+	if statement.LogsQL != "" {
+		data, err = s.api.Execute(r.Context(), statement, vlogs.EndpointConfig{
+			Endpoint:    req.Endpoint,
+			BearerToken: req.BearerToken,
+			ClientLabel: requestClientLabel(r, ""),
+		})
+		if err != nil {
+			log.Printf("ERROR: grafana query execution failed: %v", err)
+			writeJSON(w, http.StatusBadGateway, grafanaQueryResponse{LogsQL: resp.LogsQL, Error: err.Error()})
+			return
+		}
+	}
+	frame, err := grafanaFrameFromJSONL(applyHashes(data, hashes))
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, grafanaQueryResponse{LogsQL: resp.LogsQL, Error: err.Error()})
+		return
+	}
+	resp.Frames = []grafanaFrame{frame}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// grafanaTime normalizes a Grafana time value to something LogsQL accepts.
+// Grafana sends epoch milliseconds; RFC3339 strings and relative offsets
+// like "-1h" are passed through unchanged.
+func grafanaTime(raw json.RawMessage) (string, error) {
+	if len(raw) == 0 {
+		return "", nil
+	}
+	var millis int64
+	if err := json.Unmarshal(raw, &millis); err == nil {
+		return time.UnixMilli(millis).UTC().Format(time.RFC3339), nil
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return "", fmt.Errorf("expected epoch milliseconds or string, got %s", raw)
+	}
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", nil
+	}
+	// Epoch milliseconds may also arrive as a string.
+	if millis, err := strconv.ParseInt(s, 10, 64); err == nil && millis > 1e10 {
+		return time.UnixMilli(millis).UTC().Format(time.RFC3339), nil
+	}
+	return s, nil
+}
+
+// grafanaFrameFromJSONL converts a JSONL payload into a single column-major
+// data frame with string-typed fields.
+func grafanaFrameFromJSONL(data []byte) (grafanaFrame, error) {
+	columns := make([]string, 0)
+	seen := make(map[string]struct{})
+	raws := make([]map[string]any, 0)
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		var raw map[string]any
+		if err := json.Unmarshal([]byte(line), &raw); err != nil {
+			return grafanaFrame{}, fmt.Errorf("failed to parse result row: %w", err)
+		}
+		for name := range raw {
+			if _, ok := seen[name]; !ok {
+				seen[name] = struct{}{}
+				columns = append(columns, name)
+			}
+		}
+		raws = append(raws, raw)
+	}
+	sort.Strings(columns)
+
+	var frame grafanaFrame
+	frame.Schema.Fields = make([]grafanaField, 0, len(columns))
+	frame.Data.Values = make([][]any, len(columns))
+	for i, name := range columns {
+		fieldType := "string"
+		if name == "_time" {
+			fieldType = "time"
+		}
+		frame.Schema.Fields = append(frame.Schema.Fields, grafanaField{Name: name, Type: fieldType})
+		values := make([]any, 0, len(raws))
+		for _, raw := range raws {
+			values = append(values, raw[name])
+		}
+		frame.Data.Values[i] = values
+	}
+	return frame, nil
+}
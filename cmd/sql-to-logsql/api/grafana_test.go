@@ -0,0 +1,100 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGrafanaQueryTimeRange(t *testing.T) {
+	srv, err := NewServer(Config{Endpoint: "http://victoria", Tables: map[string]string{"logs": "*"}})
+	if err != nil {
+		t.Fatalf("NewServer error: %v", err)
+	}
+	var sentQuery string
+	srv.setHTTPClient(&http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if err := req.ParseForm(); err != nil {
+				t.Fatalf("failed to parse form: %v", err)
+			}
+			sentQuery = req.Form.Get("query")
+			resp := &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewBufferString("{\"_time\":\"2024-01-01T00:00:00Z\",\"_msg\":\"hello\"}\n")),
+				Header:     make(http.Header),
+			}
+			resp.Header.Set("Content-Type", "application/json")
+			return resp, nil
+		}),
+	})
+
+	body := `{"sql":"SELECT * FROM logs","from":1704067200000,"to":1704070800000}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/grafana/query", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+	srv.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	wantQuery := "_time:[2024-01-01T00:00:00Z, 2024-01-01T01:00:00Z]"
+	if sentQuery != wantQuery {
+		t.Fatalf("unexpected query sent: %q, want %q", sentQuery, wantQuery)
+	}
+
+	var resp grafanaQueryResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid json response: %v", err)
+	}
+	if len(resp.Frames) != 1 {
+		t.Fatalf("expected 1 frame, got %d", len(resp.Frames))
+	}
+	frame := resp.Frames[0]
+	if len(frame.Schema.Fields) != 2 {
+		t.Fatalf("expected 2 fields, got %+v", frame.Schema.Fields)
+	}
+	if frame.Schema.Fields[1].Name != "_time" || frame.Schema.Fields[1].Type != "time" {
+		t.Fatalf("expected _time field typed as time, got %+v", frame.Schema.Fields[1])
+	}
+	if len(frame.Data.Values) != 2 || len(frame.Data.Values[0]) != 1 {
+		t.Fatalf("unexpected frame values: %+v", frame.Data.Values)
+	}
+}
+
+func TestAddTimeFilterPreservesPipes(t *testing.T) {
+	srv, err := NewServer(Config{Endpoint: "http://victoria", Tables: map[string]string{"logs": "*"}})
+	if err != nil {
+		t.Fatalf("NewServer error: %v", err)
+	}
+	var sentQuery string
+	srv.setHTTPClient(&http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if err := req.ParseForm(); err != nil {
+				t.Fatalf("failed to parse form: %v", err)
+			}
+			sentQuery = req.Form.Get("query")
+			resp := &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewBufferString("")),
+				Header:     make(http.Header),
+			}
+			return resp, nil
+		}),
+	})
+
+	body := `{"sql":"SELECT level, COUNT(*) AS total FROM logs GROUP BY level","from":"-1h","to":"now"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/grafana/query", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+	srv.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if !strings.HasPrefix(sentQuery, "_time:[-1h, now]") {
+		t.Fatalf("expected time filter prefix, got %q", sentQuery)
+	}
+	if !strings.Contains(sentQuery, "| stats by (level)") {
+		t.Fatalf("expected stats pipe preserved, got %q", sentQuery)
+	}
+}
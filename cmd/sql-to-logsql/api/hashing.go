@@ -0,0 +1,119 @@
+package api
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/sql/ast"
+)
+
+// LogsQL has no hashing primitives, so MD5/SHA1/SHA256/HASH projections are
+// rewritten to plain field copies before translation and the digests are
+// computed here after the rows come back from VictoriaLogs.
+
+type hashedField struct {
+	// Field is the output column holding the value to hash.
+	Field string
+	// Algo is the lowercase function name (md5, sha1, sha256, hash).
+	Algo string
+}
+
+// extractHashProjections replaces top-level MD5/SHA1/SHA256/HASH projections
+// on a single field with a copy of that field and returns which output
+// columns need hashing after execution.
+func extractHashProjections(stmt ast.Statement) []hashedField {
+	sel, ok := stmt.(*ast.SelectStatement)
+	if !ok {
+		return nil
+	}
+	var specs []hashedField
+	for i := range sel.Columns {
+		col := &sel.Columns[i]
+		fn, ok := col.Expr.(*ast.FuncCall)
+		if !ok || len(fn.Name.Parts) == 0 || len(fn.Args) != 1 || fn.Over != nil {
+			continue
+		}
+		algo := strings.ToLower(fn.Name.Parts[len(fn.Name.Parts)-1])
+		switch algo {
+		case "md5", "sha1", "sha256", "hash":
+		default:
+			continue
+		}
+		ident, ok := fn.Args[0].(*ast.Identifier)
+		if !ok || len(ident.Parts) == 0 {
+			continue
+		}
+		alias := strings.TrimSpace(col.Alias)
+		if alias == "" {
+			alias = algo + "_" + ident.Parts[len(ident.Parts)-1]
+		}
+		// CONCAT(field) copies the value into the alias via a format pipe,
+		// keeping the source field intact for other projections.
+		col.Expr = &ast.FuncCall{
+			Name: ast.Identifier{Parts: []string{"CONCAT"}},
+			Args: []ast.Expr{ident},
+		}
+		col.Alias = alias
+		specs = append(specs, hashedField{Field: alias, Algo: algo})
+	}
+	return specs
+}
+
+// applyHashes replaces the configured columns of the JSONL result with hex
+// digests of their values.
+func applyHashes(data []byte, specs []hashedField) []byte {
+	if len(specs) == 0 || len(data) == 0 {
+		return data
+	}
+	var out bytes.Buffer
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		trimmed := bytes.TrimSpace(line)
+		if len(trimmed) == 0 {
+			continue
+		}
+		row := map[string]any{}
+		if err := json.Unmarshal(trimmed, &row); err != nil {
+			out.Write(trimmed)
+			out.WriteByte('\n')
+			continue
+		}
+		for _, spec := range specs {
+			value, ok := row[spec.Field]
+			if !ok {
+				continue
+			}
+			row[spec.Field] = hashValue(fmt.Sprint(value), spec.Algo)
+		}
+		encoded, err := json.Marshal(row)
+		if err != nil {
+			out.Write(trimmed)
+			out.WriteByte('\n')
+			continue
+		}
+		out.Write(encoded)
+		out.WriteByte('\n')
+	}
+	return out.Bytes()
+}
+
+func hashValue(value, algo string) string {
+	data := []byte(value)
+	switch algo {
+	case "md5":
+		return fmt.Sprintf("%x", md5.Sum(data))
+	case "sha1":
+		return fmt.Sprintf("%x", sha1.Sum(data))
+	case "sha256":
+		return fmt.Sprintf("%x", sha256.Sum256(data))
+	default:
+		h := fnv.New64a()
+		_, _ = h.Write(data)
+		return fmt.Sprintf("%016x", h.Sum64())
+	}
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandleQueryHashedProjection(t *testing.T) {
+	srv, err := NewServer(Config{Endpoint: "http://victoria", Tables: map[string]string{"logs": "*"}})
+	if err != nil {
+		t.Fatalf("NewServer error: %v", err)
+	}
+	srv.setHTTPClient(&http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if err := req.ParseForm(); err != nil {
+				t.Fatalf("failed to parse form: %v", err)
+			}
+			if got := req.Form.Get("query"); !strings.Contains(got, `format "<user>" as user_hash`) {
+				t.Fatalf("unexpected query sent: %q", got)
+			}
+			body := `{"user":"alice","user_hash":"alice"}` + "\n"
+			resp := &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewBufferString(body)),
+				Header:     make(http.Header),
+			}
+			return resp, nil
+		}),
+	})
+
+	reqBody := `{"sql":"SELECT user, SHA256(user) AS user_hash FROM logs"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/sql-to-logsql", strings.NewReader(reqBody))
+	rr := httptest.NewRecorder()
+	srv.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp queryResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid json response: %v", err)
+	}
+	var row map[string]string
+	if err := json.Unmarshal([]byte(strings.TrimSpace(resp.Data)), &row); err != nil {
+		t.Fatalf("invalid data row: %v", err)
+	}
+	if row["user"] != "alice" {
+		t.Fatalf("source field must stay intact, got %q", row["user"])
+	}
+	want := fmt.Sprintf("%x", sha256.Sum256([]byte("alice")))
+	if row["user_hash"] != want {
+		t.Fatalf("expected sha256 digest %q, got %q", want, row["user_hash"])
+	}
+}
+
+func TestApplyHashesSkipsMissingFields(t *testing.T) {
+	data := []byte(`{"level":"error"}` + "\n")
+	got := applyHashes(data, []hashedField{{Field: "user", Algo: "md5"}})
+	if string(got) != string(data) {
+		t.Fatalf("rows without the field must pass through, got %q", got)
+	}
+}
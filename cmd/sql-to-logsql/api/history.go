@@ -0,0 +1,89 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Query history keeps the last N queries per session so the web UI can offer
+// recall and re-run of previous explorations. It is opt-in via the
+// historySize config knob and lives in memory only, like the rest of the
+// session state.
+
+// historyEntry records one query attempt with its translation and outcome.
+type historyEntry struct {
+	SQL        string `json:"sql"`
+	LogsQL     string `json:"logsql,omitempty"`
+	Error      string `json:"error,omitempty"`
+	StartedAt  string `json:"startedAt"`
+	DurationMs int64  `json:"durationMs"`
+	Bytes      int    `json:"bytes,omitempty"`
+}
+
+// queryHistory is a bounded ring of history entries, newest first.
+type queryHistory struct {
+	mu      sync.Mutex
+	entries []historyEntry
+	size    int
+}
+
+func newQueryHistory(size int) *queryHistory {
+	if size <= 0 {
+		return nil
+	}
+	return &queryHistory{size: size}
+}
+
+// add prepends an entry, dropping the oldest one beyond the configured size.
+// A nil history (history disabled) ignores the call.
+func (h *queryHistory) add(entry historyEntry) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.entries = append([]historyEntry{entry}, h.entries...)
+	if len(h.entries) > h.size {
+		h.entries = h.entries[:h.size]
+	}
+}
+
+// snapshot returns a copy of the entries, newest first.
+func (h *queryHistory) snapshot() []historyEntry {
+	if h == nil {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	out := make([]historyEntry, len(h.entries))
+	copy(out, h.entries)
+	return out
+}
+
+type historyResponse struct {
+	Enabled bool           `json:"enabled"`
+	Entries []historyEntry `json:"entries"`
+}
+
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	resp := historyResponse{Enabled: s.historySize > 0, Entries: []historyEntry{}}
+	if resp.Enabled {
+		sess := s.session(r, strings.TrimSpace(r.URL.Query().Get("bearerToken")))
+		if entries := sess.history.snapshot(); entries != nil {
+			resp.Entries = entries
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// historyTimestamp formats a history entry time consistently.
+func historyTimestamp(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,112 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func historyServer(t *testing.T, size int) *Server {
+	t.Helper()
+	srv, err := NewServer(Config{
+		Endpoint:    "http://victoria",
+		Tables:      map[string]string{"logs": "*"},
+		HistorySize: size,
+	})
+	if err != nil {
+		t.Fatalf("NewServer error: %v", err)
+	}
+	srv.setHTTPClient(&http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			resp := &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewBufferString(`{"status":"ok"}`)),
+				Header:     make(http.Header),
+			}
+			resp.Header.Set("Content-Type", "application/json")
+			return resp, nil
+		}),
+	})
+	return srv
+}
+
+func fetchHistory(t *testing.T, srv *Server) historyResponse {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/history", nil)
+	rr := httptest.NewRecorder()
+	srv.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("history request failed with status %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp historyResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid history response: %v", err)
+	}
+	return resp
+}
+
+func TestQueryHistoryRecordsAndTrims(t *testing.T) {
+	srv := historyServer(t, 2)
+
+	for _, sql := range []string{
+		"SELECT * FROM logs WHERE level = 'debug'",
+		"SELECT * FROM logs WHERE level = 'warn'",
+		"SELECT * FROM logs WHERE level = 'error'",
+	} {
+		rr := postJSON(t, srv, "/api/v1/sql-to-logsql", map[string]string{"sql": sql})
+		if rr.Code != http.StatusOK {
+			t.Fatalf("query failed with status %d: %s", rr.Code, rr.Body.String())
+		}
+	}
+
+	resp := fetchHistory(t, srv)
+	if !resp.Enabled {
+		t.Fatal("expected history to be enabled")
+	}
+	if len(resp.Entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(resp.Entries))
+	}
+	if resp.Entries[0].LogsQL != "level:error" || resp.Entries[1].LogsQL != "level:warn" {
+		t.Fatalf("unexpected entries: %+v", resp.Entries)
+	}
+	if resp.Entries[0].Error != "" || resp.Entries[0].StartedAt == "" {
+		t.Fatalf("unexpected entry fields: %+v", resp.Entries[0])
+	}
+}
+
+func TestQueryHistoryRecordsErrors(t *testing.T) {
+	srv := historyServer(t, 5)
+
+	rr := postJSON(t, srv, "/api/v1/sql-to-logsql", map[string]string{"sql": "SELECT * FROM missing"})
+	if rr.Code == http.StatusOK {
+		t.Fatalf("expected query against unknown table to fail, got %d", rr.Code)
+	}
+
+	resp := fetchHistory(t, srv)
+	if len(resp.Entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(resp.Entries))
+	}
+	if resp.Entries[0].Error == "" {
+		t.Fatalf("expected entry to record the error, got %+v", resp.Entries[0])
+	}
+}
+
+func TestQueryHistoryDisabled(t *testing.T) {
+	srv := historyServer(t, 0)
+
+	rr := postJSON(t, srv, "/api/v1/sql-to-logsql", map[string]string{"sql": "SELECT * FROM logs"})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("query failed with status %d: %s", rr.Code, rr.Body.String())
+	}
+
+	resp := fetchHistory(t, srv)
+	if resp.Enabled {
+		t.Fatal("expected history to be disabled")
+	}
+	if len(resp.Entries) != 0 {
+		t.Fatalf("expected no entries, got %d", len(resp.Entries))
+	}
+}
@@ -0,0 +1,366 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/logsql"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/vlogs"
+)
+
+// Background jobs decouple query execution from the HTTP request so queries
+// that outlive the interactive write timeout still complete: POST
+// /api/v1/jobs translates the query synchronously (errors surface
+// immediately), runs it in a goroutine and returns a job ID; GET
+// /api/v1/jobs/{id} polls status and pages through the buffered result.
+
+const (
+	jobStatusRunning   = "running"
+	jobStatusCompleted = "completed"
+	jobStatusFailed    = "failed"
+	jobStatusCanceled  = "canceled"
+)
+
+// defaultJobPageSize bounds how many result rows a single poll returns when
+// the client does not ask for a limit.
+const defaultJobPageSize = 1000
+
+// jobTTL is how long a finished job's buffered result is kept for polling
+// clients. Results can be large, so jobs nobody reads are dropped instead of
+// being held until the server restarts.
+const jobTTL = 10 * time.Minute
+
+// maxQueryJobs bounds the number of tracked jobs so a client cannot grow
+// server memory without limit by submitting queries and never fetching them.
+const maxQueryJobs = 100
+
+type queryJob struct {
+	id         string
+	status     string
+	logsQL     string
+	warnings   []string
+	err        string
+	lines      []string
+	startedAt  string
+	finishedAt string
+	lastRead   time.Time
+	cancel     context.CancelFunc
+}
+
+type queryJobs struct {
+	mu   sync.Mutex
+	jobs map[string]*queryJob
+}
+
+func newQueryJobID() string {
+	var buf [8]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		return "job_fallback"
+	}
+	return "job_" + hex.EncodeToString(buf[:])
+}
+
+func (q *queryJobs) add(job *queryJob) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.jobs == nil {
+		q.jobs = make(map[string]*queryJob)
+	}
+	q.evictLocked(time.Now())
+	if len(q.jobs) >= maxQueryJobs {
+		return fmt.Errorf("too many active jobs; fetch or delete finished jobs first")
+	}
+	job.lastRead = time.Now()
+	q.jobs[job.id] = job
+	return nil
+}
+
+// evictLocked drops finished jobs whose results nobody read for jobTTL.
+// Running jobs stay until they finish; DELETE cancels them explicitly.
+func (q *queryJobs) evictLocked(now time.Time) {
+	for id, job := range q.jobs {
+		if job.status == jobStatusRunning {
+			continue
+		}
+		if now.Sub(job.lastRead) > jobTTL {
+			delete(q.jobs, id)
+		}
+	}
+}
+
+func (q *queryJobs) update(id string, fn func(*queryJob)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if job, ok := q.jobs[id]; ok {
+		fn(job)
+		job.lastRead = time.Now()
+	}
+}
+
+func (q *queryJobs) get(id string) (*queryJob, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	job, ok := q.jobs[id]
+	return job, ok
+}
+
+func (q *queryJobs) remove(id string) (*queryJob, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	job, ok := q.jobs[id]
+	delete(q.jobs, id)
+	return job, ok
+}
+
+type jobStatusResponse struct {
+	ID         string            `json:"id"`
+	Status     string            `json:"status"`
+	LogsQL     string            `json:"logsql,omitempty"`
+	Warnings   []string          `json:"warnings,omitempty"`
+	Error      string            `json:"error,omitempty"`
+	StartedAt  string            `json:"startedAt,omitempty"`
+	FinishedAt string            `json:"finishedAt,omitempty"`
+	Rows       int               `json:"rows"`
+	Offset     int               `json:"offset,omitempty"`
+	Data       []json.RawMessage `json:"data,omitempty"`
+}
+
+func (s *Server) handleJobs(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		s.handleJobList(w)
+		return
+	case http.MethodPost:
+	default:
+		w.Header().Set("Allow", strings.Join([]string{http.MethodGet, http.MethodPost}, ", "))
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	defer r.Body.Close()
+
+	var req queryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		if requestTooLarge(err) {
+			writeJSON(w, http.StatusRequestEntityTooLarge, queryResponse{Error: "request body exceeds the configured maximum size"})
+			return
+		}
+		writeJSON(w, http.StatusBadRequest, queryResponse{Error: "invalid request payload"})
+		return
+	}
+	sqlText := strings.TrimSpace(req.SQL)
+	if sqlText == "" {
+		writeJSON(w, http.StatusBadRequest, queryResponse{Error: "sql query is required"})
+		return
+	}
+	if msg := s.sqlLengthError(sqlText); msg != "" {
+		writeJSON(w, http.StatusBadRequest, queryResponse{Error: msg})
+		return
+	}
+	sqlText, err := substituteVariables(sqlText, req.Variables)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, queryResponse{Error: err.Error()})
+		return
+	}
+	sqlText = expandTimeMacros(sqlText, strings.TrimSpace(req.From), strings.TrimSpace(req.To), req.IntervalMs)
+
+	sess := s.session(r, req.BearerToken)
+	sp := s.sp.WithSessionViews(sess.views).WithSessionOptions(sess.options)
+	features, err := s.requestFeatures(req)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, queryResponse{Error: err.Error()})
+		return
+	}
+	if len(features) > 0 {
+		sp = sp.WithFeatures(features)
+	}
+	if tz := strings.TrimSpace(req.TimeZone); tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, queryResponse{Error: fmt.Sprintf("unknown time zone %q", tz)})
+			return
+		}
+		sp = sp.WithTimeZone(loc)
+	} else if _, ok := sess.options.Get("time_zone"); !ok && s.timeZone != nil {
+		sp = sp.WithTimeZone(s.timeZone)
+	}
+	statement, tables, _, err := processQuery(sqlText, sp, s.rewriters)
+	if err != nil {
+		log.Printf("ERROR: job query processing failed: %v", err)
+		writeJSON(w, http.StatusBadRequest, queryResponse{Error: err.Error()})
+		return
+	}
+	if fields := s.maskedFields(r, req.BearerToken, tables); len(fields) > 0 && statement.LogsQL != "" {
+		statement.LogsQL = logsql.MaskFields(statement.LogsQL, fields)
+	}
+	if statement.LogsQL != "" && !req.BypassGuardrails {
+		guardrails := s.guardrails
+		if n, ok := sess.options.MaxRows(); ok {
+			guardrails.MaxRows = n
+		}
+		statement.LogsQL, err = guardrails.Apply(statement.LogsQL)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, queryResponse{Error: err.Error()})
+			return
+		}
+	}
+	ec, err := s.api.ResolveEndpoint(vlogs.EndpointConfig{
+		Endpoint:    req.Endpoint,
+		BearerToken: req.BearerToken,
+		ClientLabel: requestClientLabel(r, req.ClientLabel),
+	})
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, queryResponse{Error: err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	job := &queryJob{
+		id:        newQueryJobID(),
+		status:    jobStatusRunning,
+		logsQL:    statement.LogsQL,
+		warnings:  statement.Warnings,
+		startedAt: historyTimestamp(time.Now()),
+		cancel:    cancel,
+	}
+	if err := s.jobs.add(job); err != nil {
+		cancel()
+		writeJSON(w, http.StatusTooManyRequests, queryResponse{Error: err.Error()})
+		return
+	}
+	go s.runJob(ctx, job.id, statement, ec)
+
+	writeJSON(w, http.StatusAccepted, queryResponse{LogsQL: statement.LogsQL, Warnings: statement.Warnings, JobID: job.id})
+}
+
+func (s *Server) runJob(ctx context.Context, id string, statement *logsql.StatementInfo, ec vlogs.EndpointConfig) {
+	data, err := s.executeJobStatement(ctx, statement, ec)
+	finished := historyTimestamp(time.Now())
+	s.jobs.update(id, func(job *queryJob) {
+		job.finishedAt = finished
+		if err != nil {
+			if errors.Is(err, context.Canceled) || job.status == jobStatusCanceled {
+				job.status = jobStatusCanceled
+				return
+			}
+			job.status = jobStatusFailed
+			job.err = err.Error()
+			return
+		}
+		job.status = jobStatusCompleted
+		for _, line := range strings.Split(string(data), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				job.lines = append(job.lines, line)
+			}
+		}
+	})
+}
+
+// executeJobStatement runs a statement without the interactive client
+// timeout: SELECT results are drained from the streaming endpoint, other
+// statement kinds go through the regular execution path.
+func (s *Server) executeJobStatement(ctx context.Context, statement *logsql.StatementInfo, ec vlogs.EndpointConfig) ([]byte, error) {
+	if statement.Kind == logsql.StatementTypeSelect && statement.LogsQL != "" && ec.Endpoint != "" {
+		stream, err := s.api.QueryStream(ctx, statement.LogsQL, ec)
+		if err != nil {
+			return nil, err
+		}
+		defer stream.Close()
+		return s.api.ReadResult(stream)
+	}
+	return s.api.Execute(ctx, statement, ec)
+}
+
+func (s *Server) handleJobStatus(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/api/v1/jobs/")
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodDelete:
+		job, ok := s.jobs.remove(id)
+		if !ok {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": fmt.Sprintf("unknown job %q", id)})
+			return
+		}
+		if job.cancel != nil {
+			job.cancel()
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"status": "canceled"})
+		return
+	default:
+		w.Header().Set("Allow", strings.Join([]string{http.MethodGet, http.MethodDelete}, ", "))
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.jobs.mu.Lock()
+	s.jobs.evictLocked(time.Now())
+	job, ok := s.jobs.jobs[id]
+	if !ok {
+		s.jobs.mu.Unlock()
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": fmt.Sprintf("unknown job %q", id)})
+		return
+	}
+	job.lastRead = time.Now()
+	resp := jobStatusResponse{
+		ID:         job.id,
+		Status:     job.status,
+		LogsQL:     job.logsQL,
+		Warnings:   job.warnings,
+		Error:      job.err,
+		StartedAt:  job.startedAt,
+		FinishedAt: job.finishedAt,
+		Rows:       len(job.lines),
+	}
+	if job.status == jobStatusCompleted {
+		offset := parseNonNegativeInt(r.URL.Query().Get("offset"), 0)
+		limit := parseNonNegativeInt(r.URL.Query().Get("limit"), defaultJobPageSize)
+		resp.Offset = offset
+		for i := offset; i < len(job.lines) && i < offset+limit; i++ {
+			resp.Data = append(resp.Data, json.RawMessage(job.lines[i]))
+		}
+	}
+	s.jobs.mu.Unlock()
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleJobList serves GET /api/v1/jobs as a status overview, newest first.
+func (s *Server) handleJobList(w http.ResponseWriter) {
+	s.jobs.mu.Lock()
+	s.jobs.evictLocked(time.Now())
+	out := make([]jobStatusResponse, 0, len(s.jobs.jobs))
+	for _, job := range s.jobs.jobs {
+		out = append(out, jobStatusResponse{
+			ID:         job.id,
+			Status:     job.status,
+			LogsQL:     job.logsQL,
+			Error:      job.err,
+			StartedAt:  job.startedAt,
+			FinishedAt: job.finishedAt,
+			Rows:       len(job.lines),
+		})
+	}
+	s.jobs.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].StartedAt > out[j].StartedAt })
+	writeJSON(w, http.StatusOK, out)
+}
+
+func parseNonNegativeInt(raw string, fallback int) int {
+	if raw == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 0 {
+		return fallback
+	}
+	return n
+}
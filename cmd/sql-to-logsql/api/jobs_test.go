@@ -0,0 +1,130 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func waitForJob(t *testing.T, srv *Server, id string) string {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		job, ok := srv.jobs.get(id)
+		if !ok {
+			t.Fatalf("job %q disappeared", id)
+		}
+		srv.jobs.mu.Lock()
+		status := job.status
+		srv.jobs.mu.Unlock()
+		if status != jobStatusRunning {
+			return status
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("job %q did not finish in time", id)
+	return ""
+}
+
+func TestBackgroundJobLifecycle(t *testing.T) {
+	payload := `{"level":"error","user":"alice"}` + "\n" +
+		`{"level":"error","user":"bob"}` + "\n" +
+		`{"level":"error","user":"carol"}` + "\n"
+	srv := exportServer(t, payload, t.TempDir())
+
+	rr := postJSON(t, srv, "/api/v1/jobs", map[string]string{
+		"sql": "SELECT * FROM logs WHERE level = 'error'",
+	})
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("expected status 202, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var created struct {
+		JobID  string `json:"jobId"`
+		LogsQL string `json:"logsql"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &created); err != nil || created.JobID == "" {
+		t.Fatalf("invalid job response: %v %s", err, rr.Body.String())
+	}
+	if created.LogsQL != "level:error" {
+		t.Fatalf("unexpected LogsQL: %s", created.LogsQL)
+	}
+
+	if status := waitForJob(t, srv, created.JobID); status != jobStatusCompleted {
+		t.Fatalf("expected completed job, got %s", status)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/jobs/"+created.JobID+"?offset=1&limit=1", nil)
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status request failed with %d: %s", rec.Code, rec.Body.String())
+	}
+	var status jobStatusResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+		t.Fatalf("invalid status response: %v", err)
+	}
+	if status.Status != jobStatusCompleted || status.Rows != 3 {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+	if len(status.Data) != 1 || !strings.Contains(string(status.Data[0]), "bob") {
+		t.Fatalf("unexpected page: %+v", status.Data)
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/v1/jobs/"+created.JobID, nil)
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("delete failed with %d: %s", rec.Code, rec.Body.String())
+	}
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/jobs/"+created.JobID, nil)
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 after delete, got %d", rec.Code)
+	}
+}
+
+func TestBackgroundJobEviction(t *testing.T) {
+	srv := exportServer(t, `{"level":"error"}`+"\n", t.TempDir())
+
+	rr := postJSON(t, srv, "/api/v1/jobs", map[string]string{
+		"sql": "SELECT * FROM logs",
+	})
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("expected status 202, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var created struct {
+		JobID string `json:"jobId"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &created); err != nil || created.JobID == "" {
+		t.Fatalf("invalid job response: %v %s", err, rr.Body.String())
+	}
+	if status := waitForJob(t, srv, created.JobID); status != jobStatusCompleted {
+		t.Fatalf("expected completed job, got %s", status)
+	}
+
+	// A finished job nobody reads is dropped once its TTL passes.
+	srv.jobs.mu.Lock()
+	srv.jobs.jobs[created.JobID].lastRead = time.Now().Add(-jobTTL - time.Minute)
+	srv.jobs.mu.Unlock()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/jobs/"+created.JobID, nil)
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an evicted job, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestBackgroundJobTranslationErrorIsSynchronous(t *testing.T) {
+	srv := exportServer(t, "", t.TempDir())
+	rr := postJSON(t, srv, "/api/v1/jobs", map[string]string{
+		"sql": "SELECT * FROM missing",
+	})
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown table, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Grafana-style macros supported inside the SQL text. They are expanded
+// textually before lexing, using the time range and interval supplied with
+// the request, so dashboard queries can be written once and reused across
+// time ranges:
+//
+//	$__timeFilter(col)  -> col BETWEEN '<from>' AND '<to>'
+//	$__timeFrom()       -> '<from>'
+//	$__timeTo()         -> '<to>'
+//	$__interval         -> '<interval>'   (e.g. '30s')
+//	$__interval_ms      -> <milliseconds>
+var (
+	timeFilterMacroRe = regexp.MustCompile(`\$__timeFilter\(\s*([A-Za-z_][A-Za-z0-9_.]*)\s*\)`)
+	timeFromMacroRe   = regexp.MustCompile(`\$__timeFrom\(\s*\)`)
+	timeToMacroRe     = regexp.MustCompile(`\$__timeTo\(\s*\)`)
+	intervalMsMacroRe = regexp.MustCompile(`\$__interval_ms\b`)
+	intervalMacroRe   = regexp.MustCompile(`\$__interval\b`)
+)
+
+// expandTimeMacros replaces Grafana-style time macros in sql. Macros whose
+// inputs are missing from the request (an empty time range, a zero interval)
+// are left untouched so the parser reports them instead of producing a
+// silently wrong query.
+func expandTimeMacros(sql, from, to string, intervalMs int64) string {
+	if from != "" && to != "" {
+		sql = timeFilterMacroRe.ReplaceAllString(sql,
+			fmt.Sprintf("$1 BETWEEN '%s' AND '%s'", from, to))
+	}
+	if from != "" {
+		sql = timeFromMacroRe.ReplaceAllString(sql, "'"+from+"'")
+	}
+	if to != "" {
+		sql = timeToMacroRe.ReplaceAllString(sql, "'"+to+"'")
+	}
+	if intervalMs > 0 {
+		sql = intervalMsMacroRe.ReplaceAllString(sql, fmt.Sprintf("%d", intervalMs))
+		sql = intervalMacroRe.ReplaceAllString(sql, "'"+formatInterval(intervalMs)+"'")
+	}
+	return sql
+}
+
+// formatInterval renders an interval in the short duration form LogsQL and
+// Grafana use ("500ms", "30s", "5m", "1h").
+func formatInterval(intervalMs int64) string {
+	d := time.Duration(intervalMs) * time.Millisecond
+	switch {
+	case d < time.Second:
+		return fmt.Sprintf("%dms", intervalMs)
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	case d%time.Minute == 0:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	case d%time.Second == 0:
+		return fmt.Sprintf("%ds", d/time.Second)
+	default:
+		return strings.TrimSuffix(d.String(), "0s")
+	}
+}
@@ -0,0 +1,73 @@
+package api
+
+import "testing"
+
+func TestExpandTimeMacros(t *testing.T) {
+	tests := []struct {
+		name       string
+		sql        string
+		from       string
+		to         string
+		intervalMs int64
+		want       string
+	}{
+		{
+			name: "time filter",
+			sql:  "SELECT * FROM logs WHERE $__timeFilter(_time)",
+			from: "2024-01-01T00:00:00Z",
+			to:   "2024-01-01T01:00:00Z",
+			want: "SELECT * FROM logs WHERE _time BETWEEN '2024-01-01T00:00:00Z' AND '2024-01-01T01:00:00Z'",
+		},
+		{
+			name: "time from and to",
+			sql:  "SELECT * FROM logs WHERE _time >= $__timeFrom() AND _time < $__timeTo()",
+			from: "-1h",
+			to:   "now",
+			want: "SELECT * FROM logs WHERE _time >= '-1h' AND _time < 'now'",
+		},
+		{
+			name:       "interval",
+			sql:        "SELECT COUNT(*) FROM logs GROUP BY $__interval",
+			intervalMs: 30000,
+			want:       "SELECT COUNT(*) FROM logs GROUP BY '30s'",
+		},
+		{
+			name:       "interval milliseconds",
+			sql:        "SELECT $__interval_ms FROM logs",
+			intervalMs: 500,
+			want:       "SELECT 500 FROM logs",
+		},
+		{
+			name: "missing range leaves macro untouched",
+			sql:  "SELECT * FROM logs WHERE $__timeFilter(_time)",
+			want: "SELECT * FROM logs WHERE $__timeFilter(_time)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := expandTimeMacros(tt.sql, tt.from, tt.to, tt.intervalMs)
+			if got != tt.want {
+				t.Fatalf("expandTimeMacros() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatInterval(t *testing.T) {
+	tests := []struct {
+		intervalMs int64
+		want       string
+	}{
+		{500, "500ms"},
+		{1000, "1s"},
+		{30000, "30s"},
+		{300000, "5m"},
+		{3600000, "1h"},
+		{90000, "90s"},
+	}
+	for _, tt := range tests {
+		if got := formatInterval(tt.intervalMs); got != tt.want {
+			t.Fatalf("formatInterval(%d) = %q, want %q", tt.intervalMs, got, tt.want)
+		}
+	}
+}
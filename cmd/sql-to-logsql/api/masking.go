@@ -0,0 +1,81 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/logsql"
+)
+
+// Column masking: fields listed per table in the config are overwritten with
+// a redaction marker in every translated query that touches the table,
+// unless the caller presents one of the allow-listed bypass tokens in the
+// X-Masking-Token header or as its bearer token.
+
+// maskedFields returns the union of masked fields for the given tables, or
+// nil when masking does not apply to the request.
+func (s *Server) maskedFields(r *http.Request, bearerToken string, tables []string) []string {
+	if len(s.masking) == 0 {
+		return nil
+	}
+	if s.maskingBypassed(r, bearerToken) {
+		return nil
+	}
+	return s.maskedFieldsFor(tables)
+}
+
+// maskedFieldsFor returns the union of masked fields for the given tables.
+func (s *Server) maskedFieldsFor(tables []string) []string {
+	seen := make(map[string]struct{})
+	fields := make([]string, 0)
+	for _, table := range tables {
+		if table == logsql.ViewSentinel {
+			// A view's stored LogsQL no longer names its source tables, so a
+			// statement reading through one gets every configured field
+			// masked rather than letting the view launder a masked table.
+			return s.allMaskedFields()
+		}
+		for _, field := range s.masking[table] {
+			if _, ok := seen[field]; ok {
+				continue
+			}
+			seen[field] = struct{}{}
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// allMaskedFields returns the sorted union of masked fields across every
+// configured table.
+func (s *Server) allMaskedFields() []string {
+	seen := make(map[string]struct{})
+	fields := make([]string, 0)
+	for _, tableFields := range s.masking {
+		for _, field := range tableFields {
+			if _, ok := seen[field]; ok {
+				continue
+			}
+			seen[field] = struct{}{}
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+func (s *Server) maskingBypassed(r *http.Request, bearerToken string) bool {
+	if len(s.maskingBypassTokens) == 0 {
+		return false
+	}
+	token := strings.TrimSpace(r.Header.Get("X-Masking-Token"))
+	if token == "" {
+		token = strings.TrimSpace(bearerToken)
+	}
+	if token == "" {
+		return false
+	}
+	_, ok := s.maskingBypassTokens[token]
+	return ok
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func maskingTestServer(t *testing.T) *Server {
+	t.Helper()
+	srv, err := NewServer(Config{
+		Tables:              map[string]string{"logs": "*"},
+		Masking:             map[string][]string{"logs": {"email", "ssn"}},
+		MaskingBypassTokens: []string{"secret-token"},
+	})
+	if err != nil {
+		t.Fatalf("NewServer error: %v", err)
+	}
+	return srv
+}
+
+func translateLogsQL(t *testing.T, srv *Server, body string, header http.Header) string {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/sql-to-logsql", strings.NewReader(body))
+	for name, values := range header {
+		for _, v := range values {
+			req.Header.Add(name, v)
+		}
+	}
+	rr := httptest.NewRecorder()
+	srv.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp struct {
+		LogsQL string `json:"logsql"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid json response: %v", err)
+	}
+	return resp.LogsQL
+}
+
+func TestMaskingAppliesToConfiguredTable(t *testing.T) {
+	srv := maskingTestServer(t)
+	logsQL := translateLogsQL(t, srv, `{"sql":"SELECT * FROM logs WHERE level = 'error'"}`, nil)
+	if !strings.Contains(logsQL, `format "*****" as email`) || !strings.Contains(logsQL, `format "*****" as ssn`) {
+		t.Fatalf("expected masking pipes in LogsQL, got %q", logsQL)
+	}
+}
+
+func TestMaskingBypassToken(t *testing.T) {
+	srv := maskingTestServer(t)
+	header := http.Header{"X-Masking-Token": []string{"secret-token"}}
+	logsQL := translateLogsQL(t, srv, `{"sql":"SELECT * FROM logs"}`, header)
+	if strings.Contains(logsQL, "format") {
+		t.Fatalf("expected no masking with bypass token, got %q", logsQL)
+	}
+}
+
+func TestMaskingWrongToken(t *testing.T) {
+	srv := maskingTestServer(t)
+	header := http.Header{"X-Masking-Token": []string{"wrong"}}
+	logsQL := translateLogsQL(t, srv, `{"sql":"SELECT * FROM logs"}`, header)
+	if !strings.Contains(logsQL, `format "*****" as email`) {
+		t.Fatalf("expected masking with wrong token, got %q", logsQL)
+	}
+}
+
+func TestMaskingCoversViewIndirection(t *testing.T) {
+	srv, err := NewServer(Config{
+		Tables:   map[string]string{"logs": "*"},
+		Masking:  map[string][]string{"logs": {"email"}},
+		ViewsDir: t.TempDir(),
+	})
+	if err != nil {
+		t.Fatalf("NewServer error: %v", err)
+	}
+
+	// A temporary view hides the table name from the statement, but masking
+	// must still apply when selecting through it.
+	translateLogsQL(t, srv, `{"sql":"CREATE TEMPORARY VIEW v AS SELECT email FROM logs"}`, nil)
+	logsQL := translateLogsQL(t, srv, `{"sql":"SELECT email FROM v"}`, nil)
+	if !strings.Contains(logsQL, `format "*****" as email`) {
+		t.Fatalf("expected masking through the temporary view, got %q", logsQL)
+	}
+
+	// Same for stored views.
+	translateLogsQL(t, srv, `{"sql":"CREATE VIEW sv AS SELECT email FROM logs"}`, nil)
+	logsQL = translateLogsQL(t, srv, `{"sql":"SELECT email FROM sv"}`, nil)
+	if !strings.Contains(logsQL, `format "*****" as email`) {
+		t.Fatalf("expected masking through the stored view, got %q", logsQL)
+	}
+}
+
+func TestMaskingCoversJoinAndUnionBranches(t *testing.T) {
+	srv, err := NewServer(Config{
+		Tables:  map[string]string{"logs": "*", "audit": "source:audit"},
+		Masking: map[string][]string{"logs": {"email"}},
+	})
+	if err != nil {
+		t.Fatalf("NewServer error: %v", err)
+	}
+
+	logsQL := translateLogsQL(t, srv, `{"sql":"SELECT * FROM audit a JOIN logs l ON a.user = l.user"}`, nil)
+	joinStart := strings.Index(logsQL, "join by")
+	if joinStart < 0 {
+		t.Fatalf("expected a join pipe, got %q", logsQL)
+	}
+	if !strings.Contains(logsQL[joinStart:], `format "*****" as email`) {
+		t.Fatalf("expected masking inside the join sub-query, got %q", logsQL)
+	}
+
+	logsQL = translateLogsQL(t, srv, `{"sql":"SELECT user FROM audit UNION ALL SELECT user FROM logs"}`, nil)
+	unionStart := strings.Index(logsQL, "union (")
+	if unionStart < 0 {
+		t.Fatalf("expected a union pipe, got %q", logsQL)
+	}
+	if !strings.Contains(logsQL[unionStart:], `format "*****" as email`) {
+		t.Fatalf("expected masking inside the union branch, got %q", logsQL)
+	}
+}
@@ -0,0 +1,266 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/sql/lexer"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/sql/parser"
+)
+
+// Prepared statements let dashboards validate a parameterized query once and
+// then execute it repeatedly with different bindings. PREPARE parses the SQL
+// template (standard ? placeholders) and returns a handle; EXECUTE splices
+// bound values into the template as properly escaped literals and runs the
+// regular query pipeline; CLOSE frees the handle. The same bind semantics are
+// what the wire protocols' extended query mode needs, so they share this
+// code path later.
+
+// maxPreparedStatements bounds the number of concurrently open handles so an
+// abandoned client cannot grow server memory without limit.
+const maxPreparedStatements = 1024
+
+// preparedStatement is a validated SQL template plus the byte offsets of its
+// placeholders.
+type preparedStatement struct {
+	sql     string
+	offsets []int
+}
+
+type prepareRequest struct {
+	SQL string `json:"sql"`
+}
+
+type prepareResponse struct {
+	Handle string `json:"handle,omitempty"`
+	Params int    `json:"params"`
+	Error  string `json:"error,omitempty"`
+}
+
+type executeRequest struct {
+	Handle string            `json:"handle"`
+	Params []json.RawMessage `json:"params,omitempty"`
+	// The remaining fields pass through to the regular query pipeline.
+	Variables        map[string]string `json:"variables,omitempty"`
+	From             string            `json:"from,omitempty"`
+	To               string            `json:"to,omitempty"`
+	IntervalMs       int64             `json:"intervalMs,omitempty"`
+	Pretty           bool              `json:"pretty,omitempty"`
+	BypassGuardrails bool              `json:"bypassGuardrails,omitempty"`
+	TimeZone         string            `json:"timeZone,omitempty"`
+	Endpoint         string            `json:"endpoint,omitempty"`
+	BearerToken      string            `json:"bearerToken,omitempty"`
+}
+
+type closeRequest struct {
+	Handle string `json:"handle"`
+}
+
+func newPreparedStatementHandle() string {
+	var buf [8]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		return "ps_fallback"
+	}
+	return "ps_" + hex.EncodeToString(buf[:])
+}
+
+func (s *Server) handlePrepare(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	defer r.Body.Close()
+
+	var req prepareRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, prepareResponse{Error: "invalid request payload"})
+		return
+	}
+	sqlText := strings.TrimSpace(req.SQL)
+	if sqlText == "" {
+		writeJSON(w, http.StatusBadRequest, prepareResponse{Error: "sql query is required"})
+		return
+	}
+	if msg := s.sqlLengthError(sqlText); msg != "" {
+		writeJSON(w, http.StatusBadRequest, prepareResponse{Error: msg})
+		return
+	}
+	p := parser.New(lexer.New(sqlText))
+	if stmt := p.ParseStatement(); stmt == nil {
+		writeJSON(w, http.StatusBadRequest, prepareResponse{Error: "no statement parsed"})
+		return
+	}
+	if perrs := p.Errors(); len(perrs) > 0 {
+		msgs := make([]string, 0, len(perrs))
+		for _, perr := range perrs {
+			msgs = append(msgs, perr.Error())
+		}
+		writeJSON(w, http.StatusBadRequest, prepareResponse{Error: "parse errors: " + strings.Join(msgs, "; ")})
+		return
+	}
+
+	ps := &preparedStatement{
+		sql:     sqlText,
+		offsets: placeholderOffsets(sqlText),
+	}
+	handle := newPreparedStatementHandle()
+
+	s.preparedMu.Lock()
+	if s.prepared == nil {
+		s.prepared = make(map[string]*preparedStatement)
+	}
+	if len(s.prepared) >= maxPreparedStatements {
+		s.preparedMu.Unlock()
+		writeJSON(w, http.StatusTooManyRequests, prepareResponse{Error: "too many open prepared statements; close unused handles"})
+		return
+	}
+	s.prepared[handle] = ps
+	s.preparedMu.Unlock()
+
+	writeJSON(w, http.StatusOK, prepareResponse{Handle: handle, Params: len(ps.offsets)})
+}
+
+func (s *Server) handleExecute(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	defer r.Body.Close()
+
+	var req executeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, queryResponse{Error: "invalid request payload"})
+		return
+	}
+	s.preparedMu.Lock()
+	ps, ok := s.prepared[strings.TrimSpace(req.Handle)]
+	s.preparedMu.Unlock()
+	if !ok {
+		writeJSON(w, http.StatusNotFound, queryResponse{Error: fmt.Sprintf("unknown prepared statement handle %q", req.Handle)})
+		return
+	}
+	bound, err := bindPlaceholders(ps.sql, ps.offsets, req.Params)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, queryResponse{Error: err.Error()})
+		return
+	}
+	s.runQuery(w, r, queryRequest{
+		SQL:              bound,
+		Variables:        req.Variables,
+		From:             req.From,
+		To:               req.To,
+		IntervalMs:       req.IntervalMs,
+		Pretty:           req.Pretty,
+		BypassGuardrails: req.BypassGuardrails,
+		TimeZone:         req.TimeZone,
+		Endpoint:         req.Endpoint,
+		BearerToken:      req.BearerToken,
+	})
+}
+
+func (s *Server) handleClose(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	defer r.Body.Close()
+
+	var req closeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request payload"})
+		return
+	}
+	handle := strings.TrimSpace(req.Handle)
+	s.preparedMu.Lock()
+	_, ok := s.prepared[handle]
+	delete(s.prepared, handle)
+	s.preparedMu.Unlock()
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": fmt.Sprintf("unknown prepared statement handle %q", handle)})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "closed"})
+}
+
+// placeholderOffsets returns the byte offsets of ? placeholders, skipping
+// string literals, quoted identifiers and comments.
+func placeholderOffsets(sql string) []int {
+	var offsets []int
+	for i := 0; i < len(sql); i++ {
+		switch sql[i] {
+		case '\'', '"':
+			quote := sql[i]
+			for i++; i < len(sql) && sql[i] != quote; i++ {
+			}
+		case '-':
+			if i+1 < len(sql) && sql[i+1] == '-' {
+				for i++; i < len(sql) && sql[i] != '\n'; i++ {
+				}
+			}
+		case '/':
+			if i+1 < len(sql) && sql[i+1] == '*' {
+				end := strings.Index(sql[i+2:], "*/")
+				if end < 0 {
+					return offsets
+				}
+				i += 2 + end + 1
+			}
+		case '?':
+			offsets = append(offsets, i)
+		}
+	}
+	return offsets
+}
+
+// bindPlaceholders splices params into the template as SQL literals.
+func bindPlaceholders(sql string, offsets []int, params []json.RawMessage) (string, error) {
+	if len(params) != len(offsets) {
+		return "", fmt.Errorf("statement expects %d parameters, got %d", len(offsets), len(params))
+	}
+	var out strings.Builder
+	prev := 0
+	for i, offset := range offsets {
+		literal, err := paramLiteral(params[i])
+		if err != nil {
+			return "", fmt.Errorf("parameter %d: %s", i+1, err)
+		}
+		out.WriteString(sql[prev:offset])
+		out.WriteString(literal)
+		prev = offset + 1
+	}
+	out.WriteString(sql[prev:])
+	return out.String(), nil
+}
+
+// paramLiteral renders a JSON parameter value as a SQL literal. Strings are
+// quoted with doubled single quotes, so a value can never smuggle in extra
+// SQL.
+func paramLiteral(raw json.RawMessage) (string, error) {
+	var v any
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return "", fmt.Errorf("invalid value %s", raw)
+	}
+	switch val := v.(type) {
+	case nil:
+		return "NULL", nil
+	case bool:
+		if val {
+			return "TRUE", nil
+		}
+		return "FALSE", nil
+	case float64:
+		// Re-use the JSON spelling to keep integers free of exponents.
+		return string(raw), nil
+	case string:
+		return "'" + strings.ReplaceAll(val, "'", "''") + "'", nil
+	default:
+		return "", fmt.Errorf("unsupported value %s; expected string, number, boolean or null", raw)
+	}
+}
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func preparedServer(t *testing.T) *Server {
+	t.Helper()
+	srv, err := NewServer(Config{Endpoint: "http://victoria", Tables: map[string]string{"logs": "*"}})
+	if err != nil {
+		t.Fatalf("NewServer error: %v", err)
+	}
+	srv.setHTTPClient(&http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			resp := &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewBufferString(`{"status":"ok"}`)),
+				Header:     make(http.Header),
+			}
+			resp.Header.Set("Content-Type", "application/json")
+			return resp, nil
+		}),
+	})
+	return srv
+}
+
+func postJSON(t *testing.T, srv *Server, path string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	buf, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("failed to marshal request: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(buf))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	srv.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestPrepareExecuteClose(t *testing.T) {
+	srv := preparedServer(t)
+
+	rr := postJSON(t, srv, "/api/v1/prepare", map[string]string{
+		"sql": "SELECT * FROM logs WHERE level = ? AND status > ?",
+	})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("prepare failed with status %d: %s", rr.Code, rr.Body.String())
+	}
+	var prep prepareResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &prep); err != nil {
+		t.Fatalf("invalid prepare response: %v", err)
+	}
+	if prep.Handle == "" || prep.Params != 2 {
+		t.Fatalf("unexpected prepare response: %+v", prep)
+	}
+
+	rr = postJSON(t, srv, "/api/v1/execute", map[string]any{
+		"handle": prep.Handle,
+		"params": []any{"error", 500},
+	})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("execute failed with status %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp struct {
+		LogsQL string `json:"logsql"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid execute response: %v", err)
+	}
+	if resp.LogsQL != "(level:error AND status:>500)" {
+		t.Fatalf("unexpected LogsQL: %s", resp.LogsQL)
+	}
+
+	rr = postJSON(t, srv, "/api/v1/close", map[string]string{"handle": prep.Handle})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("close failed with status %d: %s", rr.Code, rr.Body.String())
+	}
+	rr = postJSON(t, srv, "/api/v1/execute", map[string]any{
+		"handle": prep.Handle,
+		"params": []any{"error", 500},
+	})
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for closed handle, got %d", rr.Code)
+	}
+}
+
+func TestExecuteParamCountMismatch(t *testing.T) {
+	srv := preparedServer(t)
+
+	rr := postJSON(t, srv, "/api/v1/prepare", map[string]string{
+		"sql": "SELECT * FROM logs WHERE level = ?",
+	})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("prepare failed with status %d: %s", rr.Code, rr.Body.String())
+	}
+	var prep prepareResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &prep); err != nil {
+		t.Fatalf("invalid prepare response: %v", err)
+	}
+
+	rr = postJSON(t, srv, "/api/v1/execute", map[string]any{"handle": prep.Handle})
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for missing params, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if !strings.Contains(rr.Body.String(), "expects 1 parameters") {
+		t.Fatalf("unexpected error body: %s", rr.Body.String())
+	}
+}
+
+func TestPrepareRejectsInvalidSQL(t *testing.T) {
+	srv := preparedServer(t)
+	rr := postJSON(t, srv, "/api/v1/prepare", map[string]string{"sql": "SELECT FROM WHERE"})
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid SQL, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestParamLiteralEscapesQuotes(t *testing.T) {
+	literal, err := paramLiteral(json.RawMessage(`"o'brien; DROP TABLE logs"`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if literal != "'o''brien; DROP TABLE logs'" {
+		t.Fatalf("unexpected literal: %s", literal)
+	}
+}
+
+func TestPlaceholderOffsetsSkipLiterals(t *testing.T) {
+	offsets := placeholderOffsets("SELECT * FROM logs WHERE msg = '?' AND level = ? -- ?")
+	if len(offsets) != 1 {
+		t.Fatalf("expected a single placeholder, got %v", offsets)
+	}
+	if offsets[0] != 47 {
+		t.Fatalf("unexpected offset %d", offsets[0])
+	}
+}
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"fmt"
+	"plugin"
+
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/logsql"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/sql/ast"
+)
+
+// RegisterRewriter appends an AST rewriter to the server's chain. Rewriters
+// run in registration order on every parsed statement before translation,
+// across all frontends (HTTP, Trino, Grafana). Register rewriters before the
+// server starts serving requests; the chain is not synchronized.
+func (s *Server) RegisterRewriter(r logsql.Rewriter) {
+	s.rewriters = append(s.rewriters, r)
+}
+
+// loadRewriterPlugins opens each Go plugin listed in the config and
+// registers its exported Rewrite symbol, which must have the type
+// func(ast.Statement) (ast.Statement, error).
+func (s *Server) loadRewriterPlugins(paths []string) error {
+	for _, path := range paths {
+		p, err := plugin.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open rewriter plugin %s: %w", path, err)
+		}
+		sym, err := p.Lookup("Rewrite")
+		if err != nil {
+			return fmt.Errorf("rewriter plugin %s has no Rewrite symbol: %w", path, err)
+		}
+		rewrite, ok := sym.(func(ast.Statement) (ast.Statement, error))
+		if !ok {
+			return fmt.Errorf("rewriter plugin %s: Rewrite has type %T, want func(ast.Statement) (ast.Statement, error)", path, sym)
+		}
+		s.RegisterRewriter(rewrite)
+	}
+	return nil
+}
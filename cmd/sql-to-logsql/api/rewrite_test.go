@@ -0,0 +1,44 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/sql/ast"
+)
+
+func TestRegisteredRewriterRuns(t *testing.T) {
+	srv, err := NewServer(Config{Tables: map[string]string{"logs": "*"}})
+	if err != nil {
+		t.Fatalf("NewServer error: %v", err)
+	}
+	srv.RegisterRewriter(func(stmt ast.Statement) (ast.Statement, error) {
+		sel, ok := stmt.(*ast.SelectStatement)
+		if !ok {
+			return stmt, nil
+		}
+		// Force a LIMIT onto every select statement.
+		sel.Limit = &ast.LimitClause{Count: &ast.NumericLiteral{Value: "7"}}
+		return sel, nil
+	})
+
+	body := `{"sql":"SELECT * FROM logs"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/sql-to-logsql", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+	srv.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp struct {
+		LogsQL string `json:"logsql"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid json response: %v", err)
+	}
+	if !strings.Contains(resp.LogsQL, "limit 7") {
+		t.Fatalf("expected rewritten limit in LogsQL, got %q", resp.LogsQL)
+	}
+}
@@ -0,0 +1,231 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/sql/lexer"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/sql/parser"
+)
+
+// Saved queries store the original SQL together with declared parameters, so
+// a report can be shared as a URL instead of freezing its LogsQL into a
+// view. Parameters use the existing {{name}} template syntax and are bound
+// from query string values at execution time.
+
+var savedQueryNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// savedQueryParam declares one template parameter. A parameter without a
+// default must be supplied on every execution.
+type savedQueryParam struct {
+	Name    string `json:"name"`
+	Default string `json:"default,omitempty"`
+}
+
+type savedQuery struct {
+	Name   string            `json:"name"`
+	SQL    string            `json:"sql"`
+	Params []savedQueryParam `json:"params,omitempty"`
+}
+
+type savedQueryResponse struct {
+	Status string `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type savedQueryStore struct {
+	mu      sync.Mutex
+	queries map[string]savedQuery
+}
+
+func (st *savedQueryStore) save(q savedQuery) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.queries == nil {
+		st.queries = make(map[string]savedQuery)
+	}
+	st.queries[q.Name] = q
+}
+
+func (st *savedQueryStore) get(name string) (savedQuery, bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	q, ok := st.queries[name]
+	return q, ok
+}
+
+func (st *savedQueryStore) delete(name string) bool {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	_, ok := st.queries[name]
+	delete(st.queries, name)
+	return ok
+}
+
+func (st *savedQueryStore) list() []savedQuery {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	out := make([]savedQuery, 0, len(st.queries))
+	for _, q := range st.queries {
+		out = append(out, q)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// handleSavedQueries serves the collection: GET lists, POST creates or
+// replaces, DELETE removes by name.
+func (s *Server) handleSavedQueries(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, s.savedQueries.list())
+	case http.MethodPost:
+		defer r.Body.Close()
+		var q savedQuery
+		if err := json.NewDecoder(r.Body).Decode(&q); err != nil {
+			writeJSON(w, http.StatusBadRequest, savedQueryResponse{Error: "invalid request payload"})
+			return
+		}
+		if err := validateSavedQuery(&q); err != nil {
+			writeJSON(w, http.StatusBadRequest, savedQueryResponse{Error: err.Error()})
+			return
+		}
+		s.savedQueries.save(q)
+		writeJSON(w, http.StatusOK, savedQueryResponse{Status: "saved"})
+	case http.MethodDelete:
+		name := strings.TrimSpace(r.URL.Query().Get("name"))
+		if !s.savedQueries.delete(name) {
+			writeJSON(w, http.StatusNotFound, savedQueryResponse{Error: fmt.Sprintf("unknown saved query %q", name)})
+			return
+		}
+		writeJSON(w, http.StatusOK, savedQueryResponse{Status: "deleted"})
+	default:
+		w.Header().Set("Allow", strings.Join([]string{http.MethodGet, http.MethodPost, http.MethodDelete}, ", "))
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleSavedQueryRun executes /api/v1/saved/{name} with parameters bound
+// from the query string.
+func (s *Server) handleSavedQueryRun(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	name := strings.TrimPrefix(r.URL.Path, "/api/v1/saved/")
+	q, ok := s.savedQueries.get(name)
+	if !ok {
+		writeJSON(w, http.StatusNotFound, queryResponse{Error: fmt.Sprintf("unknown saved query %q", name)})
+		return
+	}
+
+	values := r.URL.Query()
+	vars := make(map[string]string, len(q.Params))
+	for _, param := range q.Params {
+		if v := values.Get(param.Name); v != "" {
+			vars[param.Name] = v
+			continue
+		}
+		if param.Default == "" {
+			writeJSON(w, http.StatusBadRequest, queryResponse{Error: fmt.Sprintf("missing required parameter %q", param.Name)})
+			return
+		}
+		vars[param.Name] = param.Default
+	}
+
+	req := queryRequest{
+		SQL:         q.SQL,
+		Variables:   vars,
+		From:        strings.TrimSpace(values.Get("from")),
+		To:          strings.TrimSpace(values.Get("to")),
+		TimeZone:    strings.TrimSpace(values.Get("timeZone")),
+		BearerToken: strings.TrimSpace(values.Get("bearerToken")),
+	}
+	if pretty, err := strconv.ParseBool(values.Get("pretty")); err == nil {
+		req.Pretty = pretty
+	}
+	s.runQuery(w, r, req)
+}
+
+// validateSavedQuery normalizes and checks a saved query definition: the
+// name must be URL-safe, every referenced template variable must be
+// declared, and the SQL must parse with the declared defaults bound.
+func validateSavedQuery(q *savedQuery) error {
+	q.Name = strings.TrimSpace(q.Name)
+	if !savedQueryNameRe.MatchString(q.Name) {
+		return fmt.Errorf("invalid saved query name %q", q.Name)
+	}
+	q.SQL = strings.TrimSpace(q.SQL)
+	if q.SQL == "" {
+		return fmt.Errorf("sql query is required")
+	}
+	declared := make(map[string]struct{}, len(q.Params))
+	probe := make(map[string]string, len(q.Params))
+	for i := range q.Params {
+		pname := strings.TrimSpace(q.Params[i].Name)
+		if pname == "" {
+			return fmt.Errorf("parameter %d has no name", i+1)
+		}
+		if _, ok := declared[pname]; ok {
+			return fmt.Errorf("parameter %q declared twice", pname)
+		}
+		q.Params[i].Name = pname
+		declared[pname] = struct{}{}
+		probe[pname] = q.Params[i].Default
+		if probe[pname] == "" {
+			probe[pname] = "0"
+		}
+	}
+	for _, ref := range referencedVariables(q.SQL) {
+		if _, ok := declared[ref]; !ok {
+			return fmt.Errorf("variable %q is referenced but not declared as a parameter", ref)
+		}
+	}
+
+	bound, err := substituteVariables(q.SQL, probe)
+	if err != nil {
+		return err
+	}
+	p := parser.New(lexer.New(bound))
+	if stmt := p.ParseStatement(); stmt == nil {
+		return fmt.Errorf("no statement parsed")
+	}
+	if perrs := p.Errors(); len(perrs) > 0 {
+		msgs := make([]string, 0, len(perrs))
+		for _, perr := range perrs {
+			msgs = append(msgs, perr.Error())
+		}
+		return fmt.Errorf("parse errors: %s", strings.Join(msgs, "; "))
+	}
+	return nil
+}
+
+// referencedVariables lists the distinct {{name}} references in sql, in
+// order of first appearance.
+func referencedVariables(sql string) []string {
+	var names []string
+	seen := make(map[string]struct{})
+	for i := 0; i+1 < len(sql); i++ {
+		if sql[i] != '{' || sql[i+1] != '{' {
+			continue
+		}
+		end := strings.Index(sql[i+2:], "}}")
+		if end < 0 {
+			break
+		}
+		name := strings.TrimSpace(sql[i+2 : i+2+end])
+		if _, ok := seen[name]; !ok && name != "" {
+			seen[name] = struct{}{}
+			names = append(names, name)
+		}
+		i += 2 + end + 1
+	}
+	return names
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSavedQueryLifecycle(t *testing.T) {
+	srv := preparedServer(t)
+
+	rr := postJSON(t, srv, "/api/v1/saved", savedQuery{
+		Name: "errors-by-service",
+		SQL:  "SELECT * FROM logs WHERE level = '{{level}}' AND service = '{{service}}'",
+		Params: []savedQueryParam{
+			{Name: "level", Default: "error"},
+			{Name: "service"},
+		},
+	})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("save failed with status %d: %s", rr.Code, rr.Body.String())
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/saved/errors-by-service?service=api", nil)
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("run failed with status %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		LogsQL string `json:"logsql"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid run response: %v", err)
+	}
+	if resp.LogsQL != "(level:error AND service:api)" {
+		t.Fatalf("unexpected LogsQL: %s", resp.LogsQL)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/saved/errors-by-service", nil)
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for missing required parameter, got %d", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/saved", nil)
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "errors-by-service") {
+		t.Fatalf("unexpected list response %d: %s", rec.Code, rec.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/v1/saved?name=errors-by-service", nil)
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("delete failed with status %d: %s", rec.Code, rec.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/saved/errors-by-service?service=api", nil)
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 after delete, got %d", rec.Code)
+	}
+}
+
+func TestSavedQueryValidation(t *testing.T) {
+	srv := preparedServer(t)
+
+	tests := []struct {
+		name  string
+		query savedQuery
+		want  string
+	}{
+		{
+			name:  "bad name",
+			query: savedQuery{Name: "../etc", SQL: "SELECT * FROM logs"},
+			want:  "invalid saved query name",
+		},
+		{
+			name:  "undeclared variable",
+			query: savedQuery{Name: "q", SQL: "SELECT * FROM logs WHERE level = '{{level}}'"},
+			want:  "not declared",
+		},
+		{
+			name: "invalid sql",
+			query: savedQuery{
+				Name:   "q",
+				SQL:    "SELECT WHERE {{level}}",
+				Params: []savedQueryParam{{Name: "level"}},
+			},
+			want: "parse errors",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := postJSON(t, srv, "/api/v1/saved", tt.query)
+			if rr.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d: %s", rr.Code, rr.Body.String())
+			}
+			if !strings.Contains(rr.Body.String(), tt.want) {
+				t.Fatalf("expected error containing %q, got %s", tt.want, rr.Body.String())
+			}
+		})
+	}
+}
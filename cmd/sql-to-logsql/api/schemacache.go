@@ -0,0 +1,88 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultSchemaCacheTTL is how long cached field-name lookups stay fresh
+// when the config does not set schemaCacheTTLSeconds.
+const defaultSchemaCacheTTL = time.Minute
+
+// schemaCache memoizes upstream field-name lookups per table and endpoint,
+// so DESCRIBE, column validation and autocomplete do not hammer
+// VictoriaLogs. Entries expire after the configured TTL and can be dropped
+// early through the /api/v1/schema-cache endpoint.
+type schemaCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]schemaCacheEntry
+}
+
+type schemaCacheEntry struct {
+	names     []string
+	fetchedAt time.Time
+}
+
+func schemaCacheKey(table, endpoint string) string {
+	return strings.ToLower(table) + "|" + endpoint
+}
+
+func (c *schemaCache) get(key string) ([]string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Since(entry.fetchedAt) > c.ttl {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.names, true
+}
+
+func (c *schemaCache) put(key string, names []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]schemaCacheEntry)
+	}
+	c.entries[key] = schemaCacheEntry{names: names, fetchedAt: time.Now()}
+}
+
+// invalidate drops the cached lookups for one table, or every lookup when
+// table is empty, and returns how many entries were removed.
+func (c *schemaCache) invalidate(table string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if table == "" {
+		n := len(c.entries)
+		c.entries = nil
+		return n
+	}
+	prefix := strings.ToLower(table) + "|"
+	n := 0
+	for key := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.entries, key)
+			n++
+		}
+	}
+	return n
+}
+
+// handleSchemaCache serves DELETE /api/v1/schema-cache, dropping cached
+// field-name lookups. An optional table query parameter restricts the
+// invalidation to one table.
+func (s *Server) handleSchemaCache(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		w.Header().Set("Allow", http.MethodDelete)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	n := s.schema.invalidate(strings.TrimSpace(r.URL.Query().Get("table")))
+	writeJSON(w, http.StatusOK, map[string]int{"invalidated": n})
+}
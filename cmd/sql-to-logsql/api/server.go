@@ -2,6 +2,8 @@ package api
 
 import (
 	"bytes"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,8 +13,10 @@ import (
 	"net/http"
 	"net/url"
 	"path"
+	"runtime/debug"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/VictoriaMetrics/sql-to-logsql/cmd/sql-to-logsql/web"
 	"github.com/VictoriaMetrics/sql-to-logsql/lib/logsql"
@@ -25,20 +29,139 @@ import (
 )
 
 type Config struct {
-	ListenAddr  string            `json:"listenAddr"`
+	ListenAddr      string `json:"listenAddr"`
+	PgListenAddr    string `json:"pgListenAddr"`
+	MysqlListenAddr string `json:"mysqlListenAddr"`
+	// GrpcListenAddr serves the gRPC TranslatorService next to the HTTP API.
+	// It requires the TLS files below: gRPC runs over HTTP/2, which the
+	// server only negotiates via TLS.
+	GrpcListenAddr  string `json:"grpcListenAddr"`
+	GrpcTLSCertFile string `json:"grpcTLSCertFile"`
+	GrpcTLSKeyFile  string `json:"grpcTLSKeyFile"`
+	// WireUsers authenticates connections to the PostgreSQL and MySQL wire
+	// listeners (username -> password). The listeners refuse to start
+	// without at least one configured user.
+	WireUsers   map[string]string `json:"wireUsers"`
 	Endpoint    string            `json:"endpoint"`
 	BearerToken string            `json:"bearerToken"`
 	Tables      map[string]string `json:"tables"`
-	ViewsDir    string            `json:"viewsDir"`
-	Limit       uint32            `json:"limit"`
+	// TableBackends routes tables to their own VictoriaLogs clusters for
+	// federation; keys must exist in Tables. Tables without an entry use
+	// the default endpoint.
+	TableBackends   map[string]tablestore.Backend `json:"tableBackends"`
+	ViewsDir        string                        `json:"viewsDir"`
+	Limit           uint32                        `json:"limit"`
+	RewriterPlugins []string                      `json:"rewriterPlugins"`
+	// Masking lists redacted fields per table; see maskedFields.
+	Masking             map[string][]string `json:"masking"`
+	MaskingBypassTokens []string            `json:"maskingBypassTokens"`
+	// Guardrails bounds the cost of translated queries; see
+	// logsql.Guardrails.
+	Guardrails logsql.Guardrails `json:"guardrails"`
+	// TimeZone interprets naive timestamp literals in queries; overridable
+	// per request and per session via SET time_zone. Defaults to UTC.
+	TimeZone string `json:"timeZone"`
+	// EnableInsert allows INSERT INTO table SELECT ... statements to write
+	// result rows back to VictoriaLogs via its JSON ingestion API.
+	EnableInsert bool `json:"enableInsert"`
+	// InsertStreamField names the field that carries the destination table
+	// as the stream label on ingested rows; defaults to "stream".
+	InsertStreamField string `json:"insertStreamField"`
+	// HistorySize keeps the last N queries per session retrievable via
+	// /api/v1/history; 0 (the default) disables history.
+	HistorySize int `json:"historySize"`
+	// Upstream tunes timeouts and connection pooling of the HTTP client
+	// used to reach VictoriaLogs; see vlogs.ClientOptions for defaults.
+	Upstream vlogs.ClientOptions `json:"upstream"`
+	// AllowedEndpoints restricts per-request endpoint overrides to this
+	// list; unset leaves overrides unrestricted.
+	AllowedEndpoints []string `json:"allowedEndpoints"`
+	// DisableEndpointOverride rejects per-request endpoints entirely.
+	DisableEndpointOverride bool `json:"disableEndpointOverride"`
+	// MaxBodyBytes caps the size of request bodies; oversized requests get
+	// a 413. 0 (the default) leaves bodies unlimited.
+	MaxBodyBytes int64 `json:"maxBodyBytes"`
+	// MaxSQLLength caps the byte length of a single SQL statement; longer
+	// statements get a 400. 0 (the default) leaves statements unlimited.
+	MaxSQLLength int `json:"maxSQLLength"`
+	// Features enables experimental translations for every request; see
+	// logsql.ValidateFeatures for the known flags. Requests can enable
+	// additional flags per query.
+	Features []string `json:"features"`
+	// PostProcessMaxRows caps how many result rows the post_process feature
+	// may buffer for client-side evaluation. 0 uses the built-in default.
+	PostProcessMaxRows int `json:"postProcessMaxRows"`
+	// ResultMemoryBytes is how much of an upstream result is held in memory
+	// before the rest spills to a temp file. 0 uses the 16 MiB default.
+	ResultMemoryBytes int64 `json:"resultMemoryBytes"`
+	// MaxResultBytes hard-caps upstream result size; larger results fail
+	// with a 413. 0 uses the 256 MiB default, negative disables the cap.
+	MaxResultBytes int64 `json:"maxResultBytes"`
+	// ExportDir is the root directory export destinations resolve under;
+	// every export writes inside it. Exports are disabled when empty.
+	ExportDir string `json:"exportDir"`
+	// ClientLabel names this deployment in the X-SQL2LogsQL-Client header
+	// sent with every upstream request, unless a request supplies its own
+	// label.
+	ClientLabel string `json:"clientLabel"`
+	// SchemaCacheTTLSeconds bounds how long cached field-name lookups stay
+	// fresh. 0 uses the one-minute default.
+	SchemaCacheTTLSeconds int `json:"schemaCacheTTLSeconds"`
 }
 
 type Server struct {
 	api *vlogs.API
 	mux *http.ServeMux
 	sp  *store.Provider
+
+	brokenViews         map[string]string
+	rewriters           []logsql.Rewriter
+	masking             map[string][]string
+	maskingBypassTokens map[string]struct{}
+	guardrails          logsql.Guardrails
+	exportDir           string
+	wireUsers           map[string]string
+	timeZone            *time.Location
+
+	trinoMu      sync.Mutex
+	trinoQueries map[string]*trinoQuery
+
+	preparedMu sync.Mutex
+	prepared   map[string]*preparedStatement
+
+	sessionMu sync.Mutex
+	sessions  map[string]*session
+
+	historySize  int
+	savedQueries savedQueryStore
+	exports      exportJobs
+	jobs         queryJobs
+
+	maxBodyBytes int64
+	maxSQLLength int
+	features     []string
+
+	schema schemaCache
+}
+
+// session groups the per-token state adjusted by CREATE TEMPORARY VIEW and
+// SET statements.
+type session struct {
+	views    *viewstore.MemStore
+	options  *store.SessionOptions
+	history  *queryHistory
+	lastUsed time.Time
 }
 
+// sessionTTL is how long a session's temporary views, options and history
+// survive without a request. Sessions are keyed by whatever bearer token the
+// client sends, so idle ones are dropped instead of accumulating forever.
+const sessionTTL = 30 * time.Minute
+
+// maxSessions caps the number of tracked sessions; at the cap the least
+// recently used session is dropped to make room for a new one.
+const maxSessions = 1000
+
 func NewServer(cfg Config) (*Server, error) {
 	serverCfg := cfg
 	serverCfg.BearerToken = strings.TrimSpace(serverCfg.BearerToken)
@@ -50,10 +173,23 @@ func NewServer(cfg Config) (*Server, error) {
 	}
 	serverCfg.ViewsDir = strings.TrimSpace(serverCfg.ViewsDir)
 
+	if err := logsql.ValidateTableSpecs(serverCfg.Tables); err != nil {
+		return nil, fmt.Errorf("invalid table definition: %w", err)
+	}
 	tableStore, err := tablestore.NewTableStore(serverCfg.Tables)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create table store: %w", err)
 	}
+	if len(serverCfg.TableBackends) > 0 {
+		for name, backend := range serverCfg.TableBackends {
+			if _, err := url.Parse(strings.TrimSpace(backend.Endpoint)); err != nil {
+				return nil, fmt.Errorf("invalid backend endpoint for table %q: %w", name, err)
+			}
+		}
+		if err := tableStore.SetBackends(serverCfg.TableBackends); err != nil {
+			return nil, fmt.Errorf("invalid table backend: %w", err)
+		}
+	}
 	viewStore, err := viewstore.NewViewStore(serverCfg.ViewsDir)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create view store: %w", err)
@@ -67,12 +203,84 @@ func NewServer(cfg Config) (*Server, error) {
 			vlogs.EndpointConfig{
 				Endpoint:    serverCfg.Endpoint,
 				BearerToken: serverCfg.BearerToken,
+				ClientLabel: serverCfg.ClientLabel,
 			},
 			serverCfg.Limit,
 		),
 	}
+	if serverCfg.EnableInsert {
+		srv.api.EnableInsert(serverCfg.InsertStreamField)
+	}
+	if !serverCfg.Upstream.IsZero() {
+		srv.api.SetHTTPClient(serverCfg.Upstream.NewHTTPClient())
+	}
+	if serverCfg.DisableEndpointOverride {
+		srv.api.DisableEndpointOverrides()
+	}
+	if len(serverCfg.AllowedEndpoints) > 0 {
+		srv.api.AllowEndpointOverrides(serverCfg.AllowedEndpoints)
+	}
+	if serverCfg.PostProcessMaxRows > 0 {
+		srv.api.SetPostProcessLimit(serverCfg.PostProcessMaxRows)
+	}
+	if serverCfg.ResultMemoryBytes != 0 || serverCfg.MaxResultBytes != 0 {
+		srv.api.SetResponseLimits(serverCfg.ResultMemoryBytes, serverCfg.MaxResultBytes)
+	}
+	srv.sessions = make(map[string]*session)
+	srv.brokenViews = validateStoredViews(viewStore)
+	if err := srv.loadRewriterPlugins(serverCfg.RewriterPlugins); err != nil {
+		return nil, err
+	}
+	srv.masking = serverCfg.Masking
+	srv.guardrails = serverCfg.Guardrails
+	srv.exportDir = serverCfg.ExportDir
+	srv.wireUsers = serverCfg.WireUsers
+	srv.historySize = serverCfg.HistorySize
+	srv.maxBodyBytes = serverCfg.MaxBodyBytes
+	srv.maxSQLLength = serverCfg.MaxSQLLength
+	if err := logsql.ValidateFeatures(serverCfg.Features); err != nil {
+		return nil, fmt.Errorf("invalid features config: %w", err)
+	}
+	srv.features = serverCfg.Features
+	srv.schema.ttl = defaultSchemaCacheTTL
+	if serverCfg.SchemaCacheTTLSeconds > 0 {
+		srv.schema.ttl = time.Duration(serverCfg.SchemaCacheTTLSeconds) * time.Second
+	}
+	if tz := strings.TrimSpace(serverCfg.TimeZone); tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time zone %q: %w", tz, err)
+		}
+		srv.timeZone = loc
+	}
+	if len(serverCfg.MaskingBypassTokens) > 0 {
+		srv.maskingBypassTokens = make(map[string]struct{}, len(serverCfg.MaskingBypassTokens))
+		for _, token := range serverCfg.MaskingBypassTokens {
+			srv.maskingBypassTokens[token] = struct{}{}
+		}
+	}
 	srv.mux.HandleFunc("/healthz", withSecurityHeaders(srv.handleHealth))
+	srv.mux.HandleFunc("/api/v1/views", withSecurityHeaders(srv.handleViews))
 	srv.mux.HandleFunc("/api/v1/sql-to-logsql", withSecurityHeaders(srv.handleQuery))
+	srv.mux.HandleFunc("/api/v1/format", withSecurityHeaders(srv.handleFormat))
+	srv.mux.HandleFunc("/api/v1/estimate", withSecurityHeaders(srv.handleEstimate))
+	srv.mux.HandleFunc("/api/v1/prepare", withSecurityHeaders(srv.handlePrepare))
+	srv.mux.HandleFunc("/api/v1/execute", withSecurityHeaders(srv.handleExecute))
+	srv.mux.HandleFunc("/api/v1/close", withSecurityHeaders(srv.handleClose))
+	srv.mux.HandleFunc("/api/v1/history", withSecurityHeaders(srv.handleHistory))
+	srv.mux.HandleFunc("/api/v1/saved", withSecurityHeaders(srv.handleSavedQueries))
+	srv.mux.HandleFunc("/api/v1/saved/", withSecurityHeaders(srv.handleSavedQueryRun))
+	srv.mux.HandleFunc("/api/v1/export", withSecurityHeaders(srv.handleExportStatus))
+	srv.mux.HandleFunc("/api/v1/export/", withSecurityHeaders(srv.handleExportStatus))
+	srv.mux.HandleFunc("/api/v1/jobs", withSecurityHeaders(srv.handleJobs))
+	srv.mux.HandleFunc("/api/v1/jobs/", withSecurityHeaders(srv.handleJobStatus))
+	srv.mux.HandleFunc("/api/v1/schema-cache", withSecurityHeaders(srv.handleSchemaCache))
+	srv.mux.HandleFunc("/api/v1/upstream-stats", withSecurityHeaders(srv.handleUpstreamStats))
+	srv.mux.HandleFunc("/api/v1/complete", withSecurityHeaders(srv.handleComplete))
+	srv.mux.HandleFunc("/api/v1/tokens", withSecurityHeaders(srv.handleTokens))
+	srv.mux.HandleFunc("/api/v1/grafana/query", withSecurityHeaders(srv.handleGrafanaQuery))
+	srv.mux.HandleFunc("/v1/statement", withSecurityHeaders(srv.handleTrinoStatement))
+	srv.mux.HandleFunc("/v1/statement/", withSecurityHeaders(srv.handleTrinoPage))
 	srv.mux.HandleFunc("/api/v1/config", withSecurityHeaders(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
 			w.Header().Set("Allow", http.MethodGet)
@@ -86,13 +294,84 @@ func NewServer(cfg Config) (*Server, error) {
 }
 
 func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			// A panic in one handler must not take down the shared service.
+			// The request ID ties the client-visible error to the logged
+			// stack without leaking internals to the caller.
+			id := newRequestID()
+			log.Printf("ERROR: panic serving %s %s (request id %s): %v\n%s", r.Method, r.URL.Path, id, rec, debug.Stack())
+			writeJSON(w, http.StatusInternalServerError, queryResponse{Error: fmt.Sprintf("internal error; request id %s", id)})
+		}
+	}()
+	if s.maxBodyBytes > 0 && r.Body != nil {
+		r.Body = http.MaxBytesReader(w, r.Body, s.maxBodyBytes)
+	}
 	s.mux.ServeHTTP(w, r)
 }
 
+func newRequestID() string {
+	var buf [8]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		return "req_fallback"
+	}
+	return "req_" + hex.EncodeToString(buf[:])
+}
+
+// requestTooLarge reports whether a body decode failure was caused by the
+// configured maxBodyBytes limit rather than malformed JSON.
+func requestTooLarge(err error) bool {
+	var mbe *http.MaxBytesError
+	return errors.As(err, &mbe)
+}
+
+// sqlLengthError returns the rejection message for a statement that exceeds
+// the configured maxSQLLength, or "" when it fits.
+func (s *Server) sqlLengthError(sqlText string) string {
+	if s.maxSQLLength > 0 && len(sqlText) > s.maxSQLLength {
+		return fmt.Sprintf("sql statement length %d exceeds the maximum of %d bytes", len(sqlText), s.maxSQLLength)
+	}
+	return ""
+}
+
 func (s *Server) setHTTPClient(client *http.Client) {
 	s.api.SetHTTPClient(client)
 }
 
+// Stores exposes the shared store provider so auxiliary frontends (for
+// example the pgwire listener) can reuse it.
+func (s *Server) Stores() *store.Provider {
+	return s.sp
+}
+
+// VLogs exposes the shared VictoriaLogs API client.
+func (s *Server) VLogs() *vlogs.API {
+	return s.api
+}
+
+// WirePasswordLookup returns the credential store for the wire-protocol
+// listeners, or nil when no wireUsers are configured.
+func (s *Server) WirePasswordLookup() func(user string) (string, bool) {
+	if len(s.wireUsers) == 0 {
+		return nil
+	}
+	users := s.wireUsers
+	return func(user string) (string, bool) {
+		password, ok := users[user]
+		return password, ok
+	}
+}
+
+// PrepareWireQuery applies the same masking and guardrails the HTTP API
+// enforces to queries arriving over the wire-protocol listeners. Wire
+// connections carry no bypass tokens, so masking always applies.
+func (s *Server) PrepareWireQuery(logsQL string, tables []string) (string, error) {
+	if fields := s.maskedFieldsFor(tables); len(fields) > 0 {
+		logsQL = logsql.MaskFields(logsQL, fields)
+	}
+	return s.guardrails.Apply(logsQL)
+}
+
 // withSecurityHeaders middleware adds security headers to responses
 func withSecurityHeaders(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -103,16 +382,106 @@ func withSecurityHeaders(next http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+// validateStoredViews runs the local LogsQL syntax probe over every stored
+// view and returns the ones that fail, keyed by view name. Broken views are
+// reported at startup instead of failing only when the view is queried.
+func validateStoredViews(vs *viewstore.ViewStore) map[string]string {
+	if vs == nil {
+		return nil
+	}
+	defs, err := vs.ViewDefinitions()
+	if err != nil {
+		log.Printf("WARNING: failed to load stored views for validation: %v", err)
+		return nil
+	}
+	broken := make(map[string]string)
+	for name, query := range defs {
+		if err := logsql.ValidateLogsQL(query); err != nil {
+			log.Printf("WARNING: stored view %q is broken: %v", name, err)
+			broken[name] = err.Error()
+		}
+	}
+	if len(broken) == 0 {
+		return nil
+	}
+	return broken
+}
+
+type viewStatus struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+func (s *Server) handleViews(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	vs := s.sp.ViewStore()
+	if vs == nil {
+		writeJSON(w, http.StatusOK, []viewStatus{})
+		return
+	}
+	names, err := vs.ListViews()
+	if err != nil {
+		log.Printf("ERROR: failed to list views: %v", err)
+		writeJSON(w, http.StatusInternalServerError, queryResponse{Error: "failed to list views"})
+		return
+	}
+	statusFilter := strings.TrimSpace(r.URL.Query().Get("status"))
+	views := make([]viewStatus, 0, len(names))
+	for _, name := range names {
+		v := viewStatus{Name: name, Status: "ok"}
+		if msg, ok := s.brokenViews[name]; ok {
+			v.Status = "broken"
+			v.Error = msg
+		}
+		if statusFilter != "" && v.Status != statusFilter {
+			continue
+		}
+		views = append(views, v)
+	}
+	writeJSON(w, http.StatusOK, views)
+}
+
 type queryRequest struct {
-	SQL         string `json:"sql"`
+	SQL        string            `json:"sql"`
+	Variables  map[string]string `json:"variables,omitempty"`
+	From       string            `json:"from,omitempty"`
+	To         string            `json:"to,omitempty"`
+	IntervalMs int64             `json:"intervalMs,omitempty"`
+	Pretty     bool              `json:"pretty,omitempty"`
+	// BypassGuardrails skips the configured cost guardrails for this
+	// request.
+	BypassGuardrails bool `json:"bypassGuardrails,omitempty"`
+	// TimeZone interprets naive timestamp literals for this request only,
+	// taking precedence over SET time_zone and the configured default.
+	TimeZone    string `json:"timeZone,omitempty"`
 	Endpoint    string `json:"endpoint,omitempty"`
 	BearerToken string `json:"bearerToken,omitempty"`
+	// ClientLabel attributes the query to a specific caller (for example a
+	// dashboard) in VictoriaLogs query logs.
+	ClientLabel string `json:"clientLabel,omitempty"`
+	// Features enables experimental translations for this request, on top
+	// of the flags enabled in the config.
+	Features []string `json:"features,omitempty"`
+	// ValidateColumns checks every referenced column against the table's
+	// actual field names before executing, failing fast on typos.
+	ValidateColumns bool `json:"validateColumns,omitempty"`
+	// Export streams the full result to a destination asynchronously
+	// instead of returning it in the response; see exportSpec.
+	Export *exportSpec `json:"export,omitempty"`
 }
 
 type queryResponse struct {
-	LogsQL string `json:"logsql"`
-	Data   string `json:"data,omitempty"`
-	Error  string `json:"error,omitempty"`
+	LogsQL   string             `json:"logsql"`
+	Fields   []logsql.FieldHint `json:"fields,omitempty"`
+	Warnings []string           `json:"warnings,omitempty"`
+	Data     string             `json:"data,omitempty"`
+	JobID    string             `json:"jobId,omitempty"`
+	Error    string             `json:"error,omitempty"`
 }
 
 func (s *Server) handleQuery(w http.ResponseWriter, r *http.Request) {
@@ -125,19 +494,65 @@ func (s *Server) handleQuery(w http.ResponseWriter, r *http.Request) {
 
 	var req queryRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		if requestTooLarge(err) {
+			writeJSON(w, http.StatusRequestEntityTooLarge, queryResponse{Error: "request body exceeds the configured maximum size"})
+			return
+		}
 		log.Printf("ERROR: failed to decode request: %v", err)
 		writeJSON(w, http.StatusBadRequest, queryResponse{Error: "invalid request payload"})
 		return
 	}
+	s.runQuery(w, r, req)
+}
 
+// runQuery executes a decoded query request; shared with the prepared
+// statement execute endpoint, which builds the request from a bound template.
+func (s *Server) runQuery(w http.ResponseWriter, r *http.Request, req queryRequest) {
 	sqlText := strings.TrimSpace(req.SQL)
 	if sqlText == "" {
 		writeJSON(w, http.StatusBadRequest, queryResponse{Error: "sql query is required"})
 		return
 	}
+	if msg := s.sqlLengthError(sqlText); msg != "" {
+		writeJSON(w, http.StatusBadRequest, queryResponse{Error: msg})
+		return
+	}
+	sqlText, err := substituteVariables(sqlText, req.Variables)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, queryResponse{Error: err.Error()})
+		return
+	}
+	sqlText = expandTimeMacros(sqlText, strings.TrimSpace(req.From), strings.TrimSpace(req.To), req.IntervalMs)
 
-	statement, err := processQuery(sqlText, s.sp)
+	sess := s.session(r, req.BearerToken)
+	start := time.Now()
+	entry := historyEntry{SQL: sqlText, StartedAt: historyTimestamp(start)}
+	defer func() {
+		entry.DurationMs = time.Since(start).Milliseconds()
+		sess.history.add(entry)
+	}()
+	sp := s.sp.WithSessionViews(sess.views).WithSessionOptions(sess.options)
+	features, err := s.requestFeatures(req)
 	if err != nil {
+		writeJSON(w, http.StatusBadRequest, queryResponse{Error: err.Error()})
+		return
+	}
+	if len(features) > 0 {
+		sp = sp.WithFeatures(features)
+	}
+	if tz := strings.TrimSpace(req.TimeZone); tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, queryResponse{Error: fmt.Sprintf("unknown time zone %q", tz)})
+			return
+		}
+		sp = sp.WithTimeZone(loc)
+	} else if _, ok := sess.options.Get("time_zone"); !ok && s.timeZone != nil {
+		sp = sp.WithTimeZone(s.timeZone)
+	}
+	statement, tables, hashes, err := processQuery(sqlText, sp, s.rewriters)
+	if err != nil {
+		entry.Error = err.Error()
 		log.Printf("ERROR: query processing failed: %v", err)
 		var ae *vlogs.APIError
 		var te *logsql.TranslationError
@@ -157,12 +572,80 @@ func (s *Server) handleQuery(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	resp := queryResponse{LogsQL: statement.LogsQL}
-	data, err := s.api.Execute(r.Context(), statement, vlogs.EndpointConfig{
+	if req.ValidateColumns && statement.Kind == logsql.StatementTypeSelect {
+		if err := s.validateQueryColumns(r, req, sqlText, tables); err != nil {
+			entry.Error = err.Error()
+			var ae *vlogs.APIError
+			if errors.As(err, &ae) {
+				writeJSON(w, ae.Code, queryResponse{Error: ae.Message})
+			} else {
+				writeJSON(w, http.StatusBadRequest, queryResponse{Error: err.Error()})
+			}
+			return
+		}
+	}
+	if fields := s.maskedFields(r, req.BearerToken, tables); len(fields) > 0 && statement.LogsQL != "" {
+		statement.LogsQL = logsql.MaskFields(statement.LogsQL, fields)
+	}
+	if req.Export != nil {
+		// Exports stream the complete result by design, so the row-count
+		// guardrails do not apply.
+		ec, err := s.api.ResolveEndpoint(vlogs.EndpointConfig{
+			Endpoint:    req.Endpoint,
+			BearerToken: req.BearerToken,
+			ClientLabel: requestClientLabel(r, req.ClientLabel),
+		})
+		if err == nil && ec.Endpoint == "" {
+			err = errors.New("an endpoint is required for export")
+		}
+		if err != nil {
+			entry.Error = err.Error()
+			writeJSON(w, http.StatusBadRequest, queryResponse{Error: err.Error()})
+			return
+		}
+		jobID, err := s.startExport(req.Export, statement, ec)
+		if err != nil {
+			entry.Error = err.Error()
+			writeJSON(w, http.StatusBadRequest, queryResponse{Error: err.Error()})
+			return
+		}
+		entry.LogsQL = statement.LogsQL
+		writeJSON(w, http.StatusAccepted, queryResponse{LogsQL: statement.LogsQL, JobID: jobID})
+		return
+	}
+	if statement.LogsQL != "" && !req.BypassGuardrails {
+		guardrails := s.guardrails
+		if n, ok := sess.options.MaxRows(); ok {
+			guardrails.MaxRows = n
+		}
+		statement.LogsQL, err = guardrails.Apply(statement.LogsQL)
+		if err != nil {
+			entry.Error = err.Error()
+			var te *logsql.TranslationError
+			if errors.As(err, &te) {
+				writeJSON(w, te.Code, queryResponse{Error: te.Message})
+			} else {
+				writeJSON(w, http.StatusBadRequest, queryResponse{Error: err.Error()})
+			}
+			return
+		}
+	}
+
+	entry.LogsQL = statement.LogsQL
+	resp := queryResponse{LogsQL: statement.LogsQL, Warnings: statement.Warnings}
+	if statement.LogsQL != "" {
+		resp.Fields = logsql.FieldHints(statement.LogsQL)
+		if req.Pretty {
+			resp.LogsQL = logsql.PrettyLogsQL(statement.LogsQL)
+		}
+	}
+	stream, err := s.api.ExecuteStream(r.Context(), statement, vlogs.EndpointConfig{
 		Endpoint:    req.Endpoint,
 		BearerToken: req.BearerToken,
+		ClientLabel: requestClientLabel(r, req.ClientLabel),
 	})
 	if err != nil {
+		entry.Error = err.Error()
 		log.Printf("ERROR: query execution failed: %v", err)
 		var ae *vlogs.APIError
 		var te *logsql.TranslationError
@@ -181,25 +664,123 @@ func (s *Server) handleQuery(w http.ResponseWriter, r *http.Request) {
 		}
 		return
 	}
-	resp.Data = string(data)
-	writeJSON(w, http.StatusOK, resp)
+	defer stream.Close()
+	if len(hashes) > 0 {
+		// Hash projections rewrite every row, so the result has to be
+		// buffered; the response size cap keeps that bounded.
+		data, err := io.ReadAll(stream)
+		if err != nil {
+			entry.Error = err.Error()
+			log.Printf("ERROR: failed to read query result: %v", err)
+			writeJSON(w, http.StatusBadGateway, queryResponse{Error: "failed to read query result"})
+			return
+		}
+		entry.Bytes = len(data)
+		resp.Data = string(applyHashes(data, hashes))
+		writeJSON(w, http.StatusOK, resp)
+		return
+	}
+	entry.Bytes = writeJSONDataStream(w, resp, stream)
+}
+
+// requestFeatures merges the config-level feature flags with the ones the
+// request enables, rejecting unknown flag names.
+func (s *Server) requestFeatures(req queryRequest) ([]string, error) {
+	if err := logsql.ValidateFeatures(req.Features); err != nil {
+		return nil, err
+	}
+	if len(req.Features) == 0 {
+		return s.features, nil
+	}
+	return append(append([]string(nil), s.features...), req.Features...), nil
+}
+
+// requestClientLabel picks the caller label for upstream attribution: the
+// request payload wins, then the X-SQL2LogsQL-Client request header. The
+// configured clientLabel applies as the fallback when both are empty.
+func requestClientLabel(r *http.Request, requested string) string {
+	if requested = strings.TrimSpace(requested); requested != "" {
+		return requested
+	}
+	return strings.TrimSpace(r.Header.Get("X-SQL2LogsQL-Client"))
 }
 
-func processQuery(sql string, sp *store.Provider) (*logsql.StatementInfo, error) {
+// session returns the state for the request's session. Sessions are keyed by
+// bearer token; requests without a token share one anonymous session.
+func (s *Server) session(r *http.Request, bearerToken string) *session {
+	key := strings.TrimSpace(bearerToken)
+	if key == "" {
+		key = strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+	}
+	s.sessionMu.Lock()
+	defer s.sessionMu.Unlock()
+	now := time.Now()
+	s.evictSessionsLocked(now)
+	sess, ok := s.sessions[key]
+	if !ok {
+		if len(s.sessions) >= maxSessions {
+			s.dropOldestSessionLocked()
+		}
+		sess = &session{
+			views:   viewstore.NewMemStore(),
+			options: store.NewSessionOptions(),
+			history: newQueryHistory(s.historySize),
+		}
+		s.sessions[key] = sess
+	}
+	sess.lastUsed = now
+	return sess
+}
+
+// evictSessionsLocked drops sessions idle for longer than sessionTTL. The
+// caller must hold sessionMu.
+func (s *Server) evictSessionsLocked(now time.Time) {
+	for key, sess := range s.sessions {
+		if now.Sub(sess.lastUsed) > sessionTTL {
+			delete(s.sessions, key)
+		}
+	}
+}
+
+// dropOldestSessionLocked removes the least recently used session. The caller
+// must hold sessionMu.
+func (s *Server) dropOldestSessionLocked() {
+	var oldestKey string
+	var oldest time.Time
+	first := true
+	for key, sess := range s.sessions {
+		if first || sess.lastUsed.Before(oldest) {
+			oldestKey, oldest, first = key, sess.lastUsed, false
+		}
+	}
+	if !first {
+		delete(s.sessions, oldestKey)
+	}
+}
+
+func processQuery(sql string, sp *store.Provider, rewriters []logsql.Rewriter) (*logsql.StatementInfo, []string, []hashedField, error) {
 	l := lexer.New(sql)
 	p := parser.New(l)
 	stmt := p.ParseStatement()
 	if stmt == nil {
-		return nil, errors.New("no statement parsed")
+		return nil, nil, nil, errors.New("no statement parsed")
 	}
 	if perrs := p.Errors(); len(perrs) > 0 {
-		return nil, fmt.Errorf("parse errors: %w", errors.Join(perrs...))
+		return nil, nil, nil, fmt.Errorf("parse errors: %w", errors.Join(perrs...))
+	}
+	stmt, err := logsql.ApplyRewriters(stmt, rewriters)
+	if err != nil {
+		return nil, nil, nil, err
 	}
+	hashes := extractHashProjections(stmt)
 	result, err := logsql.GetStatementInfo(stmt, sp)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
+	}
+	if sp.SessionOptions().PreserveComments() {
+		result.LogsQL = logsql.AppendCommentAnnotation(result.LogsQL, logsql.LeadingComments(sql))
 	}
-	return result, nil
+	return result, logsql.ReferencedBaseTables(stmt, sp), hashes, nil
 }
 
 func writeJSON(w http.ResponseWriter, status int, v interface{}) {
@@ -210,6 +791,79 @@ func writeJSON(w http.ResponseWriter, status int, v interface{}) {
 	}
 }
 
+// writeJSONDataStream encodes resp like writeJSON but copies the data field
+// straight from the result stream into the JSON string, so a result that
+// spilled to disk is relayed without being rebuilt in memory. It returns the
+// number of data bytes written.
+func writeJSONDataStream(w http.ResponseWriter, resp queryResponse, stream io.Reader) int {
+	resp.Data = ""
+	envelope, err := json.Marshal(resp)
+	if err != nil {
+		log.Printf("ERROR: failed to encode JSON response: %v", err)
+		writeJSON(w, http.StatusInternalServerError, queryResponse{Error: "failed to encode response"})
+		return 0
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	// Splice the data field in front of the closing brace of the envelope.
+	w.Write(envelope[:len(envelope)-1])
+	sep := `,"data":"`
+	if len(envelope) == 2 {
+		sep = `"data":"`
+	}
+	io.WriteString(w, sep)
+	esc := &jsonStringEscaper{w: w}
+	if _, err := io.Copy(esc, stream); err != nil {
+		// The status line is already on the wire; logging is all that's left.
+		log.Printf("ERROR: failed to stream query result: %v", err)
+	}
+	io.WriteString(w, "\"}\n")
+	return esc.n
+}
+
+// jsonStringEscaper writes its input as the contents of a JSON string,
+// escaping quotes, backslashes and control characters. Multi-byte UTF-8
+// sequences pass through untouched.
+type jsonStringEscaper struct {
+	w io.Writer
+	n int
+}
+
+func (e *jsonStringEscaper) Write(p []byte) (int, error) {
+	start := 0
+	for i := 0; i < len(p); i++ {
+		var esc string
+		switch b := p[i]; {
+		case b == '"':
+			esc = `\"`
+		case b == '\\':
+			esc = `\\`
+		case b == '\n':
+			esc = `\n`
+		case b == '\r':
+			esc = `\r`
+		case b == '\t':
+			esc = `\t`
+		case b < 0x20:
+			esc = fmt.Sprintf(`\u%04x`, b)
+		default:
+			continue
+		}
+		if _, err := e.w.Write(p[start:i]); err != nil {
+			return start, err
+		}
+		if _, err := io.WriteString(e.w, esc); err != nil {
+			return i, err
+		}
+		start = i + 1
+	}
+	if _, err := e.w.Write(p[start:]); err != nil {
+		return start, err
+	}
+	e.n += len(p)
+	return len(p), nil
+}
+
 var (
 	indexOnce  sync.Once
 	indexBytes []byte
@@ -286,3 +940,15 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	}
 	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
 }
+
+// handleUpstreamStats reports the connection reuse counters of the upstream
+// client, so the per-host limits in the upstream config can be tuned against
+// real dashboard load.
+func (s *Server) handleUpstreamStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.api.ConnStats())
+}
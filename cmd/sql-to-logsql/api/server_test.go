@@ -3,13 +3,17 @@ package api
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 )
 
 type roundTripperFunc func(*http.Request) (*http.Response, error)
@@ -161,6 +165,286 @@ func TestHandleQueryVictoriaError(t *testing.T) {
 	}
 }
 
+func TestHandleInsertSelect(t *testing.T) {
+	srv, err := NewServer(Config{
+		Endpoint:     "http://victoria",
+		Tables:       map[string]string{"logs": "*"},
+		EnableInsert: true,
+	})
+	if err != nil {
+		t.Fatalf("NewServer error: %v", err)
+	}
+	var inserted string
+	srv.setHTTPClient(&http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		switch req.URL.Path {
+		case "/select/logsql/query":
+			resp := &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewBufferString(`{"level":"error","_msg":"boom"}` + "\n")),
+				Header:     make(http.Header),
+			}
+			return resp, nil
+		case "/insert/jsonline":
+			if got := req.URL.Query().Get("_stream_fields"); got != "stream" {
+				t.Fatalf("unexpected _stream_fields: %q", got)
+			}
+			body, _ := io.ReadAll(req.Body)
+			inserted = strings.TrimSpace(string(body))
+			resp := &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewBufferString("")),
+				Header:     make(http.Header),
+			}
+			return resp, nil
+		default:
+			t.Fatalf("unexpected request path: %s", req.URL.Path)
+			return nil, nil
+		}
+	})})
+
+	reqBody := map[string]string{"sql": "INSERT INTO derived_logs SELECT * FROM logs WHERE level = 'error'"}
+	buf, _ := json.Marshal(reqBody)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/sql-to-logsql", bytes.NewReader(buf))
+	req.Header.Set("Content-Type", "application/json")
+
+	rr := httptest.NewRecorder()
+	srv.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var row map[string]string
+	if err := json.Unmarshal([]byte(inserted), &row); err != nil {
+		t.Fatalf("invalid ingested row: %v", err)
+	}
+	if row["stream"] != "derived_logs" || row["level"] != "error" {
+		t.Fatalf("unexpected ingested row: %s", inserted)
+	}
+	var resp struct {
+		Data string `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid json response: %v", err)
+	}
+	if !strings.Contains(resp.Data, `"inserted":1`) {
+		t.Fatalf("unexpected insert summary: %s", resp.Data)
+	}
+}
+
+func TestHandleInsertDisabled(t *testing.T) {
+	srv, err := NewServer(Config{Endpoint: "http://victoria", Tables: map[string]string{"logs": "*"}})
+	if err != nil {
+		t.Fatalf("NewServer error: %v", err)
+	}
+
+	reqBody := map[string]string{"sql": "INSERT INTO derived_logs SELECT * FROM logs"}
+	buf, _ := json.Marshal(reqBody)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/sql-to-logsql", bytes.NewReader(buf))
+	req.Header.Set("Content-Type", "application/json")
+
+	rr := httptest.NewRecorder()
+	srv.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected status 403, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestHandleTemporaryViewSession(t *testing.T) {
+	srv, err := NewServer(Config{Tables: map[string]string{"logs": "*"}})
+	if err != nil {
+		t.Fatalf("NewServer error: %v", err)
+	}
+
+	post := func(sql, token string) *httptest.ResponseRecorder {
+		t.Helper()
+		buf, _ := json.Marshal(map[string]string{"sql": sql})
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/sql-to-logsql", bytes.NewReader(buf))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rr := httptest.NewRecorder()
+		srv.ServeHTTP(rr, req)
+		return rr
+	}
+
+	if rr := post("CREATE TEMPORARY VIEW errors AS SELECT * FROM logs WHERE level = 'error'", "alice"); rr.Code != http.StatusOK {
+		t.Fatalf("create temporary view failed: %d: %s", rr.Code, rr.Body.String())
+	}
+
+	rr := post("SELECT * FROM errors", "alice")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("select from temporary view failed: %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp struct {
+		LogsQL string `json:"logsql"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid json response: %v", err)
+	}
+	if resp.LogsQL != "level:error" {
+		t.Fatalf("unexpected LogsQL: %q", resp.LogsQL)
+	}
+
+	if rr := post("SELECT * FROM errors", "bob"); rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for other session, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	if rr := post("DROP VIEW errors", "alice"); rr.Code != http.StatusOK {
+		t.Fatalf("drop temporary view failed: %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr := post("SELECT * FROM errors", "alice"); rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 after drop, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestSessionEviction(t *testing.T) {
+	srv, err := NewServer(Config{Tables: map[string]string{"logs": "*"}})
+	if err != nil {
+		t.Fatalf("NewServer error: %v", err)
+	}
+
+	sessionFor := func(token string) *session {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/sql-to-logsql", nil)
+		return srv.session(req, token)
+	}
+
+	// An idle session past its TTL is dropped the next time any session is
+	// looked up, so arbitrary tokens cannot pin server memory.
+	sessionFor("alice")
+	srv.sessionMu.Lock()
+	srv.sessions["alice"].lastUsed = time.Now().Add(-sessionTTL - time.Minute)
+	srv.sessionMu.Unlock()
+	sessionFor("bob")
+	srv.sessionMu.Lock()
+	_, ok := srv.sessions["alice"]
+	srv.sessionMu.Unlock()
+	if ok {
+		t.Fatal("expected the idle session to be evicted")
+	}
+
+	// At the cap the least recently used session makes room for a new one.
+	srv.sessionMu.Lock()
+	for i := len(srv.sessions); i < maxSessions; i++ {
+		srv.sessions[fmt.Sprintf("tok-%d", i)] = &session{lastUsed: time.Now()}
+	}
+	srv.sessions["bob"].lastUsed = time.Now().Add(-time.Minute)
+	srv.sessionMu.Unlock()
+	sessionFor("carol")
+	srv.sessionMu.Lock()
+	_, hasBob := srv.sessions["bob"]
+	_, hasCarol := srv.sessions["carol"]
+	total := len(srv.sessions)
+	srv.sessionMu.Unlock()
+	if hasBob || !hasCarol || total > maxSessions {
+		t.Fatalf("expected the LRU session to be replaced, bob=%v carol=%v total=%d", hasBob, hasCarol, total)
+	}
+}
+
+func TestHandleSetSessionOption(t *testing.T) {
+	srv, err := NewServer(Config{Tables: map[string]string{"logs": "*"}})
+	if err != nil {
+		t.Fatalf("NewServer error: %v", err)
+	}
+
+	post := func(sql string) *httptest.ResponseRecorder {
+		t.Helper()
+		buf, _ := json.Marshal(map[string]string{"sql": sql})
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/sql-to-logsql", bytes.NewReader(buf))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer alice")
+		rr := httptest.NewRecorder()
+		srv.ServeHTTP(rr, req)
+		return rr
+	}
+
+	if rr := post("SET max_rows = 2"); rr.Code != http.StatusOK {
+		t.Fatalf("SET failed: %d: %s", rr.Code, rr.Body.String())
+	}
+	rr := post("SELECT * FROM logs WHERE level = 'error'")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("select failed: %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp struct {
+		LogsQL string `json:"logsql"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid json response: %v", err)
+	}
+	if resp.LogsQL != "level:error | limit 2" {
+		t.Fatalf("unexpected LogsQL: %q", resp.LogsQL)
+	}
+
+	if rr := post("RESET max_rows"); rr.Code != http.StatusOK {
+		t.Fatalf("RESET failed: %d: %s", rr.Code, rr.Body.String())
+	}
+	rr = post("SELECT * FROM logs WHERE level = 'error'")
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid json response: %v", err)
+	}
+	if resp.LogsQL != "level:error" {
+		t.Fatalf("unexpected LogsQL after RESET: %q", resp.LogsQL)
+	}
+
+	if rr := post("SET not_an_option = 1"); rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown option, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestHandleQueryTimeZone(t *testing.T) {
+	srv, err := NewServer(Config{Tables: map[string]string{"logs": "*"}})
+	if err != nil {
+		t.Fatalf("NewServer error: %v", err)
+	}
+
+	post := func(body map[string]string) *httptest.ResponseRecorder {
+		t.Helper()
+		buf, _ := json.Marshal(body)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/sql-to-logsql", bytes.NewReader(buf))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer alice")
+		rr := httptest.NewRecorder()
+		srv.ServeHTTP(rr, req)
+		return rr
+	}
+	logsQL := func(rr *httptest.ResponseRecorder) string {
+		t.Helper()
+		var resp struct {
+			LogsQL string `json:"logsql"`
+		}
+		if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("invalid json response: %v", err)
+		}
+		return resp.LogsQL
+	}
+
+	sql := "SELECT * FROM logs WHERE _time >= '2024-01-15 00:00:00'"
+
+	if got := logsQL(post(map[string]string{"sql": sql})); got != `_time:>="2024-01-15 00:00:00"` {
+		t.Fatalf("unexpected UTC LogsQL: %q", got)
+	}
+
+	rr := post(map[string]string{"sql": sql, "timeZone": "America/New_York"})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("request failed: %d: %s", rr.Code, rr.Body.String())
+	}
+	if got := logsQL(rr); got != "_time:>=2024-01-15T05:00:00Z" {
+		t.Fatalf("unexpected converted LogsQL: %q", got)
+	}
+
+	if rr := post(map[string]string{"sql": "SET time_zone = 'America/New_York'"}); rr.Code != http.StatusOK {
+		t.Fatalf("SET time_zone failed: %d: %s", rr.Code, rr.Body.String())
+	}
+	if got := logsQL(post(map[string]string{"sql": sql})); got != "_time:>=2024-01-15T05:00:00Z" {
+		t.Fatalf("unexpected session-zone LogsQL: %q", got)
+	}
+
+	if rr := post(map[string]string{"sql": sql, "timeZone": "Not/AZone"}); rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for bad zone, got %d", rr.Code)
+	}
+}
+
 func TestHandleDescribeTable(t *testing.T) {
 	srv, err := NewServer(Config{Endpoint: "http://victoria", Tables: map[string]string{"logs": "*"}})
 	if err != nil {
@@ -410,3 +694,529 @@ func TestHandleHealth(t *testing.T) {
 		t.Fatalf("unexpected health status: %v", resp["status"])
 	}
 }
+
+func TestHandleViewsStatusFilter(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "good.logsql"), []byte("* | level:ERROR\n"), 0o644); err != nil {
+		t.Fatalf("failed to write view: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "bad.logsql"), []byte("(level:error\n"), 0o644); err != nil {
+		t.Fatalf("failed to write view: %v", err)
+	}
+	srv, err := NewServer(Config{ViewsDir: dir})
+	if err != nil {
+		t.Fatalf("NewServer error: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/views", nil)
+	rr := httptest.NewRecorder()
+	srv.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	var views []struct {
+		Name   string `json:"name"`
+		Status string `json:"status"`
+		Error  string `json:"error"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &views); err != nil {
+		t.Fatalf("invalid json response: %v", err)
+	}
+	if len(views) != 2 {
+		t.Fatalf("expected 2 views, got %d", len(views))
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/views?status=broken", nil)
+	rr = httptest.NewRecorder()
+	srv.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &views); err != nil {
+		t.Fatalf("invalid json response: %v", err)
+	}
+	if len(views) != 1 || views[0].Name != "bad" || views[0].Status != "broken" || views[0].Error == "" {
+		t.Fatalf("unexpected broken views payload: %+v", views)
+	}
+}
+
+func TestEndpointOverrideAllowlist(t *testing.T) {
+	srv, err := NewServer(Config{
+		Tables:           map[string]string{"logs": "*"},
+		AllowedEndpoints: []string{"http://victoria-a:9428"},
+	})
+	if err != nil {
+		t.Fatalf("NewServer error: %v", err)
+	}
+	srv.setHTTPClient(&http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			resp := &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewBufferString(`{"status":"ok"}`)),
+				Header:     make(http.Header),
+			}
+			resp.Header.Set("Content-Type", "application/json")
+			return resp, nil
+		}),
+	})
+
+	run := func(endpoint string) *httptest.ResponseRecorder {
+		buf, _ := json.Marshal(map[string]string{"sql": "SELECT * FROM logs", "endpoint": endpoint})
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/sql-to-logsql", bytes.NewReader(buf))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		srv.ServeHTTP(rr, req)
+		return rr
+	}
+
+	if rr := run("http://victoria-a:9428/"); rr.Code != http.StatusOK {
+		t.Fatalf("expected allowed endpoint to succeed, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr := run("http://169.254.169.254/latest/meta-data"); rr.Code != http.StatusForbidden {
+		t.Fatalf("expected disallowed endpoint to be rejected with 403, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestEndpointOverrideDisabled(t *testing.T) {
+	srv, err := NewServer(Config{
+		Tables:                  map[string]string{"logs": "*"},
+		DisableEndpointOverride: true,
+	})
+	if err != nil {
+		t.Fatalf("NewServer error: %v", err)
+	}
+
+	buf, _ := json.Marshal(map[string]string{"sql": "SELECT * FROM logs", "endpoint": "http://victoria:9428"})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/sql-to-logsql", bytes.NewReader(buf))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	srv.ServeHTTP(rr, req)
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 when overrides are disabled, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestRequestSizeLimits(t *testing.T) {
+	srv, err := NewServer(Config{
+		Tables:       map[string]string{"logs": "*"},
+		MaxBodyBytes: 256,
+		MaxSQLLength: 64,
+	})
+	if err != nil {
+		t.Fatalf("NewServer error: %v", err)
+	}
+
+	post := func(body []byte) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/sql-to-logsql", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		srv.ServeHTTP(rr, req)
+		return rr
+	}
+
+	buf, _ := json.Marshal(map[string]string{"sql": "SELECT * FROM logs"})
+	if rr := post(buf); rr.Code != http.StatusOK {
+		t.Fatalf("expected a small request to succeed, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	buf, _ = json.Marshal(map[string]string{
+		"sql": "SELECT * FROM logs WHERE user = 'x'",
+		"to":  strings.Repeat("9", 512),
+	})
+	if rr := post(buf); rr.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413 for oversized body, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	buf, _ = json.Marshal(map[string]string{
+		"sql": "SELECT * FROM logs WHERE user = '" + strings.Repeat("a", 64) + "'",
+	})
+	rr := post(buf)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for over-length SQL, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if !strings.Contains(rr.Body.String(), "exceeds the maximum") {
+		t.Fatalf("unexpected error message: %s", rr.Body.String())
+	}
+}
+
+func TestPanicRecovery(t *testing.T) {
+	srv, err := NewServer(Config{Tables: map[string]string{"logs": "*"}})
+	if err != nil {
+		t.Fatalf("NewServer error: %v", err)
+	}
+	srv.mux.HandleFunc("/panic", func(http.ResponseWriter, *http.Request) {
+		panic("boom")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/panic", nil)
+	rr := httptest.NewRecorder()
+	srv.ServeHTTP(rr, req)
+	if rr.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500 from panicking handler, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if !strings.Contains(rr.Body.String(), "request id req_") {
+		t.Fatalf("expected a request id in the error, got %s", rr.Body.String())
+	}
+}
+
+func TestClientLabelHeader(t *testing.T) {
+	srv, err := NewServer(Config{
+		Endpoint:    "http://victoria",
+		Tables:      map[string]string{"logs": "*"},
+		ClientLabel: "sql2logsql-prod",
+	})
+	if err != nil {
+		t.Fatalf("NewServer error: %v", err)
+	}
+	var gotLabel string
+	srv.setHTTPClient(&http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			gotLabel = req.Header.Get("X-SQL2LogsQL-Client")
+			resp := &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewBufferString(`{}`)),
+				Header:     make(http.Header),
+			}
+			return resp, nil
+		}),
+	})
+
+	post := func(body map[string]string) {
+		t.Helper()
+		buf, _ := json.Marshal(body)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/sql-to-logsql", bytes.NewReader(buf))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		srv.ServeHTTP(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+	}
+
+	post(map[string]string{"sql": "SELECT * FROM logs"})
+	if gotLabel != "sql2logsql-prod" {
+		t.Fatalf("expected configured label, got %q", gotLabel)
+	}
+
+	post(map[string]string{"sql": "SELECT * FROM logs", "clientLabel": "latency-dashboard"})
+	if gotLabel != "latency-dashboard" {
+		t.Fatalf("expected per-request label to win, got %q", gotLabel)
+	}
+}
+
+func TestValidateColumns(t *testing.T) {
+	srv, err := NewServer(Config{Endpoint: "http://victoria", Tables: map[string]string{"logs": "*"}})
+	if err != nil {
+		t.Fatalf("NewServer error: %v", err)
+	}
+	fieldNamesCalls := 0
+	srv.setHTTPClient(&http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			body := `{}`
+			if strings.HasSuffix(req.URL.Path, "/select/logsql/field_names") {
+				fieldNamesCalls++
+				body = `{"values":[{"value":"_time","hits":10},{"value":"level","hits":10},{"value":"user","hits":7}]}`
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewBufferString(body)),
+				Header:     make(http.Header),
+			}, nil
+		}),
+	})
+
+	post := func(sql string) *httptest.ResponseRecorder {
+		t.Helper()
+		buf, _ := json.Marshal(map[string]any{"sql": sql, "validateColumns": true})
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/sql-to-logsql", bytes.NewReader(buf))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		srv.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := post("SELECT usr FROM logs WHERE lvel = 'error'")
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d: %s", rr.Code, rr.Body.String())
+	}
+	errMsg := rr.Body.String()
+	if !strings.Contains(errMsg, "did you mean user?") || !strings.Contains(errMsg, "did you mean level?") {
+		t.Fatalf("expected suggestions for both typos, got: %s", errMsg)
+	}
+
+	rr = post("SELECT user FROM logs WHERE level = 'error'")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if fieldNamesCalls != 1 {
+		t.Fatalf("expected field names to be fetched once and cached, got %d calls", fieldNamesCalls)
+	}
+}
+
+func TestSchemaCacheTTLAndInvalidation(t *testing.T) {
+	srv, err := NewServer(Config{Endpoint: "http://victoria", Tables: map[string]string{"logs": "*"}})
+	if err != nil {
+		t.Fatalf("NewServer error: %v", err)
+	}
+	fieldNamesCalls := 0
+	srv.setHTTPClient(&http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			body := `{}`
+			if strings.HasSuffix(req.URL.Path, "/select/logsql/field_names") {
+				fieldNamesCalls++
+				body = `{"values":[{"value":"user","hits":1}]}`
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewBufferString(body)),
+				Header:     make(http.Header),
+			}, nil
+		}),
+	})
+
+	post := func() {
+		t.Helper()
+		buf, _ := json.Marshal(map[string]any{"sql": "SELECT user FROM logs", "validateColumns": true})
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/sql-to-logsql", bytes.NewReader(buf))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		srv.ServeHTTP(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+	}
+
+	post()
+	post()
+	if fieldNamesCalls != 1 {
+		t.Fatalf("expected a single upstream lookup, got %d", fieldNamesCalls)
+	}
+
+	rr := httptest.NewRecorder()
+	srv.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/api/v1/schema-cache?table=logs", nil))
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"invalidated":1`) {
+		t.Fatalf("unexpected invalidation response %d: %s", rr.Code, rr.Body.String())
+	}
+	post()
+	if fieldNamesCalls != 2 {
+		t.Fatalf("expected a fresh lookup after invalidation, got %d", fieldNamesCalls)
+	}
+
+	srv.schema.ttl = time.Nanosecond
+	time.Sleep(time.Millisecond)
+	post()
+	if fieldNamesCalls != 3 {
+		t.Fatalf("expected a fresh lookup after TTL expiry, got %d", fieldNamesCalls)
+	}
+
+	rr = httptest.NewRecorder()
+	srv.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/schema-cache", nil))
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405 for GET, got %d", rr.Code)
+	}
+}
+
+func TestHandleComplete(t *testing.T) {
+	srv, err := NewServer(Config{
+		Endpoint: "http://victoria",
+		Tables:   map[string]string{"logs": "*", "errors": "level:ERROR"},
+	})
+	if err != nil {
+		t.Fatalf("NewServer error: %v", err)
+	}
+	srv.schema.put(schemaCacheKey("logs", "http://victoria"), []string{"level", "user"})
+
+	get := func(sql string, pos int) completeResponse {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/complete?pos="+strconv.Itoa(pos)+"&sql="+url.QueryEscape(sql), nil)
+		rr := httptest.NewRecorder()
+		srv.ServeHTTP(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var resp completeResponse
+		if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("invalid json response: %v", err)
+		}
+		return resp
+	}
+	has := func(resp completeResponse, kind, text string) bool {
+		for _, item := range resp.Suggestions {
+			if item.Kind == kind && item.Text == text {
+				return true
+			}
+		}
+		return false
+	}
+
+	sql := "SELECT * FROM lo"
+	resp := get(sql, len(sql))
+	if !has(resp, "table", "logs") {
+		t.Fatalf("expected table suggestion for %q, got %v", sql, resp.Suggestions)
+	}
+	if has(resp, "table", "errors") {
+		t.Fatalf("expected prefix filtering, got %v", resp.Suggestions)
+	}
+
+	sql = "SELECT us FROM logs"
+	resp = get(sql, len("SELECT us"))
+	if !has(resp, "field", "user") {
+		t.Fatalf("expected cached field suggestion, got %v", resp.Suggestions)
+	}
+
+	sql = "SELECT UP FROM logs"
+	resp = get(sql, len("SELECT UP"))
+	if !has(resp, "function", "UPPER") {
+		t.Fatalf("expected function suggestions, got %v", resp.Suggestions)
+	}
+
+	resp = get("SEL", 3)
+	if !has(resp, "keyword", "SELECT") {
+		t.Fatalf("expected keyword suggestion at statement start, got %v", resp.Suggestions)
+	}
+}
+
+func TestHandleTokens(t *testing.T) {
+	srv, err := NewServer(Config{
+		Endpoint: "http://victoria",
+		Tables:   map[string]string{"logs": "*"},
+	})
+	if err != nil {
+		t.Fatalf("NewServer error: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/tokens?sql="+url.QueryEscape("SELECT *\nFROM logs @"), nil)
+	rr := httptest.NewRecorder()
+	srv.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp tokensResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid json response: %v", err)
+	}
+	expected := []tokenInfo{
+		{Type: "SELECT", Literal: "SELECT", Line: 1, Column: 1},
+		{Type: "*", Literal: "*", Line: 1, Column: 8},
+		{Type: "FROM", Literal: "FROM", Line: 2, Column: 1},
+		{Type: "IDENT", Literal: "logs", Line: 2, Column: 6},
+		{Type: "ILLEGAL", Literal: "@", Line: 2, Column: 11},
+	}
+	if len(resp.Tokens) != len(expected) {
+		t.Fatalf("expected %d tokens, got %v", len(expected), resp.Tokens)
+	}
+	for i, want := range expected {
+		if resp.Tokens[i] != want {
+			t.Fatalf("token %d: expected %+v, got %+v", i, want, resp.Tokens[i])
+		}
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/tokens", nil)
+	rr = httptest.NewRecorder()
+	srv.ServeHTTP(rr, req)
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected status 405, got %d", rr.Code)
+	}
+}
+
+func TestHandleQueryResultLimits(t *testing.T) {
+	upstream := func(body string) *http.Client {
+		return &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewBufferString(body)),
+				Header:     make(http.Header),
+			}, nil
+		})}
+	}
+	post := func(srv *Server) *httptest.ResponseRecorder {
+		t.Helper()
+		buf, _ := json.Marshal(map[string]string{"sql": "SELECT * FROM logs"})
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/sql-to-logsql", bytes.NewReader(buf))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		srv.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// A result above the hard cap is rejected instead of buffered.
+	srv, err := NewServer(Config{
+		Endpoint:       "http://victoria",
+		Tables:         map[string]string{"logs": "*"},
+		MaxResultBytes: 16,
+	})
+	if err != nil {
+		t.Fatalf("NewServer error: %v", err)
+	}
+	srv.setHTTPClient(upstream(strings.Repeat(`{"a":"1"}`+"\n", 100)))
+	if rr := post(srv); rr.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected status 413, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	// A result above the memory budget but below the cap spills to disk and
+	// still comes back intact.
+	srv, err = NewServer(Config{
+		Endpoint:          "http://victoria",
+		Tables:            map[string]string{"logs": "*"},
+		ResultMemoryBytes: 8,
+	})
+	if err != nil {
+		t.Fatalf("NewServer error: %v", err)
+	}
+	rows := strings.Repeat(`{"a":"1"}`+"\n", 100)
+	srv.setHTTPClient(upstream(rows))
+	rr := post(srv)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp struct {
+		Data string `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid json response: %v", err)
+	}
+	if resp.Data != rows {
+		t.Fatalf("spilled result differs from upstream body (%d vs %d bytes)", len(resp.Data), len(rows))
+	}
+}
+
+func TestUpstreamStats(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"level":"error"}` + "\n"))
+	}))
+	defer upstream.Close()
+
+	srv, err := NewServer(Config{Endpoint: upstream.URL, Tables: map[string]string{"logs": "*"}})
+	if err != nil {
+		t.Fatalf("NewServer error: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		buf, _ := json.Marshal(map[string]string{"sql": "SELECT * FROM logs"})
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/sql-to-logsql", bytes.NewReader(buf))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		srv.ServeHTTP(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("query %d failed with status %d: %s", i, rr.Code, rr.Body.String())
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/upstream-stats", nil)
+	rr := httptest.NewRecorder()
+	srv.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	var stats struct {
+		Requests uint64 `json:"requests"`
+		Reused   uint64 `json:"reusedConns"`
+		Dialed   uint64 `json:"dialedConns"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("invalid json response: %v", err)
+	}
+	if stats.Requests != 2 || stats.Dialed < 1 || stats.Reused+stats.Dialed != 2 {
+		t.Fatalf("unexpected connection stats: %s", rr.Body.String())
+	}
+}
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/sql/lexer"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/sql/token"
+)
+
+type tokenInfo struct {
+	Type    string `json:"type"`
+	Literal string `json:"literal"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+}
+
+type tokensResponse struct {
+	Tokens []tokenInfo `json:"tokens"`
+}
+
+// handleTokens serves GET /api/v1/tokens?sql=..., returning the lexer's
+// token stream with positions. The embedded editor highlights from this
+// stream, so its colors and ILLEGAL markers always agree with the lexer the
+// server parses with.
+func (s *Server) handleTokens(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	l := lexer.New(r.URL.Query().Get("sql"))
+	tokens := []tokenInfo{}
+	for {
+		tok := l.NextToken()
+		if tok.Type == token.EOF {
+			break
+		}
+		tokens = append(tokens, tokenInfo{
+			Type:    string(tok.Type),
+			Literal: tok.Literal,
+			Line:    tok.Pos.Line,
+			Column:  tok.Pos.Column,
+		})
+	}
+	writeJSON(w, http.StatusOK, tokensResponse{Tokens: tokens})
+}
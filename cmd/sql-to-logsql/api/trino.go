@@ -0,0 +1,296 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/logsql"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/vlogs"
+)
+
+// The Trino REST protocol subset: POST /v1/statement submits a query and
+// every response either carries a nextUri to fetch the next page from
+// GET /v1/statement/{id}/{page} or marks the query finished. This is enough
+// for the Trino JDBC driver and trino-cli to run queries against
+// sql-to-logsql without a dedicated driver.
+
+// trinoPageSize is the number of rows returned per result page.
+const trinoPageSize = 1000
+
+// trinoQueryTTL is how long a buffered result waits for the client to fetch
+// the next page before it is evicted. JDBC clients that abandon paging would
+// otherwise leak the buffer forever.
+const trinoQueryTTL = 5 * time.Minute
+
+type trinoColumn struct {
+	Name          string             `json:"name"`
+	Type          string             `json:"type"`
+	TypeSignature trinoTypeSignature `json:"typeSignature"`
+}
+
+type trinoTypeSignature struct {
+	RawType   string `json:"rawType"`
+	Arguments []any  `json:"arguments"`
+}
+
+type trinoStats struct {
+	State string `json:"state"`
+}
+
+type trinoError struct {
+	Message   string `json:"message"`
+	ErrorCode int    `json:"errorCode"`
+	ErrorName string `json:"errorName"`
+	ErrorType string `json:"errorType"`
+}
+
+type trinoResponse struct {
+	ID      string        `json:"id"`
+	InfoURI string        `json:"infoUri"`
+	NextURI string        `json:"nextUri,omitempty"`
+	Columns []trinoColumn `json:"columns,omitempty"`
+	Data    [][]any       `json:"data,omitempty"`
+	Stats   trinoStats    `json:"stats"`
+	Error   *trinoError   `json:"error,omitempty"`
+}
+
+// trinoQuery holds the buffered result of a submitted statement until the
+// client has paged through it.
+type trinoQuery struct {
+	columns  []string
+	rows     [][]any
+	lastRead time.Time
+}
+
+// evictTrinoQueriesLocked drops buffered results whose clients stopped
+// paging. The caller must hold trinoMu.
+func (s *Server) evictTrinoQueriesLocked(now time.Time) {
+	for id, q := range s.trinoQueries {
+		if now.Sub(q.lastRead) > trinoQueryTTL {
+			delete(s.trinoQueries, id)
+		}
+	}
+}
+
+func newTrinoQueryID() string {
+	var buf [8]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		return "q_fallback"
+	}
+	return "q_" + hex.EncodeToString(buf[:])
+}
+
+func (s *Server) handleTrinoStatement(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	defer r.Body.Close()
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "failed to read request body", http.StatusBadRequest)
+		return
+	}
+	sqlText := strings.TrimSpace(string(body))
+	id := newTrinoQueryID()
+	if sqlText == "" {
+		writeJSON(w, http.StatusOK, trinoFailure(r, id, "sql query is required"))
+		return
+	}
+
+	statement, tables, hashes, err := processQuery(sqlText, s.sp, s.rewriters)
+	if err != nil {
+		log.Printf("ERROR: trino query processing failed: %v", err)
+		writeJSON(w, http.StatusOK, trinoFailure(r, id, err.Error()))
+		return
+	}
+	if fields := s.maskedFields(r, "", tables); len(fields) > 0 && statement.LogsQL != "" {
+		statement.LogsQL = logsql.MaskFields(statement.LogsQL, fields)
+	}
+	if statement.LogsQL != "" {
+		statement.LogsQL, err = s.guardrails.Apply(statement.LogsQL)
+		if err != nil {
+			writeJSON(w, http.StatusOK, trinoFailure(r, id, err.Error()))
+			return
+		}
+	}
+	data := []byte(statement.Data)
+	if statement.LogsQL != "" {
+		data, err = s.api.Execute(r.Context(), statement, vlogs.EndpointConfig{
+			ClientLabel: requestClientLabel(r, ""),
+		})
+		if err != nil {
+			log.Printf("ERROR: trino query execution failed: %v", err)
+			writeJSON(w, http.StatusOK, trinoFailure(r, id, err.Error()))
+			return
+		}
+	}
+	columns, rows, err := trinoRows(applyHashes(data, hashes))
+	if err != nil {
+		writeJSON(w, http.StatusOK, trinoFailure(r, id, err.Error()))
+		return
+	}
+
+	s.trinoMu.Lock()
+	if s.trinoQueries == nil {
+		s.trinoQueries = make(map[string]*trinoQuery)
+	}
+	s.evictTrinoQueriesLocked(time.Now())
+	s.trinoQueries[id] = &trinoQuery{columns: columns, rows: rows, lastRead: time.Now()}
+	s.trinoMu.Unlock()
+
+	writeJSON(w, http.StatusOK, trinoResponse{
+		ID:      id,
+		InfoURI: trinoBaseURI(r) + "/v1/statement/" + id,
+		NextURI: trinoBaseURI(r) + "/v1/statement/" + id + "/0",
+		Stats:   trinoStats{State: "RUNNING"},
+	})
+}
+
+func (s *Server) handleTrinoPage(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.Path, "/v1/statement/")
+	parts := strings.Split(rest, "/")
+	if len(parts) != 2 {
+		http.NotFound(w, r)
+		return
+	}
+	id := parts[0]
+
+	switch r.Method {
+	case http.MethodDelete:
+		s.trinoMu.Lock()
+		delete(s.trinoQueries, id)
+		s.trinoMu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+		return
+	case http.MethodGet:
+	default:
+		w.Header().Set("Allow", "GET, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	page, err := strconv.Atoi(parts[1])
+	if err != nil || page < 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.trinoMu.Lock()
+	s.evictTrinoQueriesLocked(time.Now())
+	q := s.trinoQueries[id]
+	if q != nil {
+		q.lastRead = time.Now()
+	}
+	s.trinoMu.Unlock()
+	if q == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	start := page * trinoPageSize
+	if start > len(q.rows) {
+		start = len(q.rows)
+	}
+	end := start + trinoPageSize
+	if end > len(q.rows) {
+		end = len(q.rows)
+	}
+
+	resp := trinoResponse{
+		ID:      id,
+		InfoURI: trinoBaseURI(r) + "/v1/statement/" + id,
+		Columns: trinoColumnList(q.columns),
+		Data:    q.rows[start:end],
+		Stats:   trinoStats{State: "FINISHED"},
+	}
+	if end < len(q.rows) {
+		resp.NextURI = fmt.Sprintf("%s/v1/statement/%s/%d", trinoBaseURI(r), id, page+1)
+		resp.Stats.State = "RUNNING"
+	} else {
+		// Last page: the buffered result is no longer needed.
+		s.trinoMu.Lock()
+		delete(s.trinoQueries, id)
+		s.trinoMu.Unlock()
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func trinoBaseURI(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+func trinoFailure(r *http.Request, id, message string) trinoResponse {
+	return trinoResponse{
+		ID:      id,
+		InfoURI: trinoBaseURI(r) + "/v1/statement/" + id,
+		Stats:   trinoStats{State: "FAILED"},
+		Error: &trinoError{
+			Message:   message,
+			ErrorCode: 1,
+			ErrorName: "GENERIC_USER_ERROR",
+			ErrorType: "USER_ERROR",
+		},
+	}
+}
+
+func trinoColumnList(names []string) []trinoColumn {
+	columns := make([]trinoColumn, 0, len(names))
+	for _, name := range names {
+		columns = append(columns, trinoColumn{
+			Name:          name,
+			Type:          "varchar",
+			TypeSignature: trinoTypeSignature{RawType: "varchar", Arguments: []any{}},
+		})
+	}
+	return columns
+}
+
+// trinoRows converts a JSONL payload from VictoriaLogs into a sorted column
+// list and positional row values as the Trino protocol expects.
+func trinoRows(data []byte) ([]string, [][]any, error) {
+	columns := make([]string, 0)
+	seen := make(map[string]struct{})
+	raws := make([]map[string]any, 0)
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		var raw map[string]any
+		if err := json.Unmarshal([]byte(line), &raw); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse result row: %w", err)
+		}
+		for name := range raw {
+			if _, ok := seen[name]; !ok {
+				seen[name] = struct{}{}
+				columns = append(columns, name)
+			}
+		}
+		raws = append(raws, raw)
+	}
+	sort.Strings(columns)
+	rows := make([][]any, 0, len(raws))
+	for _, raw := range raws {
+		row := make([]any, len(columns))
+		for i, name := range columns {
+			row[i] = raw[name]
+		}
+		rows = append(rows, row)
+	}
+	return columns, rows, nil
+}
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/logsql"
+)
+
+func TestTrinoStatementPaging(t *testing.T) {
+	srv, err := NewServer(Config{Endpoint: "http://victoria", Tables: map[string]string{"logs": "*"}})
+	if err != nil {
+		t.Fatalf("NewServer error: %v", err)
+	}
+	srv.setHTTPClient(&http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			resp := &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewBufferString("{\"_msg\":\"first\"}\n{\"_msg\":\"second\"}\n")),
+				Header:     make(http.Header),
+			}
+			resp.Header.Set("Content-Type", "application/json")
+			return resp, nil
+		}),
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/statement", strings.NewReader("SELECT * FROM logs"))
+	rr := httptest.NewRecorder()
+	srv.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	var submit trinoResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &submit); err != nil {
+		t.Fatalf("invalid json response: %v", err)
+	}
+	if submit.Error != nil {
+		t.Fatalf("unexpected error: %v", submit.Error)
+	}
+	if submit.NextURI == "" {
+		t.Fatal("expected nextUri in submit response")
+	}
+
+	pagePath := strings.TrimPrefix(submit.NextURI, "http://"+req.Host)
+	req = httptest.NewRequest(http.MethodGet, pagePath, nil)
+	rr = httptest.NewRecorder()
+	srv.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200 for page, got %d", rr.Code)
+	}
+	var page trinoResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &page); err != nil {
+		t.Fatalf("invalid json page response: %v", err)
+	}
+	if page.Stats.State != "FINISHED" {
+		t.Fatalf("expected FINISHED state, got %q", page.Stats.State)
+	}
+	if len(page.Columns) != 1 || page.Columns[0].Name != "_msg" {
+		t.Fatalf("unexpected columns: %+v", page.Columns)
+	}
+	if len(page.Data) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(page.Data))
+	}
+	if page.NextURI != "" {
+		t.Fatalf("expected no nextUri on final page, got %q", page.NextURI)
+	}
+
+	// The buffered result is dropped after the final page.
+	rr = httptest.NewRecorder()
+	srv.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, pagePath, nil))
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 after final page, got %d", rr.Code)
+	}
+}
+
+func TestTrinoGuardrails(t *testing.T) {
+	srv, err := NewServer(Config{
+		Endpoint:   "http://victoria",
+		Tables:     map[string]string{"logs": "*"},
+		Guardrails: logsql.Guardrails{RejectUnfiltered: true},
+	})
+	if err != nil {
+		t.Fatalf("NewServer error: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/statement", strings.NewReader("SELECT * FROM logs"))
+	rr := httptest.NewRecorder()
+	srv.ServeHTTP(rr, req)
+	var resp trinoResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid json response: %v", err)
+	}
+	if resp.Error == nil || !strings.Contains(resp.Error.Message, "guardrails") {
+		t.Fatalf("expected a guardrails rejection, got %+v", resp.Error)
+	}
+}
+
+func TestTrinoQueryEviction(t *testing.T) {
+	srv, err := NewServer(Config{Tables: map[string]string{"logs": "*"}})
+	if err != nil {
+		t.Fatalf("NewServer error: %v", err)
+	}
+	srv.trinoQueries = map[string]*trinoQuery{
+		"q_stale": {columns: []string{"_msg"}, lastRead: time.Now().Add(-2 * trinoQueryTTL)},
+		"q_live":  {columns: []string{"_msg"}, lastRead: time.Now()},
+	}
+
+	rr := httptest.NewRecorder()
+	srv.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v1/statement/q_stale/0", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an abandoned query, got %d", rr.Code)
+	}
+	if _, ok := srv.trinoQueries["q_live"]; !ok {
+		t.Fatal("live query must survive eviction")
+	}
+}
+
+func TestTrinoStatementError(t *testing.T) {
+	srv, err := NewServer(Config{Tables: map[string]string{"logs": "*"}})
+	if err != nil {
+		t.Fatalf("NewServer error: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/statement", strings.NewReader("SELECT * FROM missing"))
+	rr := httptest.NewRecorder()
+	srv.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	var resp trinoResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid json response: %v", err)
+	}
+	if resp.Error == nil {
+		t.Fatal("expected error in response")
+	}
+	if resp.Stats.State != "FAILED" {
+		t.Fatalf("expected FAILED state, got %q", resp.Stats.State)
+	}
+}
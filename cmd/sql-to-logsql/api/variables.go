@@ -0,0 +1,60 @@
+package api
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Template variables let stored dashboard queries be parameterized without
+// string concatenation on the client: occurrences of {{name}} in the SQL
+// text are replaced from the request's variables map before lexing. The
+// substitution is position aware: inside a single-quoted literal the value
+// is escaped as a string, everywhere else it must be a valid identifier or
+// number so a value can never smuggle in extra SQL.
+
+var identifierValueRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$|^-?[0-9]+(\.[0-9]+)?$`)
+
+// substituteVariables expands {{name}} placeholders in sql. Referencing a
+// variable that is not in vars is an error, as is using a value that is not
+// a bare identifier or number outside a quoted literal.
+func substituteVariables(sql string, vars map[string]string) (string, error) {
+	if !strings.Contains(sql, "{{") {
+		return sql, nil
+	}
+	var out strings.Builder
+	inQuote := false
+	for i := 0; i < len(sql); {
+		ch := sql[i]
+		if ch == '\'' {
+			inQuote = !inQuote
+			out.WriteByte(ch)
+			i++
+			continue
+		}
+		if ch == '{' && i+1 < len(sql) && sql[i+1] == '{' {
+			end := strings.Index(sql[i+2:], "}}")
+			if end < 0 {
+				return "", fmt.Errorf("unterminated variable reference at offset %d", i)
+			}
+			name := strings.TrimSpace(sql[i+2 : i+2+end])
+			value, ok := vars[name]
+			if !ok {
+				return "", fmt.Errorf("undefined variable %q", name)
+			}
+			if inQuote {
+				out.WriteString(strings.ReplaceAll(value, "'", "''"))
+			} else {
+				if !identifierValueRe.MatchString(value) {
+					return "", fmt.Errorf("variable %q value %q is not a valid identifier; quote the reference to use it as a literal", name, value)
+				}
+				out.WriteString(value)
+			}
+			i += 2 + end + 2
+			continue
+		}
+		out.WriteByte(ch)
+		i++
+	}
+	return out.String(), nil
+}
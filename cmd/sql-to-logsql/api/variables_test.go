@@ -0,0 +1,75 @@
+package api
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSubstituteVariables(t *testing.T) {
+	tests := []struct {
+		name    string
+		sql     string
+		vars    map[string]string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "identifier substitution",
+			sql:  "SELECT {{col}} FROM {{table}}",
+			vars: map[string]string{"col": "level", "table": "logs"},
+			want: "SELECT level FROM logs",
+		},
+		{
+			name: "literal substitution with escaping",
+			sql:  "SELECT * FROM logs WHERE msg = '{{value}}'",
+			vars: map[string]string{"value": "it's broken"},
+			want: "SELECT * FROM logs WHERE msg = 'it''s broken'",
+		},
+		{
+			name: "numeric value outside quotes",
+			sql:  "SELECT * FROM logs LIMIT {{n}}",
+			vars: map[string]string{"n": "100"},
+			want: "SELECT * FROM logs LIMIT 100",
+		},
+		{
+			name: "no variables",
+			sql:  "SELECT * FROM logs",
+			want: "SELECT * FROM logs",
+		},
+		{
+			name:    "undefined variable",
+			sql:     "SELECT {{missing}} FROM logs",
+			vars:    map[string]string{},
+			wantErr: "undefined variable",
+		},
+		{
+			name:    "injection attempt outside quotes",
+			sql:     "SELECT * FROM logs WHERE level = {{value}}",
+			vars:    map[string]string{"value": "x' OR '1'='1"},
+			wantErr: "not a valid identifier",
+		},
+		{
+			name:    "unterminated reference",
+			sql:     "SELECT {{col FROM logs",
+			vars:    map[string]string{"col": "level"},
+			wantErr: "unterminated variable reference",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := substituteVariables(tt.sql, tt.vars)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("substituteVariables error: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("substituteVariables() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
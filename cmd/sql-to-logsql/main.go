@@ -12,6 +12,9 @@ import (
 	"time"
 
 	"github.com/VictoriaMetrics/sql-to-logsql/cmd/sql-to-logsql/api"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/grpcapi"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/mysqlwire"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/pgwire"
 )
 
 func main() {
@@ -52,6 +55,59 @@ func main() {
 		IdleTimeout:  60 * time.Second,
 	}
 
+	wireLookup := srv.WirePasswordLookup()
+	if cfg.PgListenAddr != "" {
+		if wireLookup == nil {
+			log.Fatalf("pgListenAddr requires wireUsers in the config")
+		}
+		pgSrv := pgwire.NewServer(srv.Stores(), srv.VLogs(), pgwire.Options{
+			LookupPassword: wireLookup,
+			PrepareLogsQL:  srv.PrepareWireQuery,
+		})
+		go func() {
+			log.Printf("pgwire listening on %s", cfg.PgListenAddr)
+			if err := pgSrv.ListenAndServe(cfg.PgListenAddr); err != nil {
+				log.Fatalf("pgwire server error: %v", err)
+			}
+		}()
+	}
+
+	if cfg.MysqlListenAddr != "" {
+		if wireLookup == nil {
+			log.Fatalf("mysqlListenAddr requires wireUsers in the config")
+		}
+		mysqlSrv := mysqlwire.NewServer(srv.Stores(), srv.VLogs(), mysqlwire.Options{
+			LookupPassword: wireLookup,
+			PrepareLogsQL:  srv.PrepareWireQuery,
+		})
+		go func() {
+			log.Printf("mysqlwire listening on %s", cfg.MysqlListenAddr)
+			if err := mysqlSrv.ListenAndServe(cfg.MysqlListenAddr); err != nil {
+				log.Fatalf("mysqlwire server error: %v", err)
+			}
+		}()
+	}
+
+	if cfg.GrpcListenAddr != "" {
+		if cfg.GrpcTLSCertFile == "" || cfg.GrpcTLSKeyFile == "" {
+			log.Fatalf("grpcListenAddr requires grpcTLSCertFile and grpcTLSKeyFile; gRPC needs HTTP/2 over TLS")
+		}
+		// No read/write timeouts: StreamExecute responses may outlive the
+		// HTTP API's 60-second budget.
+		grpcServer := &http.Server{
+			Addr: cfg.GrpcListenAddr,
+			Handler: grpcapi.NewServer(srv.Stores(), srv.VLogs(), grpcapi.Options{
+				PrepareLogsQL: srv.PrepareWireQuery,
+			}),
+		}
+		go func() {
+			log.Printf("grpc listening on %s", cfg.GrpcListenAddr)
+			if err := grpcServer.ListenAndServeTLS(cfg.GrpcTLSCertFile, cfg.GrpcTLSKeyFile); err != nil && err != http.ErrServerClosed {
+				log.Fatalf("grpc server error: %v", err)
+			}
+		}()
+	}
+
 	// Start server in background
 	go func() {
 		log.Printf("listening on %s", cfg.ListenAddr)
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// writeResults renders a JSONL result payload from VictoriaLogs in the
+// requested format. The json format passes the payload through unchanged;
+// csv and table collect all rows first to compute a stable column set.
+func writeResults(w io.Writer, data []byte, format string) error {
+	if format == "json" {
+		_, err := w.Write(data)
+		return err
+	}
+	columns, rows, err := parseResultRows(data)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case "csv":
+		return writeCSV(w, columns, rows)
+	case "table":
+		return writeTable(w, columns, rows)
+	default:
+		return fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+func parseResultRows(data []byte) ([]string, []map[string]string, error) {
+	rows := make([]map[string]string, 0)
+	seen := make(map[string]struct{})
+	columns := make([]string, 0)
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		var raw map[string]any
+		if err := json.Unmarshal([]byte(line), &raw); err != nil {
+			return nil, nil, fmt.Errorf("parse result row: %w", err)
+		}
+		row := make(map[string]string, len(raw))
+		for name, value := range raw {
+			if _, ok := seen[name]; !ok {
+				seen[name] = struct{}{}
+				columns = append(columns, name)
+			}
+			switch v := value.(type) {
+			case string:
+				row[name] = v
+			case nil:
+				row[name] = ""
+			default:
+				encoded, err := json.Marshal(v)
+				if err != nil {
+					return nil, nil, fmt.Errorf("encode result value: %w", err)
+				}
+				row[name] = string(encoded)
+			}
+		}
+		rows = append(rows, row)
+	}
+	sort.Strings(columns)
+	return columns, rows, nil
+}
+
+func writeCSV(w io.Writer, columns []string, rows []map[string]string) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(columns); err != nil {
+		return err
+	}
+	record := make([]string, len(columns))
+	for _, row := range rows {
+		for i, name := range columns {
+			record[i] = row[name]
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeTable(w io.Writer, columns []string, rows []map[string]string) error {
+	widths := make([]int, len(columns))
+	for i, name := range columns {
+		widths[i] = len(name)
+	}
+	for _, row := range rows {
+		for i, name := range columns {
+			if l := len(row[name]); l > widths[i] {
+				widths[i] = l
+			}
+		}
+	}
+	writeRow := func(values []string) error {
+		parts := make([]string, len(values))
+		for i, v := range values {
+			parts[i] = fmt.Sprintf("%-*s", widths[i], v)
+		}
+		_, err := fmt.Fprintln(w, strings.TrimRight(strings.Join(parts, " | "), " "))
+		return err
+	}
+	if err := writeRow(columns); err != nil {
+		return err
+	}
+	separators := make([]string, len(columns))
+	for i := range columns {
+		separators[i] = strings.Repeat("-", widths[i])
+	}
+	if _, err := fmt.Fprintln(w, strings.Join(separators, "-+-")); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		record := make([]string, len(columns))
+		for i, name := range columns {
+			record[i] = row[name]
+		}
+		if err := writeRow(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
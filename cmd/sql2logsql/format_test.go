@@ -0,0 +1,71 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteResultsCSV(t *testing.T) {
+	data := []byte("{\"level\":\"error\",\"count\":3}\n{\"level\":\"warn\",\"count\":1}\n")
+	var buf strings.Builder
+	if err := writeResults(&buf, data, "csv"); err != nil {
+		t.Fatalf("writeResults error: %v", err)
+	}
+	expected := "count,level\n3,error\n1,warn\n"
+	if buf.String() != expected {
+		t.Fatalf("unexpected csv output:\nexpected: %q\nactual: %q", expected, buf.String())
+	}
+}
+
+func TestWriteResultsTable(t *testing.T) {
+	data := []byte("{\"level\":\"error\",\"message\":\"disk full\"}\n")
+	var buf strings.Builder
+	if err := writeResults(&buf, data, "table"); err != nil {
+		t.Fatalf("writeResults error: %v", err)
+	}
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected header, separator and one row, got %d lines: %q", len(lines), buf.String())
+	}
+	if !strings.HasPrefix(lines[0], "level") || !strings.Contains(lines[0], "message") {
+		t.Fatalf("unexpected header: %q", lines[0])
+	}
+	if !strings.Contains(lines[2], "disk full") {
+		t.Fatalf("unexpected row: %q", lines[2])
+	}
+}
+
+func TestWriteResultsJSONPassthrough(t *testing.T) {
+	data := []byte("{\"a\":1}\n")
+	var buf strings.Builder
+	if err := writeResults(&buf, data, "json"); err != nil {
+		t.Fatalf("writeResults error: %v", err)
+	}
+	if buf.String() != string(data) {
+		t.Fatalf("json output should pass through unchanged, got %q", buf.String())
+	}
+}
+
+func TestStatementComplete(t *testing.T) {
+	complete := []string{
+		"SELECT * FROM logs;",
+		"SELECT *\nFROM logs;",
+		"SELECT * FROM logs WHERE msg = 'a;b';",
+		"SELECT * FROM logs ;  ",
+	}
+	for _, input := range complete {
+		if !statementComplete(input) {
+			t.Errorf("statementComplete(%q) = false, want true", input)
+		}
+	}
+	incomplete := []string{
+		"SELECT * FROM logs",
+		"SELECT * FROM logs WHERE msg = 'a;",
+		"",
+	}
+	for _, input := range incomplete {
+		if statementComplete(input) {
+			t.Errorf("statementComplete(%q) = true, want false", input)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+//go:build linux
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+// newEditor returns a raw-mode line editor with history navigation and tab
+// completion when stdin is a terminal, and a plain editor otherwise.
+func newEditor(complete func(prefix string) []string) editor {
+	orig, err := getTermios(os.Stdin.Fd())
+	if err != nil {
+		return newPlainEditor()
+	}
+	return &rawEditor{orig: orig, complete: complete}
+}
+
+type rawEditor struct {
+	orig     syscall.Termios
+	complete func(prefix string) []string
+	history  []string
+}
+
+func getTermios(fd uintptr) (syscall.Termios, error) {
+	var t syscall.Termios
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, syscall.TCGETS, uintptr(unsafe.Pointer(&t)))
+	if errno != 0 {
+		return t, errno
+	}
+	return t, nil
+}
+
+func setTermios(fd uintptr, t *syscall.Termios) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, syscall.TCSETS, uintptr(unsafe.Pointer(t)))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+func (e *rawEditor) AddHistory(line string)    { e.history = append(e.history, line) }
+func (e *rawEditor) History() []string         { return e.history }
+func (e *rawEditor) SetHistory(lines []string) { e.history = append([]string(nil), lines...) }
+
+func (e *rawEditor) Close() {
+	_ = setTermios(os.Stdin.Fd(), &e.orig)
+}
+
+// ReadLine reads a single line in raw mode. It supports backspace, Ctrl-C
+// (discard line), Ctrl-D (EOF on empty line), up/down history navigation and
+// tab completion of the word before the cursor.
+func (e *rawEditor) ReadLine(prompt string) (string, error) {
+	raw := e.orig
+	raw.Lflag &^= syscall.ICANON | syscall.ECHO
+	raw.Cc[syscall.VMIN] = 1
+	raw.Cc[syscall.VTIME] = 0
+	if err := setTermios(os.Stdin.Fd(), &raw); err != nil {
+		return "", err
+	}
+	defer func() { _ = setTermios(os.Stdin.Fd(), &e.orig) }()
+
+	var line []rune
+	histIdx := len(e.history)
+	var savedLine string
+	redraw := func() {
+		fmt.Printf("\r\x1b[K%s%s", prompt, string(line))
+	}
+	redraw()
+
+	buf := make([]byte, 1)
+	for {
+		if _, err := os.Stdin.Read(buf); err != nil {
+			return "", err
+		}
+		switch buf[0] {
+		case '\r', '\n':
+			fmt.Println()
+			return string(line), nil
+		case 0x03: // Ctrl-C: discard the current line
+			fmt.Println("^C")
+			line = line[:0]
+			histIdx = len(e.history)
+			redraw()
+		case 0x04: // Ctrl-D: EOF on empty line
+			if len(line) == 0 {
+				return "", io.EOF
+			}
+		case 0x7f, 0x08: // backspace
+			if len(line) > 0 {
+				line = line[:len(line)-1]
+				redraw()
+			}
+		case '\t':
+			line = e.completeLine(line)
+			redraw()
+		case 0x1b: // escape sequence
+			seq := make([]byte, 2)
+			if _, err := io.ReadFull(os.Stdin, seq); err != nil {
+				return "", err
+			}
+			if seq[0] != '[' {
+				continue
+			}
+			switch seq[1] {
+			case 'A': // up
+				if histIdx > 0 {
+					if histIdx == len(e.history) {
+						savedLine = string(line)
+					}
+					histIdx--
+					line = []rune(strings.ReplaceAll(e.history[histIdx], "\n", " "))
+					redraw()
+				}
+			case 'B': // down
+				if histIdx < len(e.history) {
+					histIdx++
+					if histIdx == len(e.history) {
+						line = []rune(savedLine)
+					} else {
+						line = []rune(strings.ReplaceAll(e.history[histIdx], "\n", " "))
+					}
+					redraw()
+				}
+			}
+		default:
+			if buf[0] >= 0x20 {
+				line = append(line, decodeRune(buf[0], os.Stdin))
+				redraw()
+			}
+		}
+	}
+}
+
+// completeLine replaces the last word of the line with its completion. When
+// several candidates match, they are listed below the prompt instead.
+func (e *rawEditor) completeLine(line []rune) []rune {
+	if e.complete == nil {
+		return line
+	}
+	text := string(line)
+	start := strings.LastIndexAny(text, " \t(,")
+	prefix := text[start+1:]
+	if prefix == "" {
+		return line
+	}
+	matches := e.complete(prefix)
+	switch len(matches) {
+	case 0:
+		return line
+	case 1:
+		return []rune(text[:start+1] + matches[0])
+	default:
+		fmt.Printf("\n%s\n", strings.Join(matches, "  "))
+		return line
+	}
+}
+
+// decodeRune reads the remaining bytes of a UTF-8 sequence whose first byte
+// has already been consumed.
+func decodeRune(first byte, r io.Reader) rune {
+	if first < 0x80 {
+		return rune(first)
+	}
+	size := 0
+	switch {
+	case first&0xe0 == 0xc0:
+		size = 1
+	case first&0xf0 == 0xe0:
+		size = 2
+	case first&0xf8 == 0xf0:
+		size = 3
+	default:
+		return rune(first)
+	}
+	rest := make([]byte, size)
+	if _, err := io.ReadFull(r, rest); err != nil {
+		return rune(first)
+	}
+	decoded := []rune(string(append([]byte{first}, rest...)))
+	if len(decoded) == 0 {
+		return rune(first)
+	}
+	return decoded[0]
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package main
+
+// newEditor falls back to plain buffered input on platforms without
+// raw-mode terminal support.
+func newEditor(complete func(prefix string) []string) editor {
+	return newPlainEditor()
+}
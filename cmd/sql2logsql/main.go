@@ -0,0 +1,234 @@
+// Command sql2logsql translates SQL statements to LogsQL from the command
+// line, or runs them against a VictoriaLogs endpoint. It reads SQL from a
+// file argument or stdin, which makes it usable in shell pipelines and CI
+// checks:
+//
+//	echo "SELECT * FROM logs WHERE level = 'error'" | sql2logsql translate
+//	sql2logsql run -endpoint http://victoria:9428 -format table query.sql
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/logsql"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/sql/lexer"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/sql/parser"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/sql/render"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/store"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/store/tablestore"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/store/viewstore"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/vlogs"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	var err error
+	switch os.Args[1] {
+	case "translate":
+		err = runTranslate(os.Args[2:])
+	case "run":
+		err = runExecute(os.Args[2:])
+	case "fmt":
+		err = runFormat(os.Args[2:])
+	case "repl":
+		err = runREPL(os.Args[2:])
+	case "-h", "--help", "help":
+		usage()
+	default:
+		fmt.Fprintf(os.Stderr, "unknown subcommand %q\n\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, `usage: sql2logsql <subcommand> [flags] [file]
+
+Subcommands:
+  translate    print the LogsQL translation of a SQL statement
+  run          execute the translated query against a VictoriaLogs endpoint
+  fmt          parse a SQL statement and print its canonical form
+  repl         start an interactive shell with history and tab completion
+
+SQL is read from the file argument, or from stdin when no file is given.
+Run 'sql2logsql <subcommand> -h' for subcommand flags.
+`)
+}
+
+type commonFlags struct {
+	tablesFile string
+	viewsDir   string
+}
+
+func registerCommonFlags(fs *flag.FlagSet) *commonFlags {
+	cf := &commonFlags{}
+	fs.StringVar(&cf.tablesFile, "tables", "", "path to JSON file mapping table names to LogsQL expressions")
+	fs.StringVar(&cf.viewsDir, "views-dir", "", "directory with stored .logsql views")
+	return cf
+}
+
+func (cf *commonFlags) storeProvider() (*store.Provider, error) {
+	tables := map[string]string{"logs": "*"}
+	if cf.tablesFile != "" {
+		data, err := os.ReadFile(cf.tablesFile)
+		if err != nil {
+			return nil, fmt.Errorf("read tables config: %w", err)
+		}
+		tables = make(map[string]string)
+		if err := json.Unmarshal(data, &tables); err != nil {
+			return nil, fmt.Errorf("parse tables config: %w", err)
+		}
+	}
+	if err := logsql.ValidateTableSpecs(tables); err != nil {
+		return nil, fmt.Errorf("invalid tables config: %w", err)
+	}
+	ts, err := tablestore.NewTableStore(tables)
+	if err != nil {
+		return nil, err
+	}
+	vs, err := viewstore.NewViewStore(cf.viewsDir)
+	if err != nil {
+		return nil, err
+	}
+	return store.NewStoreProvider(ts, vs), nil
+}
+
+func readSQL(args []string) (string, error) {
+	if len(args) > 1 {
+		return "", fmt.Errorf("expected at most one file argument, got %d", len(args))
+	}
+	var data []byte
+	var err error
+	if len(args) == 1 && args[0] != "-" {
+		data, err = os.ReadFile(args[0])
+	} else {
+		data, err = io.ReadAll(os.Stdin)
+	}
+	if err != nil {
+		return "", fmt.Errorf("read SQL: %w", err)
+	}
+	sql := strings.TrimSpace(string(data))
+	if sql == "" {
+		return "", errors.New("no SQL statement provided")
+	}
+	return sql, nil
+}
+
+func translateSQL(sql string, sp *store.Provider) (*logsql.StatementInfo, error) {
+	l := lexer.New(sql)
+	p := parser.New(l)
+	stmt := p.ParseStatement()
+	if stmt == nil {
+		return nil, errors.New("no statement parsed")
+	}
+	if perrs := p.Errors(); len(perrs) > 0 {
+		return nil, fmt.Errorf("parse errors: %w", errors.Join(perrs...))
+	}
+	return logsql.GetStatementInfo(stmt, sp)
+}
+
+func runTranslate(args []string) error {
+	fs := flag.NewFlagSet("translate", flag.ExitOnError)
+	cf := registerCommonFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	sql, err := readSQL(fs.Args())
+	if err != nil {
+		return err
+	}
+	sp, err := cf.storeProvider()
+	if err != nil {
+		return err
+	}
+	si, err := translateSQL(sql, sp)
+	if err != nil {
+		return err
+	}
+	if si.LogsQL == "" {
+		return fmt.Errorf("statement of kind %q does not produce a LogsQL query", si.Kind)
+	}
+	fmt.Println(si.LogsQL)
+	return nil
+}
+
+func runFormat(args []string) error {
+	fs := flag.NewFlagSet("fmt", flag.ExitOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	sql, err := readSQL(fs.Args())
+	if err != nil {
+		return err
+	}
+	l := lexer.New(sql)
+	p := parser.New(l)
+	stmt := p.ParseStatement()
+	if stmt == nil {
+		return errors.New("no statement parsed")
+	}
+	if perrs := p.Errors(); len(perrs) > 0 {
+		return fmt.Errorf("parse errors: %w", errors.Join(perrs...))
+	}
+	formatted, err := render.Render(stmt)
+	if err != nil {
+		return err
+	}
+	fmt.Println(formatted)
+	return nil
+}
+
+func runExecute(args []string) error {
+	fs := flag.NewFlagSet("run", flag.ExitOnError)
+	cf := registerCommonFlags(fs)
+	endpoint := fs.String("endpoint", "", "VictoriaLogs endpoint URL (required)")
+	bearerToken := fs.String("bearer-token", "", "bearer token for the endpoint")
+	limit := fs.Uint("limit", 1000, "maximum number of returned rows")
+	format := fs.String("format", "json", "output format: json, csv or table")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *endpoint == "" {
+		return errors.New("-endpoint is required for run")
+	}
+	switch *format {
+	case "json", "csv", "table":
+	default:
+		return fmt.Errorf("unsupported format %q", *format)
+	}
+	sql, err := readSQL(fs.Args())
+	if err != nil {
+		return err
+	}
+	sp, err := cf.storeProvider()
+	if err != nil {
+		return err
+	}
+	si, err := translateSQL(sql, sp)
+	if err != nil {
+		return err
+	}
+	api := vlogs.NewVLogsAPI(vlogs.EndpointConfig{
+		Endpoint:    *endpoint,
+		BearerToken: *bearerToken,
+	}, uint32(*limit))
+	data, err := api.Execute(context.Background(), si, vlogs.EndpointConfig{})
+	if err != nil {
+		return err
+	}
+	return writeResults(os.Stdout, data, *format)
+}
@@ -0,0 +1,318 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/store"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/vlogs"
+)
+
+// editor abstracts line input so the REPL can use a raw-mode editor with
+// history and tab completion on terminals, and plain buffered reads when
+// stdin is redirected.
+type editor interface {
+	ReadLine(prompt string) (string, error)
+	AddHistory(line string)
+	History() []string
+	SetHistory(lines []string)
+	Close()
+}
+
+// plainEditor reads lines without terminal handling. It is used when stdin
+// is not a terminal and on platforms without raw-mode support.
+type plainEditor struct {
+	r       *bufio.Reader
+	history []string
+}
+
+func newPlainEditor() *plainEditor {
+	return &plainEditor{r: bufio.NewReader(os.Stdin)}
+}
+
+func (e *plainEditor) ReadLine(prompt string) (string, error) {
+	fmt.Print(prompt)
+	line, err := e.r.ReadString('\n')
+	if err != nil {
+		if err == io.EOF && line != "" {
+			return strings.TrimRight(line, "\r\n"), nil
+		}
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func (e *plainEditor) AddHistory(line string)    { e.history = append(e.history, line) }
+func (e *plainEditor) History() []string         { return e.history }
+func (e *plainEditor) SetHistory(lines []string) { e.history = append([]string(nil), lines...) }
+func (e *plainEditor) Close()                    {}
+
+func runREPL(args []string) error {
+	fs := flag.NewFlagSet("repl", flag.ExitOnError)
+	cf := registerCommonFlags(fs)
+	endpoint := fs.String("endpoint", "", "VictoriaLogs endpoint URL (optional; without it statements are only translated)")
+	bearerToken := fs.String("bearer-token", "", "bearer token for the endpoint")
+	limit := fs.Uint("limit", 1000, "maximum number of returned rows")
+	format := fs.String("format", "table", "output format: json, csv or table")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	sp, err := cf.storeProvider()
+	if err != nil {
+		return err
+	}
+
+	r := &repl{
+		sp:     sp,
+		format: *format,
+	}
+	if *endpoint != "" {
+		r.api = vlogs.NewVLogsAPI(vlogs.EndpointConfig{
+			Endpoint:    *endpoint,
+			BearerToken: *bearerToken,
+		}, uint32(*limit))
+	}
+
+	ed := newEditor(r.completeWord)
+	defer ed.Close()
+	if lines, err := loadHistoryFile(); err == nil {
+		ed.SetHistory(lines)
+	}
+	defer func() {
+		if err := saveHistoryFile(ed.History()); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to save history: %v\n", err)
+		}
+	}()
+
+	fmt.Println("sql2logsql interactive shell. Type \\? for help, \\q to quit.")
+	var pending []string
+	for {
+		prompt := "sql> "
+		if len(pending) > 0 {
+			prompt = "  -> "
+		}
+		line, err := ed.ReadLine(prompt)
+		if err != nil {
+			if err == io.EOF {
+				fmt.Println()
+				return nil
+			}
+			return err
+		}
+		trimmed := strings.TrimSpace(line)
+		if len(pending) == 0 && trimmed == "" {
+			continue
+		}
+		if len(pending) == 0 && strings.HasPrefix(trimmed, "\\") {
+			ed.AddHistory(trimmed)
+			if done := r.runSlashCommand(trimmed); done {
+				return nil
+			}
+			continue
+		}
+		pending = append(pending, line)
+		if !statementComplete(strings.Join(pending, "\n")) {
+			continue
+		}
+		statement := strings.Join(pending, "\n")
+		pending = nil
+		ed.AddHistory(statement)
+		r.runStatement(statement)
+	}
+}
+
+type repl struct {
+	sp     *store.Provider
+	api    *vlogs.API
+	format string
+}
+
+// statementComplete reports whether the buffered input ends with a statement
+// terminating semicolon outside of quoted literals.
+func statementComplete(input string) bool {
+	var quote rune
+	lastMeaningful := rune(0)
+	for _, r := range input {
+		if quote != 0 {
+			if r == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch r {
+		case '\'', '"':
+			quote = r
+		}
+		if !strings.ContainsRune(" \t\r\n", r) {
+			lastMeaningful = r
+		}
+	}
+	return quote == 0 && lastMeaningful == ';'
+}
+
+func (r *repl) runSlashCommand(cmd string) (quit bool) {
+	fields := strings.Fields(cmd)
+	switch fields[0] {
+	case "\\q", "\\quit":
+		return true
+	case "\\?", "\\h", "\\help":
+		fmt.Print(`Commands:
+  \dt        list configured tables
+  \dv        list stored views
+  \q         quit
+  \?         show this help
+Statements end with ';' and may span multiple lines.
+`)
+	case "\\dt":
+		for _, name := range r.sp.TableStore().ListTables() {
+			query, _ := r.sp.TableStore().GetTableQuery(name)
+			fmt.Printf("%s\t%s\n", name, query)
+		}
+	case "\\dv":
+		vs := r.sp.ViewStore()
+		if vs == nil {
+			fmt.Println("no views directory configured (use -views-dir)")
+			return false
+		}
+		names, err := vs.ListViews()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			return false
+		}
+		for _, name := range names {
+			fmt.Println(name)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %s (try \\?)\n", fields[0])
+	}
+	return false
+}
+
+func (r *repl) runStatement(statement string) {
+	si, err := translateSQL(statement, r.sp)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return
+	}
+	if si.LogsQL != "" {
+		fmt.Printf("-- %s\n", si.LogsQL)
+	}
+	if r.api == nil {
+		if si.Data != "" {
+			fmt.Print(si.Data)
+		}
+		return
+	}
+	data, err := r.api.Execute(context.Background(), si, vlogs.EndpointConfig{})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return
+	}
+	if len(data) == 0 {
+		return
+	}
+	if err := writeResults(os.Stdout, data, r.format); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+	}
+}
+
+// sqlCompletionKeywords are offered by tab completion in addition to table
+// and view names.
+var sqlCompletionKeywords = []string{
+	"SELECT", "FROM", "WHERE", "GROUP BY", "HAVING", "ORDER BY", "LIMIT",
+	"OFFSET", "JOIN", "LEFT JOIN", "INNER JOIN", "ON", "AND", "OR", "NOT",
+	"IN", "LIKE", "BETWEEN", "IS NULL", "IS NOT NULL", "DISTINCT", "AS",
+	"CREATE VIEW", "DROP VIEW", "DESCRIBE TABLE", "DESCRIBE VIEW",
+	"SHOW TABLES", "SHOW VIEWS", "COUNT", "SUM", "AVG", "MIN", "MAX",
+}
+
+func (r *repl) completeWord(prefix string) []string {
+	if prefix == "" {
+		return nil
+	}
+	seen := make(map[string]struct{})
+	matches := make([]string, 0)
+	add := func(candidate string) {
+		if len(candidate) <= len(prefix) {
+			return
+		}
+		if !strings.EqualFold(candidate[:len(prefix)], prefix) {
+			return
+		}
+		if _, ok := seen[candidate]; ok {
+			return
+		}
+		seen[candidate] = struct{}{}
+		matches = append(matches, candidate)
+	}
+	for _, name := range r.sp.TableStore().ListTables() {
+		add(name)
+	}
+	if vs := r.sp.ViewStore(); vs != nil {
+		if names, err := vs.ListViews(); err == nil {
+			for _, name := range names {
+				add(name)
+			}
+		}
+	}
+	for _, kw := range sqlCompletionKeywords {
+		add(kw)
+	}
+	sort.Strings(matches)
+	return matches
+}
+
+const historyFileName = ".sql2logsql_history"
+
+func historyFilePath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, historyFileName), nil
+}
+
+func loadHistoryFile() ([]string, error) {
+	path, err := historyFilePath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	lines := make([]string, 0)
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, strings.ReplaceAll(line, "\\n", "\n"))
+		}
+	}
+	return lines, nil
+}
+
+func saveHistoryFile(lines []string) error {
+	if len(lines) == 0 {
+		return nil
+	}
+	path, err := historyFilePath()
+	if err != nil {
+		return err
+	}
+	const maxHistory = 500
+	if len(lines) > maxHistory {
+		lines = lines[len(lines)-maxHistory:]
+	}
+	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString(strings.ReplaceAll(line, "\n", "\\n"))
+		b.WriteByte('\n')
+	}
+	return os.WriteFile(path, []byte(b.String()), 0o600)
+}
@@ -0,0 +1,11 @@
+// Package grpcapi implements the gRPC translation and execution API defined
+// in sqltologsql.proto: a TranslatorService with Translate, Execute and
+// streaming StreamExecute RPCs mirroring the HTTP API.
+//
+// The module has no external dependencies, so the protobuf codec and the
+// gRPC framing are written by hand against the wire format instead of being
+// generated; sqltologsql.proto remains the source of truth for clients,
+// which generate their stubs from it as usual. The service is an
+// http.Handler served over TLS next to the HTTP API in cmd/sql-to-logsql
+// (Go's HTTP server only negotiates HTTP/2, which gRPC requires, via ALPN).
+package grpcapi
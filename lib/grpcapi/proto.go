@@ -0,0 +1,150 @@
+package grpcapi
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Hand-written protobuf wire encoding for the messages in sqltologsql.proto.
+// Every field is a string or bytes, so only the length-delimited wire type
+// is produced; unknown and non-length-delimited fields are skipped on decode,
+// which keeps the codec forward-compatible with regenerated clients.
+
+// TranslateRequest mirrors sqltologsql.v1.TranslateRequest.
+type TranslateRequest struct {
+	SQL string // field 1
+}
+
+// TranslateResponse mirrors sqltologsql.v1.TranslateResponse.
+type TranslateResponse struct {
+	Kind   string // field 1
+	LogsQL string // field 2
+}
+
+// ExecuteRequest mirrors sqltologsql.v1.ExecuteRequest.
+type ExecuteRequest struct {
+	SQL         string // field 1
+	Endpoint    string // field 2
+	BearerToken string // field 3
+}
+
+// ExecuteResponse mirrors sqltologsql.v1.ExecuteResponse.
+type ExecuteResponse struct {
+	Kind   string // field 1
+	LogsQL string // field 2
+	Data   []byte // field 3
+}
+
+// ResultChunk mirrors sqltologsql.v1.ResultChunk.
+type ResultChunk struct {
+	Data []byte // field 1
+}
+
+const wireLengthDelimited = 2
+
+// appendField appends a length-delimited protobuf field. Empty values are
+// omitted, matching proto3 presence rules.
+func appendField(buf []byte, field int, value []byte) []byte {
+	if len(value) == 0 {
+		return buf
+	}
+	buf = appendVarint(buf, uint64(field)<<3|wireLengthDelimited)
+	buf = appendVarint(buf, uint64(len(value)))
+	return append(buf, value...)
+}
+
+func appendVarint(buf []byte, v uint64) []byte {
+	for v >= 0x80 {
+		buf = append(buf, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(buf, byte(v))
+}
+
+// walkFields decodes the top-level fields of a protobuf message, calling fn
+// with each length-delimited field's number and value.
+func walkFields(data []byte, fn func(field int, value []byte)) error {
+	for len(data) > 0 {
+		tag, n := decodeVarint(data)
+		if n == 0 {
+			return errors.New("grpcapi: malformed message: bad field tag")
+		}
+		data = data[n:]
+		field := int(tag >> 3)
+		switch tag & 7 {
+		case 0: // varint
+			_, n := decodeVarint(data)
+			if n == 0 {
+				return errors.New("grpcapi: malformed message: bad varint")
+			}
+			data = data[n:]
+		case 1: // 64-bit
+			if len(data) < 8 {
+				return errors.New("grpcapi: malformed message: short fixed64")
+			}
+			data = data[8:]
+		case wireLengthDelimited:
+			length, n := decodeVarint(data)
+			if n == 0 || uint64(len(data)-n) < length {
+				return errors.New("grpcapi: malformed message: bad field length")
+			}
+			fn(field, data[n:n+int(length)])
+			data = data[n+int(length):]
+		case 5: // 32-bit
+			if len(data) < 4 {
+				return errors.New("grpcapi: malformed message: short fixed32")
+			}
+			data = data[4:]
+		default:
+			return fmt.Errorf("grpcapi: malformed message: unsupported wire type %d", tag&7)
+		}
+	}
+	return nil
+}
+
+func decodeVarint(data []byte) (uint64, int) {
+	var v uint64
+	for i := 0; i < len(data) && i < 10; i++ {
+		v |= uint64(data[i]&0x7f) << (7 * i)
+		if data[i] < 0x80 {
+			return v, i + 1
+		}
+	}
+	return 0, 0
+}
+
+func (m *TranslateRequest) unmarshal(data []byte) error {
+	return walkFields(data, func(field int, value []byte) {
+		if field == 1 {
+			m.SQL = string(value)
+		}
+	})
+}
+
+func (m *TranslateResponse) marshal() []byte {
+	buf := appendField(nil, 1, []byte(m.Kind))
+	return appendField(buf, 2, []byte(m.LogsQL))
+}
+
+func (m *ExecuteRequest) unmarshal(data []byte) error {
+	return walkFields(data, func(field int, value []byte) {
+		switch field {
+		case 1:
+			m.SQL = string(value)
+		case 2:
+			m.Endpoint = string(value)
+		case 3:
+			m.BearerToken = string(value)
+		}
+	})
+}
+
+func (m *ExecuteResponse) marshal() []byte {
+	buf := appendField(nil, 1, []byte(m.Kind))
+	buf = appendField(buf, 2, []byte(m.LogsQL))
+	return appendField(buf, 3, m.Data)
+}
+
+func (m *ResultChunk) marshal() []byte {
+	return appendField(nil, 1, m.Data)
+}
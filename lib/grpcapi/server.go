@@ -0,0 +1,270 @@
+package grpcapi
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/logsql"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/sql/lexer"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/sql/parser"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/store"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/store/viewstore"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/vlogs"
+)
+
+// Server implements sqltologsql.v1.TranslatorService as an http.Handler.
+// gRPC is plain HTTP/2: each RPC is a POST with length-prefixed protobuf
+// frames in the body and the status carried in response trailers, so the
+// standard library's HTTP server can serve it without a gRPC dependency.
+// Serve it over TLS; Go's HTTP server only negotiates HTTP/2 via ALPN.
+type Server struct {
+	sp   *store.Provider
+	api  *vlogs.API
+	opts Options
+}
+
+// Options configures the per-deployment policy hooks of the service.
+type Options struct {
+	// PrepareLogsQL applies deployment policy (field masking, guardrails)
+	// to a translated query before execution; tables lists every table the
+	// statement references. Optional.
+	PrepareLogsQL func(logsQL string, tables []string) (string, error)
+}
+
+// NewServer returns a TranslatorService handler backed by the shared store
+// provider and VictoriaLogs API client.
+func NewServer(sp *store.Provider, api *vlogs.API, opts Options) *Server {
+	return &Server{sp: sp, api: api, opts: opts}
+}
+
+const servicePrefix = "/sqltologsql.v1.TranslatorService/"
+
+// gRPC status codes set in the grpc-status trailer.
+const (
+	statusOK              = 0
+	statusInvalidArgument = 3
+	statusUnimplemented   = 12
+	statusInternal        = 13
+)
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || !strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+		http.Error(w, "expected a gRPC request", http.StatusUnsupportedMediaType)
+		return
+	}
+	w.Header().Set("Content-Type", "application/grpc")
+	// Declaring the trailer names up front lets net/http move the values set
+	// after the body into the HTTP/2 trailer section.
+	w.Header().Set("Trailer", "Grpc-Status, Grpc-Message")
+
+	var code int
+	var msg string
+	switch strings.TrimPrefix(r.URL.Path, servicePrefix) {
+	case "Translate":
+		code, msg = s.translateRPC(w, r)
+	case "Execute":
+		code, msg = s.executeRPC(w, r)
+	case "StreamExecute":
+		code, msg = s.streamExecuteRPC(w, r)
+	default:
+		code, msg = statusUnimplemented, fmt.Sprintf("unknown method %s", r.URL.Path)
+	}
+	w.Header().Set("Grpc-Status", strconv.Itoa(code))
+	if msg != "" {
+		w.Header().Set("Grpc-Message", encodeGrpcMessage(msg))
+	}
+}
+
+func (s *Server) translateRPC(w http.ResponseWriter, r *http.Request) (int, string) {
+	var req TranslateRequest
+	if err := readRequestMessage(r.Body, req.unmarshal); err != nil {
+		return statusInvalidArgument, err.Error()
+	}
+	si, _, err := s.translate(s.sessionProvider(), req.SQL)
+	if err != nil {
+		return statusInvalidArgument, err.Error()
+	}
+	resp := TranslateResponse{Kind: string(si.Kind), LogsQL: si.LogsQL}
+	if err := writeFrame(w, resp.marshal()); err != nil {
+		return statusInternal, "failed to write response"
+	}
+	return statusOK, ""
+}
+
+func (s *Server) executeRPC(w http.ResponseWriter, r *http.Request) (int, string) {
+	var req ExecuteRequest
+	if err := readRequestMessage(r.Body, req.unmarshal); err != nil {
+		return statusInvalidArgument, err.Error()
+	}
+	si, err := s.prepareStatement(req)
+	if err != nil {
+		return statusInvalidArgument, err.Error()
+	}
+	data := []byte(si.Data)
+	if s.api != nil {
+		data, err = s.api.Execute(r.Context(), si, vlogs.EndpointConfig{
+			Endpoint:    req.Endpoint,
+			BearerToken: req.BearerToken,
+		})
+		if err != nil {
+			return executionStatus(err)
+		}
+	}
+	resp := ExecuteResponse{Kind: string(si.Kind), LogsQL: si.LogsQL, Data: data}
+	if err := writeFrame(w, resp.marshal()); err != nil {
+		return statusInternal, "failed to write response"
+	}
+	return statusOK, ""
+}
+
+// streamChunkSize is how many result bytes go into one ResultChunk frame.
+const streamChunkSize = 64 * 1024
+
+func (s *Server) streamExecuteRPC(w http.ResponseWriter, r *http.Request) (int, string) {
+	var req ExecuteRequest
+	if err := readRequestMessage(r.Body, req.unmarshal); err != nil {
+		return statusInvalidArgument, err.Error()
+	}
+	si, err := s.prepareStatement(req)
+	if err != nil {
+		return statusInvalidArgument, err.Error()
+	}
+	stream := io.Reader(strings.NewReader(si.Data))
+	if s.api != nil {
+		body, err := s.api.ExecuteStream(r.Context(), si, vlogs.EndpointConfig{
+			Endpoint:    req.Endpoint,
+			BearerToken: req.BearerToken,
+		})
+		if err != nil {
+			return executionStatus(err)
+		}
+		defer body.Close()
+		stream = body
+	}
+	flusher, _ := w.(http.Flusher)
+	buf := make([]byte, streamChunkSize)
+	for {
+		n, err := stream.Read(buf)
+		if n > 0 {
+			chunk := ResultChunk{Data: buf[:n]}
+			if werr := writeFrame(w, chunk.marshal()); werr != nil {
+				return statusInternal, "failed to write response"
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		if err == io.EOF {
+			return statusOK, ""
+		}
+		if err != nil {
+			return executionStatus(err)
+		}
+	}
+}
+
+// prepareStatement translates the request's SQL and applies the configured
+// policy hook to the result.
+func (s *Server) prepareStatement(req ExecuteRequest) (*logsql.StatementInfo, error) {
+	si, tables, err := s.translate(s.sessionProvider(), req.SQL)
+	if err != nil {
+		return nil, err
+	}
+	if si.LogsQL != "" && s.opts.PrepareLogsQL != nil {
+		si.LogsQL, err = s.opts.PrepareLogsQL(si.LogsQL, tables)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return si, nil
+}
+
+// sessionProvider scopes temporary views and session options to a single
+// RPC; gRPC requests carry no session affinity.
+func (s *Server) sessionProvider() *store.Provider {
+	return s.sp.WithSessionViews(viewstore.NewMemStore()).WithSessionOptions(store.NewSessionOptions())
+}
+
+func (s *Server) translate(sp *store.Provider, sql string) (*logsql.StatementInfo, []string, error) {
+	l := lexer.New(sql)
+	p := parser.New(l)
+	stmt := p.ParseStatement()
+	if stmt == nil {
+		return nil, nil, errors.New("no statement parsed")
+	}
+	if perrs := p.Errors(); len(perrs) > 0 {
+		return nil, nil, fmt.Errorf("parse errors: %w", errors.Join(perrs...))
+	}
+	si, err := logsql.GetStatementInfo(stmt, sp)
+	if err != nil {
+		return nil, nil, err
+	}
+	return si, logsql.ReferencedBaseTables(stmt, sp), nil
+}
+
+// executionStatus maps an upstream execution error to a gRPC status: client
+// errors become INVALID_ARGUMENT, everything else INTERNAL.
+func executionStatus(err error) (int, string) {
+	var ae *vlogs.APIError
+	if errors.As(err, &ae) {
+		if ae.Code >= 400 && ae.Code < 500 {
+			return statusInvalidArgument, ae.Message
+		}
+		return statusInternal, ae.Message
+	}
+	return statusInternal, err.Error()
+}
+
+// maxRequestFrameSize bounds incoming request frames to protect against
+// malformed length prefixes.
+const maxRequestFrameSize = 16 << 20
+
+// readRequestMessage reads the single request frame of a unary RPC and
+// decodes it with unmarshal.
+func readRequestMessage(r io.Reader, unmarshal func([]byte) error) error {
+	var header [5]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return errors.New("grpcapi: short request frame")
+	}
+	if header[0] != 0 {
+		return errors.New("grpcapi: compressed request frames are not supported")
+	}
+	length := int(header[1])<<24 | int(header[2])<<16 | int(header[3])<<8 | int(header[4])
+	if length > maxRequestFrameSize {
+		return fmt.Errorf("grpcapi: request frame of %d bytes exceeds the %d-byte limit", length, maxRequestFrameSize)
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return errors.New("grpcapi: short request frame")
+	}
+	return unmarshal(payload)
+}
+
+// writeFrame writes one uncompressed length-prefixed message frame.
+func writeFrame(w io.Writer, payload []byte) error {
+	header := [5]byte{0, byte(len(payload) >> 24), byte(len(payload) >> 16), byte(len(payload) >> 8), byte(len(payload))}
+	if _, err := w.Write(header[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(payload)
+	return err
+}
+
+// encodeGrpcMessage percent-encodes a status message for the grpc-message
+// trailer, which only allows printable ASCII.
+func encodeGrpcMessage(msg string) string {
+	var out strings.Builder
+	for i := 0; i < len(msg); i++ {
+		b := msg[i]
+		if b >= 0x20 && b <= 0x7e && b != '%' {
+			out.WriteByte(b)
+			continue
+		}
+		fmt.Fprintf(&out, "%%%02X", b)
+	}
+	return out.String()
+}
@@ -0,0 +1,182 @@
+package grpcapi
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/store"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/store/tablestore"
+)
+
+func startTestServer(t *testing.T, opts ...Options) *httptest.Server {
+	t.Helper()
+	ts, err := tablestore.NewTableStore(map[string]string{"logs": "*"})
+	if err != nil {
+		t.Fatalf("NewTableStore error: %v", err)
+	}
+	sp := store.NewStoreProvider(ts, nil)
+	o := Options{}
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	srv := httptest.NewServer(NewServer(sp, nil, o))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// call performs one RPC and returns the raw response body and the
+// grpc-status / grpc-message trailers. The test server speaks HTTP/1.1,
+// where the declared trailers arrive via chunked encoding.
+func call(t *testing.T, srv *httptest.Server, method string, message []byte) ([]byte, string, string) {
+	t.Helper()
+	var body bytes.Buffer
+	if err := writeFrame(&body, message); err != nil {
+		t.Fatalf("write request frame: %v", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, srv.URL+servicePrefix+method, &body)
+	if err != nil {
+		t.Fatalf("build request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/grpc")
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatalf("rpc error: %v", err)
+	}
+	defer resp.Body.Close()
+	payload, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read response: %v", err)
+	}
+	return payload, resp.Trailer.Get("Grpc-Status"), resp.Trailer.Get("Grpc-Message")
+}
+
+// readFrames splits a response body into its message payloads.
+func readFrames(t *testing.T, body []byte) [][]byte {
+	t.Helper()
+	var frames [][]byte
+	r := bytes.NewReader(body)
+	for r.Len() > 0 {
+		var payload []byte
+		err := readRequestMessage(r, func(data []byte) error {
+			payload = data
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("read response frame: %v", err)
+		}
+		frames = append(frames, payload)
+	}
+	return frames
+}
+
+func TestTranslateRPC(t *testing.T) {
+	srv := startTestServer(t)
+	req := appendField(nil, 1, []byte("SELECT * FROM logs WHERE level = 'error'"))
+
+	body, status, msg := call(t, srv, "Translate", req)
+	if status != "0" {
+		t.Fatalf("unexpected status %s: %s", status, msg)
+	}
+	var resp TranslateResponse
+	frames := readFrames(t, body)
+	if len(frames) != 1 {
+		t.Fatalf("expected one response frame, got %d", len(frames))
+	}
+	if err := walkFields(frames[0], func(field int, value []byte) {
+		switch field {
+		case 1:
+			resp.Kind = string(value)
+		case 2:
+			resp.LogsQL = string(value)
+		}
+	}); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Kind != "select" || resp.LogsQL == "" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+}
+
+func TestTranslateRPCError(t *testing.T) {
+	srv := startTestServer(t)
+	req := appendField(nil, 1, []byte("SELECT * FROM missing"))
+
+	_, status, msg := call(t, srv, "Translate", req)
+	if status != "3" {
+		t.Fatalf("expected INVALID_ARGUMENT, got status %s: %s", status, msg)
+	}
+}
+
+func TestStreamExecuteRPC(t *testing.T) {
+	srv := startTestServer(t)
+	// Without a configured API client, SHOW TABLES is answered from local
+	// data, which comes back as ResultChunk frames.
+	req := appendField(nil, 1, []byte("SHOW TABLES"))
+
+	body, status, msg := call(t, srv, "StreamExecute", req)
+	if status != "0" {
+		t.Fatalf("unexpected status %s: %s", status, msg)
+	}
+	var data []byte
+	for _, frame := range readFrames(t, body) {
+		if err := walkFields(frame, func(field int, value []byte) {
+			if field == 1 {
+				data = append(data, value...)
+			}
+		}); err != nil {
+			t.Fatalf("decode chunk: %v", err)
+		}
+	}
+	if !bytes.Contains(data, []byte("logs")) {
+		t.Fatalf("expected streamed result to list the logs table, got %q", data)
+	}
+}
+
+func TestPrepareLogsQLHook(t *testing.T) {
+	srv := startTestServer(t, Options{
+		PrepareLogsQL: func(logsQL string, tables []string) (string, error) {
+			return "", errors.New("guardrails: rejected")
+		},
+	})
+	req := appendField(nil, 1, []byte("SELECT * FROM logs"))
+
+	_, status, msg := call(t, srv, "Execute", req)
+	if status != "3" || msg != "guardrails: rejected" {
+		t.Fatalf("expected guardrails rejection, got status %s: %s", status, msg)
+	}
+}
+
+func TestUnknownMethod(t *testing.T) {
+	srv := startTestServer(t)
+	_, status, _ := call(t, srv, "Unknown", nil)
+	if status != "12" {
+		t.Fatalf("expected UNIMPLEMENTED, got status %s", status)
+	}
+}
+
+func TestProtoRoundTrip(t *testing.T) {
+	msg := appendField(nil, 1, []byte("SELECT 1"))
+	msg = appendField(msg, 2, []byte("http://localhost:9428"))
+	msg = appendField(msg, 3, []byte("token"))
+
+	var req ExecuteRequest
+	if err := req.unmarshal(msg); err != nil {
+		t.Fatalf("unmarshal error: %v", err)
+	}
+	if req.SQL != "SELECT 1" || req.Endpoint != "http://localhost:9428" || req.BearerToken != "token" {
+		t.Fatalf("unexpected message: %+v", req)
+	}
+}
+
+func TestEncodeGrpcMessage(t *testing.T) {
+	if got := encodeGrpcMessage("plain message"); got != "plain message" {
+		t.Fatalf("unexpected encoding %q", got)
+	}
+	if got := encodeGrpcMessage("100% b\nad"); got != "100%25 b%0Aad" {
+		t.Fatalf("unexpected encoding %q", got)
+	}
+}
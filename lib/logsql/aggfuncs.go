@@ -0,0 +1,57 @@
+package logsql
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+var (
+	aggFuncMu sync.RWMutex
+	aggFuncs  = map[string]string{}
+)
+
+// statsFuncNameRe limits registered stats function names to what LogsQL
+// accepts, so a registration can never inject pipe syntax.
+var statsFuncNameRe = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// RegisterAggregateFunction maps a custom SQL aggregate (case-insensitive)
+// to a LogsQL stats function of the same shape: NAME(field) translates to
+// statsfunc(field) and NAME() or NAME(*) to statsfunc(). This lets new
+// VictoriaLogs stats functions be exposed without a translator release;
+// builtin aggregates cannot be shadowed.
+func RegisterAggregateFunction(name, statsFunc string) error {
+	key := strings.ToUpper(strings.TrimSpace(name))
+	if key == "" {
+		return fmt.Errorf("logsql: aggregate function name cannot be empty")
+	}
+	if isBuiltinAggregateName(key) {
+		return fmt.Errorf("logsql: aggregate function %q shadows a builtin aggregate", key)
+	}
+	statsFunc = strings.ToLower(strings.TrimSpace(statsFunc))
+	if !statsFuncNameRe.MatchString(statsFunc) {
+		return fmt.Errorf("logsql: invalid stats function name %q", statsFunc)
+	}
+	aggFuncMu.Lock()
+	defer aggFuncMu.Unlock()
+	if _, exists := aggFuncs[key]; exists {
+		return fmt.Errorf("logsql: aggregate function %q is already registered", key)
+	}
+	aggFuncs[key] = statsFunc
+	return nil
+}
+
+// UnregisterAggregateFunction removes a previously registered aggregate.
+func UnregisterAggregateFunction(name string) {
+	aggFuncMu.Lock()
+	defer aggFuncMu.Unlock()
+	delete(aggFuncs, strings.ToUpper(strings.TrimSpace(name)))
+}
+
+func lookupAggregateFunction(name string) (string, bool) {
+	aggFuncMu.RLock()
+	defer aggFuncMu.RUnlock()
+	statsFunc, ok := aggFuncs[name]
+	return statsFunc, ok
+}
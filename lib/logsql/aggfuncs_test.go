@@ -0,0 +1,46 @@
+package logsql_test
+
+import (
+	"testing"
+
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/logsql"
+)
+
+func TestRegisteredAggregateFunction(t *testing.T) {
+	if err := logsql.RegisterAggregateFunction("RATE", "rate"); err != nil {
+		t.Fatalf("RegisterAggregateFunction error: %v", err)
+	}
+	if err := logsql.RegisterAggregateFunction("SUM_LEN", "sum_len"); err != nil {
+		t.Fatalf("RegisterAggregateFunction error: %v", err)
+	}
+	t.Cleanup(func() {
+		logsql.UnregisterAggregateFunction("RATE")
+		logsql.UnregisterAggregateFunction("SUM_LEN")
+	})
+
+	got := mustTranslate(t, "SELECT user, RATE() AS r, SUM_LEN(msg) AS total FROM logs GROUP BY user")
+	expected := "* | stats by (user) rate() r, sum_len(msg) total"
+	if got != expected {
+		t.Fatalf("unexpected query:\nexpected: %s\n     got: %s", expected, got)
+	}
+
+	if _, err := translate(t, "SELECT SUM_LEN(msg, extra) FROM logs"); err == nil {
+		t.Fatal("expected error for too many arguments")
+	}
+}
+
+func TestRegisterAggregateFunctionValidation(t *testing.T) {
+	if err := logsql.RegisterAggregateFunction("COUNT", "count"); err == nil {
+		t.Fatal("expected error when shadowing a builtin aggregate")
+	}
+	if err := logsql.RegisterAggregateFunction("BAD", "rate()"); err == nil {
+		t.Fatal("expected error for invalid stats function name")
+	}
+	if err := logsql.RegisterAggregateFunction("DUP", "rate"); err != nil {
+		t.Fatalf("RegisterAggregateFunction error: %v", err)
+	}
+	t.Cleanup(func() { logsql.UnregisterAggregateFunction("DUP") })
+	if err := logsql.RegisterAggregateFunction("dup", "rate"); err == nil {
+		t.Fatal("expected error for duplicate registration")
+	}
+}
@@ -0,0 +1,118 @@
+package logsql
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/sql/ast"
+)
+
+// ReferencedColumns returns the sorted, de-duplicated column names a simple
+// single-table SELECT references in its projection, WHERE, GROUP BY, HAVING
+// and ORDER BY clauses. Qualifiers naming the base table or its alias are
+// stripped; identifiers with any other qualifier, select-list aliases and
+// subquery contents are skipped so the result only holds names that must
+// exist as fields of the base table. Statements that are not a plain SELECT
+// from one table return nil.
+func ReferencedColumns(stmt ast.Statement) []string {
+	sel, ok := stmt.(*ast.SelectStatement)
+	if !ok || sel.With != nil || len(sel.SetOps) > 0 {
+		return nil
+	}
+	tn, ok := sel.From.(*ast.TableName)
+	if !ok || tn == nil || tn.Name == nil || len(tn.Name.Parts) == 0 {
+		return nil
+	}
+	qualifiers := map[string]struct{}{
+		strings.ToLower(tn.Name.Parts[len(tn.Name.Parts)-1]): {},
+	}
+	if alias := strings.TrimSpace(tn.Alias); alias != "" {
+		qualifiers[strings.ToLower(alias)] = struct{}{}
+	}
+	aliases := make(map[string]struct{}, len(sel.Columns))
+	for _, col := range sel.Columns {
+		if alias := strings.TrimSpace(col.Alias); alias != "" {
+			aliases[strings.ToLower(alias)] = struct{}{}
+		}
+	}
+
+	seen := make(map[string]struct{})
+	add := func(ident *ast.Identifier) {
+		parts := ident.Parts
+		if len(parts) > 1 {
+			if _, ok := qualifiers[strings.ToLower(parts[0])]; !ok {
+				return
+			}
+			parts = parts[1:]
+		}
+		if len(parts) != 1 || parts[0] == "" {
+			return
+		}
+		if _, ok := aliases[strings.ToLower(parts[0])]; ok {
+			return
+		}
+		seen[parts[0]] = struct{}{}
+	}
+	for _, col := range sel.Columns {
+		collectColumnRefs(col.Expr, add)
+	}
+	collectColumnRefs(sel.Where, add)
+	for _, g := range sel.GroupBy {
+		collectColumnRefs(g, add)
+	}
+	collectColumnRefs(sel.Having, add)
+	for _, o := range sel.OrderBy {
+		collectColumnRefs(o.Expr, add)
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// collectColumnRefs calls add for every identifier in expr that can name a
+// base-table field. Subqueries are not descended into because their columns
+// resolve against their own FROM clause.
+func collectColumnRefs(expr ast.Expr, add func(*ast.Identifier)) {
+	switch e := expr.(type) {
+	case nil:
+	case *ast.Identifier:
+		add(e)
+	case *ast.BinaryExpr:
+		collectColumnRefs(e.Left, add)
+		collectColumnRefs(e.Right, add)
+	case *ast.UnaryExpr:
+		collectColumnRefs(e.Expr, add)
+	case *ast.FuncCall:
+		for _, arg := range e.Args {
+			if _, ok := arg.(*ast.StarExpr); ok {
+				continue
+			}
+			collectColumnRefs(arg, add)
+		}
+	case *ast.CaseExpr:
+		collectColumnRefs(e.Operand, add)
+		for _, when := range e.When {
+			collectColumnRefs(when.Condition, add)
+			collectColumnRefs(when.Result, add)
+		}
+		collectColumnRefs(e.Else, add)
+	case *ast.BetweenExpr:
+		collectColumnRefs(e.Expr, add)
+		collectColumnRefs(e.Lower, add)
+		collectColumnRefs(e.Upper, add)
+	case *ast.InExpr:
+		collectColumnRefs(e.Expr, add)
+		for _, item := range e.List {
+			collectColumnRefs(item, add)
+		}
+	case *ast.LikeExpr:
+		collectColumnRefs(e.Expr, add)
+		collectColumnRefs(e.Pattern, add)
+	case *ast.IsNullExpr:
+		collectColumnRefs(e.Expr, add)
+	}
+}
@@ -0,0 +1,43 @@
+package logsql_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/logsql"
+)
+
+func TestReferencedColumns(t *testing.T) {
+	tests := []struct {
+		sql      string
+		expected []string
+	}{
+		{
+			"SELECT user, UPPER(level) FROM logs WHERE msg LIKE '%x%' GROUP BY user HAVING COUNT(*) > 1 ORDER BY ip",
+			[]string{"ip", "level", "msg", "user"},
+		},
+		{
+			// Qualifiers naming the base table or alias are stripped;
+			// select-list aliases do not name base-table fields.
+			"SELECT l.user AS u, COUNT(*) AS total FROM logs AS l WHERE logs.level = 'error' ORDER BY total",
+			[]string{"level", "user"},
+		},
+		{
+			// Unknown qualifiers and subquery internals are skipped.
+			"SELECT other.user FROM logs WHERE ip IN (SELECT addr FROM blocked)",
+			[]string{"ip"},
+		},
+		{"SELECT * FROM logs", nil},
+		{"WITH c AS (SELECT user FROM logs) SELECT * FROM c", nil},
+	}
+	for _, tt := range tests {
+		stmt := parseStatement(t, tt.sql)
+		got := logsql.ReferencedColumns(stmt)
+		if len(got) == 0 && len(tt.expected) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("ReferencedColumns(%q) = %v, expected %v", tt.sql, got, tt.expected)
+		}
+	}
+}
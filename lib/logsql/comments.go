@@ -0,0 +1,66 @@
+package logsql
+
+import "strings"
+
+// LeadingComments returns the text of the SQL comments that appear before
+// the first token of sql, in order. Both `--` line comments and `/* */`
+// block comments are recognized; the comment markers are stripped and the
+// text is whitespace-trimmed. Dashboards and other query generators often
+// prepend an attribution comment (e.g. `-- dashboard:latency-panel`) that
+// would otherwise be lost when the lexer skips comments.
+func LeadingComments(sql string) []string {
+	var comments []string
+	rest := sql
+	for {
+		rest = strings.TrimLeft(rest, " \t\r\n")
+		switch {
+		case strings.HasPrefix(rest, "--"):
+			body := rest[2:]
+			if idx := strings.IndexByte(body, '\n'); idx >= 0 {
+				rest = body[idx+1:]
+				body = body[:idx]
+			} else {
+				rest = ""
+			}
+			if body = strings.TrimSpace(body); body != "" {
+				comments = append(comments, body)
+			}
+		case strings.HasPrefix(rest, "/*"):
+			body := rest[2:]
+			idx := strings.Index(body, "*/")
+			if idx < 0 {
+				// An unterminated block comment is a parse error; leave it
+				// for the lexer to report.
+				return comments
+			}
+			rest = body[idx+2:]
+			if body = strings.TrimSpace(body[:idx]); body != "" {
+				comments = append(comments, body)
+			}
+		default:
+			return comments
+		}
+	}
+}
+
+// AppendCommentAnnotation carries comments into query as a trailing LogsQL
+// `#` comment, which VictoriaLogs keeps in its query logs so queries can be
+// attributed back to their source. Multiple comments are joined with "; "
+// and embedded newlines are collapsed, keeping the annotation on the single
+// line the `#` comment covers.
+func AppendCommentAnnotation(query string, comments []string) string {
+	if query == "" || len(comments) == 0 {
+		return query
+	}
+	cleaned := make([]string, 0, len(comments))
+	for _, c := range comments {
+		c = strings.Join(strings.Fields(c), " ")
+		if c != "" {
+			cleaned = append(cleaned, c)
+		}
+	}
+	if len(cleaned) == 0 {
+		return query
+	}
+	return query + " # " + strings.Join(cleaned, "; ")
+}
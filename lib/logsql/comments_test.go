@@ -0,0 +1,44 @@
+package logsql_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/logsql"
+)
+
+func TestLeadingComments(t *testing.T) {
+	tests := []struct {
+		sql      string
+		expected []string
+	}{
+		{"SELECT * FROM logs", nil},
+		{"-- dashboard:latency-panel\nSELECT * FROM logs", []string{"dashboard:latency-panel"}},
+		{"/* team: sre */ SELECT * FROM logs", []string{"team: sre"}},
+		{"-- first\n/* second */\n-- third\nSELECT 1", []string{"first", "second", "third"}},
+		{"  \n\t-- indented\nSELECT 1", []string{"indented"}},
+		{"--\nSELECT 1", nil},
+		{"SELECT * FROM logs -- trailing", nil},
+		{"/* unterminated SELECT 1", nil},
+	}
+	for _, tt := range tests {
+		got := logsql.LeadingComments(tt.sql)
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("LeadingComments(%q) = %v, expected %v", tt.sql, got, tt.expected)
+		}
+	}
+}
+
+func TestAppendCommentAnnotation(t *testing.T) {
+	got := logsql.AppendCommentAnnotation("level:error", []string{"dashboard:latency-panel", "line\nbreak"})
+	expected := "level:error # dashboard:latency-panel; line break"
+	if got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+	if got := logsql.AppendCommentAnnotation("level:error", nil); got != "level:error" {
+		t.Errorf("expected query unchanged without comments, got %q", got)
+	}
+	if got := logsql.AppendCommentAnnotation("", []string{"x"}); got != "" {
+		t.Errorf("expected empty query to stay empty, got %q", got)
+	}
+}
@@ -48,7 +48,14 @@ func (v *createExecuteVisitor) translateCreateView(stmt *ast.CreateViewStatement
 	if stmt == nil {
 		return fmt.Errorf("translator: CREATE VIEW statement is nil")
 	}
-	if v.sp.ViewStore() == nil {
+	if stmt.Temporary {
+		if v.sp.SessionViews() == nil {
+			return &TranslationError{
+				Code:    http.StatusBadRequest,
+				Message: "translator: CREATE TEMPORARY VIEW requires a session context",
+			}
+		}
+	} else if v.sp.ViewStore() == nil {
 		return fmt.Errorf("translator: CREATE VIEW requires configured views directory")
 	}
 	if stmt.Materialized {
@@ -73,12 +80,57 @@ func (v *createExecuteVisitor) translateCreateView(stmt *ast.CreateViewStatement
 			Message: fmt.Sprintf("translator: CREATE VIEW %s missing SELECT", strings.Join(stmt.Name.Parts, ".")),
 		}
 	}
+	sel, err := applyViewColumnList(stmt)
+	if err != nil {
+		return err
+	}
+	sel, err = applyViewParameters(stmt, sel)
+	if err != nil {
+		return err
+	}
 	nestedCtx := translationContext{sp: v.sp}
-	query, err := translateSelectStatementToLogsQLWithContext(stmt.Select, nestedCtx)
+	query, err := translateSelectStatementToLogsQLWithContext(sel, nestedCtx)
 	if err != nil {
 		return fmt.Errorf("translator: failed to translate SELECT for view %s: %w", strings.Join(stmt.Name.Parts, "."), err)
 	}
-	_, err = v.sp.ViewStore().Save(stmt.Name.Parts, query, viewstore.ViewOptions{OrReplace: stmt.OrReplace, IfNotExists: stmt.IfNotExists})
+	opts := viewstore.ViewOptions{OrReplace: stmt.OrReplace, IfNotExists: stmt.IfNotExists}
+	if stmt.Temporary {
+		err = v.sp.SessionViews().Save(stmt.Name.Parts, query, opts)
+	} else {
+		_, err = v.sp.ViewStore().Save(stmt.Name.Parts, query, opts)
+	}
 	v.result = query
 	return err
 }
+
+// applyViewColumnList turns an explicit CREATE VIEW column list into aliases
+// on the SELECT list, so the stored query exposes the declared names. The
+// original statement is left untouched; the returned SELECT is a shallow copy.
+func applyViewColumnList(stmt *ast.CreateViewStatement) (*ast.SelectStatement, error) {
+	if len(stmt.Columns) == 0 {
+		return stmt.Select, nil
+	}
+	name := strings.Join(stmt.Name.Parts, ".")
+	sel := *stmt.Select
+	for _, col := range sel.Columns {
+		if _, ok := col.Expr.(*ast.StarExpr); ok {
+			return nil, &TranslationError{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("translator: CREATE VIEW %s cannot apply a column list to SELECT *; project the fields explicitly", name),
+			}
+		}
+	}
+	if len(stmt.Columns) != len(sel.Columns) {
+		return nil, &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("translator: CREATE VIEW %s declares %d columns but the SELECT produces %d", name, len(stmt.Columns), len(sel.Columns)),
+		}
+	}
+	columns := make([]ast.SelectItem, len(sel.Columns))
+	copy(columns, sel.Columns)
+	for i, col := range stmt.Columns {
+		columns[i].Alias = strings.Join(col.Parts, ".")
+	}
+	sel.Columns = columns
+	return &sel, nil
+}
@@ -33,14 +33,18 @@ func GetDescribeStatementLogsQL(stmt *ast.DescribeStatement, sp *store.Provider)
 }
 
 func getDescribeStatementTableLogsQL(parts []string, tableStore *tablestore.TableStore) (string, error) {
-	name := parts[len(parts)-1]
-	key := strings.ToLower(name)
-	expr, ok := tableStore.GetTableQuery(key)
+	// Schema-qualified names are stored verbatim, so try the full name
+	// before falling back to the last part.
+	expr, ok := tableStore.GetTableQuery(strings.ToLower(strings.Join(parts, ".")))
+	if !ok && len(parts) > 1 {
+		expr, ok = tableStore.GetTableQuery(strings.ToLower(parts[len(parts)-1]))
+	}
 	if !ok {
 		available := tableStore.ListTables()
+		displayName := strings.Join(parts, ".")
 		return "", &TranslationError{
 			Code:    http.StatusNotFound,
-			Message: fmt.Sprintf("translator: table %q is not configured (available: %s)", strings.Join(parts, "."), strings.Join(available, ", ")),
+			Message: fmt.Sprintf("translator: table %q is not configured%s (available: %s)", displayName, didYouMean(displayName, available), strings.Join(available, ", ")),
 		}
 	}
 	return expr, nil
@@ -55,9 +59,10 @@ func getDescribeStatementViewLogsQL(parts []string, store *viewstore.ViewStore)
 		return "", err
 	}
 	if !found {
+		names, _ := store.ListViews()
 		return "", &TranslationError{
 			Code:    http.StatusNotFound,
-			Message: fmt.Sprintf("translator: view %s not found", display),
+			Message: fmt.Sprintf("translator: view %s not found%s", display, didYouMean(display, names)),
 		}
 	}
 	return query, nil
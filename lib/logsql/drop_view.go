@@ -46,12 +46,6 @@ func (v *dropExecuteVisitor) translateDropView(stmt *ast.DropViewStatement) erro
 	if stmt == nil {
 		return fmt.Errorf("translator: DROP VIEW statement is nil")
 	}
-	if v.sp.ViewStore() == nil {
-		return &TranslationError{
-			Code:    http.StatusBadRequest,
-			Message: "translator: DROP VIEW requires configured views directory",
-		}
-	}
 	if stmt.Materialized {
 		name := "view"
 		if stmt.Name != nil && len(stmt.Name.Parts) > 0 {
@@ -68,5 +62,15 @@ func (v *dropExecuteVisitor) translateDropView(stmt *ast.DropViewStatement) erro
 			Message: "translator: DROP VIEW missing name",
 		}
 	}
+	// Temporary views shadow persistent ones, so try the session store first.
+	if removed, err := v.sp.SessionViews().Remove(stmt.Name.Parts); err != nil || removed {
+		return err
+	}
+	if v.sp.ViewStore() == nil {
+		return &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: DROP VIEW requires configured views directory",
+		}
+	}
 	return v.sp.ViewStore().Remove(stmt.Name.Parts, stmt.IfExists)
 }
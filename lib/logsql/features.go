@@ -0,0 +1,56 @@
+package logsql
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Experimental translations ship behind feature flags so a risky mapping can
+// be tried out per deployment or per request before it becomes default
+// behavior. Gated constructs are rejected with an error naming the flag.
+const (
+	// FeatureInSubquery enables translating x IN (SELECT ...) to the LogsQL
+	// in(<query>) filter.
+	FeatureInSubquery = "in_subquery"
+	// FeatureRawLogsQL enables the raw LogsQL escape hatches such as the
+	// LOGSQL('...') table function, which bypass the configured table
+	// allow-list.
+	FeatureRawLogsQL = "raw_logsql"
+	// FeaturePostProcess lets final operations that have no LogsQL
+	// equivalent (ORDER BY on expressions, INTERSECT, HAVING on arithmetic)
+	// be applied to the returned rows instead of failing the translation.
+	FeaturePostProcess = "post_process"
+)
+
+// knownFeatures lists every flag the translator understands.
+var knownFeatures = map[string]struct{}{
+	FeatureInSubquery:  {},
+	FeatureRawLogsQL:   {},
+	FeaturePostProcess: {},
+}
+
+// ValidateFeatures rejects unknown feature flag names.
+func ValidateFeatures(names []string) error {
+	for _, name := range names {
+		key := strings.ToLower(strings.TrimSpace(name))
+		if _, ok := knownFeatures[key]; !ok {
+			supported := make([]string, 0, len(knownFeatures))
+			for feature := range knownFeatures {
+				supported = append(supported, feature)
+			}
+			sort.Strings(supported)
+			return fmt.Errorf("unknown feature %q (supported: %s)", name, strings.Join(supported, ", "))
+		}
+	}
+	return nil
+}
+
+// featureGateError explains how to enable a gated construct.
+func featureGateError(construct, feature string) *TranslationError {
+	return &TranslationError{
+		Code:    http.StatusBadRequest,
+		Message: fmt.Sprintf("translator: %s are experimental; enable the %q feature to use them", construct, feature),
+	}
+}
@@ -0,0 +1,191 @@
+package logsql
+
+import (
+	"strings"
+)
+
+// FieldHint describes a projected result field and its inferred type so
+// clients can render tables correctly without sniffing values.
+type FieldHint struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// Field hint types.
+const (
+	FieldTypeString    = "string"
+	FieldTypeNumber    = "number"
+	FieldTypeTimestamp = "timestamp"
+)
+
+// numericStatsFuncs are the stats functions whose results are always
+// numeric.
+var numericStatsFuncs = map[string]struct{}{
+	"count":       {},
+	"count_uniq":  {},
+	"count_empty": {},
+	"sum":         {},
+	"avg":         {},
+	"quantile":    {},
+	"median":      {},
+	"sum_len":     {},
+	"rate":        {},
+	"rate_sum":    {},
+}
+
+// FieldHints inspects a translated LogsQL query and returns type hints for
+// the fields it projects. The inference walks the pipe stages: math pipes
+// produce numbers, stats aggregations produce numbers for numeric functions,
+// and _time is always a timestamp. A nil result means the projection cannot
+// be determined locally (for example SELECT * queries).
+func FieldHints(query string) []FieldHint {
+	types := make(map[string]string)
+	var projection []string
+	for _, stage := range splitPipeStages(query) {
+		stage = strings.TrimSpace(stage)
+		switch {
+		case strings.HasPrefix(stage, "math "):
+			if name, ok := trailingAlias(stage); ok {
+				types[name] = FieldTypeNumber
+			}
+		case strings.HasPrefix(stage, "stats"):
+			projection = statsFields(strings.TrimPrefix(stage, "stats"), types)
+		case strings.HasPrefix(stage, "fields "):
+			projection = splitTopLevel(strings.TrimPrefix(stage, "fields "))
+		case strings.HasPrefix(stage, "rename "):
+			for _, part := range splitTopLevel(strings.TrimPrefix(stage, "rename ")) {
+				from, to, ok := splitRename(part)
+				if !ok {
+					continue
+				}
+				if t, exists := types[from]; exists {
+					delete(types, from)
+					types[to] = t
+				}
+				for i, name := range projection {
+					if name == from {
+						projection[i] = to
+					}
+				}
+			}
+		}
+	}
+	if len(projection) == 0 {
+		return nil
+	}
+	hints := make([]FieldHint, 0, len(projection))
+	for _, name := range projection {
+		hints = append(hints, FieldHint{Name: name, Type: fieldType(name, types)})
+	}
+	return hints
+}
+
+func fieldType(name string, types map[string]string) string {
+	if name == "_time" {
+		return FieldTypeTimestamp
+	}
+	if t, ok := types[name]; ok {
+		return t
+	}
+	return FieldTypeString
+}
+
+// statsFields parses the body of a stats pipe ("by (a, b) count(*) as x")
+// and returns the full list of fields it produces, recording numeric types
+// for aggregation results in types.
+func statsFields(body string, types map[string]string) []string {
+	body = strings.TrimSpace(body)
+	fields := make([]string, 0)
+	if strings.HasPrefix(body, "by") {
+		rest := strings.TrimSpace(strings.TrimPrefix(body, "by"))
+		if strings.HasPrefix(rest, "(") {
+			end := strings.Index(rest, ")")
+			if end < 0 {
+				return nil
+			}
+			for _, name := range splitTopLevel(rest[1:end]) {
+				// Time bucket specs like _time:1h project plain _time.
+				if cut := strings.Index(name, ":"); cut >= 0 {
+					name = name[:cut]
+				}
+				fields = append(fields, name)
+			}
+			body = strings.TrimSpace(rest[end+1:])
+		}
+	}
+	for _, agg := range splitTopLevel(body) {
+		name, ok := trailingAlias(agg)
+		if !ok {
+			continue
+		}
+		fields = append(fields, name)
+		funcName := agg
+		if cut := strings.Index(agg, "("); cut >= 0 {
+			funcName = agg[:cut]
+		}
+		if _, numeric := numericStatsFuncs[strings.ToLower(strings.TrimSpace(funcName))]; numeric {
+			types[name] = FieldTypeNumber
+		}
+	}
+	return fields
+}
+
+// trailingAlias extracts the field name after the last top-level " as ".
+func trailingAlias(s string) (string, bool) {
+	idx := strings.LastIndex(s, " as ")
+	if idx < 0 {
+		return "", false
+	}
+	name := strings.TrimSpace(s[idx+4:])
+	if name == "" || strings.ContainsAny(name, " ,()") {
+		return "", false
+	}
+	return name, true
+}
+
+func splitRename(part string) (string, string, bool) {
+	idx := strings.Index(part, " as ")
+	if idx < 0 {
+		return "", "", false
+	}
+	from := strings.TrimSpace(part[:idx])
+	to := strings.TrimSpace(part[idx+4:])
+	if from == "" || to == "" {
+		return "", "", false
+	}
+	return from, to, true
+}
+
+// splitTopLevel splits a comma-separated list, ignoring commas inside
+// parentheses and quoted literals.
+func splitTopLevel(s string) []string {
+	parts := make([]string, 0)
+	depth := 0
+	var quote rune
+	start := 0
+	for i, r := range s {
+		if quote != 0 {
+			if r == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch r {
+		case '"', '\'', '`':
+			quote = r
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	if last := strings.TrimSpace(s[start:]); last != "" {
+		parts = append(parts, last)
+	}
+	return parts
+}
@@ -0,0 +1,69 @@
+package logsql_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/logsql"
+)
+
+func TestFieldHints(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  []logsql.FieldHint
+	}{
+		{
+			name:  "plain filter without projection",
+			query: "level:error",
+			want:  nil,
+		},
+		{
+			name:  "fields pipe",
+			query: "* | fields _time, level, _msg",
+			want: []logsql.FieldHint{
+				{Name: "_time", Type: "timestamp"},
+				{Name: "level", Type: "string"},
+				{Name: "_msg", Type: "string"},
+			},
+		},
+		{
+			name:  "stats aggregation",
+			query: "* | stats by (level) count(*) as total",
+			want: []logsql.FieldHint{
+				{Name: "level", Type: "string"},
+				{Name: "total", Type: "number"},
+			},
+		},
+		{
+			name:  "math pipe result",
+			query: "* | math (a + b) as total | fields level, total",
+			want: []logsql.FieldHint{
+				{Name: "level", Type: "string"},
+				{Name: "total", Type: "number"},
+			},
+		},
+		{
+			name:  "rename keeps inferred type",
+			query: "* | stats count(*) as cnt | rename cnt as hits",
+			want: []logsql.FieldHint{
+				{Name: "hits", Type: "number"},
+			},
+		},
+		{
+			name:  "min aggregation stays string",
+			query: "* | stats min(_time) as earliest",
+			want: []logsql.FieldHint{
+				{Name: "earliest", Type: "string"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := logsql.FieldHints(tt.query)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("FieldHints(%q) = %+v, want %+v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
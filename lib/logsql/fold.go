@@ -0,0 +1,179 @@
+package logsql
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/sql/ast"
+)
+
+// foldConstantExpr returns a semantically identical expression with constant
+// subexpressions precomputed: literal arithmetic collapses into a single
+// numeric literal, comparisons between literals become boolean literals and
+// tautological AND/OR branches are dropped. ORMs routinely emit guards like
+// `1=1`, which would otherwise produce needless math pipes or fail outright.
+func foldConstantExpr(expr ast.Expr) ast.Expr {
+	switch e := expr.(type) {
+	case *ast.BinaryExpr:
+		left := foldConstantExpr(e.Left)
+		right := foldConstantExpr(e.Right)
+		switch op := strings.ToUpper(e.Operator); op {
+		case "AND":
+			if b, ok := foldedBool(left); ok {
+				if b {
+					return right
+				}
+				return &ast.BooleanLiteral{Value: false}
+			}
+			if b, ok := foldedBool(right); ok {
+				if b {
+					return left
+				}
+				return &ast.BooleanLiteral{Value: false}
+			}
+		case "OR":
+			if b, ok := foldedBool(left); ok {
+				if b {
+					return &ast.BooleanLiteral{Value: true}
+				}
+				return right
+			}
+			if b, ok := foldedBool(right); ok {
+				if b {
+					return &ast.BooleanLiteral{Value: true}
+				}
+				return left
+			}
+		case "+", "-", "*", "/", "%":
+			if ln, lok := foldedNumber(left); lok {
+				if rn, rok := foldedNumber(right); rok {
+					if folded, ok := foldArithmetic(ln, rn, op); ok {
+						return &ast.NumericLiteral{Value: strconv.FormatFloat(folded, 'f', -1, 64)}
+					}
+				}
+			}
+		case "=", "!=", "<>", "<", "<=", ">", ">=":
+			if folded, ok := foldComparison(left, right, op); ok {
+				return &ast.BooleanLiteral{Value: folded}
+			}
+		}
+		if left == e.Left && right == e.Right {
+			return e
+		}
+		return &ast.BinaryExpr{Left: left, Operator: e.Operator, Right: right}
+	case *ast.UnaryExpr:
+		inner := foldConstantExpr(e.Expr)
+		switch {
+		case e.Operator == "-":
+			if n, ok := foldedNumber(inner); ok {
+				return &ast.NumericLiteral{Value: strconv.FormatFloat(-n, 'f', -1, 64)}
+			}
+		case strings.EqualFold(e.Operator, "NOT"):
+			if b, ok := foldedBool(inner); ok {
+				return &ast.BooleanLiteral{Value: !b}
+			}
+		}
+		if inner == e.Expr {
+			return e
+		}
+		return &ast.UnaryExpr{Operator: e.Operator, Expr: inner}
+	default:
+		return expr
+	}
+}
+
+// foldedBool unwraps a boolean literal.
+func foldedBool(expr ast.Expr) (bool, bool) {
+	if b, ok := expr.(*ast.BooleanLiteral); ok {
+		return b.Value, true
+	}
+	return false, false
+}
+
+// foldedNumber unwraps a plain numeric literal. Literals carrying LogsQL
+// size or duration suffixes do not parse and stay unfolded.
+func foldedNumber(expr ast.Expr) (float64, bool) {
+	lit, ok := expr.(*ast.NumericLiteral)
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.ParseFloat(lit.Value, 64)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// foldArithmetic evaluates a constant arithmetic operation. Division by zero
+// and the modulo of non-integers are left for the backend to report.
+func foldArithmetic(left, right float64, op string) (float64, bool) {
+	switch op {
+	case "+":
+		return left + right, true
+	case "-":
+		return left - right, true
+	case "*":
+		return left * right, true
+	case "/":
+		if right == 0 {
+			return 0, false
+		}
+		return left / right, true
+	case "%":
+		li, ri := int64(left), int64(right)
+		if float64(li) != left || float64(ri) != right || ri == 0 {
+			return 0, false
+		}
+		return float64(li % ri), true
+	}
+	return 0, false
+}
+
+// foldComparison evaluates a comparison between two literals of the same
+// kind.
+func foldComparison(left, right ast.Expr, op string) (bool, bool) {
+	if ln, ok := foldedNumber(left); ok {
+		rn, ok := foldedNumber(right)
+		if !ok {
+			return false, false
+		}
+		return applyComparison(compareFloats(ln, rn), op), true
+	}
+	ls, ok := left.(*ast.StringLiteral)
+	if !ok {
+		return false, false
+	}
+	rs, ok := right.(*ast.StringLiteral)
+	if !ok {
+		return false, false
+	}
+	return applyComparison(strings.Compare(ls.Value, rs.Value), op), true
+}
+
+func compareFloats(left, right float64) int {
+	switch {
+	case left < right:
+		return -1
+	case left > right:
+		return 1
+	}
+	return 0
+}
+
+func applyComparison(cmp int, op string) bool {
+	switch op {
+	case "=":
+		return cmp == 0
+	case "!=", "<>":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+	return false
+}
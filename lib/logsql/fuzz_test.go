@@ -0,0 +1,36 @@
+package logsql_test
+
+import (
+	"testing"
+
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/logsql"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/sql/lexer"
+	sqlparser "github.com/VictoriaMetrics/sql-to-logsql/lib/sql/parser"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/store"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/store/tablestore"
+)
+
+func FuzzGetStatementInfo(f *testing.F) {
+	f.Add("SELECT * FROM logs WHERE level = 'error'")
+	f.Add("SELECT user, COUNT(*) FROM logs GROUP BY user HAVING COUNT(*) > 1 ORDER BY user LIMIT 5")
+	f.Add("WITH e AS (SELECT user FROM logs) SELECT * FROM e INNER JOIN logs ON e.user = logs.user")
+	f.Add("SELECT * FROM (SELECT * FROM logs) AS t WHERE t.level IN ('a', 'b')")
+	f.Add("DESCRIBE logs")
+
+	ts, err := tablestore.NewTableStore(map[string]string{"logs": "*"})
+	if err != nil {
+		f.Fatalf("failed to create table store: %v", err)
+	}
+	sp := store.NewStoreProvider(ts, nil)
+
+	f.Fuzz(func(t *testing.T, sql string) {
+		p := sqlparser.New(lexer.New(sql))
+		stmt := p.ParseStatement()
+		if stmt == nil || len(p.Errors()) > 0 {
+			return
+		}
+		// Any parseable statement must translate or fail with an error,
+		// never panic.
+		_, _ = logsql.GetStatementInfo(stmt, sp)
+	})
+}
@@ -0,0 +1,161 @@
+package logsql
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Guardrails bounds the cost of translated queries. The zero value disables
+// all checks.
+type Guardrails struct {
+	// MaxRows appends a "limit N" pipe to queries that have no limit of
+	// their own. Zero disables the injected limit.
+	MaxRows int `json:"maxRows"`
+	// RejectUnfiltered rejects queries whose filter part matches all logs.
+	RejectUnfiltered bool `json:"rejectUnfiltered"`
+	// RejectMessageRegexpOnly rejects queries whose only filter is a regexp
+	// match on _msg, which cannot use indexes and scans every message.
+	RejectMessageRegexpOnly bool `json:"rejectMessageRegexpOnly"`
+	// MaxInValues rejects queries expanding to more than this many OR-ed
+	// values in a single filter group. Zero disables the check.
+	MaxInValues int `json:"maxInValues"`
+}
+
+// Apply validates query against the guardrails and returns it with the
+// enforced limit injected when needed. Violations are reported as
+// TranslationError with a Bad Request code.
+func (g Guardrails) Apply(query string) (string, error) {
+	stages := splitPipeStages(query)
+	filter := strings.TrimSpace(stages[0])
+
+	if g.RejectUnfiltered && (filter == "" || filter == "*") {
+		return "", &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "guardrails: query has no filter and would scan all logs; add a WHERE clause or bypass guardrails",
+		}
+	}
+	if g.RejectMessageRegexpOnly && isMessageRegexpOnly(filter) {
+		return "", &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "guardrails: query filters only by a regexp on _msg, which scans every message; add an indexed field filter",
+		}
+	}
+	if g.MaxInValues > 0 {
+		if n := maxOrGroupSize(filter); n > g.MaxInValues {
+			return "", &TranslationError{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("guardrails: filter expands to %d OR-ed values, more than the allowed %d", n, g.MaxInValues),
+			}
+		}
+	}
+
+	if g.MaxRows > 0 && !hasLimitPipe(stages) {
+		query += fmt.Sprintf(" | limit %d", g.MaxRows)
+	}
+	return query, nil
+}
+
+func hasLimitPipe(stages []string) bool {
+	for _, stage := range stages[1:] {
+		verb := strings.TrimSpace(stage)
+		if cut := strings.IndexByte(verb, ' '); cut >= 0 {
+			verb = verb[:cut]
+		}
+		if verb == "limit" || verb == "head" {
+			return true
+		}
+	}
+	return false
+}
+
+// isMessageRegexpOnly reports whether filter consists solely of regexp
+// matches on the message field.
+func isMessageRegexpOnly(filter string) bool {
+	if filter == "" {
+		return false
+	}
+	terms := strings.Fields(filter)
+	for _, term := range terms {
+		switch {
+		case strings.EqualFold(term, "or"), strings.EqualFold(term, "and"):
+		case strings.HasPrefix(term, "_msg:~"), strings.HasPrefix(term, "~"):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// maxOrGroupSize returns the largest number of OR-ed alternatives inside a
+// single parenthesized filter group, approximating the size of expanded IN
+// lists.
+func maxOrGroupSize(filter string) int {
+	maxSize := 0
+	depth := 0
+	counts := make([]int, 1, 8)
+	var quote rune
+	i := 0
+	for i < len(filter) {
+		ch := filter[i]
+		if quote != 0 {
+			if ch == '\\' {
+				i += 2
+				continue
+			}
+			if rune(ch) == quote {
+				quote = 0
+			}
+			i++
+			continue
+		}
+		switch ch {
+		case '"', '\'', '`':
+			quote = rune(ch)
+		case '(':
+			depth++
+			counts = append(counts, 1)
+		case ')':
+			if depth > 0 {
+				if counts[depth] > maxSize {
+					maxSize = counts[depth]
+				}
+				counts = counts[:depth]
+				depth--
+			}
+		case 'o', 'O':
+			if isWordAt(filter, i, "or") {
+				counts[depth]++
+				i += 2
+				continue
+			}
+		}
+		i++
+	}
+	if counts[0] > maxSize {
+		maxSize = counts[0]
+	}
+	return maxSize
+}
+
+// isWordAt reports whether the case-insensitive word starts at offset i and
+// is delimited by non-identifier characters.
+func isWordAt(s string, i int, word string) bool {
+	if i+len(word) > len(s) {
+		return false
+	}
+	if !strings.EqualFold(s[i:i+len(word)], word) {
+		return false
+	}
+	if i > 0 && isIdentChar(s[i-1]) {
+		return false
+	}
+	if i+len(word) < len(s) && isIdentChar(s[i+len(word)]) {
+		return false
+	}
+	return true
+}
+
+func isIdentChar(ch byte) bool {
+	return ch == '_' || ('a' <= ch && ch <= 'z') || ('A' <= ch && ch <= 'Z') || ('0' <= ch && ch <= '9')
+}
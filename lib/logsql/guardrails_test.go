@@ -0,0 +1,84 @@
+package logsql_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/logsql"
+)
+
+func TestGuardrailsApply(t *testing.T) {
+	tests := []struct {
+		name       string
+		guardrails logsql.Guardrails
+		query      string
+		want       string
+		wantErr    string
+	}{
+		{
+			name:       "zero value passes everything",
+			guardrails: logsql.Guardrails{},
+			query:      "*",
+			want:       "*",
+		},
+		{
+			name:       "limit injected",
+			guardrails: logsql.Guardrails{MaxRows: 100},
+			query:      "level:error",
+			want:       "level:error | limit 100",
+		},
+		{
+			name:       "existing limit kept",
+			guardrails: logsql.Guardrails{MaxRows: 100},
+			query:      "level:error | limit 5",
+			want:       "level:error | limit 5",
+		},
+		{
+			name:       "unfiltered rejected",
+			guardrails: logsql.Guardrails{RejectUnfiltered: true},
+			query:      "*",
+			wantErr:    "no filter",
+		},
+		{
+			name:       "message regexp only rejected",
+			guardrails: logsql.Guardrails{RejectMessageRegexpOnly: true},
+			query:      `_msg:~"time.*out"`,
+			wantErr:    "regexp on _msg",
+		},
+		{
+			name:       "regexp with field filter allowed",
+			guardrails: logsql.Guardrails{RejectMessageRegexpOnly: true},
+			query:      `level:error _msg:~"time.*out"`,
+			want:       `level:error _msg:~"time.*out"`,
+		},
+		{
+			name:       "huge or group rejected",
+			guardrails: logsql.Guardrails{MaxInValues: 3},
+			query:      "id:(1 or 2 or 3 or 4 or 5)",
+			wantErr:    "OR-ed values",
+		},
+		{
+			name:       "small or group allowed",
+			guardrails: logsql.Guardrails{MaxInValues: 3},
+			query:      "id:(1 or 2)",
+			want:       "id:(1 or 2)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.guardrails.Apply(tt.query)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Apply(%q) error: %v", tt.query, err)
+			}
+			if got != tt.want {
+				t.Fatalf("Apply(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package logsql
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/sql/ast"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/store"
+)
+
+// ReferencedTables walks stmt and returns the sorted, de-duplicated names of
+// all tables it references, including tables inside subqueries and CTEs.
+func ReferencedTables(stmt ast.Statement) []string {
+	c := &tableCollector{seen: make(map[string]struct{})}
+	ast.Walk(c, stmt)
+	names := make([]string, 0, len(c.seen))
+	for name := range c.seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ViewSentinel is reported by ReferencedBaseTables in place of a view
+// reference. Views are stored as translated LogsQL that no longer names its
+// source tables, so table-keyed policy such as masking must treat a
+// statement that reads from a view as possibly touching any table.
+const ViewSentinel = "(view)"
+
+// ReferencedBaseTables returns the tables stmt references after resolving
+// names through sp's view stores the same way translation does: a name that
+// loads as a session or stored view contributes ViewSentinel instead of a
+// table name.
+func ReferencedBaseTables(stmt ast.Statement, sp *store.Provider) []string {
+	names := ReferencedTables(stmt)
+	tables := make([]string, 0, len(names))
+	viewSeen := false
+	for _, name := range names {
+		if isViewReference(sp, name) {
+			viewSeen = true
+			continue
+		}
+		tables = append(tables, name)
+	}
+	if viewSeen {
+		tables = append(tables, ViewSentinel)
+	}
+	return tables
+}
+
+// isViewReference checks name against the session and stored view stores in
+// translation order. Lookup errors count as a view reference: failing open
+// would skip masking.
+func isViewReference(sp *store.Provider, name string) bool {
+	parts := strings.Split(name, ".")
+	if _, _, found, err := sp.SessionViews().Load(parts); err != nil || found {
+		return true
+	}
+	if vs := sp.ViewStore(); vs != nil {
+		if _, _, found, err := vs.Load(parts); err != nil || found {
+			return true
+		}
+	}
+	return false
+}
+
+type tableCollector struct {
+	seen map[string]struct{}
+}
+
+func (c *tableCollector) Visit(node ast.Node) ast.Visitor {
+	if tn, ok := node.(*ast.TableName); ok && tn.Name != nil && len(tn.Name.Parts) > 0 {
+		c.seen[strings.Join(tn.Name.Parts, ".")] = struct{}{}
+	}
+	return c
+}
+
+// MaskFields redacts the named fields in a translated LogsQL query by
+// inserting format pipes directly after the filter part, before any
+// projection or aggregation can observe the original values in its output.
+func MaskFields(query string, fields []string) string {
+	if len(fields) == 0 {
+		return query
+	}
+	stages := splitPipeStages(query)
+	masked := make([]string, 0, len(fields))
+	for _, field := range fields {
+		masked = append(masked, fmt.Sprintf("format \"*****\" as %s", field))
+	}
+	parts := make([]string, 0, len(stages)+len(masked))
+	parts = append(parts, strings.TrimSpace(stages[0]))
+	parts = append(parts, masked...)
+	for _, stage := range stages[1:] {
+		parts = append(parts, maskPipeSubqueries(strings.TrimSpace(stage), fields))
+	}
+	return strings.Join(parts, " | ")
+}
+
+// maskPipeSubqueries rewrites the sub-queries embedded in join and union
+// pipes. Those run their own pipelines and pull fields in after the
+// top-level mask pipes, so each branch needs its own masking pass.
+func maskPipeSubqueries(stage string, fields []string) string {
+	// The sub-query is the second paren group of a join pipe (the first
+	// holds the join keys) and the first group of a union pipe.
+	var group int
+	switch {
+	case strings.HasPrefix(stage, "join "):
+		group = 2
+	case strings.HasPrefix(stage, "union "):
+		group = 1
+	default:
+		return stage
+	}
+	groups := topLevelParenGroups(stage)
+	if len(groups) < group {
+		return stage
+	}
+	open, end := groups[group-1][0], groups[group-1][1]
+	inner := MaskFields(stage[open+1:end], fields)
+	return stage[:open+1] + inner + stage[end:]
+}
+
+// topLevelParenGroups returns the byte offsets of the opening and closing
+// parentheses of every depth-one group in the stage, skipping quoted text.
+func topLevelParenGroups(stage string) [][2]int {
+	groups := make([][2]int, 0, 2)
+	depth := 0
+	start := 0
+	var quote rune
+	escaped := false
+	for i, r := range stage {
+		if quote != 0 {
+			if escaped {
+				escaped = false
+				continue
+			}
+			switch r {
+			case '\\':
+				escaped = true
+			case quote:
+				quote = 0
+			}
+			continue
+		}
+		switch r {
+		case '"', '\'', '`':
+			quote = r
+		case '(':
+			if depth == 0 {
+				start = i
+			}
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				groups = append(groups, [2]int{start, i})
+			}
+		}
+	}
+	return groups
+}
@@ -0,0 +1,126 @@
+package logsql
+
+import "strings"
+
+// OptimizeLogsQL cleans up a translated query: it drops redundant `filter *`
+// stages, keeps only the narrower of consecutive `fields` projections, merges
+// adjacent `delete` pipes and removes duplicated adjacent `format`
+// computations. The result is semantically identical to the input. Every
+// translated SELECT passes through this stage automatically.
+func OptimizeLogsQL(query string) string {
+	stages := splitPipeStages(query)
+	if len(stages) <= 1 {
+		return strings.TrimSpace(query)
+	}
+	head := strings.TrimSpace(stages[0])
+	pipes := make([]string, 0, len(stages)-1)
+	for _, stage := range stages[1:] {
+		pipes = append(pipes, strings.TrimSpace(stage))
+	}
+	pipes = dropRedundantFilterPipes(pipes)
+	pipes = mergeAdjacentFieldsPipes(pipes)
+	pipes = mergeAdjacentDeletePipes(pipes)
+	pipes = dropDuplicateFormatPipes(pipes)
+	if len(pipes) == 0 {
+		return head
+	}
+	return head + " | " + strings.Join(pipes, " | ")
+}
+
+// dropRedundantFilterPipes removes `filter *` stages, which match every row.
+func dropRedundantFilterPipes(pipes []string) []string {
+	out := pipes[:0]
+	for _, pipe := range pipes {
+		if pipe == "filter *" {
+			continue
+		}
+		out = append(out, pipe)
+	}
+	return out
+}
+
+// mergeAdjacentFieldsPipes collapses consecutive `fields` pipes when the
+// later projection selects a subset of the earlier one, in which case the
+// earlier pipe is redundant.
+func mergeAdjacentFieldsPipes(pipes []string) []string {
+	out := make([]string, 0, len(pipes))
+	for _, pipe := range pipes {
+		if len(out) > 0 {
+			prev, prevOK := fieldListOfPipe(out[len(out)-1], "fields ")
+			cur, curOK := fieldListOfPipe(pipe, "fields ")
+			if prevOK && curOK && fieldSubset(cur, prev) {
+				out[len(out)-1] = pipe
+				continue
+			}
+		}
+		out = append(out, pipe)
+	}
+	return out
+}
+
+// mergeAdjacentDeletePipes joins consecutive `delete` pipes into one.
+func mergeAdjacentDeletePipes(pipes []string) []string {
+	out := make([]string, 0, len(pipes))
+	for _, pipe := range pipes {
+		if len(out) > 0 {
+			prev, prevOK := fieldListOfPipe(out[len(out)-1], "delete ")
+			cur, curOK := fieldListOfPipe(pipe, "delete ")
+			if prevOK && curOK {
+				out[len(out)-1] = "delete " + strings.Join(append(prev, cur...), ", ")
+				continue
+			}
+		}
+		out = append(out, pipe)
+	}
+	return out
+}
+
+// dropDuplicateFormatPipes removes a `format` stage that exactly repeats its
+// immediate predecessor and therefore recomputes the same field.
+func dropDuplicateFormatPipes(pipes []string) []string {
+	out := make([]string, 0, len(pipes))
+	for _, pipe := range pipes {
+		if len(out) > 0 && strings.HasPrefix(pipe, "format ") && pipe == out[len(out)-1] {
+			continue
+		}
+		out = append(out, pipe)
+	}
+	return out
+}
+
+// fieldListOfPipe splits a simple field-list pipe such as `fields a, b` into
+// its field names. It reports false when the pipe has a different prefix or
+// contains anything beyond a plain comma-separated list.
+func fieldListOfPipe(pipe, prefix string) ([]string, bool) {
+	rest, ok := strings.CutPrefix(pipe, prefix)
+	if !ok {
+		return nil, false
+	}
+	if strings.ContainsAny(rest, "\"'`()") {
+		return nil, false
+	}
+	parts := strings.Split(rest, ",")
+	fields := make([]string, 0, len(parts))
+	for _, part := range parts {
+		field := strings.TrimSpace(part)
+		if field == "" || field == "*" {
+			return nil, false
+		}
+		fields = append(fields, field)
+	}
+	return fields, true
+}
+
+// fieldSubset reports whether every name in sub also appears in super.
+func fieldSubset(sub, super []string) bool {
+	lookup := make(map[string]struct{}, len(super))
+	for _, f := range super {
+		lookup[f] = struct{}{}
+	}
+	for _, f := range sub {
+		if _, ok := lookup[f]; !ok {
+			return false
+		}
+	}
+	return true
+}
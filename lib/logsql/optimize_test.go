@@ -0,0 +1,58 @@
+package logsql_test
+
+import (
+	"testing"
+
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/logsql"
+)
+
+func TestOptimizeLogsQL(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			name:  "filter only",
+			query: "level:error",
+			want:  "level:error",
+		},
+		{
+			name:  "drops filter star",
+			query: "level:error | filter * | fields user",
+			want:  "level:error | fields user",
+		},
+		{
+			name:  "merges narrowing fields pipes",
+			query: "* | fields user, level, msg | fields user, level",
+			want:  "* | fields user, level",
+		},
+		{
+			name:  "keeps widening fields pipes",
+			query: "* | fields user | fields user, level",
+			want:  "* | fields user | fields user, level",
+		},
+		{
+			name:  "merges adjacent delete pipes",
+			query: "* | delete tmp_a | delete tmp_b, tmp_c | limit 10",
+			want:  "* | delete tmp_a, tmp_b, tmp_c | limit 10",
+		},
+		{
+			name:  "drops repeated format pipe",
+			query: `* | format "<lc:level>" as lvl | format "<lc:level>" as lvl | fields lvl`,
+			want:  `* | format "<lc:level>" as lvl | fields lvl`,
+		},
+		{
+			name:  "pipe inside quoted literal untouched",
+			query: `_msg:"a | filter *" | fields _msg`,
+			want:  `_msg:"a | filter *" | fields _msg`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := logsql.OptimizeLogsQL(tt.query); got != tt.want {
+				t.Fatalf("OptimizeLogsQL(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package logsql
+
+import "strings"
+
+// PlanStep describes one stage of a translated LogsQL query: the stage text
+// and the SQL construct that stages of its kind come from.
+type PlanStep struct {
+	Stage  string `json:"stage"`
+	Origin string `json:"origin"`
+}
+
+// BuildPlan splits a LogsQL query into its base filter and pipes and labels
+// every stage with its SQL origin. The plan is derived from the final query
+// text, after optimization, so it shows what is actually sent to
+// VictoriaLogs rather than the intermediate pipes the translator emitted.
+func BuildPlan(logsQL string) []PlanStep {
+	trimmed := strings.TrimSpace(logsQL)
+	if trimmed == "" {
+		return nil
+	}
+	stages := splitPipeStages(trimmed)
+	plan := make([]PlanStep, 0, len(stages))
+	for i, stage := range stages {
+		stage = strings.TrimSpace(stage)
+		if stage == "" {
+			continue
+		}
+		origin := "base filter (FROM + WHERE)"
+		if i > 0 {
+			origin = pipeOrigin(stage)
+		}
+		plan = append(plan, PlanStep{Stage: stage, Origin: origin})
+	}
+	return plan
+}
+
+// pipeOrigin maps a pipe's leading keyword to the SQL construct the
+// translator builds it from.
+func pipeOrigin(stage string) string {
+	keyword := stage
+	if i := strings.IndexAny(stage, " \t("); i >= 0 {
+		keyword = stage[:i]
+	}
+	switch strings.ToLower(keyword) {
+	case "filter":
+		return "WHERE / HAVING"
+	case "stats":
+		return "GROUP BY / aggregate functions"
+	case "fields", "rename", "delete":
+		return "projection"
+	case "sort":
+		return "ORDER BY"
+	case "top":
+		return "GROUP BY + ORDER BY + LIMIT"
+	case "limit":
+		return "LIMIT"
+	case "offset":
+		return "OFFSET"
+	case "uniq":
+		return "DISTINCT"
+	case "math", "format", "extract", "extract_regexp", "unpack_json", "replace", "replace_regexp":
+		return "computed expression"
+	case "union":
+		return "UNION ALL"
+	case "join":
+		return "JOIN"
+	default:
+		return "pipe"
+	}
+}
@@ -0,0 +1,65 @@
+package logsql_test
+
+import (
+	"testing"
+
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/logsql"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/store"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/store/tablestore"
+)
+
+func TestBuildPlan(t *testing.T) {
+	plan := logsql.BuildPlan("level:error | stats by (user) count() total | sort by (total desc) | limit 10")
+	origins := make([]string, 0, len(plan))
+	for _, step := range plan {
+		origins = append(origins, step.Origin)
+	}
+	expected := []string{
+		"base filter (FROM + WHERE)",
+		"GROUP BY / aggregate functions",
+		"ORDER BY",
+		"LIMIT",
+	}
+	if len(origins) != len(expected) {
+		t.Fatalf("expected %d steps, got %+v", len(expected), plan)
+	}
+	for i, want := range expected {
+		if origins[i] != want {
+			t.Fatalf("step %d: expected origin %q, got %q", i, want, origins[i])
+		}
+	}
+	if plan[0].Stage != "level:error" {
+		t.Fatalf("unexpected base stage: %q", plan[0].Stage)
+	}
+
+	if plan := logsql.BuildPlan(""); plan != nil {
+		t.Fatalf("expected nil plan for empty query, got %+v", plan)
+	}
+}
+
+func TestStatementInfoIncludesPlan(t *testing.T) {
+	ts, err := tablestore.NewTableStore(map[string]string{"logs": "*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sp := store.NewStoreProvider(ts, nil)
+
+	stmt := parseStatement(t, "SELECT user, COUNT(*) AS total FROM logs WHERE level = 'error' GROUP BY user ORDER BY total DESC LIMIT 5")
+	si, err := logsql.GetStatementInfo(stmt, sp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(si.Plan) == 0 {
+		t.Fatal("expected a non-empty plan")
+	}
+	var joined string
+	for _, step := range si.Plan {
+		if joined != "" {
+			joined += " | "
+		}
+		joined += step.Stage
+	}
+	if joined != si.LogsQL {
+		t.Fatalf("plan stages do not reassemble the query:\nplan: %s\nquery: %s", joined, si.LogsQL)
+	}
+}
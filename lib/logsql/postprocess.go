@@ -0,0 +1,545 @@
+package logsql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/sql/ast"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/store"
+)
+
+// PostProcess records the final operations of a SELECT that could not be
+// pushed into LogsQL. The execution layer applies them to the returned rows
+// via Apply, so queries like ORDER BY on an expression still work as long as
+// the result fits the post-processing row limit. It is only populated when
+// the post_process feature is enabled.
+type PostProcess struct {
+	// Intersect holds the LogsQL queries of INTERSECT branches; only rows
+	// that appear in every branch's result survive.
+	Intersect []string
+	// Having filters the rows after any intersection.
+	Having ast.Expr
+	// OrderBy sorts the surviving rows.
+	OrderBy []ast.OrderItem
+	// Limit and Offset window the sorted rows; Limit is -1 when absent.
+	Limit  int64
+	Offset int64
+}
+
+// buildPostProcess retries a failed SELECT translation with untranslatable
+// final operations peeled off one at a time, recording the peeled operations
+// for client-side evaluation. It reports false when peeling cannot make the
+// statement translatable, letting the caller surface the original error.
+func buildPostProcess(stmt ast.Statement, sp *store.Provider) (*StatementInfo, bool) {
+	if !sp.FeatureEnabled(FeaturePostProcess) {
+		return nil, false
+	}
+	sel, ok := stmt.(*ast.SelectStatement)
+	if !ok {
+		return nil, false
+	}
+	post := &PostProcess{Limit: -1}
+	work := *sel
+	var notes []string
+
+	if len(work.SetOps) > 0 {
+		// INTERSECT has no LogsQL pipe at all; run every branch separately
+		// and keep the common rows. Mixed set operations are left to the
+		// regular translation path.
+		if work.With != nil {
+			return nil, false
+		}
+		for _, op := range work.SetOps {
+			if op.Operator != ast.SetOpIntersect || op.Select == nil {
+				return nil, false
+			}
+			branch, _, err := TranslateSelectStatementToLogsQLWithWarnings(op.Select, sp)
+			if err != nil {
+				return nil, false
+			}
+			post.Intersect = append(post.Intersect, branch)
+		}
+		work.SetOps = nil
+		if !movePostLimit(&work, post) {
+			return nil, false
+		}
+		notes = append(notes, "INTERSECT is not available in LogsQL; the branches run separately and only rows common to all of them are returned")
+	}
+
+	for {
+		query, warnings, err := TranslateSelectStatementToLogsQLWithWarnings(&work, sp)
+		if err == nil {
+			return &StatementInfo{
+				Kind:        StatementTypeSelect,
+				LogsQL:      query,
+				Warnings:    append(warnings, notes...),
+				Plan:        BuildPlan(query),
+				PostProcess: post,
+			}, true
+		}
+		switch {
+		case len(work.OrderBy) > 0 && orderByNeedsPostProcess(work.OrderBy):
+			if !postOrderItemsSupported(work.OrderBy) {
+				return nil, false
+			}
+			post.OrderBy = work.OrderBy
+			work.OrderBy = nil
+			if !movePostLimit(&work, post) {
+				return nil, false
+			}
+			notes = append(notes, "ORDER BY uses an expression LogsQL cannot sort by, so the returned rows are sorted by the server after the query runs")
+		case work.Having != nil && postExprSupported(work.Having):
+			post.Having = work.Having
+			work.Having = nil
+			if !movePostLimit(&work, post) {
+				return nil, false
+			}
+			notes = append(notes, "HAVING does not translate to a LogsQL filter and is evaluated on the returned rows instead")
+		default:
+			return nil, false
+		}
+	}
+}
+
+// movePostLimit shifts a LIMIT/OFFSET clause into the post-processing plan.
+// Once any operation runs client-side the upstream query must not truncate
+// its input, so the window has to move with it.
+func movePostLimit(work *ast.SelectStatement, post *PostProcess) bool {
+	if work.Limit == nil {
+		return true
+	}
+	if work.Limit.Count != nil {
+		n, err := foldIntegerExpr(work.Limit.Count)
+		if err != nil || n < 0 {
+			return false
+		}
+		post.Limit = n
+	}
+	if work.Limit.Offset != nil {
+		n, err := foldIntegerExpr(work.Limit.Offset)
+		if err != nil || n < 0 {
+			return false
+		}
+		post.Offset = n
+	}
+	work.Limit = nil
+	return true
+}
+
+// orderByNeedsPostProcess reports whether the ORDER BY contains sort keys
+// the translator cannot render, i.e. anything beyond plain identifiers and
+// aggregate calls.
+func orderByNeedsPostProcess(items []ast.OrderItem) bool {
+	for _, item := range items {
+		switch item.Expr.(type) {
+		case *ast.Identifier, *ast.FuncCall:
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// postOrderItemsSupported checks that every sort key can be evaluated by the
+// row engine and uses a collation it understands.
+func postOrderItemsSupported(items []ast.OrderItem) bool {
+	for _, item := range items {
+		if !postExprSupported(item.Expr) {
+			return false
+		}
+		switch item.Collation {
+		case "", "LEXICOGRAPHIC", "NUMERIC":
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// postExprSupported reports whether the row engine can evaluate the
+// expression: identifiers, literals, arithmetic, comparisons and boolean
+// logic. Anything touching functions or subqueries stays server-side only.
+func postExprSupported(expr ast.Expr) bool {
+	switch e := expr.(type) {
+	case *ast.Identifier, *ast.StringLiteral, *ast.NumericLiteral, *ast.BooleanLiteral, *ast.NullLiteral:
+		return true
+	case *ast.UnaryExpr:
+		switch strings.ToUpper(e.Operator) {
+		case "-", "NOT":
+			return postExprSupported(e.Expr)
+		}
+		return false
+	case *ast.BinaryExpr:
+		switch strings.ToUpper(e.Operator) {
+		case "+", "-", "*", "/", "%", "=", "!=", "<>", "<", "<=", ">", ">=", "AND", "OR":
+			return postExprSupported(e.Left) && postExprSupported(e.Right)
+		}
+		return false
+	default:
+		return false
+	}
+}
+
+// postRow pairs the original NDJSON line with its decoded fields, so rows
+// that survive post-processing are emitted byte-for-byte as received.
+type postRow struct {
+	raw    []byte
+	fields map[string]string
+}
+
+// Apply runs the recorded operations over the main result rows (and the
+// INTERSECT branch results, in the same order as Intersect) and re-encodes
+// the survivors as NDJSON.
+func (p *PostProcess) Apply(main []byte, branches [][]byte) ([]byte, error) {
+	rows, err := decodePostRows(main)
+	if err != nil {
+		return nil, err
+	}
+	if len(branches) != len(p.Intersect) {
+		return nil, fmt.Errorf("expected %d INTERSECT branch results, got %d", len(p.Intersect), len(branches))
+	}
+	for _, branch := range branches {
+		branchRows, err := decodePostRows(branch)
+		if err != nil {
+			return nil, err
+		}
+		keep := make(map[string]struct{}, len(branchRows))
+		for _, row := range branchRows {
+			keep[postRowKey(row.fields)] = struct{}{}
+		}
+		kept := rows[:0]
+		seen := make(map[string]struct{}, len(rows))
+		for _, row := range rows {
+			key := postRowKey(row.fields)
+			if _, ok := keep[key]; !ok {
+				continue
+			}
+			// INTERSECT has set semantics, so duplicates collapse.
+			if _, dup := seen[key]; dup {
+				continue
+			}
+			seen[key] = struct{}{}
+			kept = append(kept, row)
+		}
+		rows = kept
+	}
+	if p.Having != nil {
+		kept := rows[:0]
+		for _, row := range rows {
+			ok, err := evalPostBool(p.Having, row.fields)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				kept = append(kept, row)
+			}
+		}
+		rows = kept
+	}
+	if len(p.OrderBy) > 0 {
+		var sortErr error
+		sort.SliceStable(rows, func(i, j int) bool {
+			less, err := p.lessPostRows(rows[i], rows[j])
+			if err != nil && sortErr == nil {
+				sortErr = err
+			}
+			return less
+		})
+		if sortErr != nil {
+			return nil, sortErr
+		}
+	}
+	if p.Offset > 0 {
+		if p.Offset >= int64(len(rows)) {
+			rows = nil
+		} else {
+			rows = rows[p.Offset:]
+		}
+	}
+	if p.Limit >= 0 && int64(len(rows)) > p.Limit {
+		rows = rows[:p.Limit]
+	}
+	out := make([]byte, 0, len(main))
+	for _, row := range rows {
+		out = append(out, row.raw...)
+		out = append(out, '\n')
+	}
+	return out, nil
+}
+
+// lessPostRows compares two rows under the ORDER BY keys. The default
+// collation compares lexicographically, matching the LogsQL sort pipe;
+// NUMERIC parses both keys as numbers first.
+func (p *PostProcess) lessPostRows(a, b postRow) (bool, error) {
+	for _, item := range p.OrderBy {
+		av, err := evalPostValue(item.Expr, a.fields)
+		if err != nil {
+			return false, err
+		}
+		bv, err := evalPostValue(item.Expr, b.fields)
+		if err != nil {
+			return false, err
+		}
+		var cmp int
+		if item.Collation == "NUMERIC" {
+			an, aerr := strconv.ParseFloat(av, 64)
+			bn, berr := strconv.ParseFloat(bv, 64)
+			if aerr != nil || berr != nil {
+				cmp = strings.Compare(av, bv)
+			} else if an < bn {
+				cmp = -1
+			} else if an > bn {
+				cmp = 1
+			}
+		} else {
+			cmp = strings.Compare(av, bv)
+		}
+		if cmp == 0 {
+			continue
+		}
+		if item.Direction == ast.Descending {
+			return cmp > 0, nil
+		}
+		return cmp < 0, nil
+	}
+	return false, nil
+}
+
+func decodePostRows(data []byte) ([]postRow, error) {
+	rows := make([]postRow, 0)
+	for _, line := range bytes.Split(data, []byte{'\n'}) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		dec := json.NewDecoder(bytes.NewReader(line))
+		dec.UseNumber()
+		var obj map[string]any
+		if err := dec.Decode(&obj); err != nil {
+			return nil, fmt.Errorf("parse result row %q: %w", line, err)
+		}
+		fields := make(map[string]string, len(obj))
+		for name, value := range obj {
+			fields[name] = postFieldValue(value)
+		}
+		rows = append(rows, postRow{raw: line, fields: fields})
+	}
+	return rows, nil
+}
+
+// postFieldValue flattens a decoded JSON value to the string form the row
+// engine works with, mirroring how VictoriaLogs stores every field as text.
+func postFieldValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		if v {
+			return "true"
+		}
+		return "false"
+	case nil:
+		return ""
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(encoded)
+	}
+}
+
+// postRowKey canonicalizes a row for INTERSECT matching. json.Marshal sorts
+// map keys, so equal rows produce equal keys regardless of field order.
+func postRowKey(fields map[string]string) string {
+	key, err := json.Marshal(fields)
+	if err != nil {
+		return fmt.Sprintf("%v", fields)
+	}
+	return string(key)
+}
+
+// evalPostValue evaluates an expression against one result row. Every value
+// is a string, matching NDJSON; numeric operators parse operands on demand.
+func evalPostValue(expr ast.Expr, fields map[string]string) (string, error) {
+	switch e := expr.(type) {
+	case *ast.Identifier:
+		return postRowField(fields, e), nil
+	case *ast.StringLiteral:
+		return e.Value, nil
+	case *ast.NumericLiteral:
+		return e.Value, nil
+	case *ast.BooleanLiteral:
+		if e.Value {
+			return "true", nil
+		}
+		return "false", nil
+	case *ast.NullLiteral:
+		return "", nil
+	case *ast.UnaryExpr:
+		switch strings.ToUpper(e.Operator) {
+		case "-":
+			n, err := evalPostNumber(e.Expr, fields)
+			if err != nil {
+				return "", err
+			}
+			return formatPostNumber(-n), nil
+		case "NOT":
+			b, err := evalPostBool(e.Expr, fields)
+			if err != nil {
+				return "", err
+			}
+			return formatPostBool(!b), nil
+		}
+	case *ast.BinaryExpr:
+		switch op := strings.ToUpper(e.Operator); op {
+		case "+", "-", "*", "/", "%":
+			left, err := evalPostNumber(e.Left, fields)
+			if err != nil {
+				return "", err
+			}
+			right, err := evalPostNumber(e.Right, fields)
+			if err != nil {
+				return "", err
+			}
+			switch op {
+			case "+":
+				return formatPostNumber(left + right), nil
+			case "-":
+				return formatPostNumber(left - right), nil
+			case "*":
+				return formatPostNumber(left * right), nil
+			case "/":
+				if right == 0 {
+					return "", fmt.Errorf("division by zero while post-processing rows")
+				}
+				return formatPostNumber(left / right), nil
+			default:
+				if right == 0 {
+					return "", fmt.Errorf("modulo by zero while post-processing rows")
+				}
+				return formatPostNumber(math.Mod(left, right)), nil
+			}
+		case "AND", "OR":
+			left, err := evalPostBool(e.Left, fields)
+			if err != nil {
+				return "", err
+			}
+			right, err := evalPostBool(e.Right, fields)
+			if err != nil {
+				return "", err
+			}
+			if op == "AND" {
+				return formatPostBool(left && right), nil
+			}
+			return formatPostBool(left || right), nil
+		case "=", "!=", "<>", "<", "<=", ">", ">=":
+			left, err := evalPostValue(e.Left, fields)
+			if err != nil {
+				return "", err
+			}
+			right, err := evalPostValue(e.Right, fields)
+			if err != nil {
+				return "", err
+			}
+			cmp := comparePostValues(left, right)
+			switch op {
+			case "=":
+				return formatPostBool(cmp == 0), nil
+			case "!=", "<>":
+				return formatPostBool(cmp != 0), nil
+			case "<":
+				return formatPostBool(cmp < 0), nil
+			case "<=":
+				return formatPostBool(cmp <= 0), nil
+			case ">":
+				return formatPostBool(cmp > 0), nil
+			default:
+				return formatPostBool(cmp >= 0), nil
+			}
+		}
+	}
+	return "", fmt.Errorf("cannot evaluate %T on result rows", expr)
+}
+
+func evalPostNumber(expr ast.Expr, fields map[string]string) (float64, error) {
+	value, err := evalPostValue(expr, fields)
+	if err != nil {
+		return 0, err
+	}
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("value %q is not a number", value)
+	}
+	return n, nil
+}
+
+func evalPostBool(expr ast.Expr, fields map[string]string) (bool, error) {
+	value, err := evalPostValue(expr, fields)
+	if err != nil {
+		return false, err
+	}
+	switch value {
+	case "true":
+		return true, nil
+	case "false", "":
+		return false, nil
+	default:
+		return false, fmt.Errorf("value %q is not a boolean", value)
+	}
+}
+
+// comparePostValues compares numerically when both sides parse as numbers
+// and falls back to a string comparison otherwise.
+func comparePostValues(a, b string) int {
+	an, aerr := strconv.ParseFloat(a, 64)
+	bn, berr := strconv.ParseFloat(b, 64)
+	if aerr == nil && berr == nil {
+		switch {
+		case an < bn:
+			return -1
+		case an > bn:
+			return 1
+		default:
+			return 0
+		}
+	}
+	return strings.Compare(a, b)
+}
+
+func formatPostNumber(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
+
+func formatPostBool(b bool) string {
+	if b {
+		return "true"
+	}
+	return "false"
+}
+
+// postRowField resolves an identifier against the row's field names, trying
+// the qualified name first and then the bare column name.
+func postRowField(fields map[string]string, ident *ast.Identifier) string {
+	name := strings.Join(ident.Parts, ".")
+	if value, ok := fields[name]; ok {
+		return value
+	}
+	if value, ok := fields[strings.ToLower(name)]; ok {
+		return value
+	}
+	last := ident.Parts[len(ident.Parts)-1]
+	if value, ok := fields[last]; ok {
+		return value
+	}
+	return fields[strings.ToLower(last)]
+}
@@ -0,0 +1,21 @@
+package logsql
+
+import "strings"
+
+// PrettyLogsQL reformats a translated LogsQL query for readability: the
+// filter part stays on the first line and every top-level pipe stage moves
+// to its own indented line. The result is semantically identical to the
+// input and can be pasted into the VictoriaLogs UI as-is.
+func PrettyLogsQL(query string) string {
+	stages := splitPipeStages(query)
+	if len(stages) <= 1 {
+		return strings.TrimSpace(query)
+	}
+	var builder strings.Builder
+	builder.WriteString(strings.TrimSpace(stages[0]))
+	for _, stage := range stages[1:] {
+		builder.WriteString("\n  | ")
+		builder.WriteString(strings.TrimSpace(stage))
+	}
+	return builder.String()
+}
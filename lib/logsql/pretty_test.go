@@ -0,0 +1,38 @@
+package logsql_test
+
+import (
+	"testing"
+
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/logsql"
+)
+
+func TestPrettyLogsQL(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			name:  "filter only",
+			query: "level:error",
+			want:  "level:error",
+		},
+		{
+			name:  "pipes on separate lines",
+			query: "level:error | stats by (level) count(*) as total | sort by (total desc) | limit 10",
+			want:  "level:error\n  | stats by (level) count(*) as total\n  | sort by (total desc)\n  | limit 10",
+		},
+		{
+			name:  "pipe inside quoted literal untouched",
+			query: `_msg:"a | b" | fields _msg`,
+			want:  "_msg:\"a | b\"\n  | fields _msg",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := logsql.PrettyLogsQL(tt.query); got != tt.want {
+				t.Fatalf("PrettyLogsQL(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
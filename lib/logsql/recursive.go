@@ -0,0 +1,223 @@
+package logsql
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/sql/ast"
+)
+
+// unrollRecursiveCTE rewrites a recursive CTE body of the canonical form
+//
+//	anchor UNION ALL recursive-member
+//
+// into a plain UNION ALL chain by substituting the previous iteration for
+// every self-reference, up to depth iterations. LogsQL has no fixpoint
+// construct, so bounded unrolling is the only way to serve hierarchy
+// expansion queries; rows beyond the configured depth are silently absent,
+// which matches what a depth-guarded recursive query would return.
+func unrollRecursiveCTE(name string, sel *ast.SelectStatement, depth int) (*ast.SelectStatement, error) {
+	if len(sel.SetOps) != 1 {
+		return nil, &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("translator: recursive CTE %q must be anchor UNION ALL recursive member", name),
+		}
+	}
+	op := sel.SetOps[0]
+	if op.Operator != ast.SetOpUnion || !op.All || op.Select == nil {
+		return nil, &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("translator: recursive CTE %q must combine its members with UNION ALL", name),
+		}
+	}
+	anchor := *sel
+	anchor.SetOps = nil
+	if countRecursiveRefs(anchor.From, name) > 0 {
+		return nil, &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("translator: recursive CTE %q references itself in the anchor member", name),
+		}
+	}
+	member := op.Select
+	if countRecursiveRefs(member.From, name) == 0 {
+		return nil, &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("translator: recursive CTE %q never references itself; drop RECURSIVE", name),
+		}
+	}
+	targets, needAlign, err := recursiveColumnTargets(name, &anchor, member)
+	if err != nil {
+		return nil, err
+	}
+
+	result := anchor
+	prev := &anchor
+	for i := 0; i < depth; i++ {
+		next := *member
+		from, n := substituteRecursiveRef(next.From, name, prev)
+		if n != 1 {
+			return nil, &TranslationError{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("translator: recursive CTE %q must reference itself exactly once in FROM", name),
+			}
+		}
+		next.From = from
+		branch := &next
+		if needAlign {
+			branch = renameRecursiveOutputs(name, branch, targets)
+		}
+		result.SetOps = append(result.SetOps, ast.SetOperation{
+			Operator: ast.SetOpUnion,
+			All:      true,
+			Select:   branch,
+		})
+		prev = branch
+	}
+	return &result, nil
+}
+
+// recursiveColumnTargets maps the recursive member's outputs onto the
+// anchor's column names positionally, the way SQL defines a recursive CTE's
+// schema. It returns the per-column target names and whether any member
+// column needs renaming; without the rename the unrolled union emits mixed
+// schemas and deeper branches filter on fields the previous branch dropped.
+// Unnamed anchor expressions get a positional alias on the anchor itself so
+// both sides agree on a name.
+func recursiveColumnTargets(name string, anchor, member *ast.SelectStatement) ([]string, bool, error) {
+	if hasStarColumn(anchor.Columns) || hasStarColumn(member.Columns) {
+		// Both members expose the full row; there is nothing to rename.
+		return nil, false, nil
+	}
+	if len(anchor.Columns) != len(member.Columns) {
+		return nil, false, &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("translator: recursive CTE %q members must return the same number of columns", name),
+		}
+	}
+	anchor.Columns = append([]ast.SelectItem(nil), anchor.Columns...)
+	targets := make([]string, len(anchor.Columns))
+	needAlign := false
+	for i := range anchor.Columns {
+		target := selectItemName(anchor.Columns[i])
+		if target == "" {
+			target = fmt.Sprintf("%s_col_%d", name, i+1)
+			anchor.Columns[i].Alias = target
+		}
+		targets[i] = target
+		if selectItemName(member.Columns[i]) != target {
+			needAlign = true
+		}
+	}
+	return targets, needAlign, nil
+}
+
+// renameRecursiveOutputs wraps a branch in a select that projects its
+// columns under the target names. Renaming in a wrapper instead of aliasing
+// the branch's own items keeps the branch's WHERE bound to the source
+// columns rather than the freshly computed values.
+func renameRecursiveOutputs(name string, branch *ast.SelectStatement, targets []string) *ast.SelectStatement {
+	inner := *branch
+	inner.Columns = append([]ast.SelectItem(nil), branch.Columns...)
+	outer := &ast.SelectStatement{
+		Columns: make([]ast.SelectItem, len(targets)),
+		From:    &ast.SubqueryTable{Select: &inner, Alias: name},
+	}
+	for i, target := range targets {
+		current := selectItemName(inner.Columns[i])
+		if current == "" {
+			// The expression has no stable name to project by; give it a
+			// scratch alias for the wrapper to pick up.
+			current = fmt.Sprintf("__rcte_%d", i+1)
+			inner.Columns[i].Alias = current
+		}
+		item := ast.SelectItem{Expr: &ast.Identifier{Parts: []string{current}}}
+		if current != target {
+			item.Alias = target
+		}
+		outer.Columns[i] = item
+	}
+	return outer
+}
+
+// selectItemName derives an item's output column name: its alias, or the
+// column it projects. Unaliased expressions have no stable name.
+func selectItemName(item ast.SelectItem) string {
+	if item.Alias != "" {
+		return item.Alias
+	}
+	if id, ok := item.Expr.(*ast.Identifier); ok && len(id.Parts) > 0 {
+		return id.Parts[len(id.Parts)-1]
+	}
+	return ""
+}
+
+func hasStarColumn(columns []ast.SelectItem) bool {
+	for _, col := range columns {
+		if _, ok := col.Expr.(*ast.StarExpr); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// countRecursiveRefs counts FROM-level references to the CTE name.
+func countRecursiveRefs(from ast.TableExpr, name string) int {
+	switch t := from.(type) {
+	case *ast.TableName:
+		if tableNameMatches(t, name) {
+			return 1
+		}
+	case *ast.JoinExpr:
+		return countRecursiveRefs(t.Left, name) + countRecursiveRefs(t.Right, name)
+	}
+	return 0
+}
+
+// substituteRecursiveRef replaces FROM-level references to the CTE name with
+// the previous iteration as a derived table, reporting how many references
+// were replaced. Shared subtrees are fine: translation never mutates the AST
+// in place.
+func substituteRecursiveRef(from ast.TableExpr, name string, prev *ast.SelectStatement) (ast.TableExpr, int) {
+	switch t := from.(type) {
+	case *ast.TableName:
+		if !tableNameMatches(t, name) {
+			return from, 0
+		}
+		alias := t.Alias
+		if alias == "" {
+			alias = name
+		}
+		return &ast.SubqueryTable{Select: prev, Alias: alias}, 1
+	case *ast.JoinExpr:
+		left, ln := substituteRecursiveRef(t.Left, name, prev)
+		right, rn := substituteRecursiveRef(t.Right, name, prev)
+		if ln+rn == 0 {
+			return from, 0
+		}
+		join := *t
+		join.Left = left
+		join.Right = right
+		return &join, ln + rn
+	}
+	return from, 0
+}
+
+// selfReferenceInSetOps counts FROM-level self-references inside the set
+// operands, where the recursive member lives.
+func selfReferenceInSetOps(sel *ast.SelectStatement, name string) int {
+	n := 0
+	for _, op := range sel.SetOps {
+		if op.Select != nil {
+			n += countRecursiveRefs(op.Select.From, name)
+		}
+	}
+	return n
+}
+
+func tableNameMatches(t *ast.TableName, name string) bool {
+	if t == nil || t.Name == nil || len(t.Name.Parts) != 1 {
+		return false
+	}
+	return strings.ToLower(t.Name.Parts[0]) == name
+}
@@ -0,0 +1,29 @@
+package logsql
+
+import (
+	"fmt"
+
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/sql/ast"
+)
+
+// Rewriter transforms a parsed statement before translation. Rewriters are
+// the extension point for policy injection, column masking and custom
+// function expansion: they receive the statement after parsing and may
+// return a modified (or entirely new) statement.
+type Rewriter func(ast.Statement) (ast.Statement, error)
+
+// ApplyRewriters runs rewriters over stmt in order. A rewriter returning an
+// error aborts the chain; returning a nil statement is an error.
+func ApplyRewriters(stmt ast.Statement, rewriters []Rewriter) (ast.Statement, error) {
+	for i, rewrite := range rewriters {
+		var err error
+		stmt, err = rewrite(stmt)
+		if err != nil {
+			return nil, fmt.Errorf("rewriter %d: %w", i, err)
+		}
+		if stmt == nil {
+			return nil, fmt.Errorf("rewriter %d returned nil statement", i)
+		}
+	}
+	return stmt, nil
+}
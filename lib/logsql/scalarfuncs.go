@@ -0,0 +1,118 @@
+package logsql
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/sql/ast"
+)
+
+// ScalarFuncArg is one resolved argument of a custom scalar function call.
+// Identifier arguments carry the resolved field name, literal arguments the
+// literal text.
+type ScalarFuncArg struct {
+	// Field holds the resolved log field name when IsField is true.
+	Field string
+	// Literal holds the literal value when IsField is false.
+	Literal string
+	IsField bool
+}
+
+// ScalarFunc expands a custom scalar function call into LogsQL pipes. It
+// returns the pipes to run and the name of the field holding the result.
+// alias is the requested result field name; when it is empty the callback
+// must pick one.
+type ScalarFunc func(args []ScalarFuncArg, alias string) (pipes []string, resultField string, err error)
+
+var (
+	scalarFuncMu sync.RWMutex
+	scalarFuncs  = map[string]ScalarFunc{}
+)
+
+// RegisterScalarFunction adds a custom scalar function under the given
+// case-insensitive name. Embedders can expand organization-specific SQL
+// functions into their own LogsQL pipes this way instead of forking the
+// builtin translator; builtin functions always take precedence over the
+// registry.
+func RegisterScalarFunction(name string, fn ScalarFunc) error {
+	key := strings.ToUpper(strings.TrimSpace(name))
+	if key == "" {
+		return fmt.Errorf("logsql: scalar function name cannot be empty")
+	}
+	if fn == nil {
+		return fmt.Errorf("logsql: scalar function %q is nil", name)
+	}
+	scalarFuncMu.Lock()
+	defer scalarFuncMu.Unlock()
+	if _, exists := scalarFuncs[key]; exists {
+		return fmt.Errorf("logsql: scalar function %q is already registered", key)
+	}
+	scalarFuncs[key] = fn
+	return nil
+}
+
+// UnregisterScalarFunction removes a previously registered scalar function.
+func UnregisterScalarFunction(name string) {
+	scalarFuncMu.Lock()
+	defer scalarFuncMu.Unlock()
+	delete(scalarFuncs, strings.ToUpper(strings.TrimSpace(name)))
+}
+
+func lookupScalarFunction(name string) (ScalarFunc, bool) {
+	scalarFuncMu.RLock()
+	defer scalarFuncMu.RUnlock()
+	fn, ok := scalarFuncs[name]
+	return fn, ok
+}
+
+// translateRegisteredFunction expands a registry-provided scalar function.
+// The bool result mirrors translateStringFunction: false means no function
+// with this name is registered.
+func (v *selectTranslatorVisitor) translateRegisteredFunction(name string, fn *ast.FuncCall, alias string) ([]string, string, bool, error) {
+	callback, ok := lookupScalarFunction(name)
+	if !ok {
+		return nil, "", false, nil
+	}
+	args := make([]ScalarFuncArg, 0, len(fn.Args))
+	for i, arg := range fn.Args {
+		switch a := arg.(type) {
+		case *ast.Identifier:
+			rawField, err := v.rawFieldName(a)
+			if err != nil {
+				return nil, "", true, err
+			}
+			args = append(args, ScalarFuncArg{Field: rawField, IsField: true})
+		case *ast.StringLiteral:
+			args = append(args, ScalarFuncArg{Literal: a.Value})
+		case *ast.NumericLiteral:
+			args = append(args, ScalarFuncArg{Literal: a.Value})
+		default:
+			return nil, "", true, &TranslationError{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("translator: argument %d of %s must be an identifier or literal", i+1, strings.ToLower(name)),
+			}
+		}
+	}
+	pipes, resultField, err := callback(args, strings.TrimSpace(alias))
+	if err != nil {
+		var te *TranslationError
+		if errors.As(err, &te) {
+			return nil, "", true, err
+		}
+		return nil, "", true, &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("translator: %s: %s", strings.ToLower(name), err),
+			Err:     err,
+		}
+	}
+	if resultField == "" {
+		return nil, "", true, &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("translator: %s returned no result field", strings.ToLower(name)),
+		}
+	}
+	return pipes, resultField, true, nil
+}
@@ -0,0 +1,55 @@
+package logsql_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/logsql"
+)
+
+func TestRegisteredScalarFunction(t *testing.T) {
+	err := logsql.RegisterScalarFunction("EXTRACT_ORDER_ID", func(args []logsql.ScalarFuncArg, alias string) ([]string, string, error) {
+		if len(args) != 1 || !args[0].IsField {
+			return nil, "", fmt.Errorf("expects a single field argument")
+		}
+		if alias == "" {
+			alias = "order_id"
+		}
+		pipe := fmt.Sprintf("extract_regexp \"order=(?P<%s>[0-9]+)\" from %s", alias, args[0].Field)
+		return []string{pipe}, alias, nil
+	})
+	if err != nil {
+		t.Fatalf("RegisterScalarFunction error: %v", err)
+	}
+	t.Cleanup(func() { logsql.UnregisterScalarFunction("EXTRACT_ORDER_ID") })
+
+	got := mustTranslate(t, "SELECT EXTRACT_ORDER_ID(msg) AS oid FROM logs WHERE level = 'error'")
+	expected := `level:error | extract_regexp "order=(?P<oid>[0-9]+)" from msg | fields oid`
+	if got != expected {
+		t.Fatalf("unexpected query:\nexpected: %s\n     got: %s", expected, got)
+	}
+
+	if _, err := translate(t, "SELECT EXTRACT_ORDER_ID(msg, 1 + 2) FROM logs"); err == nil {
+		t.Fatal("expected error for unsupported argument expression")
+	} else if !strings.Contains(err.Error(), "identifier or literal") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRegisterScalarFunctionValidation(t *testing.T) {
+	if err := logsql.RegisterScalarFunction("  ", nil); err == nil {
+		t.Fatal("expected error for empty name")
+	}
+	if err := logsql.RegisterScalarFunction("MY_FN", nil); err == nil {
+		t.Fatal("expected error for nil callback")
+	}
+	fn := func([]logsql.ScalarFuncArg, string) ([]string, string, error) { return nil, "x", nil }
+	if err := logsql.RegisterScalarFunction("my_fn", fn); err != nil {
+		t.Fatalf("RegisterScalarFunction error: %v", err)
+	}
+	t.Cleanup(func() { logsql.UnregisterScalarFunction("MY_FN") })
+	if err := logsql.RegisterScalarFunction("MY_FN", fn); err == nil {
+		t.Fatal("expected error for duplicate registration")
+	}
+}
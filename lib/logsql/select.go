@@ -2,11 +2,13 @@ package logsql
 
 import (
 	"fmt"
+	"hash/fnv"
 	"maps"
 	"net/http"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/VictoriaMetrics/sql-to-logsql/lib/sql/ast"
 	"github.com/VictoriaMetrics/sql-to-logsql/lib/sql/render"
@@ -16,26 +18,54 @@ import (
 type translationContext struct {
 	sp   *store.Provider
 	ctes map[string]string
+	// cteSelects keeps the parsed CTE bodies so references can merge
+	// pushed-down filters and re-translate instead of reusing the cached
+	// pipeline string.
+	cteSelects map[string]*ast.SelectStatement
+	// outerAliases holds the table aliases of enclosing queries so nested
+	// visitors can reject correlated references instead of silently
+	// translating them into wrong field names.
+	outerAliases map[string]struct{}
+	// cteUses counts CTE references across the whole statement; shared, not
+	// cloned, so duplicated evaluation can be reported once.
+	cteUses  map[string]int
+	warnings *[]string
 }
 
 func TranslateSelectStatementToLogsQL(stmt ast.Statement, sp *store.Provider) (string, error) {
 	return translateSelectStatementToLogsQLWithContext(stmt, translationContext{sp: sp})
 }
 
+// TranslateSelectStatementToLogsQLWithWarnings is like
+// TranslateSelectStatementToLogsQL but also reports non-fatal translation
+// notes, e.g. aggregates that become approximate in LogsQL.
+func TranslateSelectStatementToLogsQLWithWarnings(stmt ast.Statement, sp *store.Provider) (string, []string, error) {
+	var warnings []string
+	query, err := translateSelectStatementToLogsQLWithContext(stmt, translationContext{sp: sp, warnings: &warnings})
+	return query, warnings, err
+}
+
 func translateSelectStatementToLogsQLWithContext(stmt ast.Statement, ctx translationContext) (string, error) {
 	if stmt == nil {
 		return "", fmt.Errorf("translator: nil statement")
 	}
 
-	t := &selectTranslatorVisitor{sp: ctx.sp}
+	t := &selectTranslatorVisitor{sp: ctx.sp, warnings: ctx.warnings}
 	if len(ctx.ctes) > 0 {
 		t.availableCTEs = maps.Clone(ctx.ctes)
 	}
+	if len(ctx.cteSelects) > 0 {
+		t.cteSelects = maps.Clone(ctx.cteSelects)
+	}
+	if len(ctx.outerAliases) > 0 {
+		t.outerAliases = maps.Clone(ctx.outerAliases)
+	}
+	t.cteUses = ctx.cteUses
 	stmt.Accept(t)
 	if t.err != nil {
 		return "", t.err
 	}
-	return t.result, nil
+	return OptimizeLogsQL(t.result), nil
 }
 
 var (
@@ -50,13 +80,28 @@ type selectTranslatorVisitor struct {
 
 	sp *store.Provider
 
-	bindings           map[string]*tableBinding
-	autoAliasCounter   int
-	baseAlias          string
-	pendingLeftFilter  []ast.Expr
-	aggResults         map[string]string
-	groupExprAliases   map[string]string
-	availableCTEs      map[string]string
+	bindings          map[string]*tableBinding
+	autoAliasCounter  int
+	baseAlias         string
+	pendingLeftFilter []ast.Expr
+	// pendingWhere holds the WHERE clause while the FROM clause is being
+	// processed, so joins can push alias-local predicates into the right
+	// query; the remainder is translated as the base filter afterwards.
+	// pendingSelectAliases names the select-list aliases of the outer
+	// query, which must never be pushed into a derived table.
+	pendingWhere         ast.Expr
+	pendingSelectAliases map[string]ast.Expr
+	aggResults           map[string]string
+	aggExprCounter       int
+	groupExprAliases     map[string]string
+	availableCTEs        map[string]string
+	cteSelects           map[string]*ast.SelectStatement
+	// outerAliases holds table aliases of enclosing queries so correlated
+	// references can be rejected instead of silently mistranslated.
+	outerAliases map[string]struct{}
+	// cteUses counts how often each CTE is referenced, shared with nested
+	// visitors so repeated evaluation of the same pipeline is warned about.
+	cteUses            map[string]int
 	baseUsesPipeline   bool
 	basePipeline       string
 	baseFilter         string
@@ -64,6 +109,43 @@ type selectTranslatorVisitor struct {
 	filterOrder        []string
 	filterDelete       []string
 	filterDeleteSet    map[string]struct{}
+	// warnings collects non-fatal translation notes; shared with nested
+	// visitors (CTEs, subqueries) via the translation context.
+	warnings *[]string
+}
+
+func (v *selectTranslatorVisitor) addWarning(format string, args ...any) {
+	if v.warnings == nil {
+		return
+	}
+	*v.warnings = append(*v.warnings, fmt.Sprintf(format, args...))
+}
+
+// strictViolation handles a construct whose LogsQL translation does not
+// preserve exact SQL semantics: with SET strict_mode = true the construct is
+// rejected, otherwise it translates with a warning.
+func (v *selectTranslatorVisitor) strictViolation(format string, args ...any) error {
+	if v.sp.SessionOptions().StrictMode() {
+		return &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: strict mode: " + fmt.Sprintf(format, args...),
+		}
+	}
+	v.addWarning(format, args...)
+	return nil
+}
+
+// noteCTEUse records a reference to a translated CTE. LogsQL has no shared
+// materialization, so every reference re-evaluates the CTE pipeline; the
+// second reference triggers a one-time warning suggesting a temporary view.
+func (v *selectTranslatorVisitor) noteCTEUse(name string) {
+	if v.cteUses == nil {
+		v.cteUses = make(map[string]int)
+	}
+	v.cteUses[name]++
+	if v.cteUses[name] == 2 {
+		v.addWarning("CTE %q is referenced multiple times and is re-evaluated at every reference; consider a temporary view for heavy pipelines", name)
+	}
 }
 
 type tableBinding struct {
@@ -93,14 +175,40 @@ func newTableSpec(expr string) tableSpec {
 	return tableSpec{filter: value}
 }
 
-func (v *selectTranslatorVisitor) lookupTableSpec(nameLower string) (tableSpec, bool) {
-	expr, ok := v.sp.TableStore().GetTableQuery(nameLower)
+func (v *selectTranslatorVisitor) lookupTableSpec(name string) (tableSpec, bool) {
+	// Configured table names are stored lowercased, so match them
+	// case-insensitively even when identifier matching is case-sensitive.
+	expr, ok := v.sp.TableStore().GetTableQuery(strings.ToLower(name))
 	if !ok {
 		return tableSpec{}, false
 	}
 	return newTableSpec(expr), true
 }
 
+// resolveTableSpec matches a possibly schema-qualified table reference
+// against the configured tables. Qualified names are tried verbatim first,
+// then unqualified ones are resolved in the session's USE schema before
+// falling back to the bare name.
+func (v *selectTranslatorVisitor) resolveTableSpec(parts []string) (tableSpec, bool) {
+	if len(parts) == 0 {
+		return tableSpec{}, false
+	}
+	if len(parts) > 1 {
+		if spec, ok := v.lookupTableSpec(strings.Join(parts, ".")); ok {
+			return spec, true
+		}
+	}
+	name := parts[len(parts)-1]
+	if len(parts) == 1 {
+		if schema := v.sp.SessionOptions().Schema(); schema != "" {
+			if spec, ok := v.lookupTableSpec(schema + "." + name); ok {
+				return spec, true
+			}
+		}
+	}
+	return v.lookupTableSpec(name)
+}
+
 func (v *selectTranslatorVisitor) Visit(node ast.Node) ast.Visitor {
 	if node == nil || v.err != nil {
 		return v
@@ -120,16 +228,23 @@ func (v *selectTranslatorVisitor) Visit(node ast.Node) ast.Visitor {
 }
 
 func (v *selectTranslatorVisitor) translateSimpleSelect(stmt *ast.SelectStatement) (string, error) {
-	if stmt.With != nil && len(stmt.With.CTEs) > 0 {
-		if stmt.With.Recursive {
-			return "", &TranslationError{
-				Code:    http.StatusBadRequest,
-				Message: "translator: recursive CTEs are not supported",
-			}
+	if pivot, ok := stmt.From.(*ast.PivotTable); ok {
+		rewritten, err := desugarPivot(stmt, pivot)
+		if err != nil {
+			return "", err
 		}
+		stmt = rewritten
+	}
+	if stmt.With != nil && len(stmt.With.CTEs) > 0 {
 		if v.availableCTEs == nil {
 			v.availableCTEs = make(map[string]string)
 		}
+		if v.cteSelects == nil {
+			v.cteSelects = make(map[string]*ast.SelectStatement)
+		}
+		if v.cteUses == nil {
+			v.cteUses = make(map[string]int)
+		}
 		for _, cte := range stmt.With.CTEs {
 			if cte.Name == nil || len(cte.Name.Parts) == 0 {
 				return "", &TranslationError{
@@ -143,16 +258,27 @@ func (v *selectTranslatorVisitor) translateSimpleSelect(stmt *ast.SelectStatemen
 					Message: fmt.Sprintf("translator: CTE %s has nil select", strings.Join(cte.Name.Parts, ".")),
 				}
 			}
-			name := strings.ToLower(cte.Name.Parts[len(cte.Name.Parts)-1])
+			name := v.identKey(cte.Name.Parts[len(cte.Name.Parts)-1])
 			if _, exists := v.availableCTEs[name]; exists {
 				return "", &TranslationError{
 					Code:    http.StatusBadRequest,
 					Message: fmt.Sprintf("translator: duplicate CTE name %q", name),
 				}
 			}
-			query, err := translateSelectStatementToLogsQLWithContext(cte.Select, translationContext{
-				sp:   v.sp,
-				ctes: v.availableCTEs,
+			sel := cte.Select
+			if stmt.With.Recursive && countRecursiveRefs(sel.From, name)+selfReferenceInSetOps(sel, name) > 0 {
+				unrolled, err := unrollRecursiveCTE(name, sel, v.sp.SessionOptions().RecursiveCTEDepth())
+				if err != nil {
+					return "", err
+				}
+				sel = unrolled
+			}
+			query, err := translateSelectStatementToLogsQLWithContext(sel, translationContext{
+				sp:         v.sp,
+				ctes:       v.availableCTEs,
+				cteSelects: v.cteSelects,
+				cteUses:    v.cteUses,
+				warnings:   v.warnings,
 			})
 			if err != nil {
 				return "", &TranslationError{
@@ -162,6 +288,7 @@ func (v *selectTranslatorVisitor) translateSimpleSelect(stmt *ast.SelectStatemen
 				}
 			}
 			v.availableCTEs[name] = query
+			v.cteSelects[name] = sel
 		}
 	}
 	distinct := stmt.Distinct
@@ -175,7 +302,10 @@ func (v *selectTranslatorVisitor) translateSimpleSelect(stmt *ast.SelectStatemen
 	v.bindings = make(map[string]*tableBinding)
 	v.autoAliasCounter = 0
 	v.pendingLeftFilter = nil
+	v.pendingWhere = nil
+	v.pendingSelectAliases = nil
 	v.aggResults = nil
+	v.aggExprCounter = 0
 	v.baseAlias = ""
 	v.groupExprAliases = nil
 	v.baseUsesPipeline = false
@@ -186,21 +316,51 @@ func (v *selectTranslatorVisitor) translateSimpleSelect(stmt *ast.SelectStatemen
 	v.filterDelete = nil
 	v.filterDeleteSet = nil
 
+	v.pendingWhere = foldConstantExpr(stmt.Where)
+	if b, ok := v.pendingWhere.(*ast.BooleanLiteral); ok {
+		if !b.Value {
+			return "", &TranslationError{
+				Code:    http.StatusBadRequest,
+				Message: "translator: WHERE clause is constant false and would match no rows",
+			}
+		}
+		// Tautologies like WHERE 1=1 fold away entirely.
+		v.pendingWhere = nil
+	}
+	v.pendingSelectAliases = v.collectGroupAliases(stmt.Columns)
 	joinPipes, err := v.processFrom(stmt.From)
 	if err != nil {
 		return "", err
 	}
 
 	filters := make([]string, 0)
-	if stmt.Where != nil {
-		if err := v.ensureBaseAliasesOnly(stmt.Where); err != nil {
-			return "", err
+	postAliasFilters := make([]string, 0)
+	if v.pendingWhere != nil {
+		whereExpr := v.pendingWhere
+		if aliases := v.collectGroupAliases(stmt.Columns); len(aliases) > 0 {
+			remaining, extracted, err := v.extractAliasPredicates(whereExpr, aliases)
+			if err != nil {
+				return "", err
+			}
+			whereExpr = remaining
+			for _, pred := range extracted {
+				predStr, err := v.translateExpr(pred)
+				if err != nil {
+					return "", err
+				}
+				postAliasFilters = append(postAliasFilters, "filter "+predStr)
+			}
 		}
-		whereStr, err := v.translateExpr(stmt.Where)
-		if err != nil {
-			return "", err
+		if whereExpr != nil {
+			if err := v.ensureBaseAliasesOnly(whereExpr); err != nil {
+				return "", err
+			}
+			whereStr, err := v.translateExpr(whereExpr)
+			if err != nil {
+				return "", err
+			}
+			filters = append(filters, whereStr)
 		}
-		filters = append(filters, whereStr)
 	}
 	for _, lf := range v.pendingLeftFilter {
 		if err := v.ensureBaseAliasesOnly(lf); err != nil {
@@ -246,6 +406,13 @@ func (v *selectTranslatorVisitor) translateSimpleSelect(stmt *ast.SelectStatemen
 	}
 	pipes = append(pipes, joinPipes...)
 
+	if topkPipe, usedTopk, err := v.buildTopKPipe(stmt); err != nil {
+		return "", err
+	} else if usedTopk {
+		pipes = append(pipes, topkPipe)
+		return base + " | " + strings.Join(pipes, " | "), nil
+	}
+
 	statsPipes, aggregated, err := v.buildStatsPipe(stmt)
 	if err != nil {
 		return "", err
@@ -273,6 +440,9 @@ func (v *selectTranslatorVisitor) translateSimpleSelect(stmt *ast.SelectStatemen
 		return "", err
 	}
 	pipes = append(pipes, projectionPipes...)
+	// WHERE conditions on select-list aliases run after the projection, once
+	// the aliased fields exist.
+	pipes = append(pipes, postAliasFilters...)
 
 	if distinct {
 		distinctPipe, err := v.buildDistinctPipe(projectionFields, aggregated)
@@ -284,21 +454,27 @@ func (v *selectTranslatorVisitor) translateSimpleSelect(stmt *ast.SelectStatemen
 		}
 	}
 
+	limitPipes, err := v.translateLimit(stmt.Limit)
+	if err != nil {
+		return "", err
+	}
+
 	if len(stmt.OrderBy) > 0 {
-		orderPipe, err := v.translateOrderBy(stmt.OrderBy, aggregated)
+		// Fuse a plain LIMIT into the sort pipe so VictoriaLogs keeps only
+		// the top N rows while sorting instead of sorting the full set.
+		sortLimit := ""
+		if len(limitPipes) == 1 && strings.HasPrefix(limitPipes[0], "limit ") {
+			sortLimit = " " + limitPipes[0]
+			limitPipes = nil
+		}
+		orderPipe, err := v.translateOrderBy(stmt.OrderBy, aggregated, sortLimit)
 		if err != nil {
 			return "", err
 		}
 		pipes = append(pipes, orderPipe)
 	}
 
-	if stmt.Limit != nil {
-		limitPipes, err := v.translateLimit(stmt.Limit)
-		if err != nil {
-			return "", err
-		}
-		pipes = append(pipes, limitPipes...)
-	}
+	pipes = append(pipes, limitPipes...)
 
 	if len(pipes) == 0 {
 		return base, nil
@@ -306,6 +482,80 @@ func (v *selectTranslatorVisitor) translateSimpleSelect(stmt *ast.SelectStatemen
 	return base + " | " + strings.Join(pipes, " | "), nil
 }
 
+// buildTopKPipe recognizes SELECT TOPK(n, field, ...) and translates it to
+// the LogsQL "top n by (...)" pipe, which returns the most frequent value
+// combinations together with their hit counts.
+func (v *selectTranslatorVisitor) buildTopKPipe(stmt *ast.SelectStatement) (string, bool, error) {
+	var topk *ast.FuncCall
+	for i := range stmt.Columns {
+		fn, ok := stmt.Columns[i].Expr.(*ast.FuncCall)
+		if !ok || len(fn.Name.Parts) == 0 {
+			continue
+		}
+		if strings.ToUpper(fn.Name.Parts[len(fn.Name.Parts)-1]) != "TOPK" {
+			continue
+		}
+		if len(stmt.Columns) > 1 {
+			return "", false, &TranslationError{
+				Code:    http.StatusBadRequest,
+				Message: "translator: TOPK must be the only select expression",
+			}
+		}
+		if strings.TrimSpace(stmt.Columns[i].Alias) != "" {
+			return "", false, &TranslationError{
+				Code:    http.StatusBadRequest,
+				Message: "translator: TOPK does not support an alias; it returns the grouped fields plus hits",
+			}
+		}
+		topk = fn
+	}
+	if topk == nil {
+		return "", false, nil
+	}
+	if len(stmt.GroupBy) > 0 || stmt.Having != nil || stmt.Distinct || len(stmt.OrderBy) > 0 || stmt.Limit != nil {
+		return "", false, &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: TOPK cannot be combined with GROUP BY, HAVING, DISTINCT, ORDER BY or LIMIT",
+		}
+	}
+	if len(topk.Args) < 2 {
+		return "", false, &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: TOPK expects a count and at least one field",
+		}
+	}
+	lit, ok := topk.Args[0].(*ast.NumericLiteral)
+	if !ok {
+		return "", false, &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: TOPK count must be a number",
+		}
+	}
+	n, err := strconv.Atoi(lit.Value)
+	if err != nil || n < 1 {
+		return "", false, &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("translator: invalid TOPK count %s", lit.Value),
+		}
+	}
+	fields := make([]string, 0, len(topk.Args)-1)
+	for _, argExpr := range topk.Args[1:] {
+		ident, ok := argExpr.(*ast.Identifier)
+		if !ok {
+			return "", false, &TranslationError{
+				Code:    http.StatusBadRequest,
+				Message: "translator: TOPK fields must be identifiers",
+			}
+		}
+		field, err := v.normalizeIdentifier(ident)
+		if err != nil {
+			return "", false, err
+		}
+		fields = append(fields, field)
+	}
+	return fmt.Sprintf("top %d by (%s)", n, strings.Join(fields, ", ")), true, nil
+}
+
 func (v *selectTranslatorVisitor) collectFilterPrefilters() []string {
 	if len(v.filterOrder) == 0 {
 		return nil
@@ -359,8 +609,11 @@ func (v *selectTranslatorVisitor) translateSelect(stmt *ast.SelectStatement) (st
 			}
 		}
 		rhs, err := translateSelectStatementToLogsQLWithContext(op.Select, translationContext{
-			sp:   v.sp,
-			ctes: v.availableCTEs,
+			sp:         v.sp,
+			ctes:       v.availableCTEs,
+			cteSelects: v.cteSelects,
+			cteUses:    v.cteUses,
+			warnings:   v.warnings,
 		})
 		if err != nil {
 			return "", err
@@ -406,6 +659,16 @@ func (v *selectTranslatorVisitor) processFrom(from ast.TableExpr) ([]string, err
 			return nil, err
 		}
 		return nil, nil
+	case *ast.TableFunction:
+		if err := v.registerBaseTableFunction(t); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	case *ast.ValuesTable:
+		return nil, &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: VALUES tables cannot stand alone; JOIN them against a log table",
+		}
 	case *ast.JoinExpr:
 		return v.processJoin(t)
 	default:
@@ -424,13 +687,13 @@ func (v *selectTranslatorVisitor) registerBaseTable(table *ast.TableName) error
 		}
 	}
 	name := table.Name.Parts[len(table.Name.Parts)-1]
-	nameLower := strings.ToLower(name)
+	nameLower := v.identKey(name)
 
 	alias := strings.TrimSpace(table.Alias)
 	if alias == "" {
 		alias = name
 	}
-	aliasLower := strings.ToLower(alias)
+	aliasLower := v.identKey(alias)
 
 	if v.baseAlias != "" && v.baseAlias != aliasLower {
 		return &TranslationError{
@@ -441,6 +704,26 @@ func (v *selectTranslatorVisitor) registerBaseTable(table *ast.TableName) error
 
 	if v.availableCTEs != nil {
 		if query, ok := v.availableCTEs[nameLower]; ok {
+			v.noteCTEUse(nameLower)
+			if sub, ok := v.cteSelects[nameLower]; ok {
+				if rewritten, changed := v.pushdownPredicates(sub, aliasLower, nameLower); changed {
+					pushedQuery, err := translateSelectStatementToLogsQLWithContext(rewritten, translationContext{
+						sp:         v.sp,
+						ctes:       v.availableCTEs,
+						cteSelects: v.cteSelects,
+						cteUses:    v.cteUses,
+						warnings:   v.warnings,
+					})
+					if err != nil {
+						return &TranslationError{
+							Code:    http.StatusBadRequest,
+							Message: fmt.Sprintf("translator: failed to translate CTE %s: %s", nameLower, err),
+							Err:     err,
+						}
+					}
+					query = pushedQuery
+				}
+			}
 			v.baseAlias = aliasLower
 			v.baseUsesPipeline = true
 			v.basePipeline = query
@@ -450,6 +733,23 @@ func (v *selectTranslatorVisitor) registerBaseTable(table *ast.TableName) error
 		}
 	}
 
+	// Session-scoped temporary views shadow persistent ones.
+	if viewQuery, display, found, err := v.sp.SessionViews().Load(table.Name.Parts); err != nil {
+		return err
+	} else if found {
+		viewQuery, err = v.bindViewParameters(viewQuery, display, []string{aliasLower, nameLower})
+		if err != nil {
+			return err
+		}
+		v.baseAlias = aliasLower
+		v.baseUsesPipeline = true
+		v.basePipeline = viewQuery
+		v.baseFilter = ""
+		v.registerBinding(aliasLower, true)
+		v.registerBinding(nameLower, true)
+		return nil
+	}
+
 	var viewAttempted bool
 	var viewDisplay string
 	if v.sp.ViewStore() != nil {
@@ -460,6 +760,10 @@ func (v *selectTranslatorVisitor) registerBaseTable(table *ast.TableName) error
 		viewAttempted = true
 		viewDisplay = display
 		if found {
+			viewQuery, err = v.bindViewParameters(viewQuery, display, []string{aliasLower, nameLower})
+			if err != nil {
+				return err
+			}
 			v.baseAlias = aliasLower
 			v.baseUsesPipeline = true
 			v.basePipeline = viewQuery
@@ -470,18 +774,19 @@ func (v *selectTranslatorVisitor) registerBaseTable(table *ast.TableName) error
 		}
 	}
 
-	spec, ok := v.lookupTableSpec(nameLower)
+	spec, ok := v.resolveTableSpec(table.Name.Parts)
 	if !ok {
 		if viewAttempted {
 			return &TranslationError{
 				Code:    http.StatusNotFound,
-				Message: fmt.Sprintf("translator: view %s not found", viewDisplay),
+				Message: fmt.Sprintf("translator: view %s not found%s", viewDisplay, didYouMean(viewDisplay, v.nameSuggestionCandidates())),
 			}
 		}
 		available := v.sp.TableStore().ListTables()
+		displayName := strings.Join(table.Name.Parts, ".")
 		return &TranslationError{
 			Code:    http.StatusNotFound,
-			Message: fmt.Sprintf("translator: table %q is not configured (available: %s)", strings.Join(table.Name.Parts, "."), strings.Join(available, ", ")),
+			Message: fmt.Sprintf("translator: table %q is not configured%s (available: %s)", displayName, didYouMean(displayName, v.nameSuggestionCandidates()), strings.Join(available, ", ")),
 		}
 	}
 
@@ -494,6 +799,74 @@ func (v *selectTranslatorVisitor) registerBaseTable(table *ast.TableName) error
 	return nil
 }
 
+// registerBaseTableFunction wires a LOGSQL('<raw query>') table function as
+// the base pipeline, letting power users drop into raw LogsQL while the rest
+// of the statement stays SQL. Raw queries bypass the configured table
+// allow-list, so the construct is gated behind the raw_logsql feature.
+func (v *selectTranslatorVisitor) registerBaseTableFunction(table *ast.TableFunction) error {
+	if table == nil || table.Name == nil || len(table.Name.Parts) == 0 {
+		return &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: invalid table function reference",
+		}
+	}
+	name := strings.ToUpper(table.Name.Parts[len(table.Name.Parts)-1])
+	if name == "UNNEST" {
+		return &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: UNNEST must be CROSS JOINed with a log table",
+		}
+	}
+	if name != "LOGSQL" {
+		return &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("translator: table function %s is not supported%s", name, didYouMean(name, []string{"LOGSQL", "UNNEST"})),
+		}
+	}
+	if !v.sp.FeatureEnabled(FeatureRawLogsQL) {
+		return featureGateError("LOGSQL table functions", FeatureRawLogsQL)
+	}
+	if len(table.Args) != 1 {
+		return &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: LOGSQL expects a single string literal argument",
+		}
+	}
+	lit, ok := table.Args[0].(*ast.StringLiteral)
+	if !ok {
+		return &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: LOGSQL expects a single string literal argument",
+		}
+	}
+	raw := strings.TrimSpace(lit.Value)
+	if raw == "" {
+		return &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: LOGSQL query cannot be empty",
+		}
+	}
+
+	alias := strings.TrimSpace(table.Alias)
+	if alias == "" {
+		alias = "logsql"
+	}
+	aliasLower := v.identKey(alias)
+	if v.baseAlias != "" && v.baseAlias != aliasLower {
+		return &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: multiple base tables are not supported",
+		}
+	}
+
+	v.baseAlias = aliasLower
+	v.baseUsesPipeline = true
+	v.basePipeline = raw
+	v.baseFilter = ""
+	v.registerBinding(aliasLower, true)
+	return nil
+}
+
 func (v *selectTranslatorVisitor) registerBaseSubquery(table *ast.SubqueryTable) error {
 	if table == nil || table.Select == nil {
 		return &TranslationError{
@@ -505,16 +878,28 @@ func (v *selectTranslatorVisitor) registerBaseSubquery(table *ast.SubqueryTable)
 	if alias == "" {
 		alias = v.generateSubqueryAlias("base")
 	}
-	aliasLower := strings.ToLower(alias)
+	aliasLower := v.identKey(alias)
 	if v.baseAlias != "" && v.baseAlias != aliasLower {
 		return &TranslationError{
 			Code:    http.StatusBadRequest,
 			Message: "translator: multiple base tables are not supported",
 		}
 	}
-	subQuery, err := translateSelectStatementToLogsQLWithContext(table.Select, translationContext{
-		sp:   v.sp,
-		ctes: v.availableCTEs,
+	sel := table.Select
+	if rewritten, changed := v.pushdownPredicates(sel, aliasLower); changed {
+		sel = rewritten
+	}
+	// The subquery body cannot see its own alias either; include it so
+	// self-references fail the same way as other correlated references.
+	scope := v.scopeAliases()
+	scope[aliasLower] = struct{}{}
+	subQuery, err := translateSelectStatementToLogsQLWithContext(sel, translationContext{
+		sp:           v.sp,
+		ctes:         v.availableCTEs,
+		cteSelects:   v.cteSelects,
+		cteUses:      v.cteUses,
+		outerAliases: scope,
+		warnings:     v.warnings,
 	})
 	if err != nil {
 		return &TranslationError{
@@ -532,13 +917,38 @@ func (v *selectTranslatorVisitor) registerBaseSubquery(table *ast.SubqueryTable)
 }
 
 func (v *selectTranslatorVisitor) registerBinding(alias string, isBase bool) {
-	key := strings.ToLower(alias)
+	key := v.identKey(alias)
 	if key == "" {
 		return
 	}
 	v.bindings[key] = &tableBinding{alias: key, isBase: isBase}
 }
 
+// identKey normalizes an identifier for matching against bindings, CTE
+// names and alias maps. SQL identifiers match case-insensitively by
+// default; the case_sensitive_identifiers session option keeps the original
+// spelling so names resolve the way VictoriaLogs' case-sensitive fields do.
+func (v *selectTranslatorVisitor) identKey(name string) string {
+	if v.sp.SessionOptions().CaseSensitiveIdentifiers() {
+		return name
+	}
+	return strings.ToLower(name)
+}
+
+// scopeAliases merges the aliases inherited from enclosing queries with the
+// bindings registered so far, producing the outer scope a nested subquery
+// visitor checks correlated references against.
+func (v *selectTranslatorVisitor) scopeAliases() map[string]struct{} {
+	aliases := make(map[string]struct{}, len(v.outerAliases)+len(v.bindings))
+	for alias := range v.outerAliases {
+		aliases[alias] = struct{}{}
+	}
+	for alias := range v.bindings {
+		aliases[alias] = struct{}{}
+	}
+	return aliases
+}
+
 func (v *selectTranslatorVisitor) generateSubqueryAlias(prefix string) string {
 	base := strings.TrimSpace(prefix)
 	if base == "" {
@@ -561,6 +971,9 @@ func (v *selectTranslatorVisitor) processJoin(join *ast.JoinExpr) ([]string, err
 			Message: "translator: invalid JOIN clause",
 		}
 	}
+	if fn, ok := join.Right.(*ast.TableFunction); ok && isUnnestFunction(fn) {
+		return v.processUnnestJoin(join, fn)
+	}
 	if join.Type != ast.JoinInner && join.Type != ast.JoinLeft {
 		return nil, &TranslationError{
 			Code:    http.StatusBadRequest,
@@ -568,20 +981,28 @@ func (v *selectTranslatorVisitor) processJoin(join *ast.JoinExpr) ([]string, err
 		}
 	}
 
-	switch left := join.Left.(type) {
-	case *ast.TableName:
-		if err := v.registerBaseTable(left); err != nil {
-			return nil, err
+	if vt, ok := join.Left.(*ast.ValuesTable); ok {
+		// INNER JOIN is symmetric, so a VALUES derived table on the left
+		// flips onto the log table: the right side becomes the base and the
+		// literal rows become its IN filter.
+		if join.Type != ast.JoinInner {
+			return nil, &TranslationError{
+				Code:    http.StatusBadRequest,
+				Message: "translator: VALUES tables support INNER JOIN only",
+			}
 		}
-	case *ast.SubqueryTable:
-		if err := v.registerBaseSubquery(left); err != nil {
+		if err := v.registerJoinLeft(join.Right); err != nil {
 			return nil, err
 		}
-	default:
-		return nil, &TranslationError{
-			Code:    http.StatusBadRequest,
-			Message: "translator: JOIN left side must be table reference",
-		}
+		return nil, v.processValuesJoin(join, vt)
+	}
+
+	if err := v.registerJoinLeft(join.Left); err != nil {
+		return nil, err
+	}
+
+	if vt, ok := join.Right.(*ast.ValuesTable); ok {
+		return nil, v.processValuesJoin(join, vt)
 	}
 
 	var rightAlias string
@@ -598,12 +1019,12 @@ func (v *selectTranslatorVisitor) processJoin(join *ast.JoinExpr) ([]string, err
 			}
 		}
 		name := rt.Name.Parts[len(rt.Name.Parts)-1]
-		nameLower := strings.ToLower(name)
+		nameLower := v.identKey(name)
 		alias := strings.TrimSpace(rt.Alias)
 		if alias == "" {
 			alias = name
 		}
-		rightAlias = strings.ToLower(alias)
+		rightAlias = v.identKey(alias)
 		if _, exists := v.bindings[rightAlias]; exists {
 			return nil, &TranslationError{
 				Code:    http.StatusBadRequest,
@@ -612,6 +1033,7 @@ func (v *selectTranslatorVisitor) processJoin(join *ast.JoinExpr) ([]string, err
 		}
 		if v.availableCTEs != nil {
 			if query, ok := v.availableCTEs[nameLower]; ok {
+				v.noteCTEUse(nameLower)
 				rightQuery = query
 				v.registerBinding(rightAlias, false)
 				v.registerBinding(nameLower, false)
@@ -619,6 +1041,18 @@ func (v *selectTranslatorVisitor) processJoin(join *ast.JoinExpr) ([]string, err
 				break
 			}
 		}
+		if viewQuery, display, found, err := v.sp.SessionViews().Load(rt.Name.Parts); err != nil {
+			return nil, err
+		} else if found {
+			if err := rejectParameterizedJoinView(viewQuery, display); err != nil {
+				return nil, err
+			}
+			rightQuery = viewQuery
+			v.registerBinding(rightAlias, false)
+			v.registerBinding(nameLower, false)
+			rightSimple = false
+			break
+		}
 		var viewAttempted bool
 		var viewDisplay string
 		if v.sp.ViewStore() != nil {
@@ -629,6 +1063,9 @@ func (v *selectTranslatorVisitor) processJoin(join *ast.JoinExpr) ([]string, err
 			viewAttempted = true
 			viewDisplay = display
 			if found {
+				if err := rejectParameterizedJoinView(viewQuery, display); err != nil {
+					return nil, err
+				}
 				rightQuery = viewQuery
 				v.registerBinding(rightAlias, false)
 				v.registerBinding(nameLower, false)
@@ -636,18 +1073,19 @@ func (v *selectTranslatorVisitor) processJoin(join *ast.JoinExpr) ([]string, err
 				break
 			}
 		}
-		spec, ok := v.lookupTableSpec(nameLower)
+		spec, ok := v.resolveTableSpec(rt.Name.Parts)
 		if !ok {
 			if viewAttempted {
 				return nil, &TranslationError{
 					Code:    http.StatusNotFound,
-					Message: fmt.Sprintf("translator: view %s not found", viewDisplay),
+					Message: fmt.Sprintf("translator: view %s not found%s", viewDisplay, didYouMean(viewDisplay, v.nameSuggestionCandidates())),
 				}
 			}
 			available := v.sp.TableStore().ListTables()
+			displayName := strings.Join(rt.Name.Parts, ".")
 			return nil, &TranslationError{
 				Code:    http.StatusNotFound,
-				Message: fmt.Sprintf("translator: JOIN table %q is not configured (available: %s)", strings.Join(rt.Name.Parts, "."), strings.Join(available, ", ")),
+				Message: fmt.Sprintf("translator: JOIN table %q is not configured%s (available: %s)", displayName, didYouMean(displayName, v.nameSuggestionCandidates()), strings.Join(available, ", ")),
 			}
 		}
 		if strings.TrimSpace(rt.Alias) == "" {
@@ -672,7 +1110,7 @@ func (v *selectTranslatorVisitor) processJoin(join *ast.JoinExpr) ([]string, err
 		if alias == "" {
 			alias = v.generateSubqueryAlias("join")
 		}
-		rightAlias = strings.ToLower(alias)
+		rightAlias = v.identKey(alias)
 		if _, exists := v.bindings[rightAlias]; exists {
 			return nil, &TranslationError{
 				Code:    http.StatusBadRequest,
@@ -681,8 +1119,12 @@ func (v *selectTranslatorVisitor) processJoin(join *ast.JoinExpr) ([]string, err
 		}
 		v.registerBinding(rightAlias, false)
 		subQuery, err := translateSelectStatementToLogsQLWithContext(rt.Select, translationContext{
-			sp:   v.sp,
-			ctes: v.availableCTEs,
+			sp:           v.sp,
+			ctes:         v.availableCTEs,
+			cteSelects:   v.cteSelects,
+			cteUses:      v.cteUses,
+			outerAliases: v.scopeAliases(),
+			warnings:     v.warnings,
 		})
 		if err != nil {
 			return nil, &TranslationError{
@@ -711,6 +1153,9 @@ func (v *selectTranslatorVisitor) processJoin(join *ast.JoinExpr) ([]string, err
 	}
 
 	v.pendingLeftFilter = append(v.pendingLeftFilter, leftFilters...)
+	if join.Type == ast.JoinInner {
+		rightFilters = append(rightFilters, v.takeRightAliasPredicates(rightAlias)...)
+	}
 
 	parts := make([]string, 0, len(rightBaseFilters)+len(rightFilters))
 	parts = append(parts, rightBaseFilters...)
@@ -748,32 +1193,492 @@ func (v *selectTranslatorVisitor) processJoin(join *ast.JoinExpr) ([]string, err
 	return []string{joinClause}, nil
 }
 
-func (v *selectTranslatorVisitor) extractJoinSpec(cond ast.JoinCondition, rightAlias string) ([]string, []ast.Expr, []ast.Expr, error) {
-	if cond.On == nil {
-		return nil, nil, nil, &TranslationError{
+// desugarPivot rewrites FROM ... PIVOT (agg FOR col IN (v AS a, ...)) into
+// conditional aggregates appended to the select list — the same shape as
+// agg(...) FILTER (WHERE col = v) — which the translator already maps to
+// LogsQL `stats ... if (filter)`.
+func desugarPivot(stmt *ast.SelectStatement, pivot *ast.PivotTable) (*ast.SelectStatement, error) {
+	if pivot.Agg == nil || pivot.For == nil || len(pivot.Values) == 0 {
+		return nil, &TranslationError{
 			Code:    http.StatusBadRequest,
-			Message: "translator: JOIN must include ON clause",
+			Message: "translator: PIVOT requires an aggregate, FOR column and IN values",
 		}
 	}
-
-	conjuncts := flattenAnd(cond.On)
-	joinKeys := make([]string, 0)
-	leftFilters := make([]ast.Expr, 0)
-	rightFilters := make([]ast.Expr, 0)
-
-	for _, expr := range conjuncts {
-		bin, ok := expr.(*ast.BinaryExpr)
-		if !ok {
-			return nil, nil, nil, &TranslationError{
-				Code:    http.StatusBadRequest,
-				Message: fmt.Sprintf("translator: unsupported JOIN condition %T", expr),
+	out := *stmt
+	out.From = pivot.Source
+	columns := make([]ast.SelectItem, 0, len(stmt.Columns)+len(pivot.Values))
+	columns = append(columns, stmt.Columns...)
+	for _, pv := range pivot.Values {
+		alias := pv.Alias
+		if alias == "" {
+			lit, ok := pv.Value.(*ast.StringLiteral)
+			if !ok {
+				return nil, &TranslationError{
+					Code:    http.StatusBadRequest,
+					Message: "translator: PIVOT value requires an alias, e.g. 'error' AS errors",
+				}
 			}
+			alias = lit.Value
 		}
+		agg := *pivot.Agg
+		agg.Filter = &ast.BinaryExpr{Left: pivot.For, Operator: "=", Right: pv.Value}
+		columns = append(columns, ast.SelectItem{Expr: &agg, Alias: alias})
+	}
+	out.Columns = columns
+	return &out, nil
+}
 
-		switch bin.Operator {
-		case "=":
-			leftIdent, leftIsIdent := bin.Left.(*ast.Identifier)
-			rightIdent, rightIsIdent := bin.Right.(*ast.Identifier)
+// registerJoinLeft registers the left side of a JOIN as the base table.
+func (v *selectTranslatorVisitor) registerJoinLeft(left ast.TableExpr) error {
+	switch l := left.(type) {
+	case *ast.TableName:
+		return v.registerBaseTable(l)
+	case *ast.SubqueryTable:
+		return v.registerBaseSubquery(l)
+	case *ast.TableFunction:
+		return v.registerBaseTableFunction(l)
+	default:
+		return &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: JOIN left side must be table reference",
+		}
+	}
+}
+
+func isUnnestFunction(fn *ast.TableFunction) bool {
+	return fn != nil && fn.Name != nil && len(fn.Name.Parts) > 0 &&
+		strings.EqualFold(fn.Name.Parts[len(fn.Name.Parts)-1], "UNNEST")
+}
+
+// processUnnestJoin translates CROSS JOIN UNNEST(field) AS t(elem) into the
+// LogsQL unroll pipe, so each array element of the field becomes its own row
+// and per-element aggregation works from SQL. WITH ORDINALITY is parsed but
+// rejected: unroll does not expose element indexes.
+func (v *selectTranslatorVisitor) processUnnestJoin(join *ast.JoinExpr, fn *ast.TableFunction) ([]string, error) {
+	if join.Type != ast.JoinCross && join.Type != ast.JoinInner {
+		return nil, &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: UNNEST requires CROSS JOIN",
+		}
+	}
+	if join.Condition.On != nil {
+		return nil, &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: UNNEST join cannot have an ON condition",
+		}
+	}
+	if err := v.registerJoinLeft(join.Left); err != nil {
+		return nil, err
+	}
+	if fn.Ordinality {
+		return nil, &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: WITH ORDINALITY is not supported: LogsQL unroll does not expose element indexes",
+		}
+	}
+	if len(fn.Args) != 1 {
+		return nil, &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: UNNEST expects a single field argument",
+		}
+	}
+	ident, ok := fn.Args[0].(*ast.Identifier)
+	if !ok {
+		return nil, &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: UNNEST expects a single field argument",
+		}
+	}
+	field, err := v.rawFieldName(ident)
+	if err != nil {
+		return nil, err
+	}
+	if len(fn.Columns) > 1 {
+		return nil, &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: UNNEST produces a single column",
+		}
+	}
+
+	if alias := strings.TrimSpace(fn.Alias); alias != "" {
+		aliasKey := v.identKey(alias)
+		if _, exists := v.bindings[aliasKey]; exists {
+			return nil, &TranslationError{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("translator: duplicate table alias %q", alias),
+			}
+		}
+		v.registerBinding(aliasKey, true)
+	}
+
+	pipes := []string{fmt.Sprintf("unroll by (%s)", formatFieldName(field))}
+	if len(fn.Columns) == 1 && v.identKey(fn.Columns[0]) != v.identKey(field) {
+		pipes = append(pipes, fmt.Sprintf("rename %s as %s", formatFieldName(field), formatFieldName(fn.Columns[0])))
+	}
+	return pipes, nil
+}
+
+// processValuesJoin translates JOIN (VALUES ...) AS t(col) against the base
+// table into an IN filter on the base-side join key. The literal rows never
+// reach VictoriaLogs as a join pipe, so only INNER JOIN with a single
+// equality condition is supported.
+func (v *selectTranslatorVisitor) processValuesJoin(join *ast.JoinExpr, vt *ast.ValuesTable) error {
+	if join.Type != ast.JoinInner {
+		return &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: VALUES tables support INNER JOIN only",
+		}
+	}
+	alias := strings.TrimSpace(vt.Alias)
+	if alias == "" {
+		return &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: VALUES table requires an alias with a column list, e.g. AS t(service)",
+		}
+	}
+	if len(vt.Columns) == 0 {
+		return &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: VALUES table requires a column list, e.g. AS t(service)",
+		}
+	}
+	if len(vt.Rows) == 0 {
+		return &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: VALUES table cannot be empty",
+		}
+	}
+	for _, row := range vt.Rows {
+		if len(row) != len(vt.Columns) {
+			return &TranslationError{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("translator: VALUES row has %d values, expected %d", len(row), len(vt.Columns)),
+			}
+		}
+	}
+	aliasKey := v.identKey(alias)
+	if _, exists := v.bindings[aliasKey]; exists {
+		return &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("translator: duplicate table alias %q", alias),
+		}
+	}
+
+	cond, ok := join.Condition.On.(*ast.BinaryExpr)
+	if !ok || cond.Operator != "=" {
+		return &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: VALUES JOIN requires a single equality ON condition",
+		}
+	}
+	valuesSide, baseSide := cond.Left, cond.Right
+	if !v.identifierBelongsTo(valuesSide, aliasKey) {
+		valuesSide, baseSide = baseSide, valuesSide
+	}
+	valuesIdent, ok := valuesSide.(*ast.Identifier)
+	if !ok || !v.identifierBelongsTo(valuesSide, aliasKey) {
+		return &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("translator: VALUES JOIN condition must compare a %s column against the base table", alias),
+		}
+	}
+	columnKey := v.identKey(valuesIdent.Parts[len(valuesIdent.Parts)-1])
+	columnIdx := -1
+	for i, col := range vt.Columns {
+		if v.identKey(col) == columnKey {
+			columnIdx = i
+			break
+		}
+	}
+	if columnIdx < 0 {
+		return &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("translator: VALUES table %s has no column %q", alias, valuesIdent.Parts[len(valuesIdent.Parts)-1]),
+		}
+	}
+
+	items := make([]ast.Expr, 0, len(vt.Rows))
+	for _, row := range vt.Rows {
+		if _, err := literalFromExpr(row[columnIdx]); err != nil {
+			return &TranslationError{
+				Code:    http.StatusBadRequest,
+				Message: "translator: VALUES rows must contain literals",
+				Err:     err,
+			}
+		}
+		items = append(items, row[columnIdx])
+	}
+
+	v.registerBinding(aliasKey, true)
+	v.pendingLeftFilter = append(v.pendingLeftFilter, &ast.InExpr{Expr: baseSide, List: items})
+	return nil
+}
+
+// identifierBelongsTo reports whether expr is an identifier qualified by the
+// given binding key.
+func (v *selectTranslatorVisitor) identifierBelongsTo(expr ast.Expr, key string) bool {
+	id, ok := expr.(*ast.Identifier)
+	if !ok || len(id.Parts) < 2 {
+		return false
+	}
+	return v.identKey(id.Parts[0]) == key
+}
+
+// takeRightAliasPredicates removes WHERE conjuncts that reference only the
+// joined alias and returns them for pushdown into the right query. Only
+// INNER JOIN callers may use this: filtering the right side of a LEFT JOIN
+// would drop unmatched left rows.
+func (v *selectTranslatorVisitor) takeRightAliasPredicates(rightAlias string) []ast.Expr {
+	if v.pendingWhere == nil || rightAlias == "" {
+		return nil
+	}
+	var pushed, remaining []ast.Expr
+	for _, expr := range flattenAnd(v.pendingWhere) {
+		aliases := v.aliasesForExprWithDefault(expr, "")
+		if len(aliases) == 1 {
+			if _, ok := aliases[rightAlias]; ok {
+				pushed = append(pushed, expr)
+				continue
+			}
+		}
+		remaining = append(remaining, expr)
+	}
+	v.pendingWhere = combineAnd(remaining)
+	return pushed
+}
+
+// combineAnd rebuilds an AND chain from conjuncts produced by flattenAnd.
+func combineAnd(exprs []ast.Expr) ast.Expr {
+	var combined ast.Expr
+	for _, expr := range exprs {
+		if combined == nil {
+			combined = expr
+			continue
+		}
+		combined = &ast.BinaryExpr{Left: combined, Operator: "AND", Right: expr}
+	}
+	return combined
+}
+
+// pushdownPredicates merges WHERE conjuncts that only touch plain
+// passthrough fields of sub into the subquery's own WHERE clause, so the
+// filter runs before expensive stats/join pipes and reduces scanned data.
+// Conjuncts referencing aggregates, computed columns or other aliases stay
+// behind. The accepted qualifiers name the derived table in the outer query.
+// sub is never mutated because CTE bodies are shared between references.
+func (v *selectTranslatorVisitor) pushdownPredicates(sub *ast.SelectStatement, qualifiers ...string) (*ast.SelectStatement, bool) {
+	if v.pendingWhere == nil || !pushdownSafeTarget(sub) {
+		return sub, false
+	}
+	allowed := make(map[string]struct{}, len(qualifiers))
+	for _, q := range qualifiers {
+		if q != "" {
+			allowed[v.identKey(q)] = struct{}{}
+		}
+	}
+	fields, hasStar := v.pushdownFields(sub)
+	if len(fields) == 0 && !hasStar {
+		return sub, false
+	}
+
+	var pushed, remaining []ast.Expr
+	for _, expr := range flattenAnd(v.pendingWhere) {
+		stripped, ok := v.stripPushdownQualifier(expr, allowed, fields, hasStar)
+		if !ok {
+			remaining = append(remaining, expr)
+			continue
+		}
+		pushed = append(pushed, stripped)
+	}
+	if len(pushed) == 0 {
+		return sub, false
+	}
+	v.pendingWhere = combineAnd(remaining)
+
+	rewritten := *sub
+	rewritten.Where = combineAnd(append(flattenAnd(sub.Where), pushed...))
+	return &rewritten, true
+}
+
+// pushdownSafeTarget reports whether filters may be merged into sub without
+// changing its semantics.
+func pushdownSafeTarget(sub *ast.SelectStatement) bool {
+	if sub == nil || sub.Limit != nil || len(sub.SetOps) > 0 {
+		return false
+	}
+	for _, col := range sub.Columns {
+		if fn, ok := col.Expr.(*ast.FuncCall); ok && fn.Over != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// pushdownFields collects the output names of sub that pass through a source
+// field unchanged. hasStar reports an unqualified star projection, which
+// exposes every source field. Renamed columns are excluded since the pushed
+// filter would have to be rewritten to the source name.
+func (v *selectTranslatorVisitor) pushdownFields(sub *ast.SelectStatement) (map[string]struct{}, bool) {
+	fields := make(map[string]struct{})
+	hasStar := false
+	grouped := len(sub.GroupBy) > 0
+	groupKeys := make(map[string]struct{}, len(sub.GroupBy))
+	for _, g := range sub.GroupBy {
+		if id, ok := g.(*ast.Identifier); ok && len(id.Parts) > 0 {
+			groupKeys[v.identKey(id.Parts[len(id.Parts)-1])] = struct{}{}
+		}
+	}
+	for _, col := range sub.Columns {
+		switch e := col.Expr.(type) {
+		case *ast.StarExpr:
+			if e.Table == nil && !grouped {
+				hasStar = true
+			}
+		case *ast.Identifier:
+			if len(e.Parts) == 0 {
+				continue
+			}
+			name := e.Parts[len(e.Parts)-1]
+			alias := strings.TrimSpace(col.Alias)
+			if alias != "" && v.identKey(alias) != v.identKey(name) {
+				continue
+			}
+			lower := v.identKey(name)
+			if grouped {
+				if _, ok := groupKeys[lower]; !ok {
+					continue
+				}
+			}
+			fields[lower] = struct{}{}
+		}
+	}
+	return fields, hasStar
+}
+
+// stripPushdownQualifier rewrites expr for evaluation inside the derived
+// query by removing the outer table qualifier from identifiers. It reports
+// false when expr contains nodes that are unsafe to push down (functions,
+// subqueries) or identifiers that are not plain passthrough fields.
+func (v *selectTranslatorVisitor) stripPushdownQualifier(expr ast.Expr, qualifiers, fields map[string]struct{}, hasStar bool) (ast.Expr, bool) {
+	switch e := expr.(type) {
+	case *ast.Identifier:
+		parts := e.Parts
+		if len(parts) > 1 {
+			if _, ok := qualifiers[v.identKey(parts[0])]; !ok {
+				return nil, false
+			}
+			parts = parts[1:]
+		}
+		if len(parts) != 1 {
+			return nil, false
+		}
+		lower := v.identKey(parts[0])
+		// Outer select-list aliases only exist after the projection.
+		if _, isAlias := v.pendingSelectAliases[lower]; isAlias {
+			return nil, false
+		}
+		if !hasStar {
+			if _, ok := fields[lower]; !ok {
+				return nil, false
+			}
+		}
+		return &ast.Identifier{Parts: []string{parts[0]}}, true
+	case *ast.NumericLiteral, *ast.StringLiteral, *ast.BooleanLiteral, *ast.NullLiteral:
+		return expr, true
+	case *ast.BinaryExpr:
+		left, ok := v.stripPushdownQualifier(e.Left, qualifiers, fields, hasStar)
+		if !ok {
+			return nil, false
+		}
+		right, ok := v.stripPushdownQualifier(e.Right, qualifiers, fields, hasStar)
+		if !ok {
+			return nil, false
+		}
+		return &ast.BinaryExpr{Left: left, Operator: e.Operator, Right: right}, true
+	case *ast.UnaryExpr:
+		inner, ok := v.stripPushdownQualifier(e.Expr, qualifiers, fields, hasStar)
+		if !ok {
+			return nil, false
+		}
+		return &ast.UnaryExpr{Operator: e.Operator, Expr: inner}, true
+	case *ast.BetweenExpr:
+		inner, ok := v.stripPushdownQualifier(e.Expr, qualifiers, fields, hasStar)
+		if !ok {
+			return nil, false
+		}
+		lower, ok := v.stripPushdownQualifier(e.Lower, qualifiers, fields, hasStar)
+		if !ok {
+			return nil, false
+		}
+		upper, ok := v.stripPushdownQualifier(e.Upper, qualifiers, fields, hasStar)
+		if !ok {
+			return nil, false
+		}
+		return &ast.BetweenExpr{Expr: inner, Lower: lower, Upper: upper, Not: e.Not}, true
+	case *ast.InExpr:
+		if e.Subquery != nil {
+			return nil, false
+		}
+		inner, ok := v.stripPushdownQualifier(e.Expr, qualifiers, fields, hasStar)
+		if !ok {
+			return nil, false
+		}
+		list := make([]ast.Expr, 0, len(e.List))
+		for _, item := range e.List {
+			stripped, ok := v.stripPushdownQualifier(item, qualifiers, fields, hasStar)
+			if !ok {
+				return nil, false
+			}
+			list = append(list, stripped)
+		}
+		return &ast.InExpr{Expr: inner, Not: e.Not, List: list}, true
+	case *ast.LikeExpr:
+		inner, ok := v.stripPushdownQualifier(e.Expr, qualifiers, fields, hasStar)
+		if !ok {
+			return nil, false
+		}
+		pattern, ok := v.stripPushdownQualifier(e.Pattern, qualifiers, fields, hasStar)
+		if !ok {
+			return nil, false
+		}
+		return &ast.LikeExpr{Expr: inner, Not: e.Not, CaseInsensitive: e.CaseInsensitive, Pattern: pattern}, true
+	case *ast.IsNullExpr:
+		inner, ok := v.stripPushdownQualifier(e.Expr, qualifiers, fields, hasStar)
+		if !ok {
+			return nil, false
+		}
+		return &ast.IsNullExpr{Expr: inner, Not: e.Not}, true
+	default:
+		return nil, false
+	}
+}
+
+func (v *selectTranslatorVisitor) extractJoinSpec(cond ast.JoinCondition, rightAlias string) ([]string, []ast.Expr, []ast.Expr, error) {
+	if cond.On == nil {
+		return nil, nil, nil, &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: JOIN must include ON clause",
+		}
+	}
+
+	conjuncts := flattenAnd(cond.On)
+	joinKeys := make([]string, 0)
+	leftFilters := make([]ast.Expr, 0)
+	rightFilters := make([]ast.Expr, 0)
+
+	for _, expr := range conjuncts {
+		bin, ok := expr.(*ast.BinaryExpr)
+		if !ok {
+			return nil, nil, nil, &TranslationError{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("translator: unsupported JOIN condition %T", expr),
+			}
+		}
+
+		switch bin.Operator {
+		case "=":
+			leftIdent, leftIsIdent := bin.Left.(*ast.Identifier)
+			rightIdent, rightIsIdent := bin.Right.(*ast.Identifier)
 
 			switch {
 			case leftIsIdent && rightIsIdent:
@@ -854,6 +1759,19 @@ func (v *selectTranslatorVisitor) extractJoinSpec(cond ast.JoinCondition, rightA
 	return joinKeys, leftFilters, rightFilters, nil
 }
 
+func flattenOr(expr ast.Expr) []ast.Expr {
+	if expr == nil {
+		return nil
+	}
+	bin, ok := expr.(*ast.BinaryExpr)
+	if ok && bin.Operator == "OR" {
+		left := flattenOr(bin.Left)
+		right := flattenOr(bin.Right)
+		return append(left, right...)
+	}
+	return []ast.Expr{expr}
+}
+
 func flattenAnd(expr ast.Expr) []ast.Expr {
 	if expr == nil {
 		return nil
@@ -871,7 +1789,7 @@ func (v *selectTranslatorVisitor) qualifierForIdentifierWithDefault(ident *ast.I
 	if ident == nil || len(ident.Parts) == 0 {
 		return fallback
 	}
-	first := strings.ToLower(ident.Parts[0])
+	first := v.identKey(ident.Parts[0])
 	if _, ok := v.bindings[first]; ok {
 		return first
 	}
@@ -1009,7 +1927,7 @@ func (v *selectTranslatorVisitor) buildStatsPipe(stmt *ast.SelectStatement) ([]s
 	for _, col := range stmt.Columns {
 		switch expr := col.Expr.(type) {
 		case *ast.StarExpr:
-			if len(stmt.Columns) > 1 {
+			if len(stmt.Columns) > 1 && expr.Table == nil {
 				return nil, false, &TranslationError{
 					Code:    http.StatusBadRequest,
 					Message: "translator: SELECT * cannot be mixed with other columns",
@@ -1021,6 +1939,10 @@ func (v *selectTranslatorVisitor) buildStatsPipe(stmt *ast.SelectStatement) ([]s
 					Message: "translator: SELECT * not supported with GROUP BY",
 				}
 			}
+			if expr.Table != nil {
+				// Qualified stars are validated in buildProjectionPipes.
+				continue
+			}
 			return nil, false, nil
 		case *ast.Identifier:
 			if !hasGroup {
@@ -1126,6 +2048,7 @@ func (v *selectTranslatorVisitor) buildStatsPipe(stmt *ast.SelectStatement) ([]s
 	for _, agg := range aggregates {
 		funcs = append(funcs, agg.statsCall)
 		aggResults[agg.key] = agg.resultName
+		preGroupPipes = append(preGroupPipes, agg.prePipes...)
 	}
 	builder.WriteString(" ")
 	builder.WriteString(strings.Join(funcs, ", "))
@@ -1135,6 +2058,28 @@ func (v *selectTranslatorVisitor) buildStatsPipe(stmt *ast.SelectStatement) ([]s
 	return pipes, true, nil
 }
 
+// autoAlias names a generated helper field. Aliases are numbered in
+// translation order by default; with SET deterministic_aliases = true the
+// name is derived from the rendered expression instead, so semantically
+// identical queries produce identical LogsQL regardless of clause order.
+func (v *selectTranslatorVisitor) autoAlias(prefix, key string, ordinal int) string {
+	if key != "" && v.sp.SessionOptions().DeterministicAliases() {
+		h := fnv.New32a()
+		h.Write([]byte(key))
+		return fmt.Sprintf("%s_%08x", prefix, h.Sum32())
+	}
+	return fmt.Sprintf("%s_%d", prefix, ordinal)
+}
+
+// groupAlias names the helper field for a computed GROUP BY expression.
+func (v *selectTranslatorVisitor) groupAlias(expr ast.Expr, index int) string {
+	key, err := render.Render(expr)
+	if err != nil {
+		key = ""
+	}
+	return v.autoAlias("group", key, index+1)
+}
+
 func (v *selectTranslatorVisitor) prepareGroupByField(expr ast.Expr, index int) (string, []string, error) {
 	switch e := expr.(type) {
 	case *ast.Identifier:
@@ -1150,7 +2095,7 @@ func (v *selectTranslatorVisitor) prepareGroupByField(expr ast.Expr, index int)
 				Message: "translator: aggregate functions are not allowed in GROUP BY",
 			}
 		}
-		alias := fmt.Sprintf("group_%d", index+1)
+		alias := v.groupAlias(expr, index)
 		if pipes, aliasName, supported, err := v.translateStringFunction(e, alias); supported {
 			if err != nil {
 				return "", nil, err
@@ -1163,7 +2108,7 @@ func (v *selectTranslatorVisitor) prepareGroupByField(expr ast.Expr, index int)
 		}
 		return aliasName, []string{mathPipe}, nil
 	case *ast.BinaryExpr, *ast.UnaryExpr, *ast.NumericLiteral:
-		alias := fmt.Sprintf("group_%d", index+1)
+		alias := v.groupAlias(expr, index)
 		mathPipe, aliasName, err := v.translateMathProjection(expr, alias)
 		if err != nil {
 			return "", nil, err
@@ -1190,18 +2135,18 @@ func (v *selectTranslatorVisitor) collectGroupAliases(columns []ast.SelectItem)
 		if _, ok := col.Expr.(*ast.StarExpr); ok {
 			continue
 		}
-		lower := strings.ToLower(alias)
+		lower := v.identKey(alias)
 		if _, exists := aliases[lower]; !exists {
 			aliases[lower] = col.Expr
 		}
 		formatted := formatFieldName(alias)
-		formattedLower := strings.ToLower(formatted)
+		formattedLower := v.identKey(formatted)
 		if _, exists := aliases[formattedLower]; !exists {
 			aliases[formattedLower] = col.Expr
 		}
 		if strings.HasPrefix(formatted, "\"") && strings.HasSuffix(formatted, "\"") && len(formatted) >= 2 {
 			unquoted := formatted[1 : len(formatted)-1]
-			unquotedLower := strings.ToLower(unquoted)
+			unquotedLower := v.identKey(unquoted)
 			if _, exists := aliases[unquotedLower]; !exists {
 				aliases[unquotedLower] = col.Expr
 			}
@@ -1213,20 +2158,111 @@ func (v *selectTranslatorVisitor) collectGroupAliases(columns []ast.SelectItem)
 	return aliases
 }
 
-func (v *selectTranslatorVisitor) resolveGroupByAlias(expr ast.Expr, aliases map[string]ast.Expr) ast.Expr {
-	if len(aliases) == 0 {
-		return expr
-	}
-	ident, ok := expr.(*ast.Identifier)
-	if !ok {
-		return expr
+// extractAliasPredicates splits the top-level AND conjuncts of expr into the
+// ones that reference select-list aliases and the rest. The remaining
+// expression may be nil when every conjunct referenced an alias. Alias
+// conjuncts must not mix aliases with other columns, since they are evaluated
+// after the projection where only the output fields exist.
+func (v *selectTranslatorVisitor) extractAliasPredicates(expr ast.Expr, aliases map[string]ast.Expr) (ast.Expr, []ast.Expr, error) {
+	if bin, ok := expr.(*ast.BinaryExpr); ok && strings.EqualFold(bin.Operator, "AND") {
+		left, leftPost, err := v.extractAliasPredicates(bin.Left, aliases)
+		if err != nil {
+			return nil, nil, err
+		}
+		right, rightPost, err := v.extractAliasPredicates(bin.Right, aliases)
+		if err != nil {
+			return nil, nil, err
+		}
+		post := append(leftPost, rightPost...)
+		switch {
+		case left == nil:
+			return right, post, nil
+		case right == nil:
+			return left, post, nil
+		default:
+			return &ast.BinaryExpr{Left: left, Operator: bin.Operator, Right: right}, post, nil
+		}
 	}
-	if len(ident.Parts) != 1 {
-		return expr
+	var aliasRefs, otherRefs int
+	v.classifyAliasReferences(expr, aliases, &aliasRefs, &otherRefs)
+	if aliasRefs == 0 {
+		return expr, nil, nil
 	}
-	key := strings.ToLower(ident.Parts[0])
-	if replacement, ok := aliases[key]; ok {
-		return replacement
+	if otherRefs > 0 {
+		return nil, nil, &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: WHERE condition mixing a select alias with other columns is not supported",
+		}
+	}
+	return nil, []ast.Expr{expr}, nil
+}
+
+// classifyAliasReferences counts identifiers in expr that resolve to
+// select-list aliases versus everything else. Subqueries count as other
+// references because they cannot run after the projection.
+func (v *selectTranslatorVisitor) classifyAliasReferences(expr ast.Expr, aliases map[string]ast.Expr, aliasRefs, otherRefs *int) {
+	switch e := expr.(type) {
+	case nil:
+	case *ast.Identifier:
+		if len(e.Parts) == 1 {
+			if _, ok := aliases[v.identKey(e.Parts[0])]; ok {
+				*aliasRefs++
+				return
+			}
+		}
+		*otherRefs++
+	case *ast.BinaryExpr:
+		v.classifyAliasReferences(e.Left, aliases, aliasRefs, otherRefs)
+		v.classifyAliasReferences(e.Right, aliases, aliasRefs, otherRefs)
+	case *ast.UnaryExpr:
+		v.classifyAliasReferences(e.Expr, aliases, aliasRefs, otherRefs)
+	case *ast.FuncCall:
+		for _, arg := range e.Args {
+			v.classifyAliasReferences(arg, aliases, aliasRefs, otherRefs)
+		}
+	case *ast.CaseExpr:
+		v.classifyAliasReferences(e.Operand, aliases, aliasRefs, otherRefs)
+		for _, when := range e.When {
+			v.classifyAliasReferences(when.Condition, aliases, aliasRefs, otherRefs)
+			v.classifyAliasReferences(when.Result, aliases, aliasRefs, otherRefs)
+		}
+		v.classifyAliasReferences(e.Else, aliases, aliasRefs, otherRefs)
+	case *ast.BetweenExpr:
+		v.classifyAliasReferences(e.Expr, aliases, aliasRefs, otherRefs)
+		v.classifyAliasReferences(e.Lower, aliases, aliasRefs, otherRefs)
+		v.classifyAliasReferences(e.Upper, aliases, aliasRefs, otherRefs)
+	case *ast.InExpr:
+		v.classifyAliasReferences(e.Expr, aliases, aliasRefs, otherRefs)
+		for _, item := range e.List {
+			v.classifyAliasReferences(item, aliases, aliasRefs, otherRefs)
+		}
+		if e.Subquery != nil {
+			*otherRefs++
+		}
+	case *ast.LikeExpr:
+		v.classifyAliasReferences(e.Expr, aliases, aliasRefs, otherRefs)
+		v.classifyAliasReferences(e.Pattern, aliases, aliasRefs, otherRefs)
+	case *ast.IsNullExpr:
+		v.classifyAliasReferences(e.Expr, aliases, aliasRefs, otherRefs)
+	case *ast.ExistsExpr, *ast.SubqueryExpr:
+		*otherRefs++
+	}
+}
+
+func (v *selectTranslatorVisitor) resolveGroupByAlias(expr ast.Expr, aliases map[string]ast.Expr) ast.Expr {
+	if len(aliases) == 0 {
+		return expr
+	}
+	ident, ok := expr.(*ast.Identifier)
+	if !ok {
+		return expr
+	}
+	if len(ident.Parts) != 1 {
+		return expr
+	}
+	key := v.identKey(ident.Parts[0])
+	if replacement, ok := aliases[key]; ok {
+		return replacement
 	}
 	return expr
 }
@@ -1251,6 +2287,10 @@ type aggItem struct {
 	key        string
 	statsCall  string
 	resultName string
+	// prePipes compute helper fields (e.g. math expressions inside the
+	// aggregate) and must run before the stats pipe. The stats pipe drops
+	// the helper fields, so no cleanup is needed afterwards.
+	prePipes []string
 }
 
 func (v *selectTranslatorVisitor) analyzeAggregate(fn *ast.FuncCall, alias string) (aggItem, error) {
@@ -1260,22 +2300,26 @@ func (v *selectTranslatorVisitor) analyzeAggregate(fn *ast.FuncCall, alias strin
 			Message: "translator: invalid aggregate",
 		}
 	}
-	if fn.Distinct {
+	if len(fn.Name.Parts) == 0 {
 		return aggItem{}, &TranslationError{
 			Code:    http.StatusBadRequest,
-			Message: "translator: DISTINCT aggregates are not supported",
+			Message: "translator: invalid aggregate function",
 		}
 	}
-
-	if len(fn.Name.Parts) == 0 {
+	name := strings.ToUpper(fn.Name.Parts[len(fn.Name.Parts)-1])
+	if fn.Distinct && !isValueListAggregate(name) {
 		return aggItem{}, &TranslationError{
 			Code:    http.StatusBadRequest,
-			Message: "translator: invalid aggregate function",
+			Message: "translator: DISTINCT aggregates are not supported",
 		}
 	}
-	name := strings.ToUpper(fn.Name.Parts[len(fn.Name.Parts)-1])
 
 	var arg string
+	var keyArg string
+	var cond string
+	var limitSuffix string
+	var prePipes []string
+	statName := name
 	switch name {
 	case "COUNT":
 		if len(fn.Args) == 0 {
@@ -1302,6 +2346,77 @@ func (v *selectTranslatorVisitor) analyzeAggregate(fn *ast.FuncCall, alias strin
 			}
 		}
 	case "SUM", "AVG", "MIN", "MAX":
+		if len(fn.Args) != 1 {
+			return aggItem{}, &TranslationError{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("translator: %s expects single argument", strings.ToLower(name)),
+			}
+		}
+		if ident, ok := fn.Args[0].(*ast.Identifier); ok {
+			field, err := v.normalizeIdentifier(ident)
+			if err != nil {
+				return aggItem{}, err
+			}
+			arg = field
+			break
+		}
+		if caseExpr, ok := fn.Args[0].(*ast.CaseExpr); ok {
+			if name != "SUM" {
+				return aggItem{}, &TranslationError{
+					Code:    http.StatusBadRequest,
+					Message: fmt.Sprintf("translator: CASE aggregation is only supported inside SUM, not %s", strings.ToLower(name)),
+				}
+			}
+			if fn.Filter != nil {
+				return aggItem{}, &TranslationError{
+					Code:    http.StatusBadRequest,
+					Message: "translator: CASE aggregation cannot be combined with a FILTER clause",
+				}
+			}
+			caseCond, caseField, casePipes, err := v.conditionalSumParts(caseExpr)
+			if err != nil {
+				return aggItem{}, err
+			}
+			cond = caseCond
+			prePipes = casePipes
+			if caseField == "" {
+				// SUM(CASE WHEN cond THEN 1 ELSE 0 END) is the portable
+				// spelling of a conditional count.
+				statName = "COUNT"
+				arg = "*"
+			} else {
+				arg = caseField
+			}
+			rendered, renderErr := render.Render(caseExpr)
+			if renderErr != nil {
+				return aggItem{}, &TranslationError{
+					Code:    http.StatusBadRequest,
+					Message: fmt.Sprintf("translator: failed to normalize CASE expression: %s", renderErr),
+					Err:     renderErr,
+				}
+			}
+			keyArg = rendered
+			break
+		}
+		// Math expressions inside the aggregate are computed into a helper
+		// field first, e.g. SUM(bytes/1024) becomes
+		// "math (bytes / 1024) as __agg_expr_1 | stats sum(__agg_expr_1)".
+		if !isArithmeticExpr(fn.Args[0]) {
+			return aggItem{}, &TranslationError{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("translator: %s only supports identifiers and math expressions", strings.ToLower(name)),
+			}
+		}
+		mathExpr, err := v.mathExprToString(fn.Args[0])
+		if err != nil {
+			return aggItem{}, err
+		}
+		v.aggExprCounter++
+		tmpField := fmt.Sprintf("__agg_expr_%d", v.aggExprCounter)
+		prePipes = append(prePipes, fmt.Sprintf("math %s as %s", mathExpr, tmpField))
+		arg = tmpField
+		keyArg = mathExpr
+	case "FIRST", "FIRST_VALUE", "LAST", "LAST_VALUE", "ANY_VALUE":
 		if len(fn.Args) != 1 {
 			return aggItem{}, &TranslationError{
 				Code:    http.StatusBadRequest,
@@ -1320,22 +2435,255 @@ func (v *selectTranslatorVisitor) analyzeAggregate(fn *ast.FuncCall, alias strin
 			return aggItem{}, err
 		}
 		arg = field
+		// LogsQL has no positional first/last aggregate: min/max pick the
+		// smallest/largest value (string-aware), and row_any returns an
+		// arbitrary representative per group.
+		switch name {
+		case "FIRST", "FIRST_VALUE":
+			statName = "MIN"
+		case "LAST", "LAST_VALUE":
+			statName = "MAX"
+		default:
+			statName = "ROW_ANY"
+		}
+		if err := v.strictViolation("%s(%s) has no positional equivalent in LogsQL and is translated to %s over the group", strings.ToLower(name), field, strings.ToLower(statName)); err != nil {
+			return aggItem{}, err
+		}
+	case "ARRAY_AGG", "GROUP_CONCAT", "STRING_AGG":
+		field, err := v.valueListAggregateField(name, fn)
+		if err != nil {
+			return aggItem{}, err
+		}
+		arg = field
+		// The result is a JSON array per group; valueListAggregateField
+		// warns about a STRING_AGG delimiter, which cannot be applied.
+		statName = "VALUES"
+		if fn.Distinct {
+			statName = "UNIQ_VALUES"
+			keyArg = "DISTINCT " + field
+		}
+	case "APPROX_COUNT_DISTINCT":
+		field, limit, err := v.approxCountDistinctArgs(fn)
+		if err != nil {
+			return aggItem{}, err
+		}
+		arg = field
+		keyArg = field
+		statName = "COUNT_UNIQ"
+		limitSuffix = limit
+		if err := v.strictViolation("approx_count_distinct(%s) is translated to count_uniq, which returns an approximate result once the limit is exceeded", field); err != nil {
+			return aggItem{}, err
+		}
 	default:
-		return aggItem{}, &TranslationError{
-			Code:    http.StatusBadRequest,
-			Message: fmt.Sprintf("translator: unsupported aggregate %s", name),
+		statsFunc, registered := lookupAggregateFunction(name)
+		if !registered {
+			return aggItem{}, &TranslationError{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("translator: unsupported aggregate %s", name),
+			}
 		}
+		switch len(fn.Args) {
+		case 0:
+			arg = "*"
+		case 1:
+			if _, ok := fn.Args[0].(*ast.StarExpr); ok {
+				arg = "*"
+			} else if ident, ok := fn.Args[0].(*ast.Identifier); ok {
+				field, err := v.normalizeIdentifier(ident)
+				if err != nil {
+					return aggItem{}, err
+				}
+				arg = field
+			} else {
+				return aggItem{}, &TranslationError{
+					Code:    http.StatusBadRequest,
+					Message: fmt.Sprintf("translator: %s only supports identifiers or *", strings.ToLower(name)),
+				}
+			}
+		default:
+			return aggItem{}, &TranslationError{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("translator: %s expects at most one argument", strings.ToLower(name)),
+			}
+		}
+		statName = statsFunc
 	}
 
-	key := aggregateKey(name, arg)
-	fnCall := fmt.Sprintf("%s(%s)", strings.ToLower(name), formatAggregateArg(arg))
+	if fn.Filter != nil {
+		filterCond, err := v.translateExpr(fn.Filter)
+		if err != nil {
+			return aggItem{}, err
+		}
+		cond = filterCond
+	}
+	if keyArg == "" {
+		keyArg = arg
+	}
+	key := aggregateKey(name, keyArg)
+	if fn.Filter != nil {
+		rendered, err := render.Render(fn.Filter)
+		if err != nil {
+			return aggItem{}, &TranslationError{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("translator: failed to normalize FILTER condition: %s", err),
+				Err:     err,
+			}
+		}
+		key += " FILTER (" + rendered + ")"
+	}
+	fnCall := fmt.Sprintf("%s(%s)", strings.ToLower(statName), formatAggregateArg(arg))
+	if limitSuffix != "" {
+		fnCall += " limit " + limitSuffix
+	}
+	if cond != "" {
+		fnCall += " if (" + cond + ")"
+	}
 	alias = strings.TrimSpace(alias)
 	if alias == "" {
-		return aggItem{key: key, statsCall: fnCall, resultName: fnCall}, nil
+		return aggItem{key: key, statsCall: fnCall, resultName: fnCall, prePipes: prePipes}, nil
 	}
 	formattedAlias := formatFieldName(alias)
 	call := fmt.Sprintf("%s %s", fnCall, formattedAlias)
-	return aggItem{key: key, statsCall: call, resultName: formattedAlias}, nil
+	return aggItem{key: key, statsCall: call, resultName: formattedAlias, prePipes: prePipes}, nil
+}
+
+// isValueListAggregate reports whether name collects per-group value lists,
+// the only aggregates where DISTINCT is supported (via uniq_values).
+func isValueListAggregate(name string) bool {
+	switch name {
+	case "ARRAY_AGG", "GROUP_CONCAT", "STRING_AGG":
+		return true
+	default:
+		return false
+	}
+}
+
+// valueListAggregateField validates the arguments of ARRAY_AGG, GROUP_CONCAT
+// and STRING_AGG and returns the aggregated field. STRING_AGG takes a string
+// delimiter as second argument; the others take the field alone.
+func (v *selectTranslatorVisitor) valueListAggregateField(name string, fn *ast.FuncCall) (string, error) {
+	wantArgs := 1
+	if name == "STRING_AGG" {
+		wantArgs = 2
+	}
+	if len(fn.Args) != wantArgs {
+		return "", &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("translator: %s expects %d argument(s)", strings.ToLower(name), wantArgs),
+		}
+	}
+	ident, ok := fn.Args[0].(*ast.Identifier)
+	if !ok {
+		return "", &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("translator: %s only supports identifiers", strings.ToLower(name)),
+		}
+	}
+	if name == "STRING_AGG" {
+		lit, ok := fn.Args[1].(*ast.StringLiteral)
+		if !ok {
+			return "", &TranslationError{
+				Code:    http.StatusBadRequest,
+				Message: "translator: string_agg delimiter must be a string literal",
+			}
+		}
+		if err := v.strictViolation("string_agg delimiter %q cannot be applied in LogsQL; the group is returned as a JSON array instead", lit.Value); err != nil {
+			return "", err
+		}
+	}
+	return v.normalizeIdentifier(ident)
+}
+
+// approxCountDistinctArgs validates APPROX_COUNT_DISTINCT(field[, limit]) and
+// returns the field plus the optional count_uniq limit hint.
+func (v *selectTranslatorVisitor) approxCountDistinctArgs(fn *ast.FuncCall) (string, string, error) {
+	if len(fn.Args) < 1 || len(fn.Args) > 2 {
+		return "", "", &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: approx_count_distinct expects a field and an optional limit",
+		}
+	}
+	ident, ok := fn.Args[0].(*ast.Identifier)
+	if !ok {
+		return "", "", &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: approx_count_distinct only supports identifiers",
+		}
+	}
+	field, err := v.normalizeIdentifier(ident)
+	if err != nil {
+		return "", "", err
+	}
+	if len(fn.Args) == 1 {
+		return field, "", nil
+	}
+	lit, ok := fn.Args[1].(*ast.NumericLiteral)
+	if !ok {
+		return "", "", &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: approx_count_distinct limit must be a number",
+		}
+	}
+	limit, err := strconv.Atoi(lit.Value)
+	if err != nil || limit < 1 {
+		return "", "", &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("translator: invalid approx_count_distinct limit %s", lit.Value),
+		}
+	}
+	return field, lit.Value, nil
+}
+
+// conditionalSumParts decomposes CASE WHEN cond THEN value ELSE 0 END inside
+// SUM into the stats condition and the value to aggregate. An empty field
+// means the THEN branch was the literal 1, i.e. a conditional count.
+func (v *selectTranslatorVisitor) conditionalSumParts(caseExpr *ast.CaseExpr) (string, string, []string, error) {
+	if caseExpr.Operand != nil || len(caseExpr.When) != 1 {
+		return "", "", nil, &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: aggregated CASE must have a single WHEN clause and no operand",
+		}
+	}
+	if caseExpr.Else != nil {
+		lit, ok := caseExpr.Else.(*ast.NumericLiteral)
+		if !ok || lit.Value != "0" {
+			return "", "", nil, &TranslationError{
+				Code:    http.StatusBadRequest,
+				Message: "translator: aggregated CASE must end with ELSE 0 or omit ELSE",
+			}
+		}
+	}
+	cond, err := v.translateExpr(caseExpr.When[0].Condition)
+	if err != nil {
+		return "", "", nil, err
+	}
+	switch result := caseExpr.When[0].Result.(type) {
+	case *ast.NumericLiteral:
+		if result.Value == "1" {
+			return cond, "", nil, nil
+		}
+	case *ast.Identifier:
+		field, err := v.normalizeIdentifier(result)
+		if err != nil {
+			return "", "", nil, err
+		}
+		return cond, field, nil, nil
+	default:
+		if isArithmeticExpr(caseExpr.When[0].Result) {
+			mathExpr, err := v.mathExprToString(caseExpr.When[0].Result)
+			if err != nil {
+				return "", "", nil, err
+			}
+			v.aggExprCounter++
+			tmpField := fmt.Sprintf("__agg_expr_%d", v.aggExprCounter)
+			pipes := []string{fmt.Sprintf("math %s as %s", mathExpr, tmpField)}
+			return cond, tmpField, pipes, nil
+		}
+	}
+	return "", "", nil, &TranslationError{
+		Code:    http.StatusBadRequest,
+		Message: "translator: aggregated CASE THEN branch must be 1, a field or a math expression",
+	}
 }
 
 func isAggregateFunction(fn *ast.FuncCall) bool {
@@ -1343,8 +2691,17 @@ func isAggregateFunction(fn *ast.FuncCall) bool {
 		return false
 	}
 	name := strings.ToUpper(fn.Name.Parts[len(fn.Name.Parts)-1])
+	if isBuiltinAggregateName(name) {
+		return true
+	}
+	_, registered := lookupAggregateFunction(name)
+	return registered
+}
+
+func isBuiltinAggregateName(name string) bool {
 	switch name {
-	case "COUNT", "SUM", "AVG", "MIN", "MAX":
+	case "COUNT", "SUM", "AVG", "MIN", "MAX", "FIRST", "FIRST_VALUE", "LAST", "LAST_VALUE", "ANY_VALUE",
+		"ARRAY_AGG", "GROUP_CONCAT", "STRING_AGG", "APPROX_COUNT_DISTINCT":
 		return true
 	default:
 		return false
@@ -1422,6 +2779,27 @@ func (v *selectTranslatorVisitor) translateStringFunction(fn *ast.FuncCall, alia
 	case "SUBSTR", "SUBSTRING":
 		pipes, aliasName, err := v.translateSubstringFunction(fn, alias)
 		return pipes, aliasName, true, err
+	case "LENGTH", "CHAR_LENGTH":
+		pipes, aliasName, err := v.translateLengthFunction(fn, alias)
+		return pipes, aliasName, true, err
+	case "LEFT", "RIGHT":
+		pipes, aliasName, err := v.translateLeftRightFunction(name, fn, alias)
+		return pipes, aliasName, true, err
+	case "LPAD", "RPAD":
+		pipes, aliasName, err := v.translatePadFunction(name, fn, alias)
+		return pipes, aliasName, true, err
+	case "SPLIT_PART":
+		pipes, aliasName, err := v.translateSplitPartFunction(fn, alias)
+		return pipes, aliasName, true, err
+	case "REGEXP_EXTRACT":
+		pipes, aliasName, err := v.translateRegexpExtractFunction(fn, alias)
+		return pipes, aliasName, true, err
+	case "FROM_UNIXTIME", "TO_TIMESTAMP":
+		pipes, aliasName, err := v.translateFromUnixtimeFunction(name, fn, alias)
+		return pipes, aliasName, true, err
+	case "DATE_FORMAT":
+		pipes, aliasName, err := v.translateDateFormatFunction(fn, alias)
+		return pipes, aliasName, true, err
 	case "CONCAT":
 		pipes, aliasName, err := v.translateConcatFunction(fn, alias)
 		return pipes, aliasName, true, err
@@ -1438,7 +2816,7 @@ func (v *selectTranslatorVisitor) translateStringFunction(fn *ast.FuncCall, alia
 		pipes, aliasName, err := v.translateCurrentDate(alias)
 		return pipes, aliasName, true, err
 	default:
-		return nil, "", false, nil
+		return v.translateRegisteredFunction(name, fn, alias)
 	}
 }
 
@@ -1554,88 +2932,367 @@ func buildTrimPattern(kind, alias string) (string, error) {
 	}
 }
 
-func (v *selectTranslatorVisitor) translateSubstringFunction(fn *ast.FuncCall, alias string) ([]string, string, error) {
+func (v *selectTranslatorVisitor) translateSubstringFunction(fn *ast.FuncCall, alias string) ([]string, string, error) {
+	if len(fn.Args) < 2 || len(fn.Args) > 3 {
+		return nil, "", &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: substr expects two or three arguments",
+		}
+	}
+	ident, ok := fn.Args[0].(*ast.Identifier)
+	if !ok {
+		return nil, "", &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: substr only supports identifiers as first argument",
+		}
+	}
+	rawField, err := v.rawFieldName(ident)
+	if err != nil {
+		return nil, "", err
+	}
+	start, err := parseSubstringIntArg(fn.Args[1], "start")
+	if err != nil {
+		return nil, "", err
+	}
+	if start < 1 {
+		return nil, "", &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: substr start must be >= 1",
+		}
+	}
+	aliasName, err := makeProjectionAlias(strings.TrimSpace(alias), "substr", rawField)
+	if err != nil {
+		return nil, "", err
+	}
+	startIndex := start - 1
+	var pattern string
+	if len(fn.Args) == 3 {
+		length, err := parseSubstringIntArg(fn.Args[2], "length")
+		if err != nil {
+			return nil, "", err
+		}
+		if length < 0 {
+			return nil, "", &TranslationError{
+				Code:    http.StatusBadRequest,
+				Message: "translator: substr length must be >= 0",
+			}
+		}
+		pattern = fmt.Sprintf("(?s)^.{%d}(?P<%s>.{0,%d})", startIndex, aliasName, length)
+	} else {
+		pattern = fmt.Sprintf("(?s)^.{%d}(?P<%s>.*)$", startIndex, aliasName)
+	}
+	pipe := fmt.Sprintf("extract_regexp '%s' from %s", escapeSingleQuotes(pattern), rawField)
+	return []string{pipe}, aliasName, nil
+}
+
+func parseSubstringIntArg(expr ast.Expr, name string) (int, error) {
+	lit, ok := expr.(*ast.NumericLiteral)
+	if !ok {
+		return 0, &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("translator: substr %s must be integer literal", name),
+		}
+	}
+	clean := strings.ReplaceAll(strings.TrimSpace(lit.Value), "_", "")
+	if clean == "" {
+		return 0, &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("translator: substr %s must be integer literal", name),
+		}
+	}
+	if strings.ContainsAny(clean, ".eE") {
+		return 0, &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("translator: substr %s must be integer literal", name),
+		}
+	}
+	val, err := strconv.Atoi(clean)
+	if err != nil {
+		return 0, &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("translator: substr %s must be integer literal", name),
+		}
+	}
+	return val, nil
+}
+
+func (v *selectTranslatorVisitor) translateLengthFunction(fn *ast.FuncCall, alias string) ([]string, string, error) {
+	if len(fn.Args) != 1 {
+		return nil, "", &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: length expects single argument",
+		}
+	}
+	ident, ok := fn.Args[0].(*ast.Identifier)
+	if !ok {
+		return nil, "", &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: length only supports identifiers",
+		}
+	}
+	rawField, err := v.rawFieldName(ident)
+	if err != nil {
+		return nil, "", err
+	}
+	aliasName, err := makeProjectionAlias(strings.TrimSpace(alias), "len", rawField)
+	if err != nil {
+		return nil, "", err
+	}
+	pipe := fmt.Sprintf("len (%s) as %s", rawField, aliasName)
+	return []string{pipe}, aliasName, nil
+}
+
+func (v *selectTranslatorVisitor) translateLeftRightFunction(name string, fn *ast.FuncCall, alias string) ([]string, string, error) {
+	lower := strings.ToLower(name)
+	if len(fn.Args) != 2 {
+		return nil, "", &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("translator: %s expects two arguments", lower),
+		}
+	}
+	ident, ok := fn.Args[0].(*ast.Identifier)
+	if !ok {
+		return nil, "", &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("translator: %s only supports identifiers as first argument", lower),
+		}
+	}
+	rawField, err := v.rawFieldName(ident)
+	if err != nil {
+		return nil, "", err
+	}
+	length, err := parseSubstringIntArg(fn.Args[1], "length")
+	if err != nil {
+		return nil, "", err
+	}
+	if length < 0 {
+		return nil, "", &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("translator: %s length must be >= 0", lower),
+		}
+	}
+	aliasName, err := makeProjectionAlias(strings.TrimSpace(alias), lower, rawField)
+	if err != nil {
+		return nil, "", err
+	}
+	var pattern string
+	if name == "LEFT" {
+		pattern = fmt.Sprintf("(?s)^(?P<%s>.{0,%d})", aliasName, length)
+	} else {
+		pattern = fmt.Sprintf("(?s)(?P<%s>.{0,%d})$", aliasName, length)
+	}
+	pipe := fmt.Sprintf("extract_regexp '%s' from %s", escapeSingleQuotes(pattern), rawField)
+	return []string{pipe}, aliasName, nil
+}
+
+// translatePadFunction emits a format pipe that prepends (LPAD) or appends
+// (RPAD) the padding and an extract_regexp pipe that trims the result to the
+// requested width. Inputs already longer than the width are truncated from
+// the left for LPAD and from the right for RPAD.
+func (v *selectTranslatorVisitor) translatePadFunction(name string, fn *ast.FuncCall, alias string) ([]string, string, error) {
+	lower := strings.ToLower(name)
+	if len(fn.Args) < 2 || len(fn.Args) > 3 {
+		return nil, "", &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("translator: %s expects two or three arguments", lower),
+		}
+	}
+	ident, ok := fn.Args[0].(*ast.Identifier)
+	if !ok {
+		return nil, "", &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("translator: %s only supports identifiers as first argument", lower),
+		}
+	}
+	rawField, err := v.rawFieldName(ident)
+	if err != nil {
+		return nil, "", err
+	}
+	width, err := parseSubstringIntArg(fn.Args[1], "length")
+	if err != nil {
+		return nil, "", err
+	}
+	if width < 1 {
+		return nil, "", &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("translator: %s length must be >= 1", lower),
+		}
+	}
+	pad := " "
+	if len(fn.Args) == 3 {
+		lit, ok := fn.Args[2].(*ast.StringLiteral)
+		if !ok || lit.Value == "" {
+			return nil, "", &TranslationError{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("translator: %s pad must be a non-empty string literal", lower),
+			}
+		}
+		pad = lit.Value
+	}
+	aliasName, err := makeProjectionAlias(strings.TrimSpace(alias), lower, rawField)
+	if err != nil {
+		return nil, "", err
+	}
+	padding := strings.Repeat(pad, (width+len(pad)-1)/len(pad))
+	var formatPattern, extractPattern string
+	if name == "LPAD" {
+		formatPattern = padding + fmt.Sprintf("<%s>", rawField)
+		extractPattern = fmt.Sprintf("(?s)(?P<%s>.{0,%d})$", aliasName, width)
+	} else {
+		formatPattern = fmt.Sprintf("<%s>", rawField) + padding
+		extractPattern = fmt.Sprintf("(?s)^(?P<%s>.{0,%d})", aliasName, width)
+	}
+	padded := aliasName + "_padded"
+	pipes := []string{
+		fmt.Sprintf("format \"%s\" as %s", escapeFormatPattern(formatPattern), padded),
+		fmt.Sprintf("extract_regexp '%s' from %s", escapeSingleQuotes(extractPattern), padded),
+		fmt.Sprintf("delete %s", padded),
+	}
+	return pipes, aliasName, nil
+}
+
+func (v *selectTranslatorVisitor) translateSplitPartFunction(fn *ast.FuncCall, alias string) ([]string, string, error) {
+	if len(fn.Args) != 3 {
+		return nil, "", &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: split_part expects three arguments",
+		}
+	}
+	ident, ok := fn.Args[0].(*ast.Identifier)
+	if !ok {
+		return nil, "", &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: split_part only supports identifiers as first argument",
+		}
+	}
+	rawField, err := v.rawFieldName(ident)
+	if err != nil {
+		return nil, "", err
+	}
+	delimLit, ok := fn.Args[1].(*ast.StringLiteral)
+	if !ok || delimLit.Value == "" {
+		return nil, "", &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: split_part delimiter must be a non-empty string literal",
+		}
+	}
+	part, err := parseSubstringIntArg(fn.Args[2], "part")
+	if err != nil {
+		return nil, "", err
+	}
+	if part < 1 {
+		return nil, "", &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: split_part part must be >= 1",
+		}
+	}
+	aliasName, err := makeProjectionAlias(strings.TrimSpace(alias), "split", rawField)
+	if err != nil {
+		return nil, "", err
+	}
+	delim := regexp.QuoteMeta(delimLit.Value)
+	pattern := fmt.Sprintf("(?s)^(?:.*?%s){%d}(?P<%s>.*?)(?:%s|$)", delim, part-1, aliasName, delim)
+	pipe := fmt.Sprintf("extract_regexp '%s' from %s", escapeSingleQuotes(pattern), rawField)
+	return []string{pipe}, aliasName, nil
+}
+
+func (v *selectTranslatorVisitor) translateRegexpExtractFunction(fn *ast.FuncCall, alias string) ([]string, string, error) {
 	if len(fn.Args) < 2 || len(fn.Args) > 3 {
 		return nil, "", &TranslationError{
 			Code:    http.StatusBadRequest,
-			Message: "translator: substr expects two or three arguments",
+			Message: "translator: regexp_extract expects two or three arguments",
 		}
 	}
 	ident, ok := fn.Args[0].(*ast.Identifier)
 	if !ok {
 		return nil, "", &TranslationError{
 			Code:    http.StatusBadRequest,
-			Message: "translator: substr only supports identifiers as first argument",
+			Message: "translator: regexp_extract only supports identifiers as first argument",
 		}
 	}
 	rawField, err := v.rawFieldName(ident)
 	if err != nil {
 		return nil, "", err
 	}
-	start, err := parseSubstringIntArg(fn.Args[1], "start")
-	if err != nil {
-		return nil, "", err
-	}
-	if start < 1 {
+	patternLit, ok := fn.Args[1].(*ast.StringLiteral)
+	if !ok {
 		return nil, "", &TranslationError{
 			Code:    http.StatusBadRequest,
-			Message: "translator: substr start must be >= 1",
+			Message: "translator: regexp_extract pattern must be a string literal",
 		}
 	}
-	aliasName, err := makeProjectionAlias(strings.TrimSpace(alias), "substr", rawField)
-	if err != nil {
-		return nil, "", err
-	}
-	startIndex := start - 1
-	var pattern string
+	group := 1
 	if len(fn.Args) == 3 {
-		length, err := parseSubstringIntArg(fn.Args[2], "length")
+		group, err = parseSubstringIntArg(fn.Args[2], "group")
 		if err != nil {
 			return nil, "", err
 		}
-		if length < 0 {
+		if group < 1 {
 			return nil, "", &TranslationError{
 				Code:    http.StatusBadRequest,
-				Message: "translator: substr length must be >= 0",
+				Message: "translator: regexp_extract group must be >= 1",
 			}
 		}
-		pattern = fmt.Sprintf("(?s)^.{%d}(?P<%s>.{0,%d})", startIndex, aliasName, length)
-	} else {
-		pattern = fmt.Sprintf("(?s)^.{%d}(?P<%s>.*)$", startIndex, aliasName)
 	}
-	pipe := fmt.Sprintf("extract_regexp '%s' from %s", escapeSingleQuotes(pattern), rawField)
-	return []string{pipe}, aliasName, nil
-}
-
-func parseSubstringIntArg(expr ast.Expr, name string) (int, error) {
-	lit, ok := expr.(*ast.NumericLiteral)
-	if !ok {
-		return 0, &TranslationError{
-			Code:    http.StatusBadRequest,
-			Message: fmt.Sprintf("translator: substr %s must be integer literal", name),
-		}
+	aliasName, err := makeProjectionAlias(strings.TrimSpace(alias), "extract", rawField)
+	if err != nil {
+		return nil, "", err
 	}
-	clean := strings.ReplaceAll(strings.TrimSpace(lit.Value), "_", "")
-	if clean == "" {
-		return 0, &TranslationError{
-			Code:    http.StatusBadRequest,
-			Message: fmt.Sprintf("translator: substr %s must be integer literal", name),
-		}
+	pattern, err := nameCaptureGroup(patternLit.Value, group, aliasName)
+	if err != nil {
+		return nil, "", err
 	}
-	if strings.ContainsAny(clean, ".eE") {
-		return 0, &TranslationError{
+	if _, cerr := regexp.Compile(pattern); cerr != nil {
+		return nil, "", &TranslationError{
 			Code:    http.StatusBadRequest,
-			Message: fmt.Sprintf("translator: substr %s must be integer literal", name),
+			Message: fmt.Sprintf("translator: invalid regexp_extract pattern: %s", cerr),
+			Err:     cerr,
 		}
 	}
-	val, err := strconv.Atoi(clean)
-	if err != nil {
-		return 0, &TranslationError{
-			Code:    http.StatusBadRequest,
-			Message: fmt.Sprintf("translator: substr %s must be integer literal", name),
+	pipe := fmt.Sprintf("extract_regexp '%s' from %s", escapeSingleQuotes(pattern), rawField)
+	return []string{pipe}, aliasName, nil
+}
+
+// nameCaptureGroup turns the n-th capturing group of pattern into a named
+// group so extract_regexp stores the match under alias.
+func nameCaptureGroup(pattern string, group int, alias string) (string, error) {
+	inClass := false
+	count := 0
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			i++
+		case '[':
+			if !inClass {
+				inClass = true
+			}
+		case ']':
+			inClass = false
+		case '(':
+			if inClass {
+				continue
+			}
+			named := strings.HasPrefix(pattern[i+1:], "?P<")
+			if !named && i+1 < len(pattern) && pattern[i+1] == '?' {
+				// Non-capturing group or flags.
+				continue
+			}
+			count++
+			if count == group {
+				if named {
+					return "", &TranslationError{
+						Code:    http.StatusBadRequest,
+						Message: fmt.Sprintf("translator: regexp_extract group %d is already named", group),
+					}
+				}
+				return pattern[:i+1] + "?P<" + alias + ">" + pattern[i+1:], nil
+			}
 		}
 	}
-	return val, nil
+	return "", &TranslationError{
+		Code:    http.StatusBadRequest,
+		Message: fmt.Sprintf("translator: regexp_extract pattern has no capture group %d", group),
+	}
 }
 
 func (v *selectTranslatorVisitor) translateConcatFunction(fn *ast.FuncCall, alias string) ([]string, string, error) {
@@ -1873,7 +3530,7 @@ func (v *selectTranslatorVisitor) translateWindowFunction(fn *ast.FuncCall, alia
 	default:
 		return nil, "", &TranslationError{
 			Code:    http.StatusBadRequest,
-			Message: fmt.Sprintf("translator: window function %s is not supported", name),
+			Message: fmt.Sprintf("translator: window function %s is not supported%s", name, didYouMean(name, windowFunctionNames)),
 		}
 	}
 	if strings.TrimSpace(aliasSource) == "" {
@@ -1912,7 +3569,7 @@ func (v *selectTranslatorVisitor) translateWindowFunction(fn *ast.FuncCall, alia
 				return nil, "", err
 			}
 		}
-		orderPipe, err := v.translateOrderBy(fn.Over.OrderBy, false)
+		orderPipe, err := v.translateOrderBy(fn.Over.OrderBy, false, "")
 		if err != nil {
 			return nil, "", err
 		}
@@ -1966,8 +3623,165 @@ func (v *selectTranslatorVisitor) translateCurrentDate(alias string) ([]string,
 	return pipes, aliasName, nil
 }
 
+// translateFromUnixtimeFunction converts a unix-seconds field into an
+// RFC3339 timestamp via the "<time:...>" format verb.
+func (v *selectTranslatorVisitor) translateFromUnixtimeFunction(name string, fn *ast.FuncCall, alias string) ([]string, string, error) {
+	lower := strings.ToLower(name)
+	if len(fn.Args) != 1 {
+		return nil, "", &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("translator: %s expects single argument", lower),
+		}
+	}
+	ident, ok := fn.Args[0].(*ast.Identifier)
+	if !ok {
+		return nil, "", &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("translator: %s only supports identifiers", lower),
+		}
+	}
+	rawField, err := v.rawFieldName(ident)
+	if err != nil {
+		return nil, "", err
+	}
+	aliasName, err := makeProjectionAlias(strings.TrimSpace(alias), lower, rawField)
+	if err != nil {
+		return nil, "", err
+	}
+	tmpField := aliasName + "_nanos"
+	if !safeFormatFieldLiteral.MatchString(tmpField) {
+		return nil, "", &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("translator: alias %s produces unsupported field name", aliasName),
+		}
+	}
+	pipes := []string{
+		fmt.Sprintf("math (%s * 1000000000) as %s", rawField, tmpField),
+		fmt.Sprintf("format '<time:%s>' as %s", tmpField, aliasName),
+		fmt.Sprintf("delete %s", tmpField),
+	}
+	return pipes, aliasName, nil
+}
+
+// dateFormatComponents lists the RFC3339 prefix components in order together
+// with the separator preceding each of them.
+var dateFormatComponents = []struct {
+	key string
+	sep string
+}{
+	{key: "y", sep: ""},
+	{key: "mo", sep: "-"},
+	{key: "d", sep: "-"},
+	{key: "h", sep: "T"},
+	{key: "mi", sep: ":"},
+	{key: "s", sep: ":"},
+}
+
+var dateFormatSpecifiers = map[byte]int{
+	'Y': 0,
+	'm': 1,
+	'd': 2,
+	'H': 3,
+	'i': 4,
+	'S': 5,
+	's': 5,
+}
+
+// translateDateFormatFunction reshapes an RFC3339 timestamp field using a
+// MySQL-style pattern (%Y, %m, %d, %H, %i, %S): the components are captured
+// with extract_regexp and reassembled with a format pipe.
+func (v *selectTranslatorVisitor) translateDateFormatFunction(fn *ast.FuncCall, alias string) ([]string, string, error) {
+	if len(fn.Args) != 2 {
+		return nil, "", &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: date_format expects two arguments",
+		}
+	}
+	ident, ok := fn.Args[0].(*ast.Identifier)
+	if !ok {
+		return nil, "", &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: date_format only supports identifiers as first argument",
+		}
+	}
+	rawField, err := v.rawFieldName(ident)
+	if err != nil {
+		return nil, "", err
+	}
+	specLit, ok := fn.Args[1].(*ast.StringLiteral)
+	if !ok {
+		return nil, "", &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: date_format pattern must be a string literal",
+		}
+	}
+	aliasName, err := makeProjectionAlias(strings.TrimSpace(alias), "date_format", rawField)
+	if err != nil {
+		return nil, "", err
+	}
+
+	spec := specLit.Value
+	deepest := -1
+	var formatPattern strings.Builder
+	for i := 0; i < len(spec); i++ {
+		if spec[i] != '%' {
+			formatPattern.WriteByte(spec[i])
+			continue
+		}
+		i++
+		if i >= len(spec) {
+			return nil, "", &TranslationError{
+				Code:    http.StatusBadRequest,
+				Message: "translator: date_format pattern has a trailing %",
+			}
+		}
+		if spec[i] == '%' {
+			formatPattern.WriteByte('%')
+			continue
+		}
+		idx, ok := dateFormatSpecifiers[spec[i]]
+		if !ok {
+			return nil, "", &TranslationError{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("translator: date_format specifier %%%c is not supported", spec[i]),
+			}
+		}
+		if idx > deepest {
+			deepest = idx
+		}
+		formatPattern.WriteString(fmt.Sprintf("<%s_%s>", aliasName, dateFormatComponents[idx].key))
+	}
+	if deepest < 0 {
+		return nil, "", &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: date_format pattern has no time specifiers",
+		}
+	}
+
+	var extractPattern strings.Builder
+	extractPattern.WriteString("^")
+	temps := make([]string, 0, deepest+1)
+	for i := 0; i <= deepest; i++ {
+		comp := dateFormatComponents[i]
+		tmp := fmt.Sprintf("%s_%s", aliasName, comp.key)
+		temps = append(temps, tmp)
+		extractPattern.WriteString(comp.sep)
+		width := 2
+		if comp.key == "y" {
+			width = 4
+		}
+		extractPattern.WriteString(fmt.Sprintf("(?P<%s>[0-9]{%d})", tmp, width))
+	}
+	pipes := []string{
+		fmt.Sprintf("extract_regexp '%s' from %s", escapeSingleQuotes(extractPattern.String()), rawField),
+		fmt.Sprintf("format \"%s\" as %s", escapeFormatPattern(formatPattern.String()), aliasName),
+		fmt.Sprintf("delete %s", strings.Join(temps, ", ")),
+	}
+	return pipes, aliasName, nil
+}
+
 func (v *selectTranslatorVisitor) translateMathProjection(expr ast.Expr, alias string) (string, string, error) {
-	mathExpr, err := v.mathExprToString(expr)
+	mathExpr, err := v.mathExprToString(foldConstantExpr(expr))
 	if err != nil {
 		return "", "", err
 	}
@@ -2109,7 +3923,7 @@ func (v *selectTranslatorVisitor) mathFuncToString(fn *ast.FuncCall) (string, er
 	default:
 		return "", &TranslationError{
 			Code:    http.StatusBadRequest,
-			Message: fmt.Sprintf("translator: unsupported function %s in math expression", name),
+			Message: fmt.Sprintf("translator: unsupported function %s in math expression%s", name, didYouMean(name, scalarFunctionNames)),
 		}
 	}
 }
@@ -2125,7 +3939,7 @@ func isMathOperator(op string) bool {
 
 func (v *selectTranslatorVisitor) buildProjectionPipes(columns []ast.SelectItem, aggregated bool) ([]string, []string, error) {
 	if len(columns) == 1 {
-		if _, ok := columns[0].Expr.(*ast.StarExpr); ok {
+		if star, ok := columns[0].Expr.(*ast.StarExpr); ok && star.Table == nil {
 			return nil, nil, nil
 		}
 	}
@@ -2133,6 +3947,7 @@ func (v *selectTranslatorVisitor) buildProjectionPipes(columns []ast.SelectItem,
 	computedPipes := make([]string, 0)
 	renamePairs := make([]string, 0)
 	fields := make([]string, 0, len(columns))
+	keepAllFields := false
 
 	for _, col := range columns {
 		switch expr := col.Expr.(type) {
@@ -2266,10 +4081,23 @@ func (v *selectTranslatorVisitor) buildProjectionPipes(columns []ast.SelectItem,
 			computedPipes = append(computedPipes, mathPipe)
 			fields = append(fields, formatFieldName(aliasName))
 		case *ast.StarExpr:
-			return nil, nil, &TranslationError{
-				Code:    http.StatusBadRequest,
-				Message: "translator: SELECT * cannot be combined with other projections",
+			if expr.Table == nil || len(expr.Table.Parts) == 0 {
+				return nil, nil, &TranslationError{
+					Code:    http.StatusBadRequest,
+					Message: "translator: SELECT * cannot be combined with other projections",
+				}
+			}
+			qualifier := v.identKey(expr.Table.Parts[len(expr.Table.Parts)-1])
+			binding, ok := v.bindings[qualifier]
+			if !ok || !binding.isBase {
+				return nil, nil, &TranslationError{
+					Code:    http.StatusBadRequest,
+					Message: fmt.Sprintf("translator: qualified %s.* is only supported for the base table", strings.Join(expr.Table.Parts, ".")),
+				}
 			}
+			// The base table's column set is unknown at translation time, so
+			// keep every field instead of emitting a fields pipe.
+			keepAllFields = true
 		default:
 			return nil, nil, &TranslationError{
 				Code:    http.StatusBadRequest,
@@ -2278,19 +4106,39 @@ func (v *selectTranslatorVisitor) buildProjectionPipes(columns []ast.SelectItem,
 		}
 	}
 
+	seen := make(map[string]struct{}, len(fields))
+	for _, field := range fields {
+		key := v.identKey(field)
+		if _, ok := seen[key]; ok {
+			return nil, nil, &TranslationError{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("translator: duplicate output column %s; add a distinct alias", field),
+			}
+		}
+		seen[key] = struct{}{}
+	}
+
 	pipes := make([]string, 0, len(computedPipes)+2)
 	pipes = append(pipes, computedPipes...)
 	if len(renamePairs) > 0 {
 		pipes = append(pipes, "rename "+strings.Join(renamePairs, ", "))
 	}
+	if keepAllFields {
+		// DISTINCT cannot enumerate the retained columns, so report no
+		// explicit field list to the caller.
+		return pipes, nil, nil
+	}
 	if len(fields) > 0 && !aggregated {
 		pipes = append(pipes, "fields "+strings.Join(fields, ", "))
 	}
 	return pipes, fields, nil
 }
 
-func (v *selectTranslatorVisitor) translateOrderBy(items []ast.OrderItem, aggregated bool) (string, error) {
+// translateOrderBy renders the sort pipe. A non-empty sortLimit (e.g.
+// " limit 10") is appended to the sort pipe itself.
+func (v *selectTranslatorVisitor) translateOrderBy(items []ast.OrderItem, aggregated bool, sortLimit string) (string, error) {
 	clauses := make([]string, 0, len(items))
+	var prePipes, helperFields []string
 	for _, item := range items {
 		var field string
 		switch expr := item.Expr.(type) {
@@ -2325,13 +4173,34 @@ func (v *selectTranslatorVisitor) translateOrderBy(items []ast.OrderItem, aggreg
 				Message: fmt.Sprintf("translator: ORDER BY expression %T not supported", expr),
 			}
 		}
+		switch item.Collation {
+		case "", "LEXICOGRAPHIC":
+			// LogsQL sorts lexicographically by default.
+		case "NUMERIC":
+			// The math pipe parses the field as a number, so sorting the
+			// helper field orders values numerically instead of by text.
+			helper := fmt.Sprintf("__sort_expr_%d", len(helperFields)+1)
+			prePipes = append(prePipes, fmt.Sprintf("math %s as %s", field, helper))
+			helperFields = append(helperFields, helper)
+			field = helper
+		default:
+			return "", &TranslationError{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("translator: unsupported collation %q in ORDER BY (supported: LEXICOGRAPHIC, NUMERIC)", item.Collation),
+			}
+		}
 		if item.Direction == ast.Descending {
 			clauses = append(clauses, field+" desc")
 		} else {
 			clauses = append(clauses, field)
 		}
 	}
-	return "sort by (" + strings.Join(clauses, ", ") + ")", nil
+	sortPipe := "sort by (" + strings.Join(clauses, ", ") + ")" + sortLimit
+	if len(prePipes) == 0 {
+		return sortPipe, nil
+	}
+	pipes := append(prePipes, sortPipe, "delete "+strings.Join(helperFields, ", "))
+	return strings.Join(pipes, " | "), nil
 }
 
 func (v *selectTranslatorVisitor) aggregateKeyFromFunc(fn *ast.FuncCall) (string, error) {
@@ -2381,25 +4250,146 @@ func (v *selectTranslatorVisitor) aggregateKeyFromFunc(fn *ast.FuncCall) (string
 				Message: fmt.Sprintf("translator: %s expects single argument", strings.ToLower(name)),
 			}
 		}
-		ident, ok := fn.Args[0].(*ast.Identifier)
-		if !ok {
-			return "", &TranslationError{
-				Code:    http.StatusBadRequest,
-				Message: fmt.Sprintf("translator: %s only supports identifiers", strings.ToLower(name)),
-			}
+		if ident, ok := fn.Args[0].(*ast.Identifier); ok {
+			field, err := v.normalizeIdentifier(ident)
+			if err != nil {
+				return "", err
+			}
+			arg = field
+			break
+		}
+		if caseExpr, ok := fn.Args[0].(*ast.CaseExpr); ok {
+			rendered, err := render.Render(caseExpr)
+			if err != nil {
+				return "", &TranslationError{
+					Code:    http.StatusBadRequest,
+					Message: fmt.Sprintf("translator: failed to normalize CASE expression: %s", err),
+					Err:     err,
+				}
+			}
+			arg = rendered
+			break
+		}
+		if !isArithmeticExpr(fn.Args[0]) {
+			return "", &TranslationError{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("translator: %s only supports identifiers and math expressions", strings.ToLower(name)),
+			}
+		}
+		mathExpr, err := v.mathExprToString(fn.Args[0])
+		if err != nil {
+			return "", err
+		}
+		arg = mathExpr
+	case "FIRST", "FIRST_VALUE", "LAST", "LAST_VALUE", "ANY_VALUE":
+		if len(fn.Args) != 1 {
+			return "", &TranslationError{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("translator: %s expects single argument", strings.ToLower(name)),
+			}
+		}
+		ident, ok := fn.Args[0].(*ast.Identifier)
+		if !ok {
+			return "", &TranslationError{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("translator: %s only supports identifiers", strings.ToLower(name)),
+			}
+		}
+		field, err := v.normalizeIdentifier(ident)
+		if err != nil {
+			return "", err
+		}
+		arg = field
+	case "ARRAY_AGG", "GROUP_CONCAT", "STRING_AGG":
+		field, err := v.valueListAggregateField(name, fn)
+		if err != nil {
+			return "", err
+		}
+		arg = field
+		if fn.Distinct {
+			arg = "DISTINCT " + field
+		}
+	case "APPROX_COUNT_DISTINCT":
+		field, _, err := v.approxCountDistinctArgs(fn)
+		if err != nil {
+			return "", err
+		}
+		arg = field
+	default:
+		return "", &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("translator: unsupported aggregate %s", name),
+		}
+	}
+	key := aggregateKey(name, arg)
+	if fn.Filter != nil {
+		rendered, err := render.Render(fn.Filter)
+		if err != nil {
+			return "", &TranslationError{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("translator: failed to normalize FILTER condition: %s", err),
+				Err:     err,
+			}
+		}
+		key += " FILTER (" + rendered + ")"
+	}
+	return key, nil
+}
+
+// foldIntegerExpr evaluates constant integer arithmetic, e.g. the LIMIT 10*10
+// that query builders emit. Placeholders are rejected explicitly because bind
+// parameters must be substituted before translation.
+func foldIntegerExpr(expr ast.Expr) (int64, error) {
+	switch e := expr.(type) {
+	case *ast.NumericLiteral:
+		n, err := strconv.ParseInt(e.Value, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%s is not an integer", e.Value)
+		}
+		return n, nil
+	case *ast.UnaryExpr:
+		if e.Operator != "-" {
+			return 0, fmt.Errorf("unsupported unary operator %q", e.Operator)
+		}
+		n, err := foldIntegerExpr(e.Expr)
+		if err != nil {
+			return 0, err
 		}
-		field, err := v.normalizeIdentifier(ident)
+		return -n, nil
+	case *ast.BinaryExpr:
+		left, err := foldIntegerExpr(e.Left)
 		if err != nil {
-			return "", err
+			return 0, err
 		}
-		arg = field
-	default:
-		return "", &TranslationError{
-			Code:    http.StatusBadRequest,
-			Message: fmt.Sprintf("translator: unsupported aggregate %s", name),
+		right, err := foldIntegerExpr(e.Right)
+		if err != nil {
+			return 0, err
+		}
+		switch e.Operator {
+		case "+":
+			return left + right, nil
+		case "-":
+			return left - right, nil
+		case "*":
+			return left * right, nil
+		case "/":
+			if right == 0 {
+				return 0, fmt.Errorf("division by zero")
+			}
+			return left / right, nil
+		case "%":
+			if right == 0 {
+				return 0, fmt.Errorf("division by zero")
+			}
+			return left % right, nil
+		default:
+			return 0, fmt.Errorf("unsupported operator %q", e.Operator)
 		}
+	case *ast.Placeholder:
+		return 0, fmt.Errorf("bind parameter %s must be substituted before translation", e.Symbol)
+	default:
+		return 0, fmt.Errorf("unsupported expression %T", expr)
 	}
-	return aggregateKey(name, arg), nil
 }
 
 func (v *selectTranslatorVisitor) translateLimit(limit *ast.LimitClause) ([]string, error) {
@@ -2408,39 +4398,38 @@ func (v *selectTranslatorVisitor) translateLimit(limit *ast.LimitClause) ([]stri
 	}
 	pipes := make([]string, 0, 2)
 	if limit.Offset != nil {
-		offsetLit, err := literalFromExpr(limit.Offset)
+		offset, err := foldIntegerExpr(limit.Offset)
 		if err != nil {
 			return nil, &TranslationError{
 				Code:    http.StatusBadRequest,
-				Message: fmt.Sprintf("translator: OFFSET expects numeric literal: %s", err),
+				Message: fmt.Sprintf("translator: OFFSET expects a constant integer: %s", err),
 				Err:     err,
 			}
 		}
-		if offsetLit.kind != literalNumber {
+		if offset < 0 {
 			return nil, &TranslationError{
 				Code:    http.StatusBadRequest,
-				Message: "translator: OFFSET expects numeric literal",
+				Message: fmt.Sprintf("translator: OFFSET must not be negative, got %d", offset),
 			}
 		}
-		pipes = append(pipes, "offset "+offsetLit.value)
+		pipes = append(pipes, fmt.Sprintf("offset %d", offset))
 	}
 	if limit.Count != nil {
-		countLit, err := literalFromExpr(limit.Count)
+		count, err := foldIntegerExpr(limit.Count)
 		if err != nil {
 			return nil, &TranslationError{
 				Code:    http.StatusBadRequest,
-				Message: fmt.Sprintf("translator: LIMIT expects numeric literal: %s", err),
+				Message: fmt.Sprintf("translator: LIMIT expects a constant integer: %s", err),
 				Err:     err,
 			}
 		}
-		if countLit.kind != literalNumber {
+		if count < 0 {
 			return nil, &TranslationError{
 				Code:    http.StatusBadRequest,
-				Message: "translator: LIMIT expects numeric literal",
-				Err:     err,
+				Message: fmt.Sprintf("translator: LIMIT must not be negative, got %d", count),
 			}
 		}
-		pipes = append(pipes, "limit "+countLit.value)
+		pipes = append(pipes, fmt.Sprintf("limit %d", count))
 	}
 	if len(pipes) == 0 {
 		return nil, &TranslationError{
@@ -2456,6 +4445,11 @@ func (v *selectTranslatorVisitor) translateExpr(expr ast.Expr) (string, error) {
 	case *ast.BinaryExpr:
 		switch e.Operator {
 		case "AND", "OR":
+			if e.Operator == "OR" {
+				if clause, ok := v.orEqualitiesToValueList(e); ok {
+					return clause, nil
+				}
+			}
 			left, err := v.translateExpr(e.Left)
 			if err != nil {
 				return "", err
@@ -2466,8 +4460,15 @@ func (v *selectTranslatorVisitor) translateExpr(expr ast.Expr) (string, error) {
 			}
 			return "(" + left + " " + e.Operator + " " + right + ")", nil
 		case "=":
+			if operand, ok := nullComparisonOperand(e.Left, e.Right); ok {
+				// ORMs frequently emit "field = NULL"; treat it as IS NULL.
+				return v.translateIsNullExpr(&ast.IsNullExpr{Expr: operand})
+			}
 			return v.translateComparison(e.Left, e.Right, comparisonEqual)
 		case "!=", "<>":
+			if operand, ok := nullComparisonOperand(e.Left, e.Right); ok {
+				return v.translateIsNullExpr(&ast.IsNullExpr{Expr: operand, Not: true})
+			}
 			return v.translateComparison(e.Left, e.Right, comparisonNotEqual)
 		case ">":
 			return v.translateComparison(e.Left, e.Right, comparisonGreater)
@@ -2504,6 +4505,12 @@ func (v *selectTranslatorVisitor) translateExpr(expr ast.Expr) (string, error) {
 	case *ast.BetweenExpr:
 		return v.translateBetweenExpr(e)
 	case *ast.FuncCall:
+		if raw, ok, err := v.rawLogsQLFilter(e); ok {
+			if err != nil {
+				return "", err
+			}
+			return raw, nil
+		}
 		if v.aggResults != nil {
 			key, err := v.aggregateKeyFromFunc(e)
 			if err != nil {
@@ -2518,7 +4525,13 @@ func (v *selectTranslatorVisitor) translateExpr(expr ast.Expr) (string, error) {
 			Message: "translator: unsupported function expression in filter",
 		}
 	case *ast.Identifier:
-		return v.normalizeIdentifier(e)
+		// A bare identifier in filter position is a boolean field test,
+		// e.g. "WHERE is_error".
+		field, err := v.normalizeIdentifier(e)
+		if err != nil {
+			return "", err
+		}
+		return field + ":true", nil
 	case *ast.StringLiteral:
 		return formatString(e.Value), nil
 	case *ast.NumericLiteral:
@@ -2542,6 +4555,9 @@ func (v *selectTranslatorVisitor) translateExpr(expr ast.Expr) (string, error) {
 }
 
 func (v *selectTranslatorVisitor) translateComparison(left, right ast.Expr, cmp comparisonKind) (string, error) {
+	if clause, ok, err := v.translateTimeWindowComparison(left, right, cmp); ok || err != nil {
+		return clause, err
+	}
 	leftField, leftIsField, err := v.fieldNameFromExpr(left)
 	if err != nil {
 		return "", err
@@ -2550,6 +4566,20 @@ func (v *selectTranslatorVisitor) translateComparison(left, right ast.Expr, cmp
 	if err != nil {
 		return "", err
 	}
+	if !leftIsField && isArithmeticExpr(left) {
+		leftField, err = v.ensureFilterMathAlias(left)
+		if err != nil {
+			return "", err
+		}
+		leftIsField = true
+	}
+	if !rightIsField && isArithmeticExpr(right) {
+		rightField, err = v.ensureFilterMathAlias(right)
+		if err != nil {
+			return "", err
+		}
+		rightIsField = true
+	}
 
 	switch {
 	case leftIsField && rightIsField:
@@ -2559,12 +4589,20 @@ func (v *selectTranslatorVisitor) translateComparison(left, right ast.Expr, cmp
 		if err != nil {
 			return "", err
 		}
+		lit = v.convertTimeLiteral(leftField, lit)
+		if clause, ok := v.caseInsensitiveEquality(leftField, lit, cmp); ok {
+			return clause, nil
+		}
 		return buildFieldLiteralComparison(leftField, lit, false, cmp)
 	case rightIsField:
 		lit, err := literalFromExpr(left)
 		if err != nil {
 			return "", err
 		}
+		lit = v.convertTimeLiteral(rightField, lit)
+		if clause, ok := v.caseInsensitiveEquality(rightField, lit, cmp); ok {
+			return clause, nil
+		}
 		return buildFieldLiteralComparison(rightField, lit, true, cmp)
 	default:
 		return "", &TranslationError{
@@ -2574,6 +4612,143 @@ func (v *selectTranslatorVisitor) translateComparison(left, right ast.Expr, cmp
 	}
 }
 
+// caseInsensitiveEquality rewrites string equality into the i() filter when
+// the session enables SET case_insensitive = true; far cheaper than the
+// UPPER()-based filter computation it replaces.
+func (v *selectTranslatorVisitor) caseInsensitiveEquality(field string, lit literalValue, cmp comparisonKind) (string, bool) {
+	if lit.kind != literalString || (cmp != comparisonEqual && cmp != comparisonNotEqual) {
+		return "", false
+	}
+	if !v.sp.SessionOptions().CaseInsensitive() {
+		return "", false
+	}
+	clause := field + ":i(" + formatString(lit.value) + ")"
+	if cmp == comparisonNotEqual {
+		clause = "-" + clause
+	}
+	return clause, true
+}
+
+// naiveTimeLayouts lists the timestamp formats accepted without zone
+// information, most specific first.
+var naiveTimeLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// convertTimeLiteral rewrites a naive timestamp literal on _time from the
+// session time zone into UTC, since VictoriaLogs interprets bare timestamps
+// as UTC while dashboards tend to send local times. Literals that carry
+// their own zone, non-timestamp values and a UTC session are left untouched.
+func (v *selectTranslatorVisitor) convertTimeLiteral(field string, lit literalValue) literalValue {
+	if field != "_time" || lit.kind != literalString {
+		return lit
+	}
+	loc := v.sp.TimeZone()
+	if loc == time.UTC {
+		return lit
+	}
+	for _, layout := range naiveTimeLayouts {
+		if t, err := time.ParseInLocation(layout, lit.value, loc); err == nil {
+			lit.value = t.UTC().Format(time.RFC3339)
+			return lit
+		}
+	}
+	return lit
+}
+
+// intervalDurationSuffixes maps canonical INTERVAL units to LogsQL duration
+// suffixes. MONTH is absent because it has no fixed duration.
+var intervalDurationSuffixes = map[string]string{
+	"SECOND": "s",
+	"MINUTE": "m",
+	"HOUR":   "h",
+	"DAY":    "d",
+	"WEEK":   "w",
+	"YEAR":   "y",
+}
+
+// translateTimeWindowComparison recognizes the "last N units" shape that ORMs
+// generate, e.g. WHERE _time >= DATE_SUB(NOW(), INTERVAL 1 DAY), and turns it
+// into a relative _time filter such as _time:1d. It returns ok=false when the
+// comparison does not match the pattern.
+func (v *selectTranslatorVisitor) translateTimeWindowComparison(left, right ast.Expr, cmp comparisonKind) (string, bool, error) {
+	fn, ok := right.(*ast.FuncCall)
+	if !ok || len(fn.Name.Parts) == 0 {
+		return "", false, nil
+	}
+	name := strings.ToUpper(fn.Name.Parts[len(fn.Name.Parts)-1])
+	if name != "DATE_SUB" && name != "DATE_ADD" {
+		return "", false, nil
+	}
+	ident, ok := left.(*ast.Identifier)
+	if !ok {
+		return "", false, nil
+	}
+	field, err := v.normalizeIdentifier(ident)
+	if err != nil {
+		return "", false, err
+	}
+	if field != "_time" {
+		return "", false, nil
+	}
+	if len(fn.Args) != 2 {
+		return "", false, &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("translator: %s expects two arguments", strings.ToLower(name)),
+		}
+	}
+	if !isNowExpr(fn.Args[0]) {
+		return "", false, &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("translator: %s on _time is only supported relative to NOW()", strings.ToLower(name)),
+		}
+	}
+	interval, ok := fn.Args[1].(*ast.IntervalExpr)
+	if !ok {
+		return "", false, &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("translator: %s expects an INTERVAL literal as second argument", strings.ToLower(name)),
+		}
+	}
+	if name != "DATE_SUB" || (cmp != comparisonGreater && cmp != comparisonGreaterEqual) {
+		return "", false, &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: only _time >= DATE_SUB(NOW(), INTERVAL ...) is supported",
+		}
+	}
+	suffix, ok := intervalDurationSuffixes[interval.Unit]
+	if !ok {
+		return "", false, &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("translator: interval unit %s has no fixed duration", interval.Unit),
+		}
+	}
+	return "_time:" + interval.Value + suffix, true, nil
+}
+
+// isNowExpr reports whether expr is NOW() or CURRENT_TIMESTAMP in either
+// function-call or bare-identifier form.
+func isNowExpr(expr ast.Expr) bool {
+	switch e := expr.(type) {
+	case *ast.FuncCall:
+		if len(e.Name.Parts) == 0 || len(e.Args) != 0 {
+			return false
+		}
+		name := strings.ToUpper(e.Name.Parts[len(e.Name.Parts)-1])
+		return name == "NOW" || name == "CURRENT_TIMESTAMP"
+	case *ast.Identifier:
+		if len(e.Parts) != 1 {
+			return false
+		}
+		return strings.EqualFold(e.Parts[0], "CURRENT_TIMESTAMP")
+	default:
+		return false
+	}
+}
+
 func buildFieldLiteralComparison(field string, lit literalValue, flipped bool, cmp comparisonKind) (string, error) {
 	switch cmp {
 	case comparisonEqual:
@@ -2654,6 +4829,8 @@ func (v *selectTranslatorVisitor) translateBetweenExpr(expr *ast.BetweenExpr) (s
 	if err != nil {
 		return "", err
 	}
+	lower = v.convertTimeLiteral(field, lower)
+	upper = v.convertTimeLiteral(field, upper)
 	clause := field + ":[" + lower.format() + ", " + upper.format() + "]"
 	if expr.Not {
 		return "-" + clause, nil
@@ -2661,12 +4838,56 @@ func (v *selectTranslatorVisitor) translateBetweenExpr(expr *ast.BetweenExpr) (s
 	return clause, nil
 }
 
+// orEqualitiesToValueList rewrites a chain of equality comparisons against
+// the same field into a single LogsQL value-list filter, e.g.
+// service:(a OR b OR c), which VictoriaLogs evaluates much faster than
+// nested OR filters. It reports false when the chain does not match.
+func (v *selectTranslatorVisitor) orEqualitiesToValueList(expr *ast.BinaryExpr) (string, bool) {
+	if v.sp.SessionOptions().CaseInsensitive() {
+		return "", false
+	}
+	disjuncts := flattenOr(expr)
+	if len(disjuncts) < 2 {
+		return "", false
+	}
+	var field string
+	values := make([]string, 0, len(disjuncts))
+	for _, d := range disjuncts {
+		bin, ok := d.(*ast.BinaryExpr)
+		if !ok || bin.Operator != "=" {
+			return "", false
+		}
+		idExpr, litExpr := bin.Left, bin.Right
+		if _, ok := idExpr.(*ast.Identifier); !ok {
+			idExpr, litExpr = bin.Right, bin.Left
+		}
+		id, ok := idExpr.(*ast.Identifier)
+		if !ok {
+			return "", false
+		}
+		lit, err := literalFromExpr(litExpr)
+		if err != nil {
+			return "", false
+		}
+		name, err := v.filterFieldFromExpr(id)
+		if err != nil {
+			return "", false
+		}
+		switch {
+		case field == "":
+			field = name
+		case field != name:
+			return "", false
+		}
+		lit = v.convertTimeLiteral(name, lit)
+		values = append(values, lit.format())
+	}
+	return field + ":(" + strings.Join(values, " OR ") + ")", true
+}
+
 func (v *selectTranslatorVisitor) translateInExpr(expr *ast.InExpr) (string, error) {
 	if expr.Subquery != nil {
-		return "", &TranslationError{
-			Code:    http.StatusBadRequest,
-			Message: "translator: IN subqueries are not supported yet",
-		}
+		return v.translateInSubquery(expr)
 	}
 	field, err := v.filterFieldFromExpr(expr.Expr)
 	if err != nil {
@@ -2693,6 +4914,52 @@ func (v *selectTranslatorVisitor) translateInExpr(expr *ast.InExpr) (string, err
 	return clause, nil
 }
 
+// translateInSubquery maps x IN (SELECT f FROM ...) to the LogsQL
+// in(<query>) filter. The mapping is gated behind the in_subquery feature
+// while VictoriaLogs in(...) subquery semantics are still being validated.
+func (v *selectTranslatorVisitor) translateInSubquery(expr *ast.InExpr) (string, error) {
+	if !v.sp.FeatureEnabled(FeatureInSubquery) {
+		return "", featureGateError("IN subqueries", FeatureInSubquery)
+	}
+	field, err := v.filterFieldFromExpr(expr.Expr)
+	if err != nil {
+		return "", err
+	}
+	sel := expr.Subquery
+	if len(sel.Columns) != 1 {
+		return "", &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: IN subquery must select exactly one column",
+		}
+	}
+	if _, ok := sel.Columns[0].Expr.(*ast.StarExpr); ok {
+		return "", &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: IN subquery cannot select *",
+		}
+	}
+	subQuery, err := translateSelectStatementToLogsQLWithContext(sel, translationContext{
+		sp:           v.sp,
+		ctes:         v.availableCTEs,
+		cteSelects:   v.cteSelects,
+		cteUses:      v.cteUses,
+		outerAliases: v.scopeAliases(),
+		warnings:     v.warnings,
+	})
+	if err != nil {
+		return "", &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("translator: failed to translate IN subquery: %s", err),
+			Err:     err,
+		}
+	}
+	clause := field + ":in(" + subQuery + ")"
+	if expr.Not {
+		return "-" + clause, nil
+	}
+	return clause, nil
+}
+
 func (v *selectTranslatorVisitor) translateLikeExpr(expr *ast.LikeExpr) (string, error) {
 	field, err := v.filterFieldFromExpr(expr.Expr)
 	if err != nil {
@@ -2710,10 +4977,20 @@ func (v *selectTranslatorVisitor) translateLikeExpr(expr *ast.LikeExpr) (string,
 	}
 
 	pattern := lit.value
-	translated, err := convertLikePattern(pattern)
+	var translated string
+	if expr.CaseInsensitive {
+		translated, err = convertILikePattern(pattern)
+	} else {
+		translated, err = convertLikePattern(pattern)
+	}
 	if err != nil {
 		return "", err
 	}
+	if strings.HasPrefix(translated, "~") {
+		v.addWarning("LIKE pattern %q falls back to a regex filter on %s, which is expensive to evaluate; prefer prefix or substring patterns", pattern, field)
+	} else if err := v.strictViolation("LIKE pattern %q is matched with word-based LogsQL filters on %s, which are not anchored to the whole value like SQL LIKE", pattern, field); err != nil {
+		return "", err
+	}
 
 	clause := field + ":" + translated
 	if expr.Not {
@@ -2722,17 +4999,116 @@ func (v *selectTranslatorVisitor) translateLikeExpr(expr *ast.LikeExpr) (string,
 	return clause, nil
 }
 
+// nullComparisonOperand returns the non-NULL side of an equality comparison
+// against a NULL literal, reporting whether such a rewrite applies.
+func nullComparisonOperand(left, right ast.Expr) (ast.Expr, bool) {
+	if _, ok := right.(*ast.NullLiteral); ok {
+		return left, true
+	}
+	if _, ok := left.(*ast.NullLiteral); ok {
+		return right, true
+	}
+	return nil, false
+}
+
 func (v *selectTranslatorVisitor) translateIsNullExpr(expr *ast.IsNullExpr) (string, error) {
 	field, err := v.filterFieldFromExpr(expr.Expr)
 	if err != nil {
 		return "", err
 	}
+	if err := v.strictViolation("IS NULL on %s is translated to an empty-value match; LogsQL cannot distinguish a missing field from one set to an empty string", field); err != nil {
+		return "", err
+	}
 	if expr.Not {
 		return field + ":*", nil
 	}
 	return field + ":\"\"", nil
 }
 
+// rawLogsQLFilter handles the LOGSQL_FILTER('<raw filter>') escape hatch,
+// splicing a raw LogsQL filter expression into the generated filter for
+// constructs the translator does not cover yet. It shares the raw_logsql
+// gate with the LOGSQL table function. The reported bool tells the caller
+// whether the call was a LOGSQL_FILTER at all.
+func (v *selectTranslatorVisitor) rawLogsQLFilter(fn *ast.FuncCall) (string, bool, error) {
+	if fn == nil || len(fn.Name.Parts) == 0 {
+		return "", false, nil
+	}
+	if !strings.EqualFold(fn.Name.Parts[len(fn.Name.Parts)-1], "LOGSQL_FILTER") {
+		return "", false, nil
+	}
+	if !v.sp.FeatureEnabled(FeatureRawLogsQL) {
+		return "", true, featureGateError("LOGSQL_FILTER expressions", FeatureRawLogsQL)
+	}
+	if len(fn.Args) != 1 {
+		return "", true, &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: LOGSQL_FILTER expects a single string literal argument",
+		}
+	}
+	lit, ok := fn.Args[0].(*ast.StringLiteral)
+	if !ok {
+		return "", true, &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: LOGSQL_FILTER expects a single string literal argument",
+		}
+	}
+	raw := strings.TrimSpace(lit.Value)
+	if raw == "" {
+		return "", true, &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: LOGSQL_FILTER expression cannot be empty",
+		}
+	}
+	if err := validateRawFilter(raw); err != nil {
+		return "", true, err
+	}
+	return "(" + raw + ")", true, nil
+}
+
+// validateRawFilter rejects raw filter expressions whose quotes or brackets
+// do not balance, or that contain a bare pipe; either would let the splice
+// swallow or restructure the rest of the generated query.
+func validateRawFilter(raw string) error {
+	invalid := func(format string, args ...interface{}) error {
+		return &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: invalid LOGSQL_FILTER expression: " + fmt.Sprintf(format, args...),
+		}
+	}
+	var stack []rune
+	var quote rune
+	for _, r := range raw {
+		if quote != 0 {
+			if r == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch r {
+		case '"', '\'', '`':
+			quote = r
+		case '(', '{', '[':
+			stack = append(stack, r)
+		case ')', '}', ']':
+			opener := map[rune]rune{')': '(', '}': '{', ']': '['}[r]
+			if len(stack) == 0 || stack[len(stack)-1] != opener {
+				return invalid("unbalanced %q", string(r))
+			}
+			stack = stack[:len(stack)-1]
+		case '|':
+			return invalid("pipes are not allowed; use the LOGSQL table function for full queries")
+		}
+	}
+	if quote != 0 {
+		return invalid("unterminated %q quote", string(quote))
+	}
+	if len(stack) > 0 {
+		return invalid("unbalanced %q", string(stack[len(stack)-1]))
+	}
+	return nil
+}
+
 func (v *selectTranslatorVisitor) ensureFilterFunctionAlias(fn *ast.FuncCall) (string, error) {
 	if fn == nil || len(fn.Name.Parts) == 0 {
 		return "", &TranslationError{
@@ -2761,13 +5137,13 @@ func (v *selectTranslatorVisitor) ensureFilterFunctionAlias(fn *ast.FuncCall) (s
 	if comp, ok := v.filterComputations[key]; ok {
 		return comp.alias, nil
 	}
-	aliasBase := fmt.Sprintf("__filter_expr_%d", len(v.filterOrder)+1)
+	aliasBase := v.autoAlias("__filter_expr", key, len(v.filterOrder)+1)
 	pipes, aliasName, supported, err := v.translateStringFunction(fn, aliasBase)
 	if !supported {
 		name := strings.ToLower(fn.Name.Parts[len(fn.Name.Parts)-1])
 		return "", &TranslationError{
 			Code:    http.StatusBadRequest,
-			Message: fmt.Sprintf("translator: function %s is not supported in filter", name),
+			Message: fmt.Sprintf("translator: function %s is not supported in filter%s", name, didYouMean(name, scalarFunctionNames)),
 			Err:     err,
 		}
 	}
@@ -2791,6 +5167,56 @@ func (v *selectTranslatorVisitor) ensureFilterFunctionAlias(fn *ast.FuncCall) (s
 	return comp.alias, nil
 }
 
+// isArithmeticExpr reports whether expr is a binary math expression that can
+// be evaluated by a math pipe.
+func isArithmeticExpr(expr ast.Expr) bool {
+	bin, ok := expr.(*ast.BinaryExpr)
+	if !ok {
+		return false
+	}
+	return isMathOperator(strings.ToUpper(bin.Operator))
+}
+
+// ensureFilterMathAlias computes a helper field for an arithmetic expression
+// used in a filter, mirroring ensureFilterFunctionAlias: the math pipe runs
+// before the filter and the helper field is deleted afterwards.
+func (v *selectTranslatorVisitor) ensureFilterMathAlias(expr ast.Expr) (string, error) {
+	key, err := render.Render(expr)
+	if err != nil {
+		return "", &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("translator: failed to normalize math expression: %s", err),
+			Err:     err,
+		}
+	}
+	if v.filterComputations == nil {
+		v.filterComputations = make(map[string]*filterComputation)
+	}
+	if comp, ok := v.filterComputations[key]; ok {
+		return comp.alias, nil
+	}
+	mathExpr, err := v.mathExprToString(expr)
+	if err != nil {
+		return "", err
+	}
+	aliasName := v.autoAlias("__filter_expr", key, len(v.filterOrder)+1)
+	comp := &filterComputation{
+		alias:    formatFieldName(aliasName),
+		rawAlias: aliasName,
+		pipes:    []string{fmt.Sprintf("math %s as %s", mathExpr, aliasName)},
+	}
+	v.filterComputations[key] = comp
+	v.filterOrder = append(v.filterOrder, key)
+	if v.filterDeleteSet == nil {
+		v.filterDeleteSet = make(map[string]struct{})
+	}
+	if _, exists := v.filterDeleteSet[aliasName]; !exists {
+		v.filterDeleteSet[aliasName] = struct{}{}
+		v.filterDelete = append(v.filterDelete, aliasName)
+	}
+	return comp.alias, nil
+}
+
 func (v *selectTranslatorVisitor) filterFieldFromExpr(expr ast.Expr) (string, error) {
 	field, ok, err := v.fieldNameFromExpr(expr)
 	if err != nil {
@@ -2805,6 +5231,22 @@ func (v *selectTranslatorVisitor) filterFieldFromExpr(expr ast.Expr) (string, er
 	return field, nil
 }
 
+// correlatedReferenceError reports a qualified identifier whose qualifier is
+// a table alias of an enclosing query. LogsQL subquery pipelines run
+// independently of the outer query, so such references cannot be honored and
+// must fail instead of silently resolving to a literal dotted field name.
+func (v *selectTranslatorVisitor) correlatedReferenceError(alias string) error {
+	return &TranslationError{
+		Code:    http.StatusBadRequest,
+		Message: fmt.Sprintf("translator: correlated subqueries are not supported: alias %q refers to an outer query", alias),
+	}
+}
+
+// normalizeIdentifier resolves ident to a LogsQL field name. A leading part
+// that matches a table binding is treated as an alias qualifier and
+// stripped; fields whose name literally contains a dot (or collides with an
+// alias) must be double-quoted in the SQL, which keeps them as a single part
+// that is resolved verbatim.
 func (v *selectTranslatorVisitor) normalizeIdentifier(ident *ast.Identifier) (string, error) {
 	if ident == nil || len(ident.Parts) == 0 {
 		return "", &TranslationError{
@@ -2816,9 +5258,11 @@ func (v *selectTranslatorVisitor) normalizeIdentifier(ident *ast.Identifier) (st
 	copy(parts, ident.Parts)
 
 	if len(parts) > 1 {
-		first := strings.ToLower(parts[0])
+		first := v.identKey(parts[0])
 		if _, ok := v.bindings[first]; ok {
 			parts = parts[1:]
+		} else if _, ok := v.outerAliases[first]; ok {
+			return "", v.correlatedReferenceError(parts[0])
 		}
 	}
 	field := strings.Join(parts, ".")
@@ -2844,9 +5288,11 @@ func (v *selectTranslatorVisitor) rawFieldName(ident *ast.Identifier) (string, e
 	parts := make([]string, len(ident.Parts))
 	copy(parts, ident.Parts)
 	if len(parts) > 1 {
-		first := strings.ToLower(parts[0])
+		first := v.identKey(parts[0])
 		if _, ok := v.bindings[first]; ok {
 			parts = parts[1:]
+		} else if _, ok := v.outerAliases[first]; ok {
+			return "", v.correlatedReferenceError(parts[0])
 		}
 	}
 	if len(parts) == 0 {
@@ -3021,12 +5467,48 @@ func convertLikePattern(pattern string) (string, error) {
 			return "*", nil
 		}
 		return formatWildcard("*" + substr + "*"), nil
+	case !underscore && strings.HasPrefix(pattern, "%") && strings.HasSuffix(pattern, "%"):
+		// %a%b% matches substrings in order, which is exactly the seq
+		// filter; it avoids the far more expensive regex fallback.
+		parts := make([]string, 0, percentCount)
+		for _, part := range strings.Split(pattern, "%") {
+			if part != "" {
+				parts = append(parts, quoteString(part))
+			}
+		}
+		if len(parts) > 1 {
+			return "seq(" + strings.Join(parts, ", ") + ")", nil
+		}
+		regex := likeToRegex(pattern)
+		return "~" + quoteString(regex), nil
 	default:
 		regex := likeToRegex(pattern)
 		return "~" + quoteString(regex), nil
 	}
 }
 
+// convertILikePattern is the case-insensitive variant of convertLikePattern.
+// Plain and prefix patterns use the i() filter; anything else falls back to
+// a regex with the (?i) flag.
+func convertILikePattern(pattern string) (string, error) {
+	percentCount := strings.Count(pattern, "%")
+	underscore := strings.Contains(pattern, "_")
+
+	switch {
+	case percentCount == 0 && !underscore:
+		return "i(" + formatString(pattern) + ")", nil
+	case percentCount == 1 && strings.HasSuffix(pattern, "%") && !underscore:
+		prefix := pattern[:len(pattern)-1]
+		if prefix == "" {
+			return "*", nil
+		}
+		return "i(" + formatWildcard(prefix+"*") + ")", nil
+	default:
+		regex := likeToRegex(pattern)
+		return "~" + quoteString("(?i)"+regex), nil
+	}
+}
+
 func formatWildcard(val string) string {
 	if needsQuoteForPattern(val) {
 		return quoteString(val)
@@ -5,6 +5,7 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"testing"
 
@@ -111,16 +112,251 @@ func TestToLogsQLSuccess(t *testing.T) {
 			sql:      "SELECT * FROM logs AS l WHERE l.level = 'error' AND l.message LIKE 'fail%'",
 			expected: "(level:error AND message:fail*)",
 		},
+		{
+			name:     "quoted dotted field is not alias-stripped",
+			sql:      `SELECT * FROM logs AS l WHERE "l.duration" > 100`,
+			expected: "l.duration:>100",
+		},
+		{
+			name:     "qualified star keeps all fields",
+			sql:      "SELECT l.* FROM logs AS l WHERE l.level = 'error'",
+			expected: "level:error",
+		},
+		{
+			name:     "qualified star with renamed column",
+			sql:      "SELECT l.*, level AS severity FROM logs AS l",
+			expected: "* | rename level as severity",
+		},
+		{
+			name:     "length function",
+			sql:      "SELECT LENGTH(message) AS msg_len FROM logs",
+			expected: "* | len (message) as msg_len | fields msg_len",
+		},
+		{
+			name:     "left function",
+			sql:      "SELECT LEFT(message, 3) AS head FROM logs",
+			expected: "* | extract_regexp '(?s)^(?P<head>.{0,3})' from message | fields head",
+		},
+		{
+			name:     "right function",
+			sql:      "SELECT RIGHT(message, 3) AS tail FROM logs",
+			expected: "* | extract_regexp '(?s)(?P<tail>.{0,3})$' from message | fields tail",
+		},
+		{
+			name:     "lpad function",
+			sql:      "SELECT LPAD(code, 5, '0') AS padded FROM logs",
+			expected: `* | format "00000<code>" as padded_padded | extract_regexp '(?s)(?P<padded>.{0,5})$' from padded_padded | delete padded_padded | fields padded`,
+		},
+		{
+			name:     "rpad function",
+			sql:      "SELECT RPAD(code, 4) AS padded FROM logs",
+			expected: `* | format "<code>    " as padded_padded | extract_regexp '(?s)^(?P<padded>.{0,4})' from padded_padded | delete padded_padded | fields padded`,
+		},
+		{
+			name:     "split_part function",
+			sql:      "SELECT SPLIT_PART(path, '/', 2) AS dir FROM logs",
+			expected: "* | extract_regexp '(?s)^(?:.*?/){1}(?P<dir>.*?)(?:/|$)' from path | fields dir",
+		},
+		{
+			name:     "regexp_extract function",
+			sql:      `SELECT REGEXP_EXTRACT(message, 'user=(\w+)') AS user FROM logs`,
+			expected: `* | extract_regexp 'user=(?P<user>\w+)' from message | fields user`,
+		},
+		{
+			name:     "from_unixtime function",
+			sql:      "SELECT FROM_UNIXTIME(ts) AS t FROM logs",
+			expected: "* | math (ts * 1000000000) as t_nanos | format '<time:t_nanos>' as t | delete t_nanos | fields t",
+		},
+		{
+			name:     "date_format function",
+			sql:      "SELECT DATE_FORMAT(_time, '%Y-%m-%d %H') AS hour FROM logs",
+			expected: `* | extract_regexp '^(?P<hour_y>[0-9]{4})-(?P<hour_mo>[0-9]{2})-(?P<hour_d>[0-9]{2})T(?P<hour_h>[0-9]{2})' from _time | format "<hour_y>-<hour_mo>-<hour_d> <hour_h>" as hour | delete hour_y, hour_mo, hour_d, hour_h | fields hour`,
+		},
+		{
+			name:     "sum over math expression",
+			sql:      "SELECT host, SUM(bytes / 1024) AS kb FROM logs GROUP BY host",
+			expected: "* | math (bytes / 1024) as __agg_expr_1 | stats by (host) sum(__agg_expr_1) kb",
+		},
+		{
+			name:     "avg over math expression with order by",
+			sql:      "SELECT host, AVG(x * y) AS score FROM logs GROUP BY host ORDER BY AVG(x * y) DESC",
+			expected: "* | math (x * y) as __agg_expr_1 | stats by (host) avg(__agg_expr_1) score | sort by (score desc)",
+		},
+		{
+			name:     "size suffix comparison",
+			sql:      "SELECT * FROM logs WHERE bytes > 10MiB",
+			expected: "bytes:>10MiB",
+		},
+		{
+			name:     "duration suffix comparison",
+			sql:      "SELECT * FROM logs WHERE duration > 1.5s AND hits >= 1k",
+			expected: "(duration:>1.5s AND hits:>=1k)",
+		},
+		{
+			name:     "tautology conjunct dropped",
+			sql:      "SELECT * FROM logs WHERE 1 = 1 AND level = 'error'",
+			expected: "level:error",
+		},
+		{
+			name:     "constant arithmetic in comparison",
+			sql:      "SELECT * FROM logs WHERE status > 2 * 250",
+			expected: "status:>500",
+		},
+		{
+			name:     "constant projection",
+			sql:      "SELECT 2 * 3 AS six FROM logs",
+			expected: "* | math 6 as six | fields six",
+		},
+		{
+			name:     "limit constant expression",
+			sql:      "SELECT * FROM logs LIMIT 10 * 10",
+			expected: "* | limit 100",
+		},
+		{
+			name:     "offset arithmetic expression",
+			sql:      "SELECT * FROM logs LIMIT 20 OFFSET 2 * (10 + 5)",
+			expected: "* | offset 30 | limit 20",
+		},
+		{
+			name:     "ansi offset fetch pagination",
+			sql:      "SELECT * FROM logs ORDER BY _time OFFSET 10 ROWS FETCH NEXT 20 ROWS ONLY",
+			expected: "* | sort by (_time) | offset 10 | limit 20",
+		},
+		{
+			name:     "fetch first without offset",
+			sql:      "SELECT * FROM logs FETCH FIRST 20 ROWS ONLY",
+			expected: "* | limit 20",
+		},
+		{
+			name:     "topk select form",
+			sql:      "SELECT TOPK(10, user) FROM logs",
+			expected: "* | top 10 by (user)",
+		},
+		{
+			name:     "topk with filter and multiple fields",
+			sql:      "SELECT TOPK(5, host, path) FROM logs WHERE level = 'error'",
+			expected: "level:error | top 5 by (host, path)",
+		},
+		{
+			name:     "approx_count_distinct aggregate",
+			sql:      "SELECT APPROX_COUNT_DISTINCT(ip) AS ips FROM logs",
+			expected: "* | stats count_uniq(ip) ips",
+		},
+		{
+			name:     "approx_count_distinct with limit",
+			sql:      "SELECT host, APPROX_COUNT_DISTINCT(ip, 1000000) AS ips FROM logs GROUP BY host",
+			expected: "* | stats by (host) count_uniq(ip) limit 1000000 ips",
+		},
+		{
+			name:     "group_concat aggregate",
+			sql:      "SELECT host, GROUP_CONCAT(path) AS paths FROM logs GROUP BY host",
+			expected: "* | stats by (host) values(path) paths",
+		},
+		{
+			name:     "array_agg distinct aggregate",
+			sql:      "SELECT host, ARRAY_AGG(DISTINCT path) AS paths FROM logs GROUP BY host",
+			expected: "* | stats by (host) uniq_values(path) paths",
+		},
+		{
+			name:     "string_agg with delimiter",
+			sql:      "SELECT host, STRING_AGG(path, ',') AS paths FROM logs GROUP BY host",
+			expected: "* | stats by (host) values(path) paths",
+		},
+		{
+			name:     "first value per group",
+			sql:      "SELECT host, FIRST(message) AS sample FROM logs GROUP BY host",
+			expected: "* | stats by (host) min(message) sample",
+		},
+		{
+			name:     "any_value aggregate",
+			sql:      "SELECT host, ANY_VALUE(message) AS sample, LAST(status) AS last_status FROM logs GROUP BY host",
+			expected: "* | stats by (host) row_any(message) sample, max(status) last_status",
+		},
+		{
+			name:     "count with filter clause",
+			sql:      "SELECT COUNT(*) FILTER (WHERE level = 'error') AS errors FROM logs",
+			expected: "* | stats count() if (level:error) errors",
+		},
+		{
+			name:     "conditional count via case",
+			sql:      "SELECT host, SUM(CASE WHEN status >= 500 THEN 1 ELSE 0 END) AS failures FROM logs GROUP BY host",
+			expected: "* | stats by (host) count() if (status:>=500) failures",
+		},
+		{
+			name:     "conditional sum via case",
+			sql:      "SELECT SUM(CASE WHEN cached = 'false' THEN bytes ELSE 0 END) AS origin_bytes FROM logs",
+			expected: "* | stats sum(bytes) if (cached:false) origin_bytes",
+		},
+		{
+			name:     "date_sub now comparison",
+			sql:      "SELECT * FROM logs WHERE _time >= DATE_SUB(NOW(), INTERVAL 1 DAY)",
+			expected: "_time:1d",
+		},
+		{
+			name:     "date_sub quoted interval",
+			sql:      "SELECT * FROM logs WHERE _time > DATE_SUB(CURRENT_TIMESTAMP, INTERVAL '15 minutes')",
+			expected: "_time:15m",
+		},
+		{
+			name:     "arithmetic comparison against literal",
+			sql:      "SELECT * FROM logs WHERE bytes_out - bytes_in > 1000",
+			expected: "* | math (bytes_out - bytes_in) as __filter_expr_1 | filter __filter_expr_1:>1000 | delete __filter_expr_1",
+		},
+		{
+			name:     "arithmetic comparison against field",
+			sql:      "SELECT * FROM logs WHERE bytes_out > bytes_in * 2",
+			expected: "* | math (bytes_in * 2) as __filter_expr_1 | filter -bytes_out:le_field(__filter_expr_1) | delete __filter_expr_1",
+		},
+		{
+			name:     "bare boolean field",
+			sql:      "SELECT * FROM logs WHERE is_error",
+			expected: "is_error:true",
+		},
+		{
+			name:     "negated bare boolean field",
+			sql:      "SELECT * FROM logs WHERE NOT is_error AND level = 'warn'",
+			expected: "(-(is_error:true) AND level:warn)",
+		},
+		{
+			name:     "equals null literal",
+			sql:      "SELECT * FROM logs WHERE trace_id = NULL",
+			expected: `trace_id:""`,
+		},
+		{
+			name:     "not equals null literal",
+			sql:      "SELECT * FROM logs WHERE trace_id <> NULL",
+			expected: "trace_id:*",
+		},
+		{
+			name:     "where on select alias",
+			sql:      "SELECT duration_ms / 1000 AS s FROM logs WHERE s > 5",
+			expected: "* | math (duration_ms / 1000) as s | fields s | filter s:>5",
+		},
+		{
+			name:     "where mixing alias and base filter",
+			sql:      "SELECT duration_ms / 1000 AS s FROM logs WHERE level = 'error' AND s > 5",
+			expected: "level:error | math (duration_ms / 1000) as s | fields s | filter s:>5",
+		},
 		{
 			name:     "range order and limit",
 			sql:      "SELECT * FROM logs WHERE _time >= '2024-01-01' ORDER BY _time DESC LIMIT 10",
-			expected: "_time:>=2024-01-01 | sort by (_time desc) | limit 10",
+			expected: "_time:>=2024-01-01 | sort by (_time desc) limit 10",
 		},
 		{
 			name:     "limit with offset",
 			sql:      "SELECT * FROM logs ORDER BY _time LIMIT 20 OFFSET 5",
 			expected: "* | sort by (_time) | offset 5 | limit 20",
 		},
+		{
+			name:     "order by collate numeric",
+			sql:      "SELECT * FROM logs ORDER BY code COLLATE NUMERIC DESC",
+			expected: "* | math code as __sort_expr_1 | sort by (__sort_expr_1 desc) | delete __sort_expr_1",
+		},
+		{
+			name:     "order by collate numeric with lexicographic",
+			sql:      "SELECT * FROM logs ORDER BY host COLLATE LEXICOGRAPHIC, code COLLATE NUMERIC LIMIT 5",
+			expected: "* | math code as __sort_expr_1 | sort by (host, __sort_expr_1) limit 5 | delete __sort_expr_1",
+		},
 		{
 			name:     "offset only",
 			sql:      "SELECT * FROM logs OFFSET 3",
@@ -131,6 +367,16 @@ func TestToLogsQLSuccess(t *testing.T) {
 			sql:      "SELECT * FROM logs WHERE service IN ('api', 'worker')",
 			expected: "service:(api OR worker)",
 		},
+		{
+			name:     "or equalities become value list",
+			sql:      "SELECT * FROM logs WHERE service = 'a' OR service = 'b' OR service = 'c'",
+			expected: "service:(a OR b OR c)",
+		},
+		{
+			name:     "or over different fields stays generic",
+			sql:      "SELECT * FROM logs WHERE service = 'a' OR host = 'b'",
+			expected: "(service:a OR host:b)",
+		},
 		{
 			name:     "is null",
 			sql:      "SELECT * FROM logs WHERE host IS NULL",
@@ -201,6 +447,31 @@ func TestToLogsQLSuccess(t *testing.T) {
 			sql:      "SELECT * FROM logs WHERE message LIKE '_foo'",
 			expected: "message:~\"^.foo$\"",
 		},
+		{
+			name:     "like ordered substrings become seq",
+			sql:      "SELECT * FROM logs WHERE message LIKE '%timeout%retry%'",
+			expected: `message:seq("timeout", "retry")`,
+		},
+		{
+			name:     "ilike exact",
+			sql:      "SELECT * FROM logs WHERE level ILIKE 'error'",
+			expected: "level:i(error)",
+		},
+		{
+			name:     "ilike prefix",
+			sql:      "SELECT * FROM logs WHERE message ILIKE 'fail%'",
+			expected: "message:i(fail*)",
+		},
+		{
+			name:     "ilike to regex",
+			sql:      "SELECT * FROM logs WHERE message ILIKE '%time_out%'",
+			expected: "message:~\"(?i)^.*time.out.*$\"",
+		},
+		{
+			name:     "not ilike",
+			sql:      "SELECT * FROM logs WHERE level NOT ILIKE 'debug'",
+			expected: "-level:i(debug)",
+		},
 		{
 			name:     "compare fields equality",
 			sql:      "SELECT * FROM logs WHERE user_id = customer_id",
@@ -357,7 +628,30 @@ SELECT * FROM recent_errors`,
     SELECT * FROM logs WHERE level = 'error'
 )
 SELECT user FROM recent_errors WHERE service = 'api'`,
-			expected: "level:error | filter service:api | fields user",
+			expected: "(level:error AND service:api) | fields user",
+		},
+		{
+			name: "with cte pushdown below stats",
+			sql: `WITH failures AS (
+    SELECT user, COUNT(*) AS fail_count
+    FROM logs
+    WHERE level = 'error'
+    GROUP BY user
+)
+SELECT user, fail_count FROM failures WHERE user = 'alice'`,
+			expected: "(level:error AND user:alice) | stats by (user) count() fail_count | fields user, fail_count",
+		},
+		{
+			name: "derived table pushdown keeps aggregate filter outside",
+			sql: `SELECT user, fail_count
+FROM (
+    SELECT user, COUNT(*) AS fail_count
+    FROM logs
+    WHERE level = 'error'
+    GROUP BY user
+) AS m
+WHERE m.user = 'alice' AND m.fail_count > 10`,
+			expected: "(level:error AND user:alice) | stats by (user) count() fail_count | filter fail_count:>10 | fields user, fail_count",
 		},
 		{
 			name:     "group by function expression",
@@ -406,7 +700,7 @@ FROM (
 WHERE recent.fail_count > 10
 ORDER BY recent.fail_count DESC
 LIMIT 5`,
-			expected: "level:error | stats by (user) count() fail_count | filter fail_count:>10 | fields user, fail_count | sort by (fail_count desc) | limit 5",
+			expected: "level:error | stats by (user) count() fail_count | filter fail_count:>10 | fields user, fail_count | sort by (fail_count desc) limit 5",
 		},
 		{
 			name: "subquery as base with filter without alias",
@@ -420,7 +714,7 @@ FROM (
 WHERE fail_count > 10
 ORDER BY fail_count DESC
 LIMIT 5`,
-			expected: "level:error | stats by (user) count() fail_count | filter fail_count:>10 | fields user, fail_count | sort by (fail_count desc) | limit 5",
+			expected: "level:error | stats by (user) count() fail_count | filter fail_count:>10 | fields user, fail_count | sort by (fail_count desc) limit 5",
 		},
 		{
 			name: "join with subquery",
@@ -435,7 +729,30 @@ INNER JOIN (
 WHERE l.level = 'error'
 ORDER BY m.fail_count DESC
 LIMIT 5`,
-			expected: "level:error | join by (user) (level:error | stats by (user) count() fail_count) inner | fields user, fail_count | sort by (fail_count desc) | limit 5",
+			expected: "level:error | join by (user) (level:error | stats by (user) count() fail_count) inner | fields user, fail_count | sort by (fail_count desc) limit 5",
+		},
+		{
+			name: "join with predicate pushed into subquery",
+			sql: `SELECT l.user, m.fail_count
+FROM logs AS l
+INNER JOIN (
+    SELECT user, COUNT(*) AS fail_count
+    FROM logs
+    WHERE level = 'error'
+    GROUP BY user
+) AS m ON l.user = m.user
+WHERE l.level = 'error' AND m.fail_count > 10
+ORDER BY m.fail_count DESC
+LIMIT 5`,
+			expected: "level:error | join by (user) (level:error | stats by (user) count() fail_count | filter fail_count:>10) inner | fields user, fail_count | sort by (fail_count desc) limit 5",
+		},
+		{
+			name: "join with predicate pushed into table",
+			sql: `SELECT l.user, a.status
+FROM logs AS l
+INNER JOIN logs AS a ON l.user = a.user
+WHERE l.level = 'error' AND a.status = 500`,
+			expected: "level:error | join by (user) (status:500) inner | fields user, status",
 		},
 		{
 			name: "join with subquery without alias",
@@ -450,7 +767,7 @@ INNER JOIN (
 WHERE l.level = 'error'
 ORDER BY fail_count DESC
 LIMIT 5`,
-			expected: "level:error | join by (user) (level:error | stats by (user) count() fail_count) inner | fields user, fail_count | sort by (fail_count desc) | limit 5",
+			expected: "level:error | join by (user) (level:error | stats by (user) count() fail_count) inner | fields user, fail_count | sort by (fail_count desc) limit 5",
 		},
 	}
 
@@ -518,6 +835,163 @@ INNER JOIN api AS a ON recent.user = a.user`
 	})
 }
 
+func TestLikeRegexFallbackWarning(t *testing.T) {
+	ts, err := tablestore.NewTableStore(map[string]string{"logs": "*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sp := store.NewStoreProvider(ts, nil)
+	stmt := parseStatement(t, "SELECT * FROM logs WHERE message LIKE '%error_%'")
+	query, warnings, err := logsql.TranslateSelectStatementToLogsQLWithWarnings(stmt, sp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != `message:~"^.*error..*$"` {
+		t.Fatalf("unexpected query: %s", query)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "regex filter") {
+		t.Fatalf("expected regex fallback warning, got %v", warnings)
+	}
+}
+
+func TestCTEMultipleReferenceWarning(t *testing.T) {
+	ts, err := tablestore.NewTableStore(map[string]string{"logs": "*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sp := store.NewStoreProvider(ts, nil)
+	sql := `WITH errs AS (SELECT user, trace_id FROM logs WHERE level = 'error')
+		SELECT a.user FROM errs AS a INNER JOIN errs AS b ON a.trace_id = b.trace_id`
+	stmt := parseStatement(t, sql)
+	_, warnings, err := logsql.TranslateSelectStatementToLogsQLWithWarnings(stmt, sp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "re-evaluated at every reference") {
+		t.Fatalf("expected duplicated CTE evaluation warning, got %v", warnings)
+	}
+
+	stmt = parseStatement(t, "WITH errs AS (SELECT user FROM logs WHERE level = 'error') SELECT user FROM errs")
+	_, warnings, err = logsql.TranslateSelectStatementToLogsQLWithWarnings(stmt, sp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 0 {
+		t.Fatalf("single CTE reference should not warn, got %v", warnings)
+	}
+}
+
+func TestCaseInsensitiveEqualityOption(t *testing.T) {
+	ts, err := tablestore.NewTableStore(map[string]string{"logs": "*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := store.NewSessionOptions()
+	if err := opts.Set("case_insensitive", "true"); err != nil {
+		t.Fatal(err)
+	}
+	sp := store.NewStoreProvider(ts, nil).WithSessionOptions(opts)
+
+	tests := []struct {
+		name     string
+		sql      string
+		expected string
+	}{
+		{
+			name:     "equality",
+			sql:      "SELECT * FROM logs WHERE level = 'Error'",
+			expected: "level:i(Error)",
+		},
+		{
+			name:     "not equal",
+			sql:      "SELECT * FROM logs WHERE level != 'Debug'",
+			expected: "-level:i(Debug)",
+		},
+		{
+			name:     "numeric comparison unaffected",
+			sql:      "SELECT * FROM logs WHERE status = 500",
+			expected: "status:500",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt := parseStatement(t, tt.sql)
+			si, err := logsql.GetStatementInfo(stmt, sp)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if si.LogsQL != tt.expected {
+				t.Fatalf("unexpected query:\nexpected: %s\n     got: %s", tt.expected, si.LogsQL)
+			}
+		})
+	}
+}
+
+func TestRecursiveCTEUnrolling(t *testing.T) {
+	ts, err := tablestore.NewTableStore(map[string]string{"logs": "*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := store.NewSessionOptions()
+	if err := opts.Set("recursive_cte_depth", "2"); err != nil {
+		t.Fatal(err)
+	}
+	sp := store.NewStoreProvider(ts, nil).WithSessionOptions(opts)
+
+	sql := `WITH RECURSIVE t AS (
+		SELECT user FROM logs WHERE level = 'error'
+		UNION ALL
+		SELECT user FROM t WHERE user != 'root'
+	) SELECT user FROM t`
+	stmt := parseStatement(t, sql)
+	si, err := logsql.GetStatementInfo(stmt, sp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "level:error | fields user" +
+		" | union ((level:error AND -user:root) | fields user)" +
+		" | union (((level:error AND -user:root) AND -user:root) | fields user)" +
+		" | fields user"
+	if si.LogsQL != expected {
+		t.Fatalf("unexpected query:\nexpected: %s\n     got: %s", expected, si.LogsQL)
+	}
+}
+
+func TestRecursiveCTEColumnAlignment(t *testing.T) {
+	ts, err := tablestore.NewTableStore(map[string]string{"logs": "*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := store.NewSessionOptions()
+	if err := opts.Set("recursive_cte_depth", "2"); err != nil {
+		t.Fatal(err)
+	}
+	sp := store.NewStoreProvider(ts, nil).WithSessionOptions(opts)
+
+	// The recursive member's expression column maps positionally onto the
+	// anchor's name, so every branch emits n and the WHERE keeps binding to
+	// the previous iteration's value.
+	sql := `WITH RECURSIVE r AS (
+		SELECT 1 AS n FROM logs
+		UNION ALL
+		SELECT n + 1 FROM r WHERE n < 3
+	) SELECT n FROM r`
+	stmt := parseStatement(t, sql)
+	si, err := logsql.GetStatementInfo(stmt, sp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	step := " | filter n:<3 | math (n + 1) as __rcte_1 | fields __rcte_1 | rename __rcte_1 as n | fields n"
+	expected := "* | math 1 as n | fields n" +
+		" | union (* | math 1 as n | fields n" + step + ")" +
+		" | union (* | math 1 as n | fields n" + step + step + ")" +
+		" | fields n"
+	if si.LogsQL != expected {
+		t.Fatalf("unexpected query:\nexpected: %s\n     got: %s", expected, si.LogsQL)
+	}
+}
+
 func TestToLogsQLErrors(t *testing.T) {
 	tests := []struct {
 		name string
@@ -527,6 +1001,46 @@ func TestToLogsQLErrors(t *testing.T) {
 			name: "unsupported distinct",
 			sql:  "SELECT DISTINCT * FROM logs",
 		},
+		{
+			name: "topk combined with limit",
+			sql:  "SELECT TOPK(10, user) FROM logs LIMIT 5",
+		},
+		{
+			name: "delete statement",
+			sql:  "DELETE FROM logs WHERE level = 'debug'",
+		},
+		{
+			name: "order by unknown collation",
+			sql:  "SELECT * FROM logs ORDER BY host COLLATE FRENCH",
+		},
+		{
+			name: "constant false where",
+			sql:  "SELECT * FROM logs WHERE 1 = 0",
+		},
+		{
+			name: "recursive cte without union all",
+			sql:  "WITH RECURSIVE t AS (SELECT user FROM logs UNION SELECT user FROM t) SELECT user FROM t",
+		},
+		{
+			name: "correlated join subquery",
+			sql:  "SELECT l.user FROM logs AS l INNER JOIN (SELECT user FROM logs WHERE l.level = 'error') AS m ON l.user = m.user",
+		},
+		{
+			name: "correlated base subquery",
+			sql:  "SELECT t.user FROM (SELECT user FROM logs WHERE t.level = 'error') AS t",
+		},
+		{
+			name: "aggregated case with non-zero else",
+			sql:  "SELECT SUM(CASE WHEN status >= 500 THEN 1 ELSE 5 END) FROM logs",
+		},
+		{
+			name: "date_sub relative to a field",
+			sql:  "SELECT * FROM logs WHERE _time >= DATE_SUB(created_at, INTERVAL 1 DAY)",
+		},
+		{
+			name: "date_sub with month interval",
+			sql:  "SELECT * FROM logs WHERE _time >= DATE_SUB(NOW(), INTERVAL 1 MONTH)",
+		},
 		{
 			name: "non logs table",
 			sql:  "SELECT * FROM users",
@@ -549,6 +1063,22 @@ func TestToLogsQLErrors(t *testing.T) {
 FROM logs l
 JOIN logs ON l.user = logs.user`,
 		},
+		{
+			name: "qualified star for unknown table",
+			sql:  "SELECT x.* FROM logs",
+		},
+		{
+			name: "alias mixed with other column in one condition",
+			sql:  "SELECT duration_ms / 1000 AS s FROM logs WHERE s > retries",
+		},
+		{
+			name: "duplicate output column",
+			sql:  "SELECT level, level FROM logs",
+		},
+		{
+			name: "alias collides with selected column",
+			sql:  "SELECT level, message AS level FROM logs",
+		},
 		{
 			name: "unsupported window function",
 			sql:  "SELECT AVG(duration_ms) OVER (ORDER BY _time) FROM logs",
@@ -992,3 +1522,865 @@ func TestRouteShowViews(t *testing.T) {
 		t.Fatalf("unexpected SHOW VIEWS payload:\nexpected: %s\nactual: %s", expected, res.Data)
 	}
 }
+
+func TestDeterministicAliasesOption(t *testing.T) {
+	ts, err := tablestore.NewTableStore(map[string]string{"logs": "*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := store.NewSessionOptions()
+	if err := opts.Set("deterministic_aliases", "true"); err != nil {
+		t.Fatal(err)
+	}
+	sp := store.NewStoreProvider(ts, nil).WithSessionOptions(opts)
+
+	translate := func(sql string) string {
+		t.Helper()
+		stmt := parseStatement(t, sql)
+		si, err := logsql.GetStatementInfo(stmt, sp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return si.LogsQL
+	}
+
+	aliasRe := regexp.MustCompile(`__filter_expr_[0-9a-f]{8}`)
+	a := translate("SELECT * FROM logs WHERE LENGTH(msg) > 10 AND UPPER(level) = 'E'")
+	b := translate("SELECT * FROM logs WHERE UPPER(level) = 'E' AND LENGTH(msg) > 10")
+
+	aliasesA := aliasRe.FindAllString(a, -1)
+	aliasesB := aliasRe.FindAllString(b, -1)
+	if len(aliasesA) == 0 || len(aliasesB) == 0 {
+		t.Fatalf("expected content-hash aliases, got:\n%s\n%s", a, b)
+	}
+	set := func(names []string) map[string]struct{} {
+		out := make(map[string]struct{}, len(names))
+		for _, name := range names {
+			out[name] = struct{}{}
+		}
+		return out
+	}
+	setA, setB := set(aliasesA), set(aliasesB)
+	if len(setA) != len(setB) {
+		t.Fatalf("alias sets differ:\n%s\n%s", a, b)
+	}
+	for name := range setA {
+		if _, ok := setB[name]; !ok {
+			t.Fatalf("alias %s missing from reordered query:\n%s\n%s", name, a, b)
+		}
+	}
+
+	// Without the option the positional numbering stays.
+	got := mustTranslate(t, "SELECT * FROM logs WHERE LENGTH(msg) > 10")
+	if !strings.Contains(got, "__filter_expr_1") {
+		t.Fatalf("expected positional alias by default, got: %s", got)
+	}
+}
+
+func TestInSubqueryFeature(t *testing.T) {
+	if _, err := translate(t, "SELECT * FROM logs WHERE user IN (SELECT user FROM logs WHERE level = 'error')"); err == nil {
+		t.Fatal("expected IN subquery to be gated by default")
+	} else if !strings.Contains(err.Error(), `"in_subquery"`) {
+		t.Fatalf("expected error to name the feature flag, got: %v", err)
+	}
+
+	ts, err := tablestore.NewTableStore(map[string]string{"logs": "*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sp := store.NewStoreProvider(ts, nil).WithFeatures([]string{"in_subquery"})
+
+	stmt := parseStatement(t, "SELECT * FROM logs WHERE user IN (SELECT user FROM logs WHERE level = 'error')")
+	si, err := logsql.GetStatementInfo(stmt, sp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "user:in(level:error | fields user)"
+	if si.LogsQL != expected {
+		t.Fatalf("unexpected query:\nexpected: %s\n     got: %s", expected, si.LogsQL)
+	}
+
+	stmt = parseStatement(t, "SELECT * FROM logs WHERE user NOT IN (SELECT user, level FROM logs)")
+	if _, err := logsql.GetStatementInfo(stmt, sp); err == nil {
+		t.Fatal("expected error for multi-column IN subquery")
+	}
+}
+
+func TestValidateFeatures(t *testing.T) {
+	if err := logsql.ValidateFeatures([]string{"in_subquery"}); err != nil {
+		t.Fatalf("expected known feature to validate, got %v", err)
+	}
+	if err := logsql.ValidateFeatures([]string{"warp_drive"}); err == nil {
+		t.Fatal("expected error for unknown feature")
+	}
+}
+
+func TestStrictModeOption(t *testing.T) {
+	ts, err := tablestore.NewTableStore(map[string]string{"logs": "*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := store.NewSessionOptions()
+	if err := opts.Set("strict_mode", "true"); err != nil {
+		t.Fatal(err)
+	}
+	strict := store.NewStoreProvider(ts, nil).WithSessionOptions(opts)
+	permissive := store.NewStoreProvider(ts, nil)
+
+	rejected := []string{
+		"SELECT * FROM logs WHERE user IS NULL",
+		"SELECT * FROM logs WHERE message LIKE 'fail%'",
+		"SELECT APPROX_COUNT_DISTINCT(ip) FROM logs",
+		"SELECT user, FIRST(msg) FROM logs GROUP BY user",
+		"SELECT user, STRING_AGG(msg, ',') FROM logs GROUP BY user",
+	}
+	for _, sql := range rejected {
+		stmt := parseStatement(t, sql)
+		_, err := logsql.GetStatementInfo(stmt, strict)
+		if err == nil {
+			t.Fatalf("expected strict mode to reject %q", sql)
+		}
+		if !strings.Contains(err.Error(), "strict mode") {
+			t.Fatalf("expected strict mode error for %q, got: %v", sql, err)
+		}
+
+		stmt = parseStatement(t, sql)
+		si, err := logsql.GetStatementInfo(stmt, permissive)
+		if err != nil {
+			t.Fatalf("permissive mode failed for %q: %v", sql, err)
+		}
+		if len(si.Warnings) == 0 {
+			t.Fatalf("expected a warning in permissive mode for %q", sql)
+		}
+	}
+
+	// Exact translations stay allowed in strict mode.
+	stmt := parseStatement(t, "SELECT * FROM logs WHERE level = 'error'")
+	si, err := logsql.GetStatementInfo(stmt, strict)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if si.LogsQL != "level:error" {
+		t.Fatalf("unexpected query: %s", si.LogsQL)
+	}
+}
+
+func TestBacktickQuotedIdentifiers(t *testing.T) {
+	got := mustTranslate(t, "SELECT `order` FROM logs WHERE `limit` > 5")
+	expected := "limit:>5 | fields order"
+	if got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestCaseSensitiveIdentifiersOption(t *testing.T) {
+	ts, err := tablestore.NewTableStore(map[string]string{"logs": "*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := store.NewSessionOptions()
+	if err := opts.Set("case_sensitive_identifiers", "true"); err != nil {
+		t.Fatal(err)
+	}
+	sensitive := store.NewStoreProvider(ts, nil).WithSessionOptions(opts)
+	insensitive := store.NewStoreProvider(ts, nil)
+
+	// By default a differently-cased qualifier still resolves the alias and
+	// column names that differ only in case collide.
+	stmt := parseStatement(t, "SELECT L.user FROM logs AS l")
+	si, err := logsql.GetStatementInfo(stmt, insensitive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if si.LogsQL != "* | fields user" {
+		t.Fatalf("unexpected query: %s", si.LogsQL)
+	}
+	stmt = parseStatement(t, "SELECT user, User FROM logs")
+	if _, err := logsql.GetStatementInfo(stmt, insensitive); err == nil {
+		t.Fatal("expected duplicate column error for user vs User")
+	}
+
+	// With case_sensitive_identifiers the two columns are distinct fields.
+	stmt = parseStatement(t, "SELECT user, User FROM logs")
+	si, err = logsql.GetStatementInfo(stmt, sensitive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if si.LogsQL != "* | fields user, User" {
+		t.Fatalf("unexpected query: %s", si.LogsQL)
+	}
+
+	// An exactly-spelled alias qualifier still resolves.
+	stmt = parseStatement(t, "SELECT l.user FROM logs AS l")
+	si, err = logsql.GetStatementInfo(stmt, sensitive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if si.LogsQL != "* | fields user" {
+		t.Fatalf("unexpected query: %s", si.LogsQL)
+	}
+
+	// CTE names no longer match case-insensitively.
+	stmt = parseStatement(t, "WITH Errs AS (SELECT user FROM logs) SELECT * FROM errs")
+	if _, err := logsql.GetStatementInfo(stmt, sensitive); err == nil {
+		t.Fatal("expected errs to miss the CTE named Errs")
+	}
+	stmt = parseStatement(t, "WITH Errs AS (SELECT user FROM logs) SELECT * FROM errs")
+	if _, err := logsql.GetStatementInfo(stmt, insensitive); err != nil {
+		t.Fatalf("case-insensitive CTE lookup failed: %v", err)
+	}
+
+	// Configured table names stay case-insensitive; they are not
+	// VictoriaLogs fields.
+	stmt = parseStatement(t, "SELECT user FROM Logs")
+	if _, err := logsql.GetStatementInfo(stmt, sensitive); err != nil {
+		t.Fatalf("table lookup should stay case-insensitive: %v", err)
+	}
+}
+
+func TestDidYouMeanSuggestions(t *testing.T) {
+	tables := map[string]string{"logs": "*", "errors": "level:ERROR"}
+
+	t.Run("table typo", func(t *testing.T) {
+		_, err := translateWithTables(t, "SELECT * FROM logz", tables)
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if !strings.Contains(err.Error(), "did you mean logs?") {
+			t.Fatalf("expected table suggestion, got %q", err.Error())
+		}
+	})
+
+	t.Run("no suggestion for distant names", func(t *testing.T) {
+		_, err := translateWithTables(t, "SELECT * FROM completely_unrelated", tables)
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if strings.Contains(err.Error(), "did you mean") {
+			t.Fatalf("expected no suggestion, got %q", err.Error())
+		}
+	})
+
+	t.Run("function typo in filter", func(t *testing.T) {
+		_, err := translateWithTables(t, "SELECT * FROM logs WHERE LOWWER(level) = 'x'", tables)
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if !strings.Contains(err.Error(), "did you mean LOWER") {
+			t.Fatalf("expected function suggestion, got %q", err.Error())
+		}
+	})
+}
+
+func TestLogsQLTableFunction(t *testing.T) {
+	if _, err := translate(t, "SELECT * FROM LOGSQL('level:error')"); err == nil {
+		t.Fatal("expected LOGSQL table function to be gated by default")
+	} else if !strings.Contains(err.Error(), `"raw_logsql"`) {
+		t.Fatalf("expected error to name the feature flag, got: %v", err)
+	}
+
+	ts, err := tablestore.NewTableStore(map[string]string{"logs": "*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sp := store.NewStoreProvider(ts, nil).WithFeatures([]string{"raw_logsql"})
+
+	run := func(sql string) (string, error) {
+		t.Helper()
+		si, err := logsql.GetStatementInfo(parseStatement(t, sql), sp)
+		if err != nil {
+			return "", err
+		}
+		return si.LogsQL, nil
+	}
+
+	got, err := run(`SELECT * FROM LOGSQL('_stream:{app="nginx"} level:error')`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `_stream:{app="nginx"} level:error`
+	if got != expected {
+		t.Fatalf("unexpected query:\nexpected: %s\n     got: %s", expected, got)
+	}
+
+	got, err = run("SELECT user FROM LOGSQL('level:error | stats by (user) count() hits') AS t WHERE t.hits > 5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, "level:error | stats by (user) count() hits") || !strings.Contains(got, "fields user") {
+		t.Fatalf("expected raw pipeline with SQL projections on top, got: %s", got)
+	}
+
+	if _, err := run("SELECT * FROM LOGSQL(42)"); err == nil {
+		t.Fatal("expected error for non-string LOGSQL argument")
+	}
+	if _, err := run("SELECT * FROM UNNEST('x')"); err == nil {
+		t.Fatal("expected error for unsupported table function")
+	}
+}
+
+func TestLogsQLFilterFunction(t *testing.T) {
+	if _, err := translate(t, "SELECT * FROM logs WHERE LOGSQL_FILTER('ip:in_range(10.0.0.0, 10.0.0.255)')"); err == nil {
+		t.Fatal("expected LOGSQL_FILTER to be gated by default")
+	} else if !strings.Contains(err.Error(), `"raw_logsql"`) {
+		t.Fatalf("expected error to name the feature flag, got: %v", err)
+	}
+
+	ts, err := tablestore.NewTableStore(map[string]string{"logs": "*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sp := store.NewStoreProvider(ts, nil).WithFeatures([]string{"raw_logsql"})
+
+	run := func(sql string) (string, error) {
+		t.Helper()
+		si, err := logsql.GetStatementInfo(parseStatement(t, sql), sp)
+		if err != nil {
+			return "", err
+		}
+		return si.LogsQL, nil
+	}
+
+	got, err := run(`SELECT * FROM logs WHERE level = 'error' AND LOGSQL_FILTER('_stream:{app="nginx"} AND ip:in_range(10.0.0.0, 10.0.0.255)')`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, `(_stream:{app="nginx"} AND ip:in_range(10.0.0.0, 10.0.0.255))`) || !strings.Contains(got, "level:error") {
+		t.Fatalf("expected raw filter spliced alongside translated filter, got: %s", got)
+	}
+
+	for _, sql := range []string{
+		`SELECT * FROM logs WHERE LOGSQL_FILTER('ip:in_range(10.0.0.0')`,
+		`SELECT * FROM logs WHERE LOGSQL_FILTER('_stream:{app="nginx}')`,
+		`SELECT * FROM logs WHERE LOGSQL_FILTER('* | delete_all')`,
+		`SELECT * FROM logs WHERE LOGSQL_FILTER(42)`,
+		`SELECT * FROM logs WHERE LOGSQL_FILTER('')`,
+	} {
+		if _, err := run(sql); err == nil {
+			t.Fatalf("expected error for %s", sql)
+		}
+	}
+}
+
+func TestValuesTableJoin(t *testing.T) {
+	got := mustTranslate(t, "SELECT * FROM logs JOIN (VALUES ('api'), ('worker')) AS t(service) ON logs.service = t.service")
+	expected := "service:(api OR worker)"
+	if got != expected {
+		t.Fatalf("unexpected query:\nexpected: %s\n     got: %s", expected, got)
+	}
+
+	got = mustTranslate(t, "SELECT user, service FROM logs JOIN (VALUES ('api'), ('worker')) AS t(service) ON logs.service = t.service WHERE level = 'error'")
+	expected = "(level:error AND service:(api OR worker)) | fields user, service"
+	if got != expected {
+		t.Fatalf("unexpected query:\nexpected: %s\n     got: %s", expected, got)
+	}
+
+	// The VALUES table also works on the left side of the join.
+	got = mustTranslate(t, "SELECT * FROM (VALUES ('api'), ('worker')) AS t(service) JOIN logs ON t.service = logs.service")
+	expected = "service:(api OR worker)"
+	if got != expected {
+		t.Fatalf("unexpected query:\nexpected: %s\n     got: %s", expected, got)
+	}
+
+	for _, tt := range []struct {
+		sql  string
+		want string
+	}{
+		{
+			sql:  "SELECT * FROM logs LEFT JOIN (VALUES ('api')) AS t(service) ON logs.service = t.service",
+			want: "INNER JOIN only",
+		},
+		{
+			sql:  "SELECT * FROM (VALUES ('api')) AS t(service)",
+			want: "cannot stand alone",
+		},
+		{
+			sql:  "SELECT * FROM logs JOIN (VALUES ('api')) AS t ON logs.service = t.service",
+			want: "requires a column list",
+		},
+		{
+			sql:  "SELECT * FROM logs JOIN (VALUES ('api')) AS t(service) ON logs.service = t.other",
+			want: "has no column",
+		},
+		{
+			sql:  "SELECT * FROM logs JOIN (VALUES ('api', 1)) AS t(service) ON logs.service = t.service",
+			want: "expected 1",
+		},
+	} {
+		_, err := translate(t, tt.sql)
+		if err == nil {
+			t.Fatalf("expected error for %s", tt.sql)
+		}
+		if !strings.Contains(err.Error(), tt.want) {
+			t.Fatalf("expected error containing %q for %s, got %v", tt.want, tt.sql, err)
+		}
+	}
+}
+
+func TestUnnestCrossJoin(t *testing.T) {
+	got := mustTranslate(t, "SELECT * FROM logs CROSS JOIN UNNEST(tags) AS t(tag)")
+	expected := "* | unroll by (tags) | rename tags as tag"
+	if got != expected {
+		t.Fatalf("unexpected query:\nexpected: %s\n     got: %s", expected, got)
+	}
+
+	got = mustTranslate(t, "SELECT tag, COUNT(*) AS n FROM logs CROSS JOIN UNNEST(tags) AS t(tag) WHERE level = 'error' GROUP BY tag")
+	expected = "level:error | unroll by (tags) | rename tags as tag | stats by (tag) count() n"
+	if got != expected {
+		t.Fatalf("unexpected query:\nexpected: %s\n     got: %s", expected, got)
+	}
+
+	// Without a column list the unrolled elements keep the source field name.
+	got = mustTranslate(t, "SELECT * FROM logs CROSS JOIN UNNEST(tags)")
+	expected = "* | unroll by (tags)"
+	if got != expected {
+		t.Fatalf("unexpected query:\nexpected: %s\n     got: %s", expected, got)
+	}
+
+	for _, tt := range []struct {
+		sql  string
+		want string
+	}{
+		{
+			sql:  "SELECT * FROM logs CROSS JOIN UNNEST(tags) WITH ORDINALITY AS t(tag, n)",
+			want: "WITH ORDINALITY is not supported",
+		},
+		{
+			sql:  "SELECT * FROM UNNEST(tags) AS t(tag)",
+			want: "must be CROSS JOINed",
+		},
+		{
+			sql:  "SELECT * FROM logs LEFT JOIN UNNEST(tags) AS t(tag) ON 1 = 1",
+			want: "requires CROSS JOIN",
+		},
+	} {
+		_, err := translate(t, tt.sql)
+		if err == nil {
+			t.Fatalf("expected error for %s", tt.sql)
+		}
+		if !strings.Contains(err.Error(), tt.want) {
+			t.Fatalf("expected error containing %q for %s, got %v", tt.want, tt.sql, err)
+		}
+	}
+}
+
+func TestPivotTranslation(t *testing.T) {
+	got := mustTranslate(t, "SELECT service FROM logs PIVOT (COUNT(*) FOR level IN ('error' AS errors, 'warn' AS warns, 'info' AS infos)) GROUP BY service")
+	expected := "* | stats by (service) count() if (level:error) errors, count() if (level:warn) warns, count() if (level:info) infos"
+	if got != expected {
+		t.Fatalf("unexpected query:\nexpected: %s\n     got: %s", expected, got)
+	}
+
+	// Unaliased string values name their columns after the value itself.
+	got = mustTranslate(t, "SELECT service FROM logs PIVOT (COUNT(*) FOR level IN ('error', 'warn')) GROUP BY service")
+	expected = "* | stats by (service) count() if (level:error) error, count() if (level:warn) warn"
+	if got != expected {
+		t.Fatalf("unexpected query:\nexpected: %s\n     got: %s", expected, got)
+	}
+
+	_, err := translate(t, "SELECT service FROM logs PIVOT (COUNT(*) FOR level IN (1)) GROUP BY service")
+	if err == nil || !strings.Contains(err.Error(), "requires an alias") {
+		t.Fatalf("expected alias error for non-string pivot value, got %v", err)
+	}
+}
+
+func TestCreateViewColumnList(t *testing.T) {
+	dir := t.TempDir()
+	tables := map[string]string{"logs": "*"}
+	out := mustTranslateWithTablesAndViews(t, "CREATE VIEW sev_by_svc (sev, svc) AS SELECT level, service FROM logs", tables, dir)
+	if out != "* | rename level as sev, service as svc | fields sev, svc" {
+		t.Fatalf("unexpected view query: %q", out)
+	}
+
+	out = mustTranslateWithTablesAndViews(t, "CREATE VIEW counts (svc, n) AS SELECT service, COUNT(*) FROM logs GROUP BY service", tables, dir)
+	if out != "* | stats by (service) count() n | rename service as svc" {
+		t.Fatalf("unexpected aggregated view query: %q", out)
+	}
+
+	if _, err := translateWithTablesAndViews(t, "CREATE VIEW bad (a) AS SELECT level, service FROM logs", tables, dir); err == nil || !strings.Contains(err.Error(), "declares 1 columns but the SELECT produces 2") {
+		t.Fatalf("expected arity error, got %v", err)
+	}
+	if _, err := translateWithTablesAndViews(t, "CREATE VIEW bad (a, b) AS SELECT * FROM logs", tables, dir); err == nil || !strings.Contains(err.Error(), "cannot apply a column list to SELECT *") {
+		t.Fatalf("expected star error, got %v", err)
+	}
+}
+
+func TestParameterizedView(t *testing.T) {
+	dir := t.TempDir()
+	tables := map[string]string{"logs": "*"}
+	out := mustTranslateWithTablesAndViews(t, "CREATE VIEW svc_errors WITH PARAMETERS (svc) AS SELECT * FROM logs WHERE level = 'error' AND service = svc", tables, dir)
+	if out != `(level:error AND service:"{{svc}}")` {
+		t.Fatalf("unexpected stored view query: %q", out)
+	}
+
+	out = mustTranslateWithTablesAndViews(t, "SELECT * FROM svc_errors WHERE svc = 'api'", tables, dir)
+	if out != `(level:error AND service:"api")` {
+		t.Fatalf("unexpected bound view query: %q", out)
+	}
+
+	// Qualified parameter bindings are consumed; the rest of the WHERE stays
+	// a regular filter.
+	out = mustTranslateWithTablesAndViews(t, "SELECT user FROM svc_errors AS e WHERE e.svc = 'worker' AND e.user != ''", tables, dir)
+	if out != `(level:error AND service:"worker") | filter -user:"" | fields user` {
+		t.Fatalf("unexpected filtered view query: %q", out)
+	}
+
+	if _, err := translateWithTablesAndViews(t, "SELECT * FROM svc_errors", tables, dir); err == nil || !strings.Contains(err.Error(), `requires parameter "svc"`) {
+		t.Fatalf("expected unbound parameter error, got %v", err)
+	}
+	if _, err := translateWithTablesAndViews(t, "SELECT * FROM svc_errors WHERE svc = 'a\"b'", tables, dir); err == nil || !strings.Contains(err.Error(), "must not contain quotes") {
+		t.Fatalf("expected quote rejection, got %v", err)
+	}
+	if _, err := translateWithTablesAndViews(t, "SELECT * FROM logs l JOIN svc_errors s ON l.user = s.user", tables, dir); err == nil || !strings.Contains(err.Error(), "can only be used as the base FROM table") {
+		t.Fatalf("expected join rejection, got %v", err)
+	}
+	if _, err := translateWithTablesAndViews(t, "CREATE VIEW dup WITH PARAMETERS (a, A) AS SELECT * FROM logs WHERE x = a", tables, dir); err == nil || !strings.Contains(err.Error(), `declares parameter "a" twice`) {
+		t.Fatalf("expected duplicate parameter error, got %v", err)
+	}
+}
+
+func TestShowTablesLikePattern(t *testing.T) {
+	ts, err := tablestore.NewTableStore(map[string]string{
+		"logs":       "*",
+		"errors":     "* | level:ERROR",
+		"error_logs": "level:error",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sp := store.NewStoreProvider(ts, nil)
+	stmt := parseStatement(t, "SHOW TABLES LIKE 'err%'")
+	res, err := logsql.GetStatementInfo(stmt, sp)
+	if err != nil {
+		t.Fatalf("GetStatementInfo failed: %v", err)
+	}
+	const expected = "{\"table_name\":\"error_logs\",\"query\":\"level:error\"}\n{\"table_name\":\"errors\",\"query\":\"* | level:ERROR\"}\n"
+	if res.Data != expected {
+		t.Fatalf("unexpected filtered payload:\nexpected: %s\nactual: %s", expected, res.Data)
+	}
+
+	stmt = parseStatement(t, "SHOW TABLES LIKE 'nothing%'")
+	res, err = logsql.GetStatementInfo(stmt, sp)
+	if err != nil {
+		t.Fatalf("GetStatementInfo failed: %v", err)
+	}
+	if res.Data != "" {
+		t.Fatalf("expected empty payload for non-matching pattern, got %q", res.Data)
+	}
+}
+
+func TestShowViewsLikePattern(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "errors.logsql"), []byte("* | level:ERROR\n"), 0o644); err != nil {
+		t.Fatalf("failed to write errors view: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "requests.logsql"), []byte("count(*)\n"), 0o644); err != nil {
+		t.Fatalf("failed to write requests view: %v", err)
+	}
+	ts, err := tablestore.NewTableStore(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vs, err := viewstore.NewViewStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sp := store.NewStoreProvider(ts, vs)
+	stmt := parseStatement(t, "SHOW VIEWS LIKE '%quest%'")
+	res, err := logsql.GetStatementInfo(stmt, sp)
+	if err != nil {
+		t.Fatalf("GetStatementInfo failed: %v", err)
+	}
+	const expected = "{\"view_name\":\"requests\",\"query\":\"count(*)\"}\n"
+	if res.Data != expected {
+		t.Fatalf("unexpected filtered payload:\nexpected: %s\nactual: %s", expected, res.Data)
+	}
+}
+
+func TestShowTablesLimitOffset(t *testing.T) {
+	ts, err := tablestore.NewTableStore(map[string]string{
+		"a_logs": "*",
+		"b_logs": "*",
+		"c_logs": "*",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sp := store.NewStoreProvider(ts, nil)
+	stmt := parseStatement(t, "SHOW TABLES LIMIT 1 OFFSET 1")
+	res, err := logsql.GetStatementInfo(stmt, sp)
+	if err != nil {
+		t.Fatalf("GetStatementInfo failed: %v", err)
+	}
+	if res.Data != "{\"table_name\":\"b_logs\",\"query\":\"*\"}\n" {
+		t.Fatalf("unexpected windowed payload: %q", res.Data)
+	}
+	if res.Result == nil {
+		t.Fatal("expected structured result")
+	}
+	if got := res.Result.Columns; len(got) != 2 || got[0] != "table_name" || got[1] != "query" {
+		t.Fatalf("unexpected result columns: %v", got)
+	}
+	if len(res.Result.Rows) != 1 || res.Result.Rows[0][0] != "b_logs" {
+		t.Fatalf("unexpected result rows: %v", res.Result.Rows)
+	}
+
+	stmt = parseStatement(t, "SHOW TABLES LIMIT -1")
+	if _, err := logsql.GetStatementInfo(stmt, sp); err == nil || !strings.Contains(err.Error(), "non-negative constant integer") {
+		t.Fatalf("expected negative limit error, got %v", err)
+	}
+}
+
+func TestUseSchemaSwitching(t *testing.T) {
+	ts, err := tablestore.NewTableStore(map[string]string{
+		"prod.logs":    "env:prod",
+		"staging.logs": "env:staging",
+		"logs":         "*",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sp := store.NewStoreProvider(ts, nil).WithSessionOptions(store.NewSessionOptions())
+
+	translate := func(sql string) string {
+		t.Helper()
+		stmt := parseStatement(t, sql)
+		si, err := logsql.GetStatementInfo(stmt, sp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return si.LogsQL
+	}
+
+	// Qualified names resolve to their schema's table without USE.
+	if got := translate("SELECT * FROM staging.logs"); got != "env:staging" {
+		t.Fatalf("unexpected qualified translation: %q", got)
+	}
+	// Unqualified names fall back to the global table until USE switches.
+	if got := translate("SELECT * FROM logs"); got != "*" {
+		t.Fatalf("unexpected unqualified translation: %q", got)
+	}
+
+	stmt := parseStatement(t, "USE prod")
+	res, err := logsql.GetStatementInfo(stmt, sp)
+	if err != nil {
+		t.Fatalf("USE failed: %v", err)
+	}
+	if res.Kind != logsql.StatementTypeUse {
+		t.Fatalf("unexpected route kind: %s", res.Kind)
+	}
+	if res.Data != "{\"name\":\"schema\",\"value\":\"prod\"}\n" {
+		t.Fatalf("unexpected USE payload: %q", res.Data)
+	}
+	if got := translate("SELECT * FROM logs"); got != "env:prod" {
+		t.Fatalf("unexpected translation after USE: %q", got)
+	}
+	// Qualified names still override the session schema.
+	if got := translate("SELECT * FROM staging.logs"); got != "env:staging" {
+		t.Fatalf("unexpected qualified translation after USE: %q", got)
+	}
+
+	stmt = parseStatement(t, "USE prodd")
+	if _, err := logsql.GetStatementInfo(stmt, sp); err == nil || !strings.Contains(err.Error(), `schema "prodd" is not configured (did you mean prod?)`) {
+		t.Fatalf("expected unknown schema error, got %v", err)
+	}
+}
+
+func TestStatementBackendRouting(t *testing.T) {
+	ts, err := tablestore.NewTableStore(map[string]string{
+		"logs":    "*",
+		"audit":   "kind:audit",
+		"eu_logs": "*",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ts.SetBackends(map[string]tablestore.Backend{
+		"audit":   {Endpoint: "http://audit:9428"},
+		"eu_logs": {Endpoint: "http://eu:9428", BearerToken: "secret"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	sp := store.NewStoreProvider(ts, nil)
+
+	info := func(sql string) (*logsql.StatementInfo, error) {
+		t.Helper()
+		return logsql.GetStatementInfo(parseStatement(t, sql), sp)
+	}
+
+	si, err := info("SELECT * FROM audit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if si.Backend == nil || si.Backend.Endpoint != "http://audit:9428" {
+		t.Fatalf("expected audit backend, got %+v", si.Backend)
+	}
+
+	si, err = info("SELECT * FROM logs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if si.Backend != nil {
+		t.Fatalf("expected default backend for logs, got %+v", si.Backend)
+	}
+
+	if _, err := info("SELECT * FROM audit a JOIN eu_logs e ON a.user = e.user"); err == nil || !strings.Contains(err.Error(), "different backends") {
+		t.Fatalf("expected cross-backend rejection, got %v", err)
+	}
+	if _, err := info("SELECT * FROM logs l JOIN audit a ON l.user = a.user"); err == nil || !strings.Contains(err.Error(), "different backends") {
+		t.Fatalf("expected default/backend mix rejection, got %v", err)
+	}
+}
+
+func TestCrossBackendUnionFanout(t *testing.T) {
+	ts, err := tablestore.NewTableStore(map[string]string{
+		"hot_logs":     "*",
+		"archive_logs": "*",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ts.SetBackends(map[string]tablestore.Backend{
+		"archive_logs": {Endpoint: "http://archive:9428"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	sp := store.NewStoreProvider(ts, nil)
+
+	si, err := logsql.GetStatementInfo(parseStatement(t, "SELECT * FROM hot_logs WHERE level = 'error' UNION ALL SELECT * FROM archive_logs WHERE level = 'error'"), sp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(si.Fanout) != 2 {
+		t.Fatalf("expected 2 fanout branches, got %d", len(si.Fanout))
+	}
+	if si.Fanout[0].LogsQL != "level:error" || si.Fanout[0].Backend != nil {
+		t.Fatalf("unexpected first branch: %+v", si.Fanout[0])
+	}
+	if si.Fanout[1].LogsQL != "level:error" || si.Fanout[1].Backend == nil || si.Fanout[1].Backend.Endpoint != "http://archive:9428" {
+		t.Fatalf("unexpected second branch: %+v", si.Fanout[1])
+	}
+	if si.LogsQL != "level:error | union (level:error)" {
+		t.Fatalf("unexpected combined query: %q", si.LogsQL)
+	}
+
+	// Three-way chains parse nested to the right and must still flatten into
+	// one branch per select.
+	si, err = logsql.GetStatementInfo(parseStatement(t, "SELECT * FROM hot_logs UNION ALL SELECT * FROM archive_logs UNION ALL SELECT * FROM hot_logs WHERE level = 'warn'"), sp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(si.Fanout) != 3 {
+		t.Fatalf("expected 3 fanout branches, got %d", len(si.Fanout))
+	}
+	if si.Fanout[2].LogsQL != "level:warn" || si.Fanout[2].Backend != nil {
+		t.Fatalf("unexpected third branch: %+v", si.Fanout[2])
+	}
+
+	// A trailing ORDER BY/LIMIT orders the whole union; it moves off the last
+	// branch into a post-processing plan applied to the merged rows.
+	si, err = logsql.GetStatementInfo(parseStatement(t, "SELECT * FROM hot_logs UNION ALL SELECT * FROM archive_logs ORDER BY _time DESC LIMIT 10"), sp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(si.Fanout) != 2 || si.Fanout[1].LogsQL != "*" {
+		t.Fatalf("expected the sort to leave the last branch, got %+v", si.Fanout)
+	}
+	if si.PostProcess == nil || len(si.PostProcess.OrderBy) != 1 || si.PostProcess.Limit != 10 {
+		t.Fatalf("unexpected post-process plan: %+v", si.PostProcess)
+	}
+	if len(si.Warnings) == 0 || !strings.Contains(si.Warnings[len(si.Warnings)-1], "merged") {
+		t.Fatalf("expected a merge-ordering warning, got %v", si.Warnings)
+	}
+
+	// Cross-backend joins inside a branch still fail.
+	if _, err := logsql.GetStatementInfo(parseStatement(t, "SELECT * FROM hot_logs h JOIN archive_logs a ON h.user = a.user UNION ALL SELECT * FROM hot_logs"), sp); err == nil || !strings.Contains(err.Error(), "different backends") {
+		t.Fatalf("expected cross-backend rejection, got %v", err)
+	}
+}
+
+func TestPostProcessFallback(t *testing.T) {
+	if _, err := translate(t, "SELECT a, b FROM logs ORDER BY a + b DESC"); err == nil {
+		t.Fatal("expected ORDER BY expression to fail without the post_process feature")
+	}
+
+	ts, err := tablestore.NewTableStore(map[string]string{"logs": "*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sp := store.NewStoreProvider(ts, nil).WithFeatures([]string{"post_process"})
+
+	stmt := parseStatement(t, "SELECT a, b FROM logs ORDER BY a + b COLLATE NUMERIC DESC LIMIT 1 OFFSET 1")
+	si, err := logsql.GetStatementInfo(stmt, sp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if si.LogsQL != "* | fields a, b" {
+		t.Fatalf("expected the peeled query to drop the sort, got: %s", si.LogsQL)
+	}
+	if si.PostProcess == nil || len(si.PostProcess.OrderBy) != 1 || si.PostProcess.Limit != 1 || si.PostProcess.Offset != 1 {
+		t.Fatalf("unexpected post-process plan: %+v", si.PostProcess)
+	}
+	if len(si.Warnings) == 0 || !strings.Contains(si.Warnings[len(si.Warnings)-1], "sorted by the server") {
+		t.Fatalf("expected a post-processing warning, got: %v", si.Warnings)
+	}
+	rows := []byte(`{"a":"1","b":"9"}` + "\n" + `{"a":"5","b":"2"}` + "\n" + `{"a":"2","b":"2"}` + "\n")
+	out, err := si.PostProcess.Apply(rows, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Sorted by a+b descending (10, 7, 4), then OFFSET 1 LIMIT 1 keeps the
+	// middle row.
+	if string(out) != `{"a":"5","b":"2"}`+"\n" {
+		t.Fatalf("unexpected post-processed rows: %q", out)
+	}
+}
+
+func TestPostProcessIntersect(t *testing.T) {
+	ts, err := tablestore.NewTableStore(map[string]string{"logs": "*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sp := store.NewStoreProvider(ts, nil).WithFeatures([]string{"post_process"})
+
+	stmt := parseStatement(t, "SELECT user FROM logs WHERE level = 'error' INTERSECT SELECT user FROM logs WHERE level = 'warn'")
+	si, err := logsql.GetStatementInfo(stmt, sp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if si.LogsQL != "level:error | fields user" {
+		t.Fatalf("unexpected base query: %s", si.LogsQL)
+	}
+	if si.PostProcess == nil || len(si.PostProcess.Intersect) != 1 || si.PostProcess.Intersect[0] != "level:warn | fields user" {
+		t.Fatalf("unexpected post-process plan: %+v", si.PostProcess)
+	}
+	main := []byte(`{"user":"alice"}` + "\n" + `{"user":"bob"}` + "\n" + `{"user":"bob"}` + "\n")
+	branch := []byte(`{"user":"bob"}` + "\n" + `{"user":"carol"}` + "\n")
+	out, err := si.PostProcess.Apply(main, [][]byte{branch})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Only the common row survives, once: INTERSECT has set semantics.
+	if string(out) != `{"user":"bob"}`+"\n" {
+		t.Fatalf("unexpected intersection: %q", out)
+	}
+}
+
+func TestPostProcessHavingEvaluation(t *testing.T) {
+	post := &logsql.PostProcess{
+		Limit: -1,
+		Having: &ast.BinaryExpr{
+			Left:     &ast.BinaryExpr{Left: &ast.Identifier{Parts: []string{"n"}}, Operator: "*", Right: &ast.NumericLiteral{Value: "2"}},
+			Operator: ">",
+			Right:    &ast.NumericLiteral{Value: "10"},
+		},
+	}
+	rows := []byte(`{"service":"api","n":"7"}` + "\n" + `{"service":"db","n":"3"}` + "\n")
+	out, err := post.Apply(rows, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != `{"service":"api","n":"7"}`+"\n" {
+		t.Fatalf("unexpected filtered rows: %q", out)
+	}
+}
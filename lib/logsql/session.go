@@ -0,0 +1,178 @@
+package logsql
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/sql/ast"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/store"
+)
+
+type sessionOptionRow struct {
+	Name  string `json:"name"`
+	Value string `json:"value,omitempty"`
+}
+
+func executeSetStatement(stmt *ast.SetStatement, sp *store.Provider) (*StatementInfo, error) {
+	opts := sp.SessionOptions()
+	if opts == nil {
+		return nil, &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: SET requires a session context",
+		}
+	}
+	if stmt.Name == nil || len(stmt.Name.Parts) == 0 || stmt.Value == nil {
+		return nil, &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: SET requires an option name and value",
+		}
+	}
+	name := strings.ToLower(strings.Join(stmt.Name.Parts, "."))
+	value, err := sessionOptionValue(stmt.Value)
+	if err != nil {
+		return nil, err
+	}
+	if err := opts.Set(name, value); err != nil {
+		return nil, &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: " + err.Error(),
+		}
+	}
+	payload, err := json.Marshal(sessionOptionRow{Name: name, Value: value})
+	if err != nil {
+		return nil, &TranslationError{
+			Code:    http.StatusInternalServerError,
+			Message: "translator: marshal SET payload",
+			Err:     err,
+		}
+	}
+	return &StatementInfo{Kind: StatementTypeSet, Data: string(payload) + "\n"}, nil
+}
+
+func executeResetStatement(stmt *ast.ResetStatement, sp *store.Provider) (*StatementInfo, error) {
+	opts := sp.SessionOptions()
+	if opts == nil {
+		return nil, &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: RESET requires a session context",
+		}
+	}
+	if stmt.Name == nil || len(stmt.Name.Parts) == 0 {
+		return nil, &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: RESET requires an option name",
+		}
+	}
+	name := strings.ToLower(strings.Join(stmt.Name.Parts, "."))
+	if err := opts.Reset(name); err != nil {
+		return nil, &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: " + err.Error(),
+		}
+	}
+	payload, err := json.Marshal(sessionOptionRow{Name: name})
+	if err != nil {
+		return nil, &TranslationError{
+			Code:    http.StatusInternalServerError,
+			Message: "translator: marshal RESET payload",
+			Err:     err,
+		}
+	}
+	return &StatementInfo{Kind: StatementTypeSet, Data: string(payload) + "\n"}, nil
+}
+
+// executeUseStatement switches the session's default schema after checking
+// that at least one configured table lives under it.
+func executeUseStatement(stmt *ast.UseStatement, sp *store.Provider) (*StatementInfo, error) {
+	opts := sp.SessionOptions()
+	if opts == nil {
+		return nil, &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: USE requires a session context",
+		}
+	}
+	if stmt.Schema == nil || len(stmt.Schema.Parts) != 1 {
+		return nil, &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: USE expects a single schema name",
+		}
+	}
+	schema := strings.ToLower(stmt.Schema.Parts[0])
+	schemas := listSchemas(sp)
+	found := false
+	for _, s := range schemas {
+		if s == schema {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, &TranslationError{
+			Code:    http.StatusNotFound,
+			Message: fmt.Sprintf("translator: schema %q is not configured%s (available: %s)", schema, didYouMean(schema, schemas), strings.Join(schemas, ", ")),
+		}
+	}
+	if err := opts.Set("schema", schema); err != nil {
+		return nil, &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: " + err.Error(),
+		}
+	}
+	payload, err := json.Marshal(sessionOptionRow{Name: "schema", Value: schema})
+	if err != nil {
+		return nil, &TranslationError{
+			Code:    http.StatusInternalServerError,
+			Message: "translator: marshal USE payload",
+			Err:     err,
+		}
+	}
+	return &StatementInfo{Kind: StatementTypeUse, Data: string(payload) + "\n"}, nil
+}
+
+// listSchemas derives the schema names from the configured tables: every
+// dotted table name prefixes its tables with a schema.
+func listSchemas(sp *store.Provider) []string {
+	ts := sp.TableStore()
+	if ts == nil {
+		return nil
+	}
+	seen := make(map[string]struct{})
+	schemas := make([]string, 0)
+	for _, name := range ts.ListTables() {
+		idx := strings.Index(name, ".")
+		if idx <= 0 {
+			continue
+		}
+		schema := name[:idx]
+		if _, ok := seen[schema]; ok {
+			continue
+		}
+		seen[schema] = struct{}{}
+		schemas = append(schemas, schema)
+	}
+	return schemas
+}
+
+// sessionOptionValue extracts the literal value of a SET statement.
+func sessionOptionValue(expr ast.Expr) (string, error) {
+	switch e := expr.(type) {
+	case *ast.StringLiteral:
+		return e.Value, nil
+	case *ast.NumericLiteral:
+		return e.Value, nil
+	case *ast.BooleanLiteral:
+		if e.Value {
+			return "true", nil
+		}
+		return "false", nil
+	case *ast.Identifier:
+		return strings.Join(e.Parts, "."), nil
+	default:
+		return "", &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("translator: SET expects a literal value, got %T", expr),
+		}
+	}
+}
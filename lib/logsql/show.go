@@ -3,91 +3,159 @@ package logsql
 import (
 	"encoding/json"
 	"net/http"
+	"regexp"
 	"strings"
 
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/sql/ast"
 	"github.com/VictoriaMetrics/sql-to-logsql/lib/store/tablestore"
 	"github.com/VictoriaMetrics/sql-to-logsql/lib/store/viewstore"
 )
 
-type showTableRow struct {
-	TableName string `json:"table_name"`
-	Query     string `json:"query,omitempty"`
+// ResultSet is the structured form of SHOW command output: a fixed column
+// list plus one string row per entry. Wire-protocol frontends consume it
+// directly; NDJSON renders the same rows for streaming callers.
+type ResultSet struct {
+	Columns []string
+	Rows    [][]string
 }
 
-func buildShowTablesPayload(ts *tablestore.TableStore) (string, error) {
+// NDJSON renders the result set as one JSON object per line. Cells holding
+// the empty string are omitted from their row, matching the omitempty
+// behavior the hand-built SHOW payloads used to have.
+func (rs *ResultSet) NDJSON() string {
+	if rs == nil || len(rs.Rows) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	for _, row := range rs.Rows {
+		b.WriteByte('{')
+		first := true
+		for i, col := range rs.Columns {
+			if i >= len(row) || (row[i] == "" && i > 0) {
+				continue
+			}
+			if !first {
+				b.WriteByte(',')
+			}
+			first = false
+			key, _ := json.Marshal(col)
+			val, _ := json.Marshal(row[i])
+			b.Write(key)
+			b.WriteByte(':')
+			b.Write(val)
+		}
+		b.WriteString("}\n")
+	}
+	return b.String()
+}
+
+func buildShowTablesResult(ts *tablestore.TableStore, stmt *ast.ShowTablesStatement) (*ResultSet, error) {
 	if ts == nil {
-		return "", &TranslationError{
+		return nil, &TranslationError{
 			Code:    http.StatusBadRequest,
 			Message: "translator: SHOW TABLES requires configured tables",
 		}
 	}
-	names := ts.ListTables()
-	if len(names) == 0 {
-		return "", nil
+	names := filterShowNames(ts.ListTables(), stmt.Pattern)
+	names, err := applyShowLimit(names, stmt.Limit)
+	if err != nil {
+		return nil, err
 	}
-	rows := strings.Builder{}
+	rs := &ResultSet{Columns: []string{"table_name", "query"}}
 	for _, name := range names {
 		query, _ := ts.GetTableQuery(name)
-		row := showTableRow{TableName: name, Query: query}
-		payload, err := json.Marshal(row)
-		if err != nil {
-			return "", &TranslationError{
-				Code:    http.StatusInternalServerError,
-				Message: "translator: marshal SHOW TABLES payload",
-				Err:     err,
-			}
-		}
-		rows.Write(payload)
-		rows.WriteByte('\n')
+		rs.Rows = append(rs.Rows, []string{name, query})
 	}
-	return rows.String(), nil
-}
-
-type showViewRow struct {
-	ViewName string `json:"view_name"`
-	Query    string `json:"query"`
+	return rs, nil
 }
 
-func buildShowViewsPayload(vs *viewstore.ViewStore) (string, error) {
+func buildShowViewsResult(vs *viewstore.ViewStore, stmt *ast.ShowViewsStatement) (*ResultSet, error) {
 	if vs == nil {
-		return "", &TranslationError{
+		return nil, &TranslationError{
 			Code:    http.StatusBadRequest,
 			Message: "translator: SHOW VIEWS requires configured views directory",
 		}
 	}
 	names, err := vs.ListViews()
 	if err != nil {
-		return "", &TranslationError{
+		return nil, &TranslationError{
 			Code:    http.StatusInternalServerError,
 			Message: "translator: list views",
 			Err:     err,
 		}
 	}
-	if len(names) == 0 {
-		return "", nil
+	names = filterShowNames(names, stmt.Pattern)
+	names, err = applyShowLimit(names, stmt.Limit)
+	if err != nil {
+		return nil, err
 	}
-	rows := strings.Builder{}
 	config, err := vs.ViewDefinitions()
 	if err != nil {
-		return "", &TranslationError{
+		return nil, &TranslationError{
 			Code:    http.StatusInternalServerError,
 			Message: "translator: load view definitions",
 			Err:     err,
 		}
 	}
+	rs := &ResultSet{Columns: []string{"view_name", "query"}}
+	for _, name := range names {
+		rs.Rows = append(rs.Rows, []string{name, config[name]})
+	}
+	return rs, nil
+}
+
+// filterShowNames keeps the names matching the LIKE pattern, or all of them
+// when no pattern was given. Matching is case-insensitive, as SQL clients
+// expect from SHOW ... LIKE.
+func filterShowNames(names []string, pattern string) []string {
+	if pattern == "" {
+		return names
+	}
+	re, err := regexp.Compile("(?i)" + likeToRegex(pattern))
+	if err != nil {
+		return nil
+	}
+	filtered := make([]string, 0, len(names))
 	for _, name := range names {
-		query := config[name]
-		row := showViewRow{ViewName: name, Query: query}
-		payload, err := json.Marshal(row)
-		if err != nil {
-			return "", &TranslationError{
-				Code:    http.StatusInternalServerError,
-				Message: "translator: marshal SHOW VIEWS payload",
+		if re.MatchString(name) {
+			filtered = append(filtered, name)
+		}
+	}
+	return filtered
+}
+
+// applyShowLimit windows the name list by the optional LIMIT/OFFSET clause.
+func applyShowLimit(names []string, limit *ast.LimitClause) ([]string, error) {
+	if limit == nil {
+		return names, nil
+	}
+	if limit.Offset != nil {
+		offset, err := foldIntegerExpr(limit.Offset)
+		if err != nil || offset < 0 {
+			return nil, &TranslationError{
+				Code:    http.StatusBadRequest,
+				Message: "translator: SHOW OFFSET expects a non-negative constant integer",
+				Err:     err,
+			}
+		}
+		if offset >= int64(len(names)) {
+			names = nil
+		} else {
+			names = names[offset:]
+		}
+	}
+	if limit.Count != nil {
+		count, err := foldIntegerExpr(limit.Count)
+		if err != nil || count < 0 {
+			return nil, &TranslationError{
+				Code:    http.StatusBadRequest,
+				Message: "translator: SHOW LIMIT expects a non-negative constant integer",
 				Err:     err,
 			}
 		}
-		rows.Write(payload)
-		rows.WriteByte('\n')
+		if count < int64(len(names)) {
+			names = names[:count]
+		}
 	}
-	return rows.String(), nil
+	return names, nil
 }
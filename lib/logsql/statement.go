@@ -3,9 +3,11 @@ package logsql
 import (
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/VictoriaMetrics/sql-to-logsql/lib/sql/ast"
 	"github.com/VictoriaMetrics/sql-to-logsql/lib/store"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/store/tablestore"
 )
 
 type StatementType string
@@ -17,12 +19,81 @@ const (
 	StatementTypeDropView   StatementType = "drop_view"
 	StatementTypeShowTables StatementType = "show_tables"
 	StatementTypeShowViews  StatementType = "show_views"
+	StatementTypeDelete     StatementType = "delete"
+	StatementTypeInsert     StatementType = "insert"
+	StatementTypeSet        StatementType = "set"
+	StatementTypeUse        StatementType = "use"
 )
 
 type StatementInfo struct {
 	Kind   StatementType
 	LogsQL string
 	Data   string
+	// Warnings lists non-fatal translation notes, e.g. aggregates that
+	// become approximate in LogsQL.
+	Warnings []string
+	// Target names the destination table for INSERT ... SELECT statements.
+	Target string
+	// Result holds the structured rows behind Data for SHOW statements, so
+	// frontends do not need to re-parse the NDJSON.
+	Result *ResultSet
+	// Backend points at the upstream cluster serving the statement's tables
+	// when one is configured; nil means the default endpoint.
+	Backend *tablestore.Backend
+	// Fanout carries the branches of a cross-backend UNION ALL, each with
+	// its own backend; the execution layer runs them separately and merges
+	// the streams. Empty for single-backend statements.
+	Fanout []FanoutQuery
+	// PostProcess describes final operations the execution layer applies to
+	// the returned rows because they have no LogsQL equivalent; nil when the
+	// whole statement translated.
+	PostProcess *PostProcess
+	// Plan lists the stages of the LogsQL query with their SQL origins; see
+	// BuildPlan.
+	Plan []PlanStep
+}
+
+// FanoutQuery is one branch of a cross-backend UNION ALL: a standalone
+// LogsQL query plus the backend it must run against (nil for the default
+// endpoint).
+type FanoutQuery struct {
+	LogsQL  string
+	Backend *tablestore.Backend
+}
+
+// translateInsertStatement handles INSERT INTO table SELECT ... by
+// translating the inner SELECT and recording the destination table. Whether
+// the result is actually ingested is decided by the execution layer, which
+// keeps INSERT opt-in.
+func translateInsertStatement(stmt *ast.InsertStatement, sp *store.Provider) (*StatementInfo, error) {
+	if stmt.Select == nil {
+		return nil, &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: only INSERT INTO table SELECT ... is supported",
+		}
+	}
+	if len(stmt.Columns) > 0 {
+		return nil, &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: INSERT column lists are not supported; project the desired fields in the SELECT instead",
+		}
+	}
+	if stmt.Table == nil || stmt.Table.Name == nil || len(stmt.Table.Name.Parts) == 0 {
+		return nil, &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: INSERT requires a destination table name",
+		}
+	}
+	query, warnings, err := TranslateSelectStatementToLogsQLWithWarnings(stmt.Select, sp)
+	if err != nil {
+		return nil, err
+	}
+	return &StatementInfo{
+		Kind:     StatementTypeInsert,
+		LogsQL:   query,
+		Warnings: warnings,
+		Target:   strings.Join(stmt.Table.Name.Parts, "."),
+	}, nil
 }
 
 func GetStatementInfo(
@@ -32,7 +103,143 @@ func GetStatementInfo(
 	if stmt == nil {
 		return nil, fmt.Errorf("translator: nil statement")
 	}
+	info, err := routeStatement(stmt, sp)
+	if err != nil {
+		fallback, ok := buildPostProcess(stmt, sp)
+		if !ok {
+			return nil, err
+		}
+		info = fallback
+	}
+	switch info.Kind {
+	case StatementTypeSelect, StatementTypeInsert, StatementTypeDescribe:
+		backend, err := resolveStatementBackend(stmt, sp)
+		if err != nil {
+			if info.Kind == StatementTypeSelect {
+				if fanout, post, ok := buildUnionFanout(stmt, sp); ok {
+					info.Fanout = fanout
+					if post != nil {
+						info.PostProcess = post
+						info.Warnings = append(info.Warnings, "the trailing ORDER BY/LIMIT of the UNION runs on the server after the per-backend results are merged")
+					}
+					return info, nil
+				}
+			}
+			return nil, err
+		}
+		info.Backend = backend
+	}
+	return info, nil
+}
+
+// buildUnionFanout splits a UNION ALL whose branches live on different
+// backends into one standalone query per branch, so each can run against its
+// own cluster. A trailing ORDER BY/LIMIT orders the whole union, which no
+// single branch can honor once the streams merge, so it is hoisted into a
+// post-processing plan applied to the merged rows. It reports false when the
+// statement is not such a union, letting the caller surface the original
+// cross-backend error.
+func buildUnionFanout(stmt ast.Statement, sp *store.Provider) ([]FanoutQuery, *PostProcess, bool) {
+	sel, ok := stmt.(*ast.SelectStatement)
+	if !ok || len(sel.SetOps) == 0 {
+		return nil, nil, false
+	}
+	branches, ok := flattenUnionBranches(sel)
+	if !ok {
+		return nil, nil, false
+	}
+	var post *PostProcess
+	last := branches[len(branches)-1]
+	if len(last.OrderBy) > 0 || last.Limit != nil {
+		if !postOrderItemsSupported(last.OrderBy) {
+			return nil, nil, false
+		}
+		trimmed := *last
+		post = &PostProcess{OrderBy: trimmed.OrderBy, Limit: -1}
+		trimmed.OrderBy = nil
+		if !movePostLimit(&trimmed, post) {
+			return nil, nil, false
+		}
+		branches[len(branches)-1] = &trimmed
+	}
+	fanout := make([]FanoutQuery, 0, len(branches))
+	for _, branch := range branches {
+		backend, err := resolveStatementBackend(branch, sp)
+		if err != nil {
+			// The branch itself mixes backends; fanning out cannot help.
+			return nil, nil, false
+		}
+		query, _, err := TranslateSelectStatementToLogsQLWithWarnings(branch, sp)
+		if err != nil {
+			return nil, nil, false
+		}
+		fanout = append(fanout, FanoutQuery{LogsQL: query, Backend: backend})
+	}
+	return fanout, post, true
+}
+
+// flattenUnionBranches expands a UNION ALL chain into its leaf selects. The
+// parser nests `a UNION ALL b UNION ALL c` to the right, so each branch's own
+// SetOps must be unwound too. It reports false for anything other than a pure
+// UNION ALL chain.
+func flattenUnionBranches(sel *ast.SelectStatement) ([]*ast.SelectStatement, bool) {
+	if sel.With != nil {
+		return nil, false
+	}
+	base := *sel
+	base.SetOps = nil
+	branches := []*ast.SelectStatement{&base}
+	for _, op := range sel.SetOps {
+		if op.Operator != ast.SetOpUnion || !op.All || op.Select == nil {
+			return nil, false
+		}
+		rest, ok := flattenUnionBranches(op.Select)
+		if !ok {
+			return nil, false
+		}
+		branches = append(branches, rest...)
+	}
+	return branches, true
+}
+
+// resolveStatementBackend picks the upstream backend for the statement's
+// tables. Tables on different backends cannot be combined in one LogsQL
+// query, so mixing them is rejected rather than silently querying one side.
+func resolveStatementBackend(stmt ast.Statement, sp *store.Provider) (*tablestore.Backend, error) {
+	ts := sp.TableStore()
+	if ts == nil || !ts.HasBackends() {
+		return nil, nil
+	}
+	var chosen *tablestore.Backend
+	var chosenTable string
+	for _, name := range ReferencedTables(stmt) {
+		if _, ok := ts.GetTableQuery(strings.ToLower(name)); !ok {
+			// Views, CTE names and aliases have no backend of their own.
+			continue
+		}
+		backend, ok := ts.GetTableBackend(name)
+		if chosen == nil && chosenTable == "" {
+			chosenTable = name
+			if ok {
+				chosen = &backend
+			}
+			continue
+		}
+		same := (chosen == nil && !ok) || (chosen != nil && ok && *chosen == backend)
+		if !same {
+			return nil, &TranslationError{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("translator: tables %s and %s live on different backends; cross-backend queries are not supported", chosenTable, name),
+			}
+		}
+	}
+	return chosen, nil
+}
 
+func routeStatement(
+	stmt ast.Statement,
+	sp *store.Provider,
+) (*StatementInfo, error) {
 	switch s := stmt.(type) {
 	case *ast.DescribeStatement:
 		query, err := GetDescribeStatementLogsQL(s, sp)
@@ -53,23 +260,38 @@ func GetStatementInfo(
 		}
 		return &StatementInfo{LogsQL: query, Kind: StatementTypeDropView}, nil
 	case *ast.ShowTablesStatement:
-		payload, err := buildShowTablesPayload(sp.TableStore())
+		result, err := buildShowTablesResult(sp.TableStore(), s)
 		if err != nil {
 			return nil, err
 		}
-		return &StatementInfo{Kind: StatementTypeShowTables, Data: payload}, nil
+		return &StatementInfo{Kind: StatementTypeShowTables, Data: result.NDJSON(), Result: result}, nil
 	case *ast.ShowViewsStatement:
-		payload, err := buildShowViewsPayload(sp.ViewStore())
+		result, err := buildShowViewsResult(sp.ViewStore(), s)
 		if err != nil {
 			return nil, err
 		}
-		return &StatementInfo{Kind: StatementTypeShowViews, Data: payload}, nil
+		return &StatementInfo{Kind: StatementTypeShowViews, Data: result.NDJSON(), Result: result}, nil
+	case *ast.InsertStatement:
+		return translateInsertStatement(s, sp)
+	case *ast.SetStatement:
+		return executeSetStatement(s, sp)
+	case *ast.UseStatement:
+		return executeUseStatement(s, sp)
+	case *ast.ResetStatement:
+		return executeResetStatement(s, sp)
+	case *ast.DeleteStatement:
+		// VictoriaLogs storage is append-only, so deletes can never be
+		// translated. Route them explicitly to keep the error actionable.
+		return nil, &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: "translator: DELETE is not supported: VictoriaLogs storage is append-only; use retention filters to expire logs instead",
+		}
 	case *ast.SelectStatement:
-		query, err := TranslateSelectStatementToLogsQL(stmt, sp)
+		query, warnings, err := TranslateSelectStatementToLogsQLWithWarnings(stmt, sp)
 		if err != nil {
 			return nil, err
 		}
-		return &StatementInfo{LogsQL: query, Kind: StatementTypeSelect}, nil
+		return &StatementInfo{LogsQL: query, Kind: StatementTypeSelect, Warnings: warnings, Plan: BuildPlan(query)}, nil
 	default:
 		return nil, &TranslationError{
 			Code:    http.StatusBadRequest,
@@ -0,0 +1,103 @@
+package logsql
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxNameSuggestions caps how many near-miss names an error message offers.
+const maxNameSuggestions = 3
+
+// scalarFunctionNames lists the scalar and math function names the translator
+// understands, used as suggestion candidates when a function is unknown.
+var scalarFunctionNames = []string{
+	"ABS", "CEIL", "CHAR_LENGTH", "CONCAT", "CURRENT_DATE", "CURRENT_TIMESTAMP",
+	"DATE_FORMAT", "EXP", "FLOOR", "FROM_UNIXTIME", "GREATEST", "JSON_VALUE",
+	"LEAST", "LEFT", "LENGTH", "LN", "LOWER", "LPAD", "LTRIM", "POW", "POWER",
+	"REGEXP_EXTRACT", "REPLACE", "RIGHT", "ROUND", "RPAD", "RTRIM", "SPLIT_PART",
+	"SUBSTR", "SUBSTRING", "TO_TIMESTAMP", "TRIM", "UPPER",
+}
+
+// windowFunctionNames lists the window functions translateWindowFunction
+// accepts.
+var windowFunctionNames = []string{"COUNT", "MAX", "MIN", "SUM"}
+
+// didYouMean returns a " (did you mean ...?)" suffix listing candidates within
+// a small edit distance of name, or "" when nothing is close enough.
+func didYouMean(name string, candidates []string) string {
+	matches := closeNameMatches(name, candidates)
+	if len(matches) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (did you mean %s?)", strings.Join(matches, ", "))
+}
+
+func closeNameMatches(name string, candidates []string) []string {
+	lower := strings.ToLower(name)
+	type scored struct {
+		name string
+		dist int
+	}
+	var matches []scored
+	seen := make(map[string]struct{})
+	for _, candidate := range candidates {
+		key := strings.ToLower(candidate)
+		if _, ok := seen[key]; ok || key == lower {
+			continue
+		}
+		seen[key] = struct{}{}
+		dist := nameEditDistance(lower, key)
+		if dist > 2 {
+			continue
+		}
+		matches = append(matches, scored{name: candidate, dist: dist})
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].dist != matches[j].dist {
+			return matches[i].dist < matches[j].dist
+		}
+		return matches[i].name < matches[j].name
+	})
+	if len(matches) > maxNameSuggestions {
+		matches = matches[:maxNameSuggestions]
+	}
+	names := make([]string, len(matches))
+	for i, m := range matches {
+		names[i] = m.name
+	}
+	return names
+}
+
+// nameEditDistance computes the Levenshtein distance between a and b.
+func nameEditDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// nameSuggestionCandidates merges configured table names with stored view
+// names for near-miss suggestions on unknown relations.
+func (v *selectTranslatorVisitor) nameSuggestionCandidates() []string {
+	names := v.sp.TableStore().ListTables()
+	if vs := v.sp.ViewStore(); vs != nil {
+		if views, err := vs.ListViews(); err == nil {
+			names = append(names, views...)
+		}
+	}
+	return names
+}
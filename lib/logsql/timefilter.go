@@ -0,0 +1,41 @@
+package logsql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AddTimeFilter narrows a translated LogsQL query to the [start, end] time
+// range by ANDing a _time filter onto the query's filter part. The pipe
+// stages, if any, are preserved unchanged. start and end must already be in
+// a format VictoriaLogs accepts (RFC3339 timestamps or relative offsets).
+func AddTimeFilter(query, start, end string) string {
+	start = strings.TrimSpace(start)
+	end = strings.TrimSpace(end)
+	if start == "" && end == "" {
+		return query
+	}
+	timeFilter := fmt.Sprintf("_time:[%s, %s]", start, end)
+	switch {
+	case start == "":
+		timeFilter = fmt.Sprintf("_time:<=%s", end)
+	case end == "":
+		timeFilter = fmt.Sprintf("_time:>=%s", start)
+	}
+
+	stages := splitPipeStages(query)
+	filter := strings.TrimSpace(stages[0])
+	if filter == "" || filter == "*" {
+		filter = timeFilter
+	} else {
+		filter = fmt.Sprintf("%s (%s)", timeFilter, filter)
+	}
+	if len(stages) == 1 {
+		return filter
+	}
+	rest := make([]string, 0, len(stages)-1)
+	for _, stage := range stages[1:] {
+		rest = append(rest, strings.TrimSpace(stage))
+	}
+	return filter + " | " + strings.Join(rest, " | ")
+}
@@ -0,0 +1,118 @@
+package logsql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidateLogsQL performs a local syntax probe of a LogsQL query without
+// contacting VictoriaLogs. It catches the common ways a stored view file can
+// rot: empty queries, unterminated string literals, unbalanced parentheses
+// and empty pipe stages. It intentionally does not understand the full LogsQL
+// grammar; queries that pass may still be rejected by VictoriaLogs.
+func ValidateLogsQL(query string) error {
+	trimmed := strings.TrimSpace(query)
+	if trimmed == "" {
+		return fmt.Errorf("logsql: query is empty")
+	}
+
+	depth := 0
+	var quote rune
+	escaped := false
+	for _, r := range trimmed {
+		if quote != 0 {
+			if escaped {
+				escaped = false
+				continue
+			}
+			switch r {
+			case '\\':
+				escaped = true
+			case quote:
+				quote = 0
+			}
+			continue
+		}
+		switch r {
+		case '"', '\'', '`':
+			quote = r
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth < 0 {
+				return fmt.Errorf("logsql: unbalanced closing parenthesis")
+			}
+		}
+	}
+	if quote != 0 {
+		return fmt.Errorf("logsql: unterminated %q quoted literal", quote)
+	}
+	if depth != 0 {
+		return fmt.Errorf("logsql: %d unclosed parenthesis(es)", depth)
+	}
+
+	for _, stage := range splitPipeStages(trimmed) {
+		if strings.TrimSpace(stage) == "" {
+			return fmt.Errorf("logsql: empty pipe stage")
+		}
+	}
+	return nil
+}
+
+// ValidateTableSpecs runs the local LogsQL syntax probe over every configured
+// table expression. A table spec is raw LogsQL that gets concatenated with
+// generated pipes, so a malformed spec silently corrupts every query against
+// that table; checking at load time turns that into an immediate, named
+// config error.
+func ValidateTableSpecs(tables map[string]string) error {
+	for name, expr := range tables {
+		if strings.TrimSpace(expr) == "" {
+			continue
+		}
+		if err := ValidateLogsQL(expr); err != nil {
+			return fmt.Errorf("table %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// splitPipeStages splits a LogsQL query on top-level '|' pipe separators,
+// ignoring pipes inside quoted literals and parentheses.
+func splitPipeStages(query string) []string {
+	stages := make([]string, 0)
+	depth := 0
+	var quote rune
+	escaped := false
+	start := 0
+	for i, r := range query {
+		if quote != 0 {
+			if escaped {
+				escaped = false
+				continue
+			}
+			switch r {
+			case '\\':
+				escaped = true
+			case quote:
+				quote = 0
+			}
+			continue
+		}
+		switch r {
+		case '"', '\'', '`':
+			quote = r
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case '|':
+			if depth == 0 {
+				stages = append(stages, query[start:i])
+				start = i + 1
+			}
+		}
+	}
+	stages = append(stages, query[start:])
+	return stages
+}
@@ -0,0 +1,59 @@
+package logsql_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/logsql"
+)
+
+func TestValidateLogsQL(t *testing.T) {
+	valid := []string{
+		"*",
+		"level:error",
+		"_time:5m | stats by (level) count() total",
+		"(level:error OR level:warn) | limit 10",
+		`message:"quoted | pipe" | fields message`,
+	}
+	for _, query := range valid {
+		if err := logsql.ValidateLogsQL(query); err != nil {
+			t.Errorf("ValidateLogsQL(%q) returned error: %v", query, err)
+		}
+	}
+
+	invalid := []string{
+		"",
+		"   ",
+		"(level:error",
+		"level:error)",
+		`message:"unterminated`,
+		"level:error | | limit 10",
+		"level:error |",
+	}
+	for _, query := range invalid {
+		if err := logsql.ValidateLogsQL(query); err == nil {
+			t.Errorf("ValidateLogsQL(%q) expected error, got nil", query)
+		}
+	}
+}
+
+func TestValidateTableSpecs(t *testing.T) {
+	if err := logsql.ValidateTableSpecs(map[string]string{
+		"logs":   "*",
+		"errors": "level:error",
+		"empty":  "",
+	}); err != nil {
+		t.Fatalf("expected valid table specs to pass, got %v", err)
+	}
+
+	err := logsql.ValidateTableSpecs(map[string]string{
+		"logs": "*",
+		"bad":  "level:error |",
+	})
+	if err == nil {
+		t.Fatal("expected error for malformed table spec")
+	}
+	if got := err.Error(); !strings.Contains(got, `table "bad"`) {
+		t.Fatalf("expected error to name the table, got %q", got)
+	}
+}
@@ -0,0 +1,243 @@
+package logsql
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/sql/ast"
+)
+
+// paramPlaceholder returns the marker embedded in stored view queries for the
+// named parameter.
+func paramPlaceholder(name string) string {
+	return "{{" + name + "}}"
+}
+
+// viewParamPattern matches the placeholders paramPlaceholder embeds.
+var viewParamPattern = regexp.MustCompile(`\{\{([a-z_][a-z0-9_]*)\}\}`)
+
+// viewParamRewriter replaces references to declared view parameters with
+// placeholder string literals, so the stored LogsQL carries {{name}} markers
+// wherever the parameter was used.
+type viewParamRewriter struct {
+	params map[string]string
+}
+
+func (r *viewParamRewriter) replace(e ast.Expr) ast.Expr {
+	id, ok := e.(*ast.Identifier)
+	if !ok || len(id.Parts) != 1 {
+		return e
+	}
+	if placeholder, ok := r.params[strings.ToLower(id.Parts[0])]; ok {
+		return &ast.StringLiteral{Value: placeholder}
+	}
+	return e
+}
+
+func (r *viewParamRewriter) Visit(node ast.Node) ast.Visitor {
+	switch n := node.(type) {
+	case *ast.BinaryExpr:
+		n.Left = r.replace(n.Left)
+		n.Right = r.replace(n.Right)
+	case *ast.UnaryExpr:
+		n.Expr = r.replace(n.Expr)
+	case *ast.FuncCall:
+		for i := range n.Args {
+			n.Args[i] = r.replace(n.Args[i])
+		}
+		n.Filter = r.replace(n.Filter)
+	case *ast.CaseExpr:
+		n.Operand = r.replace(n.Operand)
+		for i := range n.When {
+			n.When[i].Condition = r.replace(n.When[i].Condition)
+			n.When[i].Result = r.replace(n.When[i].Result)
+		}
+		n.Else = r.replace(n.Else)
+	case *ast.BetweenExpr:
+		n.Expr = r.replace(n.Expr)
+		n.Lower = r.replace(n.Lower)
+		n.Upper = r.replace(n.Upper)
+	case *ast.InExpr:
+		n.Expr = r.replace(n.Expr)
+		for i := range n.List {
+			n.List[i] = r.replace(n.List[i])
+		}
+	case *ast.LikeExpr:
+		n.Expr = r.replace(n.Expr)
+		n.Pattern = r.replace(n.Pattern)
+	case *ast.IsNullExpr:
+		n.Expr = r.replace(n.Expr)
+	}
+	return r
+}
+
+// applyViewParameters rewrites references to WITH PARAMETERS names inside the
+// view's SELECT into placeholder literals that are resolved when the view is
+// selected. The select is mutated in place; CREATE statements are single-use.
+func applyViewParameters(stmt *ast.CreateViewStatement, sel *ast.SelectStatement) (*ast.SelectStatement, error) {
+	if len(stmt.Parameters) == 0 {
+		return sel, nil
+	}
+	name := strings.Join(stmt.Name.Parts, ".")
+	params := make(map[string]string, len(stmt.Parameters))
+	for _, param := range stmt.Parameters {
+		if len(param.Parts) != 1 {
+			return nil, &TranslationError{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("translator: CREATE VIEW %s parameter %q must be an unqualified name", name, strings.Join(param.Parts, ".")),
+			}
+		}
+		key := strings.ToLower(param.Parts[0])
+		if _, dup := params[key]; dup {
+			return nil, &TranslationError{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("translator: CREATE VIEW %s declares parameter %q twice", name, key),
+			}
+		}
+		params[key] = paramPlaceholder(key)
+	}
+	ast.Walk(&viewParamRewriter{params: params}, sel)
+	return sel, nil
+}
+
+// bindViewParameters resolves {{name}} placeholders in a stored view query
+// using equality predicates on the parameter names from the WHERE clause. The
+// consumed predicates are removed from the pending WHERE so they do not also
+// become filters. qualifiers lists the table names/aliases that may prefix a
+// parameter reference.
+func (v *selectTranslatorVisitor) bindViewParameters(query, display string, qualifiers []string) (string, error) {
+	matches := viewParamPattern.FindAllStringSubmatch(query, -1)
+	if len(matches) == 0 {
+		return query, nil
+	}
+	needed := make(map[string]struct{}, len(matches))
+	for _, m := range matches {
+		needed[m[1]] = struct{}{}
+	}
+	values := make(map[string]string, len(needed))
+	remaining, err := v.extractParamBindings(v.pendingWhere, needed, qualifiers, values)
+	if err != nil {
+		return "", err
+	}
+	for name := range needed {
+		value, ok := values[name]
+		if !ok {
+			return "", &TranslationError{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("translator: view %s requires parameter %q; bind it with WHERE %s = '...'", display, name, name),
+			}
+		}
+		query = strings.ReplaceAll(query, paramPlaceholder(name), value)
+	}
+	v.pendingWhere = remaining
+	return query, nil
+}
+
+// extractParamBindings walks the AND-chain of expr, consuming `param = value`
+// predicates into values and returning whatever is left of the chain.
+func (v *selectTranslatorVisitor) extractParamBindings(expr ast.Expr, needed map[string]struct{}, qualifiers []string, values map[string]string) (ast.Expr, error) {
+	if expr == nil {
+		return nil, nil
+	}
+	bin, ok := expr.(*ast.BinaryExpr)
+	if !ok {
+		return expr, nil
+	}
+	if strings.EqualFold(bin.Operator, "AND") {
+		left, err := v.extractParamBindings(bin.Left, needed, qualifiers, values)
+		if err != nil {
+			return nil, err
+		}
+		right, err := v.extractParamBindings(bin.Right, needed, qualifiers, values)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case left == nil:
+			return right, nil
+		case right == nil:
+			return left, nil
+		default:
+			return &ast.BinaryExpr{Left: left, Operator: bin.Operator, Right: right}, nil
+		}
+	}
+	if bin.Operator != "=" {
+		return expr, nil
+	}
+	name, ok := v.paramReferenceName(bin.Left, needed, qualifiers)
+	valueExpr := bin.Right
+	if !ok {
+		name, ok = v.paramReferenceName(bin.Right, needed, qualifiers)
+		valueExpr = bin.Left
+	}
+	if !ok {
+		return expr, nil
+	}
+	value, ok := viewParamValue(valueExpr)
+	if !ok {
+		return nil, &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("translator: view parameter %s must be bound to a string or number literal", name),
+		}
+	}
+	if strings.ContainsAny(value, "\"'`\\\n") {
+		return nil, &TranslationError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("translator: view parameter %s value must not contain quotes or backslashes", name),
+		}
+	}
+	values[name] = value
+	return nil, nil
+}
+
+// paramReferenceName reports whether expr is a reference to one of the needed
+// parameters, optionally qualified by the view's name or alias.
+func (v *selectTranslatorVisitor) paramReferenceName(expr ast.Expr, needed map[string]struct{}, qualifiers []string) (string, bool) {
+	id, ok := expr.(*ast.Identifier)
+	if !ok || len(id.Parts) == 0 {
+		return "", false
+	}
+	if len(id.Parts) > 1 {
+		qualifier := v.identKey(id.Parts[0])
+		allowed := false
+		for _, q := range qualifiers {
+			if qualifier == q {
+				allowed = true
+				break
+			}
+		}
+		if len(id.Parts) > 2 || !allowed {
+			return "", false
+		}
+	}
+	name := strings.ToLower(id.Parts[len(id.Parts)-1])
+	if _, ok := needed[name]; !ok {
+		return "", false
+	}
+	return name, true
+}
+
+// rejectParameterizedJoinView refuses views with unbound placeholders on the
+// right side of a JOIN, where there is no WHERE clause of their own to bind
+// the parameters from.
+func rejectParameterizedJoinView(query, display string) error {
+	if !viewParamPattern.MatchString(query) {
+		return nil
+	}
+	return &TranslationError{
+		Code:    http.StatusBadRequest,
+		Message: fmt.Sprintf("translator: parameterized view %s can only be used as the base FROM table", display),
+	}
+}
+
+func viewParamValue(expr ast.Expr) (string, bool) {
+	switch lit := expr.(type) {
+	case *ast.StringLiteral:
+		return lit.Value, true
+	case *ast.NumericLiteral:
+		return lit.Value, true
+	}
+	return "", false
+}
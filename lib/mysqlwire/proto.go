@@ -0,0 +1,214 @@
+package mysqlwire
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strings"
+)
+
+// maxPacketSize bounds incoming packet payloads to protect against malformed
+// length prefixes. The MySQL protocol caps a single packet at 16 MiB - 1.
+const maxPacketSize = 1<<24 - 1
+
+// packetConn wraps a client connection with MySQL packet framing: a 3-byte
+// little-endian payload length followed by a 1-byte sequence number.
+type packetConn struct {
+	r   *bufio.Reader
+	w   *bufio.Writer
+	seq byte
+}
+
+func newPacketConn(conn net.Conn) *packetConn {
+	return &packetConn{r: bufio.NewReader(conn), w: bufio.NewWriter(conn)}
+}
+
+func (c *packetConn) readPacket() ([]byte, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(c.r, header[:]); err != nil {
+		return nil, err
+	}
+	length := int(header[0]) | int(header[1])<<8 | int(header[2])<<16
+	if length > maxPacketSize {
+		return nil, fmt.Errorf("mysqlwire: invalid packet length %d", length)
+	}
+	c.seq = header[3] + 1
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.r, payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+func (c *packetConn) writePacket(payload []byte) error {
+	var header [4]byte
+	header[0] = byte(len(payload))
+	header[1] = byte(len(payload) >> 8)
+	header[2] = byte(len(payload) >> 16)
+	header[3] = c.seq
+	c.seq++
+	if _, err := c.w.Write(header[:]); err != nil {
+		return err
+	}
+	_, err := c.w.Write(payload)
+	return err
+}
+
+func (c *packetConn) flush() error {
+	return c.w.Flush()
+}
+
+// pktBuilder accumulates a packet payload.
+type pktBuilder struct {
+	buf []byte
+}
+
+func (b *pktBuilder) byte(v byte) {
+	b.buf = append(b.buf, v)
+}
+
+func (b *pktBuilder) uint16(v int) {
+	b.buf = binary.LittleEndian.AppendUint16(b.buf, uint16(v))
+}
+
+func (b *pktBuilder) uint32(v int) {
+	b.buf = binary.LittleEndian.AppendUint32(b.buf, uint32(v))
+}
+
+func (b *pktBuilder) bytes(p []byte) {
+	b.buf = append(b.buf, p...)
+}
+
+func (b *pktBuilder) nullString(s string) {
+	b.buf = append(b.buf, s...)
+	b.buf = append(b.buf, 0)
+}
+
+// lenencInt appends a length-encoded integer.
+func (b *pktBuilder) lenencInt(v int) {
+	switch {
+	case v < 251:
+		b.buf = append(b.buf, byte(v))
+	case v < 1<<16:
+		b.buf = append(b.buf, 0xfc)
+		b.uint16(v)
+	default:
+		b.buf = append(b.buf, 0xfd, byte(v), byte(v>>8), byte(v>>16))
+	}
+}
+
+// lenencString appends a length-encoded string.
+func (b *pktBuilder) lenencString(s string) {
+	b.lenencInt(len(s))
+	b.buf = append(b.buf, s...)
+}
+
+func (c *packetConn) writeOK() error {
+	b := &pktBuilder{}
+	b.byte(0x00)     // OK header
+	b.byte(0)        // affected rows
+	b.byte(0)        // last insert id
+	b.uint16(0x0002) // status: autocommit
+	b.uint16(0)      // warnings
+	return c.writePacket(b.buf)
+}
+
+func (c *packetConn) writeEOF() error {
+	b := &pktBuilder{}
+	b.byte(0xfe)     // EOF header
+	b.uint16(0)      // warnings
+	b.uint16(0x0002) // status: autocommit
+	return c.writePacket(b.buf)
+}
+
+func (c *packetConn) writeError(message string) error {
+	b := &pktBuilder{}
+	b.byte(0xff)   // ERR header
+	b.uint16(1064) // ER_PARSE_ERROR; the closest generic code
+	b.byte('#')    // SQLSTATE marker
+	b.bytes([]byte("42000"))
+	b.bytes([]byte(message))
+	return c.writePacket(b.buf)
+}
+
+// charsetUTF8 is the utf8_general_ci collation identifier.
+const charsetUTF8 = 33
+
+// typeVarString is the MYSQL_TYPE_VAR_STRING column type. All result values
+// are sent as text since VictoriaLogs returns JSON strings.
+const typeVarString = 0xfd
+
+func (c *packetConn) writeColumnDefinition(name string) error {
+	b := &pktBuilder{}
+	b.lenencString("def") // catalog
+	b.lenencString("")    // schema
+	b.lenencString("")    // table
+	b.lenencString("")    // original table
+	b.lenencString(name)
+	b.lenencString(name) // original name
+	b.byte(0x0c)         // length of fixed fields
+	b.uint16(charsetUTF8)
+	b.uint32(1024) // column length
+	b.byte(typeVarString)
+	b.uint16(0) // flags
+	b.byte(0)   // decimals
+	b.uint16(0) // filler
+	return c.writePacket(b.buf)
+}
+
+func (c *packetConn) writeResultRow(columns []string, row map[string]string) error {
+	b := &pktBuilder{}
+	for _, name := range columns {
+		value, ok := row[name]
+		if !ok {
+			b.byte(0xfb) // NULL
+			continue
+		}
+		b.lenencString(value)
+	}
+	return c.writePacket(b.buf)
+}
+
+// resultRows converts a JSONL payload from VictoriaLogs into a sorted column
+// list and per-row value maps.
+func resultRows(data []byte) ([]string, []map[string]string, error) {
+	columns := make([]string, 0)
+	seen := make(map[string]struct{})
+	rows := make([]map[string]string, 0)
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		var raw map[string]any
+		if err := json.Unmarshal([]byte(line), &raw); err != nil {
+			return nil, nil, fmt.Errorf("mysqlwire: parse result row: %w", err)
+		}
+		row := make(map[string]string, len(raw))
+		for name, value := range raw {
+			if _, ok := seen[name]; !ok {
+				seen[name] = struct{}{}
+				columns = append(columns, name)
+			}
+			switch v := value.(type) {
+			case string:
+				row[name] = v
+			case nil:
+				row[name] = ""
+			default:
+				encoded, err := json.Marshal(v)
+				if err != nil {
+					return nil, nil, fmt.Errorf("mysqlwire: encode result value: %w", err)
+				}
+				row[name] = string(encoded)
+			}
+		}
+		rows = append(rows, row)
+	}
+	sort.Strings(columns)
+	return columns, rows, nil
+}
@@ -0,0 +1,337 @@
+// Package mysqlwire implements a minimal MySQL wire-protocol frontend for
+// the SQL to LogsQL translator. It lets the mysql CLI, ETL systems and other
+// MySQL-only clients query VictoriaLogs through the translator without HTTP
+// glue: incoming SQL is translated through lib/logsql, executed against
+// VictoriaLogs and streamed back as text result sets.
+//
+// The server speaks the classic protocol (HandshakeV10 + protocol 4.1
+// result sets). TLS is not advertised, clients authenticate with
+// mysql_native_password against the configured credential store, and only
+// the text protocol (COM_QUERY) is supported.
+package mysqlwire
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/logsql"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/sql/lexer"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/sql/parser"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/store"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/store/viewstore"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/vlogs"
+)
+
+// Server accepts MySQL client connections and serves translated queries.
+type Server struct {
+	sp   *store.Provider
+	api  *vlogs.API
+	opts Options
+}
+
+// Options configures the per-deployment policy hooks of the listener.
+type Options struct {
+	// LookupPassword returns the password configured for a user. It must be
+	// set: connections are rejected when no credential store is configured.
+	LookupPassword func(user string) (string, bool)
+	// PrepareLogsQL applies deployment policy (field masking, guardrails)
+	// to a translated query before execution; tables lists every table the
+	// statement references. Optional.
+	PrepareLogsQL func(logsQL string, tables []string) (string, error)
+}
+
+// NewServer returns a mysqlwire server backed by the shared store provider
+// and VictoriaLogs API client.
+func NewServer(sp *store.Provider, api *vlogs.API, opts Options) *Server {
+	return &Server{sp: sp, api: api, opts: opts}
+}
+
+// ListenAndServe listens on addr and serves connections until the listener
+// fails.
+func (s *Server) ListenAndServe(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("mysqlwire: listen %s: %w", addr, err)
+	}
+	return s.Serve(ln)
+}
+
+// Serve accepts connections from ln. Each connection is handled in its own
+// goroutine.
+func (s *Server) Serve(ln net.Listener) error {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return fmt.Errorf("mysqlwire: accept: %w", err)
+		}
+		go s.handleConn(conn)
+	}
+}
+
+func (s *Server) handleConn(conn net.Conn) {
+	defer conn.Close()
+	// The context ties upstream query lifetime to the connection: once the
+	// handler returns, in-flight queries are canceled instead of running on.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c := newPacketConn(conn)
+	if err := s.handshake(c); err != nil {
+		log.Printf("mysqlwire: handshake failed: %v", err)
+		return
+	}
+	// Each connection gets its own temporary view and option session.
+	sp := s.sp.WithSessionViews(viewstore.NewMemStore()).WithSessionOptions(store.NewSessionOptions())
+	if err := s.serveCommands(ctx, c, sp); err != nil {
+		log.Printf("mysqlwire: connection closed: %v", err)
+	}
+}
+
+// Capability flags advertised in the handshake. CLIENT_DEPRECATE_EOF is
+// deliberately omitted so result sets keep the classic EOF framing.
+const (
+	clientProtocol41           = 0x00000200
+	clientSecureConnection     = 0x00008000
+	clientPluginAuth           = 0x00080000
+	clientPluginAuthLenencData = 0x00200000
+	serverCapabilities         = clientProtocol41 | clientSecureConnection | clientPluginAuth
+)
+
+// scrambleLen is the size of the per-connection nonce sent in the handshake,
+// split across the two auth-plugin-data fields of HandshakeV10.
+const scrambleLen = 20
+
+func (s *Server) handshake(c *packetConn) error {
+	if s.opts.LookupPassword == nil {
+		_ = c.writeError("no wire credentials are configured; refusing connection")
+		_ = c.flush()
+		return errors.New("mysqlwire: no credential store configured")
+	}
+	// A fresh nonce per connection keeps captured auth responses from being
+	// replayed against later handshakes.
+	scramble := make([]byte, scrambleLen)
+	if _, err := rand.Read(scramble); err != nil {
+		return fmt.Errorf("mysqlwire: generate auth scramble: %w", err)
+	}
+	b := &pktBuilder{}
+	b.byte(10) // protocol version
+	b.nullString("8.0.0-sql-to-logsql")
+	b.uint32(0)                           // thread ID
+	b.bytes(scramble[:8])                 // auth plugin data part 1
+	b.byte(0)                             // filler
+	b.uint16(serverCapabilities & 0xffff) // capability flags, lower
+	b.byte(charsetUTF8)
+	b.uint16(0x0002)                   // status: autocommit
+	b.uint16(serverCapabilities >> 16) // capability flags, upper
+	b.byte(21)                         // auth plugin data length
+	b.bytes(make([]byte, 10))          // reserved
+	b.bytes(scramble[8:])              // auth plugin data part 2
+	b.byte(0)                          // part 2 terminator
+	b.nullString("mysql_native_password")
+	if err := c.writePacket(b.buf); err != nil {
+		return err
+	}
+	if err := c.flush(); err != nil {
+		return err
+	}
+	payload, err := c.readPacket()
+	if err != nil {
+		return err
+	}
+	user, authResponse, err := parseHandshakeResponse(payload)
+	if err != nil {
+		_ = c.writeError(err.Error())
+		_ = c.flush()
+		return fmt.Errorf("mysqlwire: %w", err)
+	}
+	password, ok := s.opts.LookupPassword(user)
+	if !ok || !checkNativePassword(password, scramble, authResponse) {
+		_ = c.writeError(fmt.Sprintf("access denied for user %q", user))
+		_ = c.flush()
+		return fmt.Errorf("mysqlwire: authentication failed for user %q", user)
+	}
+	if err := c.writeOK(); err != nil {
+		return err
+	}
+	return c.flush()
+}
+
+// parseHandshakeResponse extracts the user name and auth response from a
+// HandshakeResponse41 packet.
+func parseHandshakeResponse(payload []byte) (string, []byte, error) {
+	if len(payload) < 32 {
+		return "", nil, errors.New("short handshake response")
+	}
+	caps := binary.LittleEndian.Uint32(payload)
+	if caps&clientProtocol41 == 0 {
+		return "", nil, errors.New("client does not support protocol 4.1")
+	}
+	rest := payload[32:]
+	nul := strings.IndexByte(string(rest), 0)
+	if nul < 0 {
+		return "", nil, errors.New("malformed handshake response: missing user name")
+	}
+	user := string(rest[:nul])
+	rest = rest[nul+1:]
+	switch {
+	case caps&clientPluginAuthLenencData != 0:
+		if len(rest) == 0 || rest[0] >= 0xfb {
+			return "", nil, errors.New("malformed handshake response: bad auth data length")
+		}
+		n := int(rest[0])
+		if len(rest) < 1+n {
+			return "", nil, errors.New("malformed handshake response: truncated auth data")
+		}
+		return user, rest[1 : 1+n], nil
+	case caps&clientSecureConnection != 0:
+		if len(rest) == 0 || len(rest) < 1+int(rest[0]) {
+			return "", nil, errors.New("malformed handshake response: truncated auth data")
+		}
+		return user, rest[1 : 1+int(rest[0])], nil
+	default:
+		if n := strings.IndexByte(string(rest), 0); n >= 0 {
+			rest = rest[:n]
+		}
+		return user, rest, nil
+	}
+}
+
+// checkNativePassword verifies a mysql_native_password auth response:
+// SHA1(scramble ‖ SHA1(SHA1(password))) XOR SHA1(password). Clients send an
+// empty response for an empty password.
+func checkNativePassword(password string, scramble, response []byte) bool {
+	if password == "" {
+		return len(response) == 0
+	}
+	stage1 := sha1.Sum([]byte(password))
+	stage2 := sha1.Sum(stage1[:])
+	h := sha1.New()
+	h.Write(scramble)
+	h.Write(stage2[:])
+	expected := h.Sum(nil)
+	for i := range expected {
+		expected[i] ^= stage1[i]
+	}
+	return subtle.ConstantTimeCompare(expected, response) == 1
+}
+
+// Command codes handled in the command phase.
+const (
+	comQuit   = 0x01
+	comInitDB = 0x02
+	comQuery  = 0x03
+	comPing   = 0x0e
+)
+
+func (s *Server) serveCommands(ctx context.Context, c *packetConn, sp *store.Provider) error {
+	for {
+		c.seq = 0
+		payload, err := c.readPacket()
+		if err != nil {
+			return err
+		}
+		if len(payload) == 0 {
+			return errors.New("mysqlwire: empty command packet")
+		}
+		switch payload[0] {
+		case comQuit:
+			return nil
+		case comPing, comInitDB:
+			if err := c.writeOK(); err != nil {
+				return err
+			}
+		case comQuery:
+			s.runQuery(ctx, c, sp, string(payload[1:]))
+		default:
+			if err := c.writeError(fmt.Sprintf("unsupported command 0x%02x", payload[0])); err != nil {
+				return err
+			}
+		}
+		if err := c.flush(); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) runQuery(ctx context.Context, c *packetConn, sp *store.Provider, sql string) {
+	sql = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(sql), ";"))
+	if sql == "" || isClientSessionQuery(sql) {
+		_ = c.writeOK()
+		return
+	}
+	si, tables, err := s.translate(sp, sql)
+	if err != nil {
+		_ = c.writeError(err.Error())
+		return
+	}
+	if si.LogsQL != "" && s.opts.PrepareLogsQL != nil {
+		si.LogsQL, err = s.opts.PrepareLogsQL(si.LogsQL, tables)
+		if err != nil {
+			_ = c.writeError(err.Error())
+			return
+		}
+	}
+	data := []byte(si.Data)
+	if s.api != nil {
+		data, err = s.api.Execute(ctx, si, vlogs.EndpointConfig{})
+		if err != nil {
+			_ = c.writeError(err.Error())
+			return
+		}
+	}
+	columns, rows, err := resultRows(data)
+	if err != nil {
+		_ = c.writeError(err.Error())
+		return
+	}
+	if len(columns) == 0 {
+		// The protocol cannot describe a zero-column result set.
+		_ = c.writeOK()
+		return
+	}
+	b := &pktBuilder{}
+	b.lenencInt(len(columns))
+	_ = c.writePacket(b.buf)
+	for _, name := range columns {
+		_ = c.writeColumnDefinition(name)
+	}
+	_ = c.writeEOF()
+	for _, row := range rows {
+		_ = c.writeResultRow(columns, row)
+	}
+	_ = c.writeEOF()
+}
+
+// isClientSessionQuery reports whether sql is one of the session statements
+// MySQL clients issue automatically on connect. They are acknowledged with
+// OK instead of being translated.
+func isClientSessionQuery(sql string) bool {
+	upper := strings.ToUpper(sql)
+	return strings.HasPrefix(upper, "SET ") ||
+		strings.HasPrefix(upper, "SELECT @@") ||
+		strings.HasPrefix(upper, "USE ")
+}
+
+func (s *Server) translate(sp *store.Provider, sql string) (*logsql.StatementInfo, []string, error) {
+	l := lexer.New(sql)
+	p := parser.New(l)
+	stmt := p.ParseStatement()
+	if stmt == nil {
+		return nil, nil, errors.New("no statement parsed")
+	}
+	if perrs := p.Errors(); len(perrs) > 0 {
+		return nil, nil, fmt.Errorf("parse errors: %w", errors.Join(perrs...))
+	}
+	si, err := logsql.GetStatementInfo(stmt, sp)
+	if err != nil {
+		return nil, nil, err
+	}
+	return si, logsql.ReferencedBaseTables(stmt, sp), nil
+}
@@ -0,0 +1,197 @@
+package mysqlwire
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/store"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/store/tablestore"
+)
+
+func startTestServer(t *testing.T, opts ...Options) net.Addr {
+	t.Helper()
+	ts, err := tablestore.NewTableStore(map[string]string{"logs": "*"})
+	if err != nil {
+		t.Fatalf("NewTableStore error: %v", err)
+	}
+	sp := store.NewStoreProvider(ts, nil)
+	o := Options{LookupPassword: testPasswordLookup}
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	srv := NewServer(sp, nil, o)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen error: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go srv.Serve(ln)
+	return ln.Addr()
+}
+
+func testPasswordLookup(user string) (string, bool) {
+	if user == "test" {
+		return "secret", true
+	}
+	return "", false
+}
+
+// nativePasswordToken computes the mysql_native_password auth response for
+// a handshake scramble.
+func nativePasswordToken(scramble []byte, password string) []byte {
+	stage1 := sha1.Sum([]byte(password))
+	stage2 := sha1.Sum(stage1[:])
+	h := sha1.New()
+	h.Write(scramble)
+	h.Write(stage2[:])
+	token := h.Sum(nil)
+	for i := range token {
+		token[i] ^= stage1[i]
+	}
+	return token
+}
+
+// handshakeScramble extracts the 20-byte nonce from a HandshakeV10 payload.
+func handshakeScramble(t *testing.T, handshake []byte) []byte {
+	t.Helper()
+	i := bytes.IndexByte(handshake[1:], 0)
+	if i < 0 {
+		t.Fatalf("malformed handshake: missing server version terminator")
+	}
+	i += 2 // protocol version byte and server version terminator
+	i += 4 // thread ID
+	scramble := append([]byte(nil), handshake[i:i+8]...)
+	i += 8 + 1 + 2 + 1 + 2 + 2 + 1 + 10 // filler, caps, charset, status, caps, auth len, reserved
+	return append(scramble, handshake[i:i+12]...)
+}
+
+func readPacketT(t *testing.T, r *bufio.Reader) []byte {
+	t.Helper()
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(r, header); err != nil {
+		t.Fatalf("read packet header: %v", err)
+	}
+	length := int(header[0]) | int(header[1])<<8 | int(header[2])<<16
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		t.Fatalf("read packet payload: %v", err)
+	}
+	return payload
+}
+
+func writePacketT(t *testing.T, conn net.Conn, seq byte, payload []byte) {
+	t.Helper()
+	header := []byte{byte(len(payload)), byte(len(payload) >> 8), byte(len(payload) >> 16), seq}
+	if _, err := conn.Write(append(header, payload...)); err != nil {
+		t.Fatalf("write packet: %v", err)
+	}
+}
+
+func connectTestClient(t *testing.T, addr net.Addr) (net.Conn, *bufio.Reader) {
+	conn, r, handshakeResult := dialAndHandshake(t, addr, "test", "secret")
+	if handshakeResult[0] != 0x00 {
+		t.Fatalf("expected OK after handshake, got 0x%02x", handshakeResult[0])
+	}
+	return conn, r
+}
+
+// dialAndHandshake connects, answers the handshake with native-password
+// credentials and returns the server's OK or ERR packet.
+func dialAndHandshake(t *testing.T, addr net.Addr, user, password string) (net.Conn, *bufio.Reader, []byte) {
+	t.Helper()
+	conn, err := net.DialTimeout("tcp", addr.String(), time.Second)
+	if err != nil {
+		t.Fatalf("dial error: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	r := bufio.NewReader(conn)
+
+	handshake := readPacketT(t, r)
+	if handshake[0] != 10 {
+		t.Fatalf("expected protocol version 10, got %d", handshake[0])
+	}
+	// HandshakeResponse41 with CLIENT_PROTOCOL_41 | CLIENT_SECURE_CONNECTION.
+	resp := make([]byte, 32)
+	resp[0] = 0x00
+	resp[1] = 0x82
+	resp = append(resp, user...)
+	resp = append(resp, 0)
+	token := nativePasswordToken(handshakeScramble(t, handshake), password)
+	resp = append(resp, byte(len(token)))
+	resp = append(resp, token...)
+	writePacketT(t, conn, 1, resp)
+
+	return conn, r, readPacketT(t, r)
+}
+
+func TestQueryResultSet(t *testing.T) {
+	addr := startTestServer(t)
+	conn, r := connectTestClient(t, addr)
+
+	// COM_QUERY without configured endpoint: translation succeeds and an
+	// empty result set is acknowledged with OK.
+	writePacketT(t, conn, 0, append([]byte{comQuery}, "SELECT * FROM logs"...))
+	resp := readPacketT(t, r)
+	if resp[0] != 0x00 {
+		t.Fatalf("expected OK for empty result, got 0x%02x", resp[0])
+	}
+
+	// SHOW TABLES produces data locally, so a full result set comes back.
+	writePacketT(t, conn, 0, append([]byte{comQuery}, "SHOW TABLES"...))
+	count := readPacketT(t, r)
+	if count[0] == 0 || count[0] >= 251 {
+		t.Fatalf("expected column count packet, got 0x%02x", count[0])
+	}
+	sawEOF := 0
+	for i := 0; i < 20 && sawEOF < 2; i++ {
+		pkt := readPacketT(t, r)
+		if pkt[0] == 0xfe && len(pkt) < 9 {
+			sawEOF++
+		}
+	}
+	if sawEOF != 2 {
+		t.Fatalf("expected two EOF packets, got %d", sawEOF)
+	}
+}
+
+func TestQueryError(t *testing.T) {
+	addr := startTestServer(t)
+	conn, r := connectTestClient(t, addr)
+
+	writePacketT(t, conn, 0, append([]byte{comQuery}, "SELECT * FROM missing"...))
+	resp := readPacketT(t, r)
+	if resp[0] != 0xff {
+		t.Fatalf("expected ERR packet, got 0x%02x", resp[0])
+	}
+}
+
+func TestAuthenticationFailure(t *testing.T) {
+	addr := startTestServer(t)
+
+	_, _, resp := dialAndHandshake(t, addr, "test", "wrong")
+	if resp[0] != 0xff {
+		t.Fatalf("expected ERR for wrong password, got 0x%02x", resp[0])
+	}
+
+	_, _, resp = dialAndHandshake(t, addr, "nobody", "secret")
+	if resp[0] != 0xff {
+		t.Fatalf("expected ERR for unknown user, got 0x%02x", resp[0])
+	}
+}
+
+func TestPing(t *testing.T) {
+	addr := startTestServer(t)
+	conn, r := connectTestClient(t, addr)
+
+	writePacketT(t, conn, 0, []byte{comPing})
+	resp := readPacketT(t, r)
+	if resp[0] != 0x00 {
+		t.Fatalf("expected OK for ping, got 0x%02x", resp[0])
+	}
+}
@@ -0,0 +1,268 @@
+package pgwire
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strings"
+)
+
+// maxMessageSize bounds incoming message payloads to protect against
+// malformed length prefixes.
+const maxMessageSize = 16 << 20
+
+// wireConn wraps a client connection with buffered, message-oriented reads
+// and writes.
+type wireConn struct {
+	r *bufio.Reader
+	w *bufio.Writer
+}
+
+func newWireConn(conn net.Conn) *wireConn {
+	return &wireConn{r: bufio.NewReader(conn), w: bufio.NewWriter(conn)}
+}
+
+// readStartupMessage reads a length-prefixed startup message (no type byte).
+func (c *wireConn) readStartupMessage() ([]byte, error) {
+	var lenBuf [4]byte
+	if _, err := io.ReadFull(c.r, lenBuf[:]); err != nil {
+		return nil, err
+	}
+	length := int(beUint32(lenBuf[:]))
+	if length < 4 || length > maxMessageSize {
+		return nil, fmt.Errorf("pgwire: invalid startup message length %d", length)
+	}
+	payload := make([]byte, length-4)
+	if _, err := io.ReadFull(c.r, payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// readMessage reads a regular typed message.
+func (c *wireConn) readMessage() (byte, []byte, error) {
+	msgType, err := c.r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	var lenBuf [4]byte
+	if _, err := io.ReadFull(c.r, lenBuf[:]); err != nil {
+		return 0, nil, err
+	}
+	length := int(beUint32(lenBuf[:]))
+	if length < 4 || length > maxMessageSize {
+		return 0, nil, fmt.Errorf("pgwire: invalid message length %d", length)
+	}
+	payload := make([]byte, length-4)
+	if _, err := io.ReadFull(c.r, payload); err != nil {
+		return 0, nil, err
+	}
+	return msgType, payload, nil
+}
+
+// msgBuilder accumulates a message payload.
+type msgBuilder struct {
+	buf []byte
+}
+
+func (b *msgBuilder) int16(v int) {
+	b.buf = binary.BigEndian.AppendUint16(b.buf, uint16(v))
+}
+
+func (b *msgBuilder) int32(v int) {
+	b.buf = binary.BigEndian.AppendUint32(b.buf, uint32(v))
+}
+
+func (b *msgBuilder) cstring(s string) {
+	b.buf = append(b.buf, s...)
+	b.buf = append(b.buf, 0)
+}
+
+func (b *msgBuilder) bytes(p []byte) {
+	b.buf = append(b.buf, p...)
+}
+
+func (c *wireConn) writeMessage(msgType byte, fill func(*msgBuilder)) {
+	b := &msgBuilder{}
+	if fill != nil {
+		fill(b)
+	}
+	_ = c.w.WriteByte(msgType)
+	var lenBuf [4]byte
+	binary.BigEndian.PutUint32(lenBuf[:], uint32(len(b.buf)+4))
+	_, _ = c.w.Write(lenBuf[:])
+	_, _ = c.w.Write(b.buf)
+}
+
+func (c *wireConn) writeByte(v byte) error {
+	if err := c.w.WriteByte(v); err != nil {
+		return err
+	}
+	return c.w.Flush()
+}
+
+func (c *wireConn) writeReadyForQuery() error {
+	c.writeMessage('Z', func(b *msgBuilder) {
+		b.bytes([]byte{'I'}) // idle, no transaction support
+	})
+	return c.flush()
+}
+
+func (c *wireConn) flush() error {
+	return c.w.Flush()
+}
+
+func (c *wireConn) writeError(message string) {
+	c.writeMessage('E', func(b *msgBuilder) {
+		b.bytes([]byte{'S'})
+		b.cstring("ERROR")
+		b.bytes([]byte{'C'})
+		b.cstring("42601") // syntax_error; the closest generic SQLSTATE
+		b.bytes([]byte{'M'})
+		b.cstring(message)
+		b.bytes([]byte{0})
+	})
+}
+
+// textOID is the PostgreSQL OID for the text type. All result values are
+// sent as text since VictoriaLogs returns JSON strings.
+const textOID = 25
+
+func (c *wireConn) writeRowDescription(columns []string) {
+	c.writeMessage('T', func(b *msgBuilder) {
+		b.int16(len(columns))
+		for _, name := range columns {
+			b.cstring(name)
+			b.int32(0) // table OID
+			b.int16(0) // column attribute number
+			b.int32(textOID)
+			b.int16(-1) // type size (variable)
+			b.int32(-1) // type modifier
+			b.int16(0)  // text format
+		}
+	})
+}
+
+func (c *wireConn) writeDataRow(columns []string, row map[string]string) {
+	c.writeMessage('D', func(b *msgBuilder) {
+		b.int16(len(columns))
+		for _, name := range columns {
+			value, ok := row[name]
+			if !ok {
+				b.int32(-1) // NULL
+				continue
+			}
+			b.int32(len(value))
+			b.bytes([]byte(value))
+		}
+	})
+}
+
+// msgReader consumes fields from a message payload.
+type msgReader struct {
+	buf []byte
+	pos int
+}
+
+func (r *msgReader) cstring() string {
+	start := r.pos
+	for r.pos < len(r.buf) && r.buf[r.pos] != 0 {
+		r.pos++
+	}
+	s := string(r.buf[start:r.pos])
+	if r.pos < len(r.buf) {
+		r.pos++ // skip terminator
+	}
+	return s
+}
+
+func (r *msgReader) byte() byte {
+	if r.pos >= len(r.buf) {
+		return 0
+	}
+	b := r.buf[r.pos]
+	r.pos++
+	return b
+}
+
+func (r *msgReader) int16() int {
+	if r.pos+2 > len(r.buf) {
+		r.pos = len(r.buf)
+		return 0
+	}
+	v := int(int16(binary.BigEndian.Uint16(r.buf[r.pos:])))
+	r.pos += 2
+	return v
+}
+
+func (r *msgReader) int32() int {
+	if r.pos+4 > len(r.buf) {
+		r.pos = len(r.buf)
+		return 0
+	}
+	v := int(int32(binary.BigEndian.Uint32(r.buf[r.pos:])))
+	r.pos += 4
+	return v
+}
+
+func (r *msgReader) bytesN(n int) []byte {
+	if n < 0 || r.pos+n > len(r.buf) {
+		n = len(r.buf) - r.pos
+	}
+	p := r.buf[r.pos : r.pos+n]
+	r.pos += n
+	return p
+}
+
+func cstringAt(payload []byte, offset int) string {
+	r := &msgReader{buf: payload, pos: offset}
+	return r.cstring()
+}
+
+func beUint32(p []byte) uint32 {
+	return binary.BigEndian.Uint32(p)
+}
+
+// resultRows converts a JSONL payload from VictoriaLogs into a sorted column
+// list and per-row value maps.
+func resultRows(data []byte) ([]string, []map[string]string, error) {
+	columns := make([]string, 0)
+	seen := make(map[string]struct{})
+	rows := make([]map[string]string, 0)
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		var raw map[string]any
+		if err := json.Unmarshal([]byte(line), &raw); err != nil {
+			return nil, nil, fmt.Errorf("pgwire: parse result row: %w", err)
+		}
+		row := make(map[string]string, len(raw))
+		for name, value := range raw {
+			if _, ok := seen[name]; !ok {
+				seen[name] = struct{}{}
+				columns = append(columns, name)
+			}
+			switch v := value.(type) {
+			case string:
+				row[name] = v
+			case nil:
+				row[name] = ""
+			default:
+				encoded, err := json.Marshal(v)
+				if err != nil {
+					return nil, nil, fmt.Errorf("pgwire: encode result value: %w", err)
+				}
+				row[name] = string(encoded)
+			}
+		}
+		rows = append(rows, row)
+	}
+	sort.Strings(columns)
+	return columns, rows, nil
+}
@@ -0,0 +1,456 @@
+// Package pgwire implements a minimal PostgreSQL wire-protocol frontend for
+// the SQL to LogsQL translator. It lets psql, Grafana's Postgres datasource
+// and similar tools connect directly: incoming SQL is translated through
+// lib/logsql, executed against VictoriaLogs and streamed back as row
+// descriptions and data rows.
+//
+// The server speaks protocol version 3.0. SSL is not supported (SSLRequest
+// is answered with 'N'), clients authenticate with a cleartext password
+// against the configured credential store, and the extended query protocol
+// supports text-format parameter binding.
+package pgwire
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/logsql"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/sql/lexer"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/sql/parser"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/store"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/store/viewstore"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/vlogs"
+)
+
+// Server accepts PostgreSQL client connections and serves translated
+// queries.
+type Server struct {
+	sp   *store.Provider
+	api  *vlogs.API
+	opts Options
+}
+
+// Options configures the per-deployment policy hooks of the listener.
+type Options struct {
+	// LookupPassword returns the password configured for a user. It must be
+	// set: connections are rejected when no credential store is configured.
+	LookupPassword func(user string) (string, bool)
+	// PrepareLogsQL applies deployment policy (field masking, guardrails)
+	// to a translated query before execution; tables lists every table the
+	// statement references. Optional.
+	PrepareLogsQL func(logsQL string, tables []string) (string, error)
+}
+
+// NewServer returns a pgwire server backed by the shared store provider and
+// VictoriaLogs API client.
+func NewServer(sp *store.Provider, api *vlogs.API, opts Options) *Server {
+	return &Server{sp: sp, api: api, opts: opts}
+}
+
+// ListenAndServe listens on addr and serves connections until the listener
+// fails.
+func (s *Server) ListenAndServe(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("pgwire: listen %s: %w", addr, err)
+	}
+	return s.Serve(ln)
+}
+
+// Serve accepts connections from ln. Each connection is handled in its own
+// goroutine.
+func (s *Server) Serve(ln net.Listener) error {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return fmt.Errorf("pgwire: accept: %w", err)
+		}
+		go s.handleConn(conn)
+	}
+}
+
+func (s *Server) handleConn(conn net.Conn) {
+	defer conn.Close()
+	// The context ties upstream query lifetime to the connection: once the
+	// handler returns, in-flight queries are canceled instead of running on.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c := newWireConn(conn)
+	if err := s.handshake(c); err != nil {
+		if !errors.Is(err, errCancelRequest) {
+			log.Printf("pgwire: handshake failed: %v", err)
+		}
+		return
+	}
+	// Each connection gets its own temporary view and option session.
+	sp := s.sp.WithSessionViews(viewstore.NewMemStore()).WithSessionOptions(store.NewSessionOptions())
+	if err := s.serveMessages(ctx, c, sp); err != nil {
+		log.Printf("pgwire: connection closed: %v", err)
+	}
+}
+
+var errCancelRequest = errors.New("pgwire: cancel request")
+
+const (
+	protocolVersion30 = 196608
+	sslRequestCode    = 80877103
+	cancelRequestCode = 80877102
+	gssEncRequestCode = 80877104
+)
+
+func (s *Server) handshake(c *wireConn) error {
+	for {
+		payload, err := c.readStartupMessage()
+		if err != nil {
+			return err
+		}
+		if len(payload) < 4 {
+			return errors.New("pgwire: short startup message")
+		}
+		code := int(beUint32(payload[:4]))
+		switch code {
+		case sslRequestCode, gssEncRequestCode:
+			// Encryption is not supported; ask the client to continue in
+			// plaintext.
+			if err := c.writeByte('N'); err != nil {
+				return err
+			}
+		case cancelRequestCode:
+			return errCancelRequest
+		case protocolVersion30:
+			return s.completeStartup(c, payload[4:])
+		default:
+			return fmt.Errorf("pgwire: unsupported protocol version %d", code)
+		}
+	}
+}
+
+func (s *Server) completeStartup(c *wireConn, startup []byte) error {
+	if err := s.authenticate(c, startupParam(startup, "user")); err != nil {
+		return err
+	}
+	c.writeMessage('R', func(b *msgBuilder) {
+		b.int32(0) // AuthenticationOk
+	})
+	params := map[string]string{
+		"server_version":    "14.0 (sql-to-logsql)",
+		"client_encoding":   "UTF8",
+		"DateStyle":         "ISO",
+		"integer_datetimes": "on",
+	}
+	for name, value := range params {
+		c.writeMessage('S', func(b *msgBuilder) {
+			b.cstring(name)
+			b.cstring(value)
+		})
+	}
+	c.writeMessage('K', func(b *msgBuilder) {
+		b.int32(0) // process ID
+		b.int32(0) // secret key (cancellation is not supported)
+	})
+	return c.writeReadyForQuery()
+}
+
+// authenticate runs the cleartext-password exchange against the configured
+// credential store. Connections are refused outright when no store is
+// configured, so the listener cannot be exposed without credentials.
+func (s *Server) authenticate(c *wireConn, user string) error {
+	if s.opts.LookupPassword == nil {
+		c.writeError("no wire credentials are configured; refusing connection")
+		c.flush()
+		return errors.New("pgwire: no credential store configured")
+	}
+	c.writeMessage('R', func(b *msgBuilder) {
+		b.int32(3) // AuthenticationCleartextPassword
+	})
+	if err := c.flush(); err != nil {
+		return err
+	}
+	msgType, payload, err := c.readMessage()
+	if err != nil {
+		return err
+	}
+	if msgType != 'p' {
+		c.writeError("expected a password message")
+		c.flush()
+		return fmt.Errorf("pgwire: expected password message, got %q", msgType)
+	}
+	password := cstringAt(payload, 0)
+	want, ok := s.opts.LookupPassword(user)
+	if !ok || subtle.ConstantTimeCompare([]byte(want), []byte(password)) != 1 {
+		c.writeError(fmt.Sprintf("password authentication failed for user %q", user))
+		c.flush()
+		return fmt.Errorf("pgwire: authentication failed for user %q", user)
+	}
+	return nil
+}
+
+// startupParam extracts a parameter from the key/value pairs of a startup
+// message.
+func startupParam(params []byte, key string) string {
+	r := &msgReader{buf: params}
+	for {
+		name := r.cstring()
+		if name == "" {
+			return ""
+		}
+		value := r.cstring()
+		if name == key {
+			return value
+		}
+	}
+}
+
+func (s *Server) serveMessages(ctx context.Context, c *wireConn, sp *store.Provider) error {
+	// preparedStatements maps statement names to their SQL for the extended
+	// query protocol. Bind substitutes text-format parameters into the SQL.
+	prepared := make(map[string]string)
+	portals := make(map[string]string)
+
+	for {
+		msgType, payload, err := c.readMessage()
+		if err != nil {
+			return err
+		}
+		switch msgType {
+		case 'Q':
+			sql := cstringAt(payload, 0)
+			s.runSimpleQuery(ctx, c, sp, sql)
+			if err := c.writeReadyForQuery(); err != nil {
+				return err
+			}
+		case 'P': // Parse
+			r := &msgReader{buf: payload}
+			name := r.cstring()
+			sql := r.cstring()
+			prepared[name] = sql
+			c.writeMessage('1', nil) // ParseComplete
+		case 'B': // Bind
+			r := &msgReader{buf: payload}
+			portal := r.cstring()
+			stmt := r.cstring()
+			sql, err := bindPortal(r, prepared[stmt])
+			if err != nil {
+				c.writeError(err.Error())
+				break
+			}
+			portals[portal] = sql
+			c.writeMessage('2', nil) // BindComplete
+		case 'D': // Describe
+			r := &msgReader{buf: payload}
+			kind := r.byte()
+			name := r.cstring()
+			if kind == 'S' {
+				c.writeMessage('t', func(b *msgBuilder) { // ParameterDescription
+					params := countStatementParams(prepared[name])
+					b.int16(params)
+					for i := 0; i < params; i++ {
+						b.int32(textOID)
+					}
+				})
+			}
+			// The row layout is only known after execution.
+			c.writeMessage('n', nil) // NoData
+		case 'E': // Execute
+			r := &msgReader{buf: payload}
+			portal := r.cstring()
+			s.runSimpleQuery(ctx, c, sp, portals[portal])
+		case 'C': // Close
+			r := &msgReader{buf: payload}
+			kind := r.byte()
+			name := r.cstring()
+			if kind == 'S' {
+				delete(prepared, name)
+			} else {
+				delete(portals, name)
+			}
+			c.writeMessage('3', nil) // CloseComplete
+		case 'S': // Sync
+			if err := c.writeReadyForQuery(); err != nil {
+				return err
+			}
+		case 'H': // Flush
+			if err := c.flush(); err != nil {
+				return err
+			}
+		case 'X': // Terminate
+			return nil
+		default:
+			c.writeError(fmt.Sprintf("unsupported message type %q", msgType))
+			if err := c.writeReadyForQuery(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *Server) runSimpleQuery(ctx context.Context, c *wireConn, sp *store.Provider, sql string) {
+	sql = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(sql), ";"))
+	if sql == "" {
+		c.writeMessage('I', nil) // EmptyQueryResponse
+		return
+	}
+	si, tables, err := s.translate(sp, sql)
+	if err != nil {
+		c.writeError(err.Error())
+		return
+	}
+	if si.LogsQL != "" && s.opts.PrepareLogsQL != nil {
+		si.LogsQL, err = s.opts.PrepareLogsQL(si.LogsQL, tables)
+		if err != nil {
+			c.writeError(err.Error())
+			return
+		}
+	}
+	data := []byte(si.Data)
+	if s.api != nil {
+		data, err = s.api.Execute(ctx, si, vlogs.EndpointConfig{})
+		if err != nil {
+			c.writeError(err.Error())
+			return
+		}
+	}
+	columns, rows, err := resultRows(data)
+	if err != nil {
+		c.writeError(err.Error())
+		return
+	}
+	c.writeRowDescription(columns)
+	for _, row := range rows {
+		c.writeDataRow(columns, row)
+	}
+	c.writeMessage('C', func(b *msgBuilder) {
+		b.cstring(fmt.Sprintf("SELECT %d", len(rows)))
+	})
+}
+
+func (s *Server) translate(sp *store.Provider, sql string) (*logsql.StatementInfo, []string, error) {
+	l := lexer.New(sql)
+	p := parser.New(l)
+	stmt := p.ParseStatement()
+	if stmt == nil {
+		return nil, nil, errors.New("no statement parsed")
+	}
+	if perrs := p.Errors(); len(perrs) > 0 {
+		return nil, nil, fmt.Errorf("parse errors: %w", errors.Join(perrs...))
+	}
+	si, err := logsql.GetStatementInfo(stmt, sp)
+	if err != nil {
+		return nil, nil, err
+	}
+	return si, logsql.ReferencedBaseTables(stmt, sp), nil
+}
+
+// bindPortal applies a Bind message's parameter values to the prepared SQL.
+// Only the text parameter format is supported.
+func bindPortal(r *msgReader, sql string) (string, error) {
+	nFormats := r.int16()
+	for i := 0; i < nFormats; i++ {
+		if r.int16() != 0 {
+			return "", errors.New("binary parameter format is not supported")
+		}
+	}
+	nParams := r.int16()
+	values := make([]*string, nParams)
+	for i := range values {
+		n := r.int32()
+		if n < 0 {
+			continue // NULL
+		}
+		v := string(r.bytesN(n))
+		values[i] = &v
+	}
+	return bindStatementParams(sql, values)
+}
+
+// bindStatementParams substitutes every $N placeholder outside quoted text
+// with its bound value rendered as a SQL literal, so prepared statements
+// behave like their inlined equivalents.
+func bindStatementParams(sql string, values []*string) (string, error) {
+	if !strings.ContainsRune(sql, '$') {
+		return sql, nil
+	}
+	var out strings.Builder
+	var quote byte
+	for i := 0; i < len(sql); i++ {
+		ch := sql[i]
+		switch {
+		case quote != 0:
+			out.WriteByte(ch)
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '\'' || ch == '"':
+			quote = ch
+			out.WriteByte(ch)
+		case ch == '$' && i+1 < len(sql) && isDigit(sql[i+1]):
+			j := i + 1
+			for j < len(sql) && isDigit(sql[j]) {
+				j++
+			}
+			n, err := strconv.Atoi(sql[i+1 : j])
+			if err != nil || n < 1 || n > len(values) {
+				return "", fmt.Errorf("parameter $%s is not bound", sql[i+1:j])
+			}
+			out.WriteString(renderParam(values[n-1]))
+			i = j - 1
+		default:
+			out.WriteByte(ch)
+		}
+	}
+	return out.String(), nil
+}
+
+// numericParam matches values that can be inlined without quoting, so bound
+// numbers keep working in numeric contexts like LIMIT.
+var numericParam = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+
+// renderParam renders a bound value as a SQL literal.
+func renderParam(v *string) string {
+	if v == nil {
+		return "NULL"
+	}
+	if numericParam.MatchString(*v) {
+		return *v
+	}
+	return "'" + strings.ReplaceAll(*v, "'", "''") + "'"
+}
+
+// countStatementParams returns the highest $N placeholder referenced outside
+// quoted text, which is the parameter count Describe reports.
+func countStatementParams(sql string) int {
+	max := 0
+	var quote byte
+	for i := 0; i < len(sql); i++ {
+		ch := sql[i]
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '\'' || ch == '"':
+			quote = ch
+		case ch == '$' && i+1 < len(sql) && isDigit(sql[i+1]):
+			j := i + 1
+			for j < len(sql) && isDigit(sql[j]) {
+				j++
+			}
+			if n, err := strconv.Atoi(sql[i+1 : j]); err == nil && n > max {
+				max = n
+			}
+			i = j - 1
+		}
+	}
+	return max
+}
+
+func isDigit(ch byte) bool {
+	return ch >= '0' && ch <= '9'
+}
@@ -0,0 +1,277 @@
+package pgwire
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/store"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/store/tablestore"
+)
+
+func startTestServer(t *testing.T, opts ...Options) net.Addr {
+	t.Helper()
+	ts, err := tablestore.NewTableStore(map[string]string{"logs": "*"})
+	if err != nil {
+		t.Fatalf("NewTableStore error: %v", err)
+	}
+	sp := store.NewStoreProvider(ts, nil)
+	o := Options{LookupPassword: testPasswordLookup}
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	srv := NewServer(sp, nil, o)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen error: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go srv.Serve(ln)
+	return ln.Addr()
+}
+
+func testPasswordLookup(user string) (string, bool) {
+	if user == "test" {
+		return "secret", true
+	}
+	return "", false
+}
+
+// authenticate answers the cleartext-password request sent after startup.
+func authenticate(t *testing.T, conn net.Conn, r *bufio.Reader, password string) {
+	t.Helper()
+	authPayload := readUntil(t, r, 'R')
+	if binary.BigEndian.Uint32(authPayload) != 3 {
+		t.Fatalf("expected AuthenticationCleartextPassword, got %v", authPayload)
+	}
+	msg := []byte{'p'}
+	msg = binary.BigEndian.AppendUint32(msg, uint32(len(password)+1+4))
+	msg = append(msg, password...)
+	msg = append(msg, 0)
+	if _, err := conn.Write(msg); err != nil {
+		t.Fatalf("write password: %v", err)
+	}
+}
+
+func writeStartup(t *testing.T, conn net.Conn) {
+	t.Helper()
+	payload := make([]byte, 0, 64)
+	payload = binary.BigEndian.AppendUint32(payload, protocolVersion30)
+	payload = append(payload, "user\x00test\x00\x00"...)
+	msg := binary.BigEndian.AppendUint32(nil, uint32(len(payload)+4))
+	msg = append(msg, payload...)
+	if _, err := conn.Write(msg); err != nil {
+		t.Fatalf("write startup: %v", err)
+	}
+}
+
+func readMessageT(t *testing.T, r *bufio.Reader) (byte, []byte) {
+	t.Helper()
+	msgType, err := r.ReadByte()
+	if err != nil {
+		t.Fatalf("read message type: %v", err)
+	}
+	lenBuf := make([]byte, 4)
+	if _, err := io.ReadFull(r, lenBuf); err != nil {
+		t.Fatalf("read message length: %v", err)
+	}
+	payload := make([]byte, binary.BigEndian.Uint32(lenBuf)-4)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		t.Fatalf("read message payload: %v", err)
+	}
+	return msgType, payload
+}
+
+func readUntil(t *testing.T, r *bufio.Reader, want byte) []byte {
+	t.Helper()
+	for i := 0; i < 20; i++ {
+		msgType, payload := readMessageT(t, r)
+		if msgType == want {
+			return payload
+		}
+	}
+	t.Fatalf("message %q not received", want)
+	return nil
+}
+
+func TestSimpleQueryProtocol(t *testing.T) {
+	addr := startTestServer(t)
+	conn, err := net.DialTimeout("tcp", addr.String(), time.Second)
+	if err != nil {
+		t.Fatalf("dial error: %v", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	writeStartup(t, conn)
+	r := bufio.NewReader(conn)
+	authenticate(t, conn, r, "secret")
+
+	authPayload := readUntil(t, r, 'R')
+	if binary.BigEndian.Uint32(authPayload) != 0 {
+		t.Fatalf("expected AuthenticationOk, got %v", authPayload)
+	}
+	readUntil(t, r, 'Z')
+
+	// Simple query without configured endpoint: translation succeeds and an
+	// empty result set is returned.
+	query := "SELECT * FROM logs;\x00"
+	msg := []byte{'Q'}
+	msg = binary.BigEndian.AppendUint32(msg, uint32(len(query)+4))
+	msg = append(msg, query...)
+	if _, err := conn.Write(msg); err != nil {
+		t.Fatalf("write query: %v", err)
+	}
+
+	readUntil(t, r, 'T') // RowDescription
+	complete := readUntil(t, r, 'C')
+	if got := string(complete[:len(complete)-1]); got != "SELECT 0" {
+		t.Fatalf("unexpected command tag %q", got)
+	}
+	readUntil(t, r, 'Z')
+}
+
+func TestSimpleQueryError(t *testing.T) {
+	addr := startTestServer(t)
+	conn, err := net.DialTimeout("tcp", addr.String(), time.Second)
+	if err != nil {
+		t.Fatalf("dial error: %v", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	writeStartup(t, conn)
+	r := bufio.NewReader(conn)
+	authenticate(t, conn, r, "secret")
+	readUntil(t, r, 'Z')
+
+	query := "SELECT * FROM missing;\x00"
+	msg := []byte{'Q'}
+	msg = binary.BigEndian.AppendUint32(msg, uint32(len(query)+4))
+	msg = append(msg, query...)
+	if _, err := conn.Write(msg); err != nil {
+		t.Fatalf("write query: %v", err)
+	}
+
+	readUntil(t, r, 'E') // ErrorResponse
+	readUntil(t, r, 'Z')
+}
+
+func TestAuthenticationFailure(t *testing.T) {
+	addr := startTestServer(t)
+	conn, err := net.DialTimeout("tcp", addr.String(), time.Second)
+	if err != nil {
+		t.Fatalf("dial error: %v", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	writeStartup(t, conn)
+	r := bufio.NewReader(conn)
+	authenticate(t, conn, r, "wrong")
+	readUntil(t, r, 'E')
+	if _, err := r.ReadByte(); err != io.EOF {
+		t.Fatalf("expected connection close after failed auth, got %v", err)
+	}
+}
+
+func TestExtendedQueryParameters(t *testing.T) {
+	addr := startTestServer(t)
+	conn, err := net.DialTimeout("tcp", addr.String(), time.Second)
+	if err != nil {
+		t.Fatalf("dial error: %v", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	writeStartup(t, conn)
+	r := bufio.NewReader(conn)
+	authenticate(t, conn, r, "secret")
+	readUntil(t, r, 'Z')
+
+	// Parse a statement with a placeholder; the parser would reject $1, so
+	// the query only succeeds if Bind substitutes the value.
+	var parse []byte
+	parse = append(parse, "\x00SELECT * FROM logs WHERE level = $1\x00"...)
+	parse = binary.BigEndian.AppendUint16(parse, 0) // no parameter type OIDs
+	writeTypedMessage(t, conn, 'P', parse)
+
+	// Describe the unnamed statement: one text parameter, no row data yet.
+	writeTypedMessage(t, conn, 'D', []byte("S\x00"))
+
+	var bind []byte
+	bind = append(bind, "\x00\x00"...)            // unnamed portal and statement
+	bind = binary.BigEndian.AppendUint16(bind, 0) // no format codes (all text)
+	bind = binary.BigEndian.AppendUint16(bind, 1) // one parameter
+	bind = binary.BigEndian.AppendUint32(bind, 5) // value length
+	bind = append(bind, "error"...)               // value
+	bind = binary.BigEndian.AppendUint16(bind, 0) // no result format codes
+	writeTypedMessage(t, conn, 'B', bind)
+	writeTypedMessage(t, conn, 'E', []byte("\x00"))
+	writeTypedMessage(t, conn, 'S', nil)
+
+	desc := readUntil(t, r, 't')
+	if binary.BigEndian.Uint16(desc) != 1 || binary.BigEndian.Uint32(desc[2:]) != textOID {
+		t.Fatalf("unexpected parameter description: %v", desc)
+	}
+	readUntil(t, r, '2') // BindComplete
+	complete := readUntil(t, r, 'C')
+	if got := string(complete[:len(complete)-1]); got != "SELECT 0" {
+		t.Fatalf("unexpected command tag %q", got)
+	}
+	readUntil(t, r, 'Z')
+}
+
+func writeTypedMessage(t *testing.T, conn net.Conn, msgType byte, payload []byte) {
+	t.Helper()
+	msg := []byte{msgType}
+	msg = binary.BigEndian.AppendUint32(msg, uint32(len(payload)+4))
+	msg = append(msg, payload...)
+	if _, err := conn.Write(msg); err != nil {
+		t.Fatalf("write %q message: %v", msgType, err)
+	}
+}
+
+func TestBindStatementParams(t *testing.T) {
+	v1, v2 := "err'or", "10"
+	sql, err := bindStatementParams("SELECT * FROM logs WHERE level = $1 AND n < $2 AND tag = '$1'", []*string{&v1, &v2})
+	if err != nil {
+		t.Fatalf("bind error: %v", err)
+	}
+	want := "SELECT * FROM logs WHERE level = 'err''or' AND n < 10 AND tag = '$1'"
+	if sql != want {
+		t.Fatalf("unexpected bound SQL:\nexpected: %q\n  actual: %q", want, sql)
+	}
+	if _, err := bindStatementParams("SELECT $3", []*string{&v1}); err == nil {
+		t.Fatal("expected unbound parameter error")
+	}
+	if n := countStatementParams("SELECT $1, $2 WHERE x = '$9'"); n != 2 {
+		t.Fatalf("expected 2 parameters, got %d", n)
+	}
+}
+
+func TestSSLRequestDowngrade(t *testing.T) {
+	addr := startTestServer(t)
+	conn, err := net.DialTimeout("tcp", addr.String(), time.Second)
+	if err != nil {
+		t.Fatalf("dial error: %v", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	msg := binary.BigEndian.AppendUint32(nil, 8)
+	msg = binary.BigEndian.AppendUint32(msg, sslRequestCode)
+	if _, err := conn.Write(msg); err != nil {
+		t.Fatalf("write ssl request: %v", err)
+	}
+	resp := make([]byte, 1)
+	if _, err := io.ReadFull(conn, resp); err != nil {
+		t.Fatalf("read ssl response: %v", err)
+	}
+	if resp[0] != 'N' {
+		t.Fatalf("expected 'N' SSL response, got %q", resp[0])
+	}
+}
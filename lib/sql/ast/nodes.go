@@ -29,9 +29,11 @@ type SelectItem struct {
 	Alias string
 }
 
-// OrderItem represents ORDER BY terms.
+// OrderItem represents ORDER BY terms. Collation is the uppercased name from
+// an optional COLLATE clause, or empty for the default lexicographic order.
 type OrderItem struct {
 	Expr      Expr
+	Collation string
 	Direction OrderDirection
 }
 
@@ -107,13 +109,32 @@ type DeleteStatement struct {
 
 func (*DeleteStatement) statementNode() {}
 
-// CreateViewStatement models CREATE VIEW statements.
+// SetStatement models SET statements that adjust session options.
+type SetStatement struct {
+	Name  *Identifier
+	Value Expr
+}
+
+func (*SetStatement) statementNode() {}
+
+// ResetStatement models RESET statements that restore a session option to
+// its default.
+type ResetStatement struct {
+	Name *Identifier
+}
+
+func (*ResetStatement) statementNode() {}
+
+// CreateViewStatement models CREATE VIEW statements. Parameters holds the
+// names declared in an optional WITH PARAMETERS (...) clause.
 type CreateViewStatement struct {
 	OrReplace    bool
 	IfNotExists  bool
 	Materialized bool
+	Temporary    bool
 	Name         *Identifier
 	Columns      []*Identifier
+	Parameters   []*Identifier
 	Select       *SelectStatement
 }
 
@@ -144,16 +165,33 @@ type DescribeStatement struct {
 
 func (*DescribeStatement) statementNode() {}
 
-// ShowTablesStatement models SHOW TABLES commands.
-type ShowTablesStatement struct{}
+// ShowTablesStatement models SHOW TABLES commands. Pattern holds the LIKE
+// pattern filtering the listed names, or empty for all of them; Limit carries
+// an optional LIMIT/OFFSET window over the list.
+type ShowTablesStatement struct {
+	Pattern string
+	Limit   *LimitClause
+}
 
 func (*ShowTablesStatement) statementNode() {}
 
-// ShowViewsStatement models SHOW VIEWS commands.
-type ShowViewsStatement struct{}
+// ShowViewsStatement models SHOW VIEWS commands. Pattern and Limit work as in
+// ShowTablesStatement.
+type ShowViewsStatement struct {
+	Pattern string
+	Limit   *LimitClause
+}
 
 func (*ShowViewsStatement) statementNode() {}
 
+// UseStatement models USE statements that switch the session's default
+// schema for unqualified table names.
+type UseStatement struct {
+	Schema *Identifier
+}
+
+func (*UseStatement) statementNode() {}
+
 // Assignment represents column=expr pairs in UPDATE SET.
 type Assignment struct {
 	Column *Identifier
@@ -184,6 +222,46 @@ type SubqueryTable struct {
 
 func (*SubqueryTable) tableNode() {}
 
+// PivotValue is one pivoted literal with its output column alias.
+type PivotValue struct {
+	Value Expr
+	Alias string
+}
+
+// PivotTable wraps a table expression with a PIVOT clause that produces one
+// aggregated column per pivoted value.
+type PivotTable struct {
+	Source TableExpr
+	Agg    *FuncCall
+	For    *Identifier
+	Values []PivotValue
+	Alias  string
+}
+
+func (*PivotTable) tableNode() {}
+
+// ValuesTable represents an inline VALUES list used as a table expression,
+// e.g. (VALUES ('api'), ('worker')) AS t(service).
+type ValuesTable struct {
+	Rows    [][]Expr
+	Alias   string
+	Columns []string
+}
+
+func (*ValuesTable) tableNode() {}
+
+// TableFunction represents a table-valued function reference in FROM,
+// such as LOGSQL('<raw query>') or UNNEST(field) WITH ORDINALITY AS t(x, n).
+type TableFunction struct {
+	Name       *Identifier
+	Args       []Expr
+	Ordinality bool
+	Alias      string
+	Columns    []string
+}
+
+func (*TableFunction) tableNode() {}
+
 // JoinType enumerates supported ANSI join types.
 type JoinType string
 
@@ -248,6 +326,16 @@ func (*BooleanLiteral) exprNode() {}
 func (*NullLiteral) exprNode()    {}
 func (*Placeholder) exprNode()    {}
 
+// IntervalExpr models INTERVAL literals such as INTERVAL '5 minutes' or
+// INTERVAL 1 HOUR. Value holds the numeric quantity and Unit the canonical
+// singular unit name (SECOND, MINUTE, HOUR, DAY, WEEK, MONTH, YEAR).
+type IntervalExpr struct {
+	Value string
+	Unit  string
+}
+
+func (*IntervalExpr) exprNode() {}
+
 // BinaryExpr models binary operations like a+b or a AND b.
 type BinaryExpr struct {
 	Left     Expr
@@ -265,12 +353,14 @@ type UnaryExpr struct {
 
 func (*UnaryExpr) exprNode() {}
 
-// FuncCall models function invocations.
+// FuncCall models function invocations. Filter holds the condition of a
+// FILTER (WHERE ...) clause on aggregate calls.
 type FuncCall struct {
 	Name     Identifier
 	Distinct bool
 	Args     []Expr
 	Over     *WindowSpecification
+	Filter   Expr
 }
 
 func (*FuncCall) exprNode() {}
@@ -316,11 +406,13 @@ type InExpr struct {
 
 func (*InExpr) exprNode() {}
 
-// LikeExpr models LIKE expressions.
+// LikeExpr models LIKE and ILIKE expressions.
 type LikeExpr struct {
-	Expr    Expr
-	Not     bool
-	Pattern Expr
+	Expr Expr
+	Not  bool
+	// CaseInsensitive marks ILIKE.
+	CaseInsensitive bool
+	Pattern         Expr
 }
 
 func (*LikeExpr) exprNode() {}
@@ -10,14 +10,20 @@ func (s *SelectStatement) Accept(v Visitor)     { Walk(v, s) }
 func (s *InsertStatement) Accept(v Visitor)     { Walk(v, s) }
 func (s *UpdateStatement) Accept(v Visitor)     { Walk(v, s) }
 func (s *DeleteStatement) Accept(v Visitor)     { Walk(v, s) }
+func (s *SetStatement) Accept(v Visitor)        { Walk(v, s) }
+func (s *ResetStatement) Accept(v Visitor)      { Walk(v, s) }
 func (s *CreateViewStatement) Accept(v Visitor) { Walk(v, s) }
 func (s *DropViewStatement) Accept(v Visitor)   { Walk(v, s) }
 func (s *DescribeStatement) Accept(v Visitor)   { Walk(v, s) }
 func (s *ShowTablesStatement) Accept(v Visitor) { Walk(v, s) }
+func (s *UseStatement) Accept(v Visitor)        { Walk(v, s) }
 func (s *ShowViewsStatement) Accept(v Visitor)  { Walk(v, s) }
 func (i *Identifier) Accept(v Visitor)          { Walk(v, i) }
 func (t *TableName) Accept(v Visitor)           { Walk(v, t) }
 func (t *SubqueryTable) Accept(v Visitor)       { Walk(v, t) }
+func (t *TableFunction) Accept(v Visitor)       { Walk(v, t) }
+func (t *ValuesTable) Accept(v Visitor)         { Walk(v, t) }
+func (t *PivotTable) Accept(v Visitor)          { Walk(v, t) }
 func (j *JoinExpr) Accept(v Visitor)            { Walk(v, j) }
 func (s *StarExpr) Accept(v Visitor)            { Walk(v, s) }
 func (n *NumericLiteral) Accept(v Visitor)      { Walk(v, n) }
@@ -25,6 +31,7 @@ func (s *StringLiteral) Accept(v Visitor)       { Walk(v, s) }
 func (b *BooleanLiteral) Accept(v Visitor)      { Walk(v, b) }
 func (n *NullLiteral) Accept(v Visitor)         { Walk(v, n) }
 func (p *Placeholder) Accept(v Visitor)         { Walk(v, p) }
+func (i *IntervalExpr) Accept(v Visitor)        { Walk(v, i) }
 func (b *BinaryExpr) Accept(v Visitor)          { Walk(v, b) }
 func (u *UnaryExpr) Accept(v Visitor)           { Walk(v, u) }
 func (f *FuncCall) Accept(v Visitor)            { Walk(v, f) }
@@ -100,22 +107,50 @@ func Walk(v Visitor, node Node) {
 		Walk(v, n.Table)
 		Walk(v, n.Using)
 		Walk(v, n.Where)
+	case *SetStatement:
+		Walk(v, n.Name)
+		Walk(v, n.Value)
+	case *ResetStatement:
+		Walk(v, n.Name)
 	case *CreateViewStatement:
 		Walk(v, n.Name)
 		for _, col := range n.Columns {
 			Walk(v, col)
 		}
+		for _, param := range n.Parameters {
+			Walk(v, param)
+		}
 		Walk(v, n.Select)
 	case *DropViewStatement:
 		Walk(v, n.Name)
 	case *ShowTablesStatement, *ShowViewsStatement:
 		// leaves have no children
+	case *UseStatement:
+		Walk(v, n.Schema)
 	case *Identifier:
 		// leaves have no children
 	case *TableName:
 		Walk(v, n.Name)
 	case *SubqueryTable:
 		Walk(v, n.Select)
+	case *TableFunction:
+		Walk(v, n.Name)
+		for _, arg := range n.Args {
+			Walk(v, arg)
+		}
+	case *ValuesTable:
+		for _, row := range n.Rows {
+			for _, expr := range row {
+				Walk(v, expr)
+			}
+		}
+	case *PivotTable:
+		Walk(v, n.Source)
+		Walk(v, n.Agg)
+		Walk(v, n.For)
+		for _, pv := range n.Values {
+			Walk(v, pv.Value)
+		}
 	case *JoinExpr:
 		Walk(v, n.Left)
 		Walk(v, n.Right)
@@ -124,7 +159,7 @@ func Walk(v Visitor, node Node) {
 		if n.Table != nil {
 			Walk(v, n.Table)
 		}
-	case *NumericLiteral, *StringLiteral, *BooleanLiteral, *NullLiteral, *Placeholder:
+	case *NumericLiteral, *StringLiteral, *BooleanLiteral, *NullLiteral, *Placeholder, *IntervalExpr:
 		// leaves
 	case *BinaryExpr:
 		Walk(v, n.Left)
@@ -136,6 +171,7 @@ func Walk(v Visitor, node Node) {
 		for _, arg := range n.Args {
 			Walk(v, arg)
 		}
+		Walk(v, n.Filter)
 		if n.Over != nil {
 			for _, expr := range n.Over.PartitionBy {
 				Walk(v, expr)
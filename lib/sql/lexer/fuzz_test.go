@@ -0,0 +1,24 @@
+package lexer
+
+import (
+	"testing"
+
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/sql/token"
+)
+
+func FuzzNextToken(f *testing.F) {
+	f.Add("SELECT * FROM logs WHERE level = 'error'")
+	f.Add(`SELECT "a b" -- comment`)
+	f.Add("/* unterminated")
+	f.Add("'unterminated string")
+	f.Add("1.5e+10 <> != <= >= || ?")
+	f.Fuzz(func(t *testing.T, input string) {
+		l := New(input)
+		for {
+			tok := l.NextToken()
+			if tok.Type == token.EOF {
+				break
+			}
+		}
+	})
+}
@@ -108,7 +108,11 @@ func (l *Lexer) NextToken() token.Token {
 		tok = token.Token{Type: token.STRING, Literal: literal, Pos: startPos}
 		return tok
 	case '"':
-		literal := l.readQuotedIdentifier()
+		literal := l.readQuotedIdentifier('"')
+		tok = token.Token{Type: token.IDENT, Literal: literal, Pos: startPos}
+		return tok
+	case '`':
+		literal := l.readQuotedIdentifier('`')
 		tok = token.Token{Type: token.IDENT, Literal: literal, Pos: startPos}
 		return tok
 	default:
@@ -225,9 +229,56 @@ func (l *Lexer) readNumber() string {
 		}
 		l.readRune()
 	}
+	// A LogsQL size or duration suffix (10MiB, 1.5s) becomes part of the
+	// literal so comparisons can pass it through to LogsQL unchanged.
+	for n := l.numberSuffixLen(); n > 0; n-- {
+		l.readRune()
+	}
 	return l.input[start:l.position]
 }
 
+// numberSuffixes lists the LogsQL size and duration suffixes accepted after
+// a numeric literal, lowercased.
+var numberSuffixes = map[string]struct{}{
+	"k": {}, "kb": {}, "ki": {}, "kib": {},
+	"m": {}, "mb": {}, "mi": {}, "mib": {},
+	"g": {}, "gb": {}, "gi": {}, "gib": {},
+	"t": {}, "tb": {}, "ti": {}, "tib": {},
+	"ns": {}, "us": {}, "ms": {}, "s": {},
+	"h": {}, "d": {}, "w": {}, "y": {},
+}
+
+// numberSuffixLen returns the byte length of the size/duration suffix at the
+// current position, or 0 when the following letters are not a known suffix.
+func (l *Lexer) numberSuffixLen() int {
+	if !isASCIILetter(l.ch) {
+		return 0
+	}
+	end := l.position
+	for end < len(l.input) && isASCIILetter(rune(l.input[end])) {
+		end++
+	}
+	run := l.input[l.position:end]
+	if len(run) > 3 {
+		return 0
+	}
+	// A trailing identifier character means this is not a bare suffix.
+	if end < len(l.input) {
+		next := l.input[end]
+		if next == '_' || (next >= '0' && next <= '9') {
+			return 0
+		}
+	}
+	if _, ok := numberSuffixes[strings.ToLower(run)]; !ok {
+		return 0
+	}
+	return len(run)
+}
+
+func isASCIILetter(ch rune) bool {
+	return (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z')
+}
+
 func (l *Lexer) readString(quote rune) string {
 	var builder strings.Builder
 	for {
@@ -251,14 +302,17 @@ func (l *Lexer) readString(quote rune) string {
 	}
 }
 
-func (l *Lexer) readQuotedIdentifier() string {
+// readQuotedIdentifier reads an identifier delimited by quote, either
+// ANSI double quotes or MySQL-style backticks. A doubled quote character
+// inside the identifier escapes it.
+func (l *Lexer) readQuotedIdentifier(quote rune) string {
 	var builder strings.Builder
 	for {
 		l.readRune()
 		switch l.ch {
-		case '"':
-			if l.peekRune() == '"' {
-				builder.WriteRune('"')
+		case quote:
+			if l.peekRune() == quote {
+				builder.WriteRune(quote)
 				l.readRune()
 			} else {
 				l.readRune()
@@ -119,6 +119,53 @@ func TestNextTokenLiterals(t *testing.T) {
 	}
 }
 
+func TestNextTokenBacktickIdentifiers(t *testing.T) {
+	input := "SELECT `limit`, `or``der` FROM `logs`"
+
+	expected := []token.Token{
+		{Type: token.SELECT, Literal: "SELECT"},
+		{Type: token.IDENT, Literal: "limit"},
+		{Type: token.COMMA, Literal: ","},
+		{Type: token.IDENT, Literal: "or`der"},
+		{Type: token.FROM, Literal: "FROM"},
+		{Type: token.IDENT, Literal: "logs"},
+		{Type: token.EOF, Literal: ""},
+	}
+
+	l := New(input)
+	for i, exp := range expected {
+		tok := l.NextToken()
+		if tok.Type != exp.Type || tok.Literal != exp.Literal {
+			t.Fatalf("token[%d] - expected %#v, got %#v", i, exp, tok)
+		}
+	}
+}
+
+func TestNextTokenNumberSuffixes(t *testing.T) {
+	input := `SELECT * FROM logs WHERE bytes > 10MiB AND duration > 1.5s AND count > 5`
+
+	l := New(input)
+	var numbers []string
+	for {
+		tok := l.NextToken()
+		if tok.Type == token.EOF {
+			break
+		}
+		if tok.Type == token.NUMBER {
+			numbers = append(numbers, tok.Literal)
+		}
+	}
+	expected := []string{"10MiB", "1.5s", "5"}
+	if len(numbers) != len(expected) {
+		t.Fatalf("expected %d numbers, got %v", len(expected), numbers)
+	}
+	for i, exp := range expected {
+		if numbers[i] != exp {
+			t.Fatalf("number[%d] - expected %q, got %q", i, exp, numbers[i])
+		}
+	}
+}
+
 func TestNextTokenCreateView(t *testing.T) {
 	input := `CREATE OR REPLACE MATERIALIZED VIEW IF NOT EXISTS reporting.active_users (id, name) AS SELECT id, name FROM users WHERE active = TRUE;`
 
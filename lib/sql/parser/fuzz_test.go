@@ -0,0 +1,21 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/sql/lexer"
+	sqlparser "github.com/VictoriaMetrics/sql-to-logsql/lib/sql/parser"
+)
+
+func FuzzParseStatement(f *testing.F) {
+	f.Add("SELECT * FROM logs WHERE level = 'error' LIMIT 10")
+	f.Add("WITH e AS (SELECT user FROM logs) SELECT * FROM e JOIN logs ON e.user = logs.user")
+	f.Add("SELECT COUNT(*) OVER (PARTITION BY host ORDER BY _time) FROM logs")
+	f.Add("CREATE VIEW v AS SELECT 1; DROP VIEW v")
+	f.Add("SELECT ((((1))))")
+	f.Fuzz(func(t *testing.T, sql string) {
+		p := sqlparser.New(lexer.New(sql))
+		_ = p.ParseStatement()
+		_ = p.Errors()
+	})
+}
@@ -14,6 +14,8 @@ const (
 	MaxParserDepth = 100
 	// MaxExpressionCount limits number of expressions in lists
 	MaxExpressionCount = 1000
+	// MaxTokenCount limits how many tokens a single statement may contain
+	MaxTokenCount = 100000
 )
 
 // Parser consumes SQL tokens and produces AST nodes for a core ANSI subset.
@@ -24,7 +26,9 @@ type Parser struct {
 	curToken  token.Token
 	peekToken token.Token
 
-	depth int // Current recursion depth
+	depth         int // Current recursion depth
+	tokenCount    int
+	tokenLimitHit bool
 }
 
 // New returns a parser over the provided lexer.
@@ -47,6 +51,17 @@ func (p *Parser) addError(pos token.Position, format string, args ...interface{}
 
 func (p *Parser) nextToken() {
 	p.curToken = p.peekToken
+	if p.tokenCount >= MaxTokenCount {
+		// Pathologically long inputs stop here instead of churning through
+		// the whole token stream.
+		if !p.tokenLimitHit {
+			p.tokenLimitHit = true
+			p.addError(p.peekToken.Pos, "maximum token count exceeded")
+		}
+		p.peekToken = token.Token{Type: token.EOF, Pos: p.peekToken.Pos}
+		return
+	}
+	p.tokenCount++
 	p.peekToken = p.l.NextToken()
 }
 
@@ -84,6 +99,12 @@ func (p *Parser) ParseStatement() ast.Statement {
 		stmt = p.parseUpdateStatement()
 	case token.DELETE:
 		stmt = p.parseDeleteStatement()
+	case token.SET:
+		stmt = p.parseSetStatement()
+	case token.RESET:
+		stmt = p.parseResetStatement()
+	case token.USE:
+		stmt = p.parseUseStatement()
 	case token.CREATE:
 		stmt = p.parseCreateViewStatement()
 	case token.DROP:
@@ -219,11 +240,15 @@ func (p *Parser) parseShowStatement() ast.Statement {
 	case p.peekTokenIs(token.TABLES):
 		p.nextToken()
 		stmt := &ast.ShowTablesStatement{}
+		stmt.Pattern = p.parseShowLikePattern()
+		stmt.Limit = p.parseShowLimitClause()
 		p.nextToken()
 		return stmt
 	case p.peekTokenIs(token.VIEWS):
 		p.nextToken()
 		stmt := &ast.ShowViewsStatement{}
+		stmt.Pattern = p.parseShowLikePattern()
+		stmt.Limit = p.parseShowLimitClause()
 		p.nextToken()
 		return stmt
 	default:
@@ -232,6 +257,41 @@ func (p *Parser) parseShowStatement() ast.Statement {
 	}
 }
 
+// parseShowLikePattern consumes an optional LIKE '<pattern>' suffix on SHOW
+// statements and returns the pattern.
+func (p *Parser) parseShowLikePattern() string {
+	if !p.peekTokenIs(token.LIKE) {
+		return ""
+	}
+	p.nextToken()
+	if !p.expectPeek(token.STRING) {
+		return ""
+	}
+	return p.curToken.Literal
+}
+
+// parseShowLimitClause consumes an optional LIMIT n [OFFSET m] or OFFSET m
+// suffix on SHOW statements.
+func (p *Parser) parseShowLimitClause() *ast.LimitClause {
+	if p.peekTokenIs(token.LIMIT) {
+		p.expectPeek(token.LIMIT)
+		p.nextToken()
+		limit := &ast.LimitClause{Count: p.parseExpression(lowest)}
+		if p.peekTokenIs(token.OFFSET) {
+			p.expectPeek(token.OFFSET)
+			p.nextToken()
+			limit.Offset = p.parseExpression(lowest)
+		}
+		return limit
+	}
+	if p.peekTokenIs(token.OFFSET) {
+		p.expectPeek(token.OFFSET)
+		p.nextToken()
+		return &ast.LimitClause{Offset: p.parseExpression(lowest)}
+	}
+	return nil
+}
+
 func (p *Parser) parseSelectStatement() *ast.SelectStatement {
 	p.depth++
 	if p.depth > MaxParserDepth {
@@ -257,39 +317,51 @@ func (p *Parser) parseSelectCore() *ast.SelectStatement {
 	}
 
 	p.nextToken()
+	errsBefore := len(p.errors)
 	stmt.Columns = p.parseSelectList()
+	p.recoverAtClauseBoundary(errsBefore)
 
 	if p.peekTokenIs(token.FROM) {
 		p.expectPeek(token.FROM)
 		p.nextToken()
+		errsBefore = len(p.errors)
 		stmt.From = p.parseTableExpression()
+		p.recoverAtClauseBoundary(errsBefore)
 	}
 
 	if p.peekTokenIs(token.WHERE) {
 		p.expectPeek(token.WHERE)
 		p.nextToken()
+		errsBefore = len(p.errors)
 		stmt.Where = p.parseExpression(lowest)
+		p.recoverAtClauseBoundary(errsBefore)
 	}
 
 	if p.peekTokenIs(token.GROUP) {
 		p.expectPeek(token.GROUP)
 		if p.expectPeek(token.BY) {
 			p.nextToken()
+			errsBefore = len(p.errors)
 			stmt.GroupBy = p.parseExpressionList()
+			p.recoverAtClauseBoundary(errsBefore)
 		}
 	}
 
 	if p.peekTokenIs(token.HAVING) {
 		p.expectPeek(token.HAVING)
 		p.nextToken()
+		errsBefore = len(p.errors)
 		stmt.Having = p.parseExpression(lowest)
+		p.recoverAtClauseBoundary(errsBefore)
 	}
 
 	if p.peekTokenIs(token.ORDER) {
 		p.expectPeek(token.ORDER)
 		if p.expectPeek(token.BY) {
 			p.nextToken()
+			errsBefore = len(p.errors)
 			stmt.OrderBy = p.parseOrderList()
+			p.recoverAtClauseBoundary(errsBefore)
 		}
 	}
 
@@ -306,12 +378,74 @@ func (p *Parser) parseSelectCore() *ast.SelectStatement {
 	} else if p.peekTokenIs(token.OFFSET) {
 		p.expectPeek(token.OFFSET)
 		p.nextToken()
-		stmt.Limit = &ast.LimitClause{Offset: p.parseExpression(lowest)}
+		limit := &ast.LimitClause{Offset: p.parseExpression(lowest)}
+		p.skipRowsKeyword()
+		if p.peekTokenIs(token.FETCH) {
+			p.expectPeek(token.FETCH)
+			limit.Count = p.parseFetchClause()
+		}
+		stmt.Limit = limit
+	} else if p.peekTokenIs(token.FETCH) {
+		p.expectPeek(token.FETCH)
+		stmt.Limit = &ast.LimitClause{Count: p.parseFetchClause()}
 	}
 
 	return stmt
 }
 
+// recoverAtClauseBoundary performs panic-mode recovery: when the clause just
+// parsed added errors, the parser may be stranded mid-clause, so skip ahead
+// to the next clause keyword (or subquery close) and keep parsing. Later
+// clauses then report their own mistakes instead of cascading off the first.
+func (p *Parser) recoverAtClauseBoundary(errsBefore int) {
+	if len(p.errors) == errsBefore {
+		return
+	}
+	for !p.peekTokenIs(token.EOF) && !isClauseBoundary(p.peekToken.Type) {
+		switch p.peekToken.Type {
+		case token.FETCH, token.RPAREN, token.SEMICOLON:
+			return
+		}
+		p.nextToken()
+	}
+}
+
+// skipRowsKeyword consumes the optional ROW/ROWS noise word of ANSI paging
+// clauses.
+func (p *Parser) skipRowsKeyword() {
+	if !p.peekTokenIs(token.IDENT) {
+		return
+	}
+	switch strings.ToUpper(p.peekToken.Literal) {
+	case "ROW", "ROWS":
+		p.nextToken()
+	}
+}
+
+// parseFetchClause parses the FIRST/NEXT <count> [ROW|ROWS] ONLY tail of an
+// ANSI FETCH clause and returns the row count expression.
+func (p *Parser) parseFetchClause() ast.Expr {
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	switch strings.ToUpper(p.curToken.Literal) {
+	case "FIRST", "NEXT":
+	default:
+		p.addError(p.curToken.Pos, "expected FIRST or NEXT after FETCH, got %s", p.curToken.Literal)
+		return nil
+	}
+	p.nextToken()
+	count := p.parseExpression(lowest)
+	p.skipRowsKeyword()
+	if !p.expectPeek(token.IDENT) {
+		return count
+	}
+	if !strings.EqualFold(p.curToken.Literal, "ONLY") {
+		p.addError(p.curToken.Pos, "expected ONLY at end of FETCH clause, got %s", p.curToken.Literal)
+	}
+	return count
+}
+
 func (p *Parser) parseSetOperations(stmt *ast.SelectStatement) *ast.SelectStatement {
 	for {
 		op, ok := p.peekSetOperator()
@@ -405,6 +539,14 @@ func (p *Parser) parseOrderList() []ast.OrderItem {
 
 	for {
 		expr := p.parseExpression(lowest)
+		collation := ""
+		if p.peekTokenIs(token.COLLATE) {
+			p.nextToken()
+			if !p.expectPeek(token.IDENT) {
+				return items
+			}
+			collation = strings.ToUpper(p.curToken.Literal)
+		}
 		direction := ast.Ascending
 		if p.peekTokenIs(token.DESC) || p.peekTokenIs(token.ASC) {
 			p.nextToken()
@@ -412,7 +554,7 @@ func (p *Parser) parseOrderList() []ast.OrderItem {
 				direction = ast.Descending
 			}
 		}
-		items = append(items, ast.OrderItem{Expr: expr, Direction: direction})
+		items = append(items, ast.OrderItem{Expr: expr, Collation: collation, Direction: direction})
 		if p.peekTokenIs(token.COMMA) {
 			p.nextToken()
 			p.nextToken()
@@ -528,6 +670,12 @@ func isClauseBoundary(t token.Type) bool {
 
 func (p *Parser) parseTableExpression() ast.TableExpr {
 	left := p.parseTableFactor()
+	if p.peekTokenIs(token.PIVOT) {
+		left = p.parsePivot(left)
+	} else if p.peekTokenIs(token.UNPIVOT) {
+		p.addError(p.peekToken.Pos, "UNPIVOT is not supported")
+		return left
+	}
 
 	for {
 		joinType, ok := p.peekJoinType()
@@ -600,6 +748,9 @@ func (p *Parser) parseTableFactor() ast.TableExpr {
 	switch p.curToken.Type {
 	case token.IDENT:
 		ident := p.parseQualifiedName()
+		if p.peekTokenIs(token.LPAREN) {
+			return p.parseTableFunction(ident)
+		}
 		tbl := &ast.TableName{Name: ident}
 		if alias := p.parseAliasIfPresent(); alias != "" {
 			tbl.Alias = alias
@@ -608,6 +759,8 @@ func (p *Parser) parseTableFactor() ast.TableExpr {
 	case token.LPAREN:
 		p.nextToken()
 		switch p.curToken.Type {
+		case token.VALUES:
+			return p.parseValuesTable()
 		case token.WITH:
 			with := p.parseWithClause()
 			if !p.curTokenIs(token.SELECT) {
@@ -648,6 +801,153 @@ func (p *Parser) parseTableFactor() ast.TableExpr {
 	}
 }
 
+// parsePivot parses a PIVOT clause following a table reference:
+// PIVOT (agg FOR column IN (value [AS alias], ...)).
+func (p *Parser) parsePivot(source ast.TableExpr) ast.TableExpr {
+	pt := &ast.PivotTable{Source: source}
+	p.nextToken()
+	if !p.expectPeek(token.LPAREN) {
+		return pt
+	}
+	p.nextToken()
+	agg, ok := p.parseExpression(lowest).(*ast.FuncCall)
+	if !ok {
+		p.addError(p.curToken.Pos, "PIVOT expects an aggregate function call")
+		return pt
+	}
+	pt.Agg = agg
+	if !p.expectPeek(token.FOR) {
+		return pt
+	}
+	if !p.expectPeek(token.IDENT) {
+		return pt
+	}
+	pt.For = p.parseQualifiedName()
+	if !p.expectPeek(token.IN) {
+		return pt
+	}
+	if !p.expectPeek(token.LPAREN) {
+		return pt
+	}
+	for {
+		p.nextToken()
+		pv := ast.PivotValue{Value: p.parseExpression(lowest)}
+		if p.peekTokenIs(token.AS) {
+			p.nextToken()
+			if !p.expectPeek(token.IDENT) {
+				return pt
+			}
+			pv.Alias = p.curToken.Literal
+		}
+		pt.Values = append(pt.Values, pv)
+		if p.peekTokenIs(token.COMMA) {
+			p.nextToken()
+			continue
+		}
+		break
+	}
+	if !p.expectPeek(token.RPAREN) {
+		return pt
+	}
+	if !p.expectPeek(token.RPAREN) {
+		return pt
+	}
+	if alias := p.parseAliasIfPresent(); alias != "" {
+		pt.Alias = alias
+	}
+	return pt
+}
+
+// parseValuesTable parses an inline (VALUES (...), (...)) table with its
+// optional alias and column list, with curToken on VALUES.
+func (p *Parser) parseValuesTable() ast.TableExpr {
+	vt := &ast.ValuesTable{}
+	for p.expectPeek(token.LPAREN) {
+		p.nextToken()
+		row := []ast.Expr{p.parseExpression(lowest)}
+		for p.peekTokenIs(token.COMMA) {
+			p.nextToken()
+			p.nextToken()
+			row = append(row, p.parseExpression(lowest))
+		}
+		vt.Rows = append(vt.Rows, row)
+		if !p.expectPeek(token.RPAREN) {
+			return nil
+		}
+		if p.peekTokenIs(token.COMMA) {
+			p.nextToken()
+			continue
+		}
+		break
+	}
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+	if alias := p.parseAliasIfPresent(); alias != "" {
+		vt.Alias = alias
+		if p.peekTokenIs(token.LPAREN) {
+			p.nextToken()
+			if p.expectPeek(token.IDENT) {
+				vt.Columns = append(vt.Columns, p.curToken.Literal)
+				for p.peekTokenIs(token.COMMA) {
+					p.nextToken()
+					if !p.expectPeek(token.IDENT) {
+						return vt
+					}
+					vt.Columns = append(vt.Columns, p.curToken.Literal)
+				}
+			}
+			p.expectPeek(token.RPAREN)
+		}
+	}
+	return vt
+}
+
+// parseTableFunction parses a table-valued function reference such as
+// LOGSQL('...') in FROM position.
+func (p *Parser) parseTableFunction(name *ast.Identifier) ast.TableExpr {
+	fn := &ast.TableFunction{Name: name}
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+	if !p.peekTokenIs(token.RPAREN) {
+		p.nextToken()
+		fn.Args = p.parseExpressionList()
+	}
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+	if p.peekTokenIs(token.WITH) {
+		p.nextToken()
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		if !strings.EqualFold(p.curToken.Literal, "ORDINALITY") {
+			p.addError(p.curToken.Pos, "expected ORDINALITY after WITH, got %s", p.curToken.Literal)
+			return nil
+		}
+		fn.Ordinality = true
+	}
+	if alias := p.parseAliasIfPresent(); alias != "" {
+		fn.Alias = alias
+		if p.peekTokenIs(token.LPAREN) {
+			p.nextToken()
+			if p.expectPeek(token.IDENT) {
+				fn.Columns = append(fn.Columns, p.curToken.Literal)
+				for p.peekTokenIs(token.COMMA) {
+					p.nextToken()
+					if !p.expectPeek(token.IDENT) {
+						return fn
+					}
+					fn.Columns = append(fn.Columns, p.curToken.Literal)
+				}
+			}
+			p.expectPeek(token.RPAREN)
+		}
+	}
+	return fn
+}
+
 func (p *Parser) parseIdentifier() *ast.Identifier {
 	return &ast.Identifier{Parts: []string{p.curToken.Literal}}
 }
@@ -664,6 +964,24 @@ func (p *Parser) parseQualifiedName() *ast.Identifier {
 	return &ast.Identifier{Parts: parts}
 }
 
+// parseQualifiedNameOrStar parses a dotted name in expression position, where
+// the final segment may be the wildcard selector (e.g. "l.*").
+func (p *Parser) parseQualifiedNameOrStar() ast.Expr {
+	parts := []string{p.curToken.Literal}
+	for p.peekTokenIs(token.DOT) {
+		p.nextToken()
+		if p.peekTokenIs(token.STAR) {
+			p.nextToken()
+			return &ast.StarExpr{Table: &ast.Identifier{Parts: parts}}
+		}
+		if !p.expectPeek(token.IDENT) {
+			return &ast.Identifier{Parts: parts}
+		}
+		parts = append(parts, p.curToken.Literal)
+	}
+	return &ast.Identifier{Parts: parts}
+}
+
 const (
 	_ int = iota
 	lowest
@@ -689,6 +1007,7 @@ var precedences = map[token.Type]int{
 	token.IN:      precedenceComparison,
 	token.BETWEEN: precedenceComparison,
 	token.LIKE:    precedenceComparison,
+	token.ILIKE:   precedenceComparison,
 	token.IS:      precedenceComparison,
 	token.PLUS:    precedenceSum,
 	token.MINUS:   precedenceSum,
@@ -698,6 +1017,7 @@ var precedences = map[token.Type]int{
 	token.DOT:     precedenceCall,
 	token.LPAREN:  precedenceCall,
 	token.OVER:    precedenceCall,
+	token.FILTER:  precedenceCall,
 }
 
 func (p *Parser) peekPrecedence() int {
@@ -727,9 +1047,17 @@ func (p *Parser) parseExpression(precedence int) ast.Expr {
 
 	switch p.curToken.Type {
 	case token.IDENT:
-		left = p.parseQualifiedName()
+		left = p.parseQualifiedNameOrStar()
 	case token.REPLACE:
 		left = &ast.Identifier{Parts: []string{p.curToken.Literal}}
+	case token.LEFT, token.RIGHT:
+		// LEFT/RIGHT are join keywords, but in expression position they are
+		// the string functions of the same name.
+		if !p.peekTokenIs(token.LPAREN) {
+			p.addError(p.curToken.Pos, "unexpected token %s", p.curToken.Type)
+			return nil
+		}
+		left = &ast.Identifier{Parts: []string{p.curToken.Literal}}
 	case token.NUMBER:
 		left = &ast.NumericLiteral{Value: p.curToken.Literal}
 	case token.STRING:
@@ -761,6 +1089,10 @@ func (p *Parser) parseExpression(precedence int) ast.Expr {
 		left = expr
 	case token.EXISTS:
 		left = p.parseExistsExpression(false)
+	case token.INTERVAL:
+		left = p.parseIntervalLiteral()
+	case token.CASE:
+		left = p.parseCaseExpression()
 	default:
 		p.addError(p.curToken.Pos, "unexpected token %s", p.curToken.Type)
 		return nil
@@ -789,6 +1121,116 @@ func terminatesExpression(t token.Type) bool {
 	}
 }
 
+// parseCaseExpression handles both searched CASE (WHEN cond THEN result) and
+// simple CASE with an operand before the first WHEN.
+func (p *Parser) parseCaseExpression() ast.Expr {
+	expr := &ast.CaseExpr{}
+	if !p.peekTokenIs(token.WHEN) {
+		p.nextToken()
+		expr.Operand = p.parseExpression(lowest)
+	}
+	for p.peekTokenIs(token.WHEN) {
+		p.nextToken()
+		p.nextToken()
+		condition := p.parseExpression(lowest)
+		if !p.expectPeek(token.THEN) {
+			return expr
+		}
+		p.nextToken()
+		result := p.parseExpression(lowest)
+		expr.When = append(expr.When, ast.WhenClause{Condition: condition, Result: result})
+	}
+	if len(expr.When) == 0 {
+		p.addError(p.curToken.Pos, "CASE requires at least one WHEN clause")
+		return expr
+	}
+	if p.peekTokenIs(token.ELSE) {
+		p.nextToken()
+		p.nextToken()
+		expr.Else = p.parseExpression(lowest)
+	}
+	p.expectPeek(token.END)
+	return expr
+}
+
+// intervalUnits maps accepted INTERVAL unit spellings to their canonical
+// singular form.
+var intervalUnits = map[string]string{
+	"SECOND":  "SECOND",
+	"SECONDS": "SECOND",
+	"MINUTE":  "MINUTE",
+	"MINUTES": "MINUTE",
+	"HOUR":    "HOUR",
+	"HOURS":   "HOUR",
+	"DAY":     "DAY",
+	"DAYS":    "DAY",
+	"WEEK":    "WEEK",
+	"WEEKS":   "WEEK",
+	"MONTH":   "MONTH",
+	"MONTHS":  "MONTH",
+	"YEAR":    "YEAR",
+	"YEARS":   "YEAR",
+}
+
+// parseIntervalLiteral handles both INTERVAL 1 HOUR and INTERVAL '5 minutes'.
+func (p *Parser) parseIntervalLiteral() ast.Expr {
+	switch p.peekToken.Type {
+	case token.NUMBER:
+		p.nextToken()
+		value := p.curToken.Literal
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		unit, ok := intervalUnits[strings.ToUpper(p.curToken.Literal)]
+		if !ok {
+			p.addError(p.curToken.Pos, "unsupported interval unit %s", p.curToken.Literal)
+			return nil
+		}
+		return &ast.IntervalExpr{Value: value, Unit: unit}
+	case token.STRING:
+		p.nextToken()
+		fields := strings.Fields(p.curToken.Literal)
+		if len(fields) != 2 {
+			p.addError(p.curToken.Pos, "interval literal must look like '<number> <unit>'")
+			return nil
+		}
+		if !isIntervalQuantity(fields[0]) {
+			p.addError(p.curToken.Pos, "invalid interval quantity %s", fields[0])
+			return nil
+		}
+		unit, ok := intervalUnits[strings.ToUpper(fields[1])]
+		if !ok {
+			p.addError(p.curToken.Pos, "unsupported interval unit %s", fields[1])
+			return nil
+		}
+		return &ast.IntervalExpr{Value: fields[0], Unit: unit}
+	default:
+		p.addError(p.peekToken.Pos, "expected number or string after INTERVAL, got %s", p.peekToken.Type)
+		return nil
+	}
+}
+
+// isIntervalQuantity reports whether s is a plain non-negative number.
+func isIntervalQuantity(s string) bool {
+	if s == "" {
+		return false
+	}
+	dots := 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] >= '0' && s[i] <= '9':
+		case s[i] == '.':
+			dots++
+			if dots > 1 {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return s != "."
+}
+
 func (p *Parser) parseExistsExpression(negate bool) ast.Expr {
 	if !p.expectPeek(token.LPAREN) {
 		return nil
@@ -814,7 +1256,9 @@ func (p *Parser) parseInfixExpression(left ast.Expr) ast.Expr {
 	case token.IN:
 		return p.parseInExpression(left, false)
 	case token.LIKE:
-		return p.parseLikeExpression(left, false)
+		return p.parseLikeExpression(left, false, false)
+	case token.ILIKE:
+		return p.parseLikeExpression(left, false, true)
 	case token.BETWEEN:
 		return p.parseBetweenExpression(left, false)
 	case token.IS:
@@ -826,7 +1270,10 @@ func (p *Parser) parseInfixExpression(left ast.Expr) ast.Expr {
 			return p.parseInExpression(left, true)
 		case p.peekTokenIs(token.LIKE):
 			p.nextToken()
-			return p.parseLikeExpression(left, true)
+			return p.parseLikeExpression(left, true, false)
+		case p.peekTokenIs(token.ILIKE):
+			p.nextToken()
+			return p.parseLikeExpression(left, true, true)
 		case p.peekTokenIs(token.BETWEEN):
 			p.nextToken()
 			return p.parseBetweenExpression(left, true)
@@ -871,6 +1318,22 @@ func (p *Parser) parseInfixExpression(left ast.Expr) ast.Expr {
 		}
 		call.Over = p.parseWindowSpecification()
 		return call
+	case token.FILTER:
+		call, ok := left.(*ast.FuncCall)
+		if !ok {
+			p.addError(p.curToken.Pos, "FILTER requires preceding function call")
+			return left
+		}
+		if !p.expectPeek(token.LPAREN) {
+			return call
+		}
+		if !p.expectPeek(token.WHERE) {
+			return call
+		}
+		p.nextToken()
+		call.Filter = p.parseExpression(lowest)
+		p.expectPeek(token.RPAREN)
+		return call
 	case token.DOT:
 		ident, ok := left.(*ast.Identifier)
 		if !ok {
@@ -914,10 +1377,10 @@ func (p *Parser) parseInExpression(left ast.Expr, not bool) ast.Expr {
 	return expr
 }
 
-func (p *Parser) parseLikeExpression(left ast.Expr, not bool) ast.Expr {
+func (p *Parser) parseLikeExpression(left ast.Expr, not, caseInsensitive bool) ast.Expr {
 	p.nextToken()
 	pattern := p.parseExpression(precedenceComparison)
-	return &ast.LikeExpr{Expr: left, Not: not, Pattern: pattern}
+	return &ast.LikeExpr{Expr: left, Not: not, CaseInsensitive: caseInsensitive, Pattern: pattern}
 }
 
 func (p *Parser) parseBetweenExpression(left ast.Expr, not bool) ast.Expr {
@@ -950,9 +1413,12 @@ func (p *Parser) parseInsertStatement() *ast.InsertStatement {
 		return stmt
 	}
 	p.nextToken()
-	table := p.parseTableFactor()
-	if tbl, ok := table.(*ast.TableName); ok {
-		stmt.Table = tbl
+	// The target is a plain table name; a following LPAREN is the column
+	// list, not a table function.
+	if p.curTokenIs(token.IDENT) {
+		stmt.Table = &ast.TableName{Name: p.parseQualifiedName()}
+	} else {
+		p.addError(p.curToken.Pos, "expected table name after INTO, got %s", p.curToken.Type)
 	}
 	if p.peekTokenIs(token.LPAREN) {
 		p.nextToken()
@@ -1024,6 +1490,41 @@ func (p *Parser) parseAssignment() ast.Assignment {
 	return ast.Assignment{Column: name, Value: value}
 }
 
+func (p *Parser) parseSetStatement() *ast.SetStatement {
+	stmt := &ast.SetStatement{}
+	if !p.expectPeek(token.IDENT) {
+		return stmt
+	}
+	stmt.Name = p.parseQualifiedName()
+	// Accept both SET name = value and the PostgreSQL SET name TO value.
+	if p.peekTokenIs(token.IDENT) && strings.EqualFold(p.peekToken.Literal, "TO") {
+		p.nextToken()
+	} else if !p.expectPeek(token.EQ) {
+		return stmt
+	}
+	p.nextToken()
+	stmt.Value = p.parseExpression(lowest)
+	return stmt
+}
+
+func (p *Parser) parseResetStatement() *ast.ResetStatement {
+	stmt := &ast.ResetStatement{}
+	if !p.expectPeek(token.IDENT) {
+		return stmt
+	}
+	stmt.Name = p.parseQualifiedName()
+	return stmt
+}
+
+func (p *Parser) parseUseStatement() *ast.UseStatement {
+	stmt := &ast.UseStatement{}
+	if !p.expectPeek(token.IDENT) {
+		return stmt
+	}
+	stmt.Schema = p.parseQualifiedName()
+	return stmt
+}
+
 func (p *Parser) parseDeleteStatement() *ast.DeleteStatement {
 	stmt := &ast.DeleteStatement{}
 	if !p.expectPeek(token.FROM) {
@@ -1050,6 +1551,11 @@ func (p *Parser) parseCreateViewStatement() *ast.CreateViewStatement {
 		stmt.OrReplace = true
 	}
 
+	if p.peekTokenIs(token.TEMPORARY) {
+		p.expectPeek(token.TEMPORARY)
+		stmt.Temporary = true
+	}
+
 	if p.peekTokenIs(token.MATERIALIZED) {
 		p.expectPeek(token.MATERIALIZED)
 		stmt.Materialized = true
@@ -1093,6 +1599,33 @@ func (p *Parser) parseCreateViewStatement() *ast.CreateViewStatement {
 		}
 	}
 
+	// WITH PARAMETERS (name, ...) declares template parameters; WITH only
+	// introduces CTEs after AS, so there is no ambiguity here.
+	if p.peekTokenIs(token.WITH) {
+		p.expectPeek(token.WITH)
+		if !p.expectPeek(token.IDENT) || !strings.EqualFold(p.curToken.Literal, "PARAMETERS") {
+			p.addError(p.curToken.Pos, "expected PARAMETERS after WITH, got %s", p.curToken.Literal)
+			return stmt
+		}
+		if !p.expectPeek(token.LPAREN) {
+			return stmt
+		}
+		if !p.expectPeek(token.IDENT) {
+			return stmt
+		}
+		stmt.Parameters = append(stmt.Parameters, p.parseQualifiedName())
+		for p.peekTokenIs(token.COMMA) {
+			p.nextToken()
+			if !p.expectPeek(token.IDENT) {
+				return stmt
+			}
+			stmt.Parameters = append(stmt.Parameters, p.parseQualifiedName())
+		}
+		if !p.expectPeek(token.RPAREN) {
+			return stmt
+		}
+	}
+
 	if !p.expectPeek(token.AS) {
 		return stmt
 	}
@@ -208,6 +208,53 @@ func TestParseAdditionalPredicates(t *testing.T) {
 	}
 }
 
+func TestParseIntervalLiteral(t *testing.T) {
+	tests := []struct {
+		name  string
+		sql   string
+		value string
+		unit  string
+	}{
+		{
+			name:  "number with unit keyword",
+			sql:   "SELECT * FROM logs WHERE ts >= DATE_SUB(NOW(), INTERVAL 1 HOUR)",
+			value: "1",
+			unit:  "HOUR",
+		},
+		{
+			name:  "quoted quantity normalizes plural unit",
+			sql:   "SELECT * FROM logs WHERE ts >= DATE_SUB(NOW(), INTERVAL '5 minutes')",
+			value: "5",
+			unit:  "MINUTE",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			stmt := mustParse(t, tc.sql)
+			selectStmt, ok := stmt.(*ast.SelectStatement)
+			if !ok {
+				t.Fatalf("expected SelectStatement, got %T", stmt)
+			}
+			cmp, ok := selectStmt.Where.(*ast.BinaryExpr)
+			if !ok {
+				t.Fatalf("expected BinaryExpr in WHERE, got %T", selectStmt.Where)
+			}
+			fn, ok := cmp.Right.(*ast.FuncCall)
+			if !ok || len(fn.Args) != 2 {
+				t.Fatalf("expected two-argument FuncCall, got %T", cmp.Right)
+			}
+			interval, ok := fn.Args[1].(*ast.IntervalExpr)
+			if !ok {
+				t.Fatalf("expected IntervalExpr argument, got %T", fn.Args[1])
+			}
+			if interval.Value != tc.value || interval.Unit != tc.unit {
+				t.Fatalf("unexpected interval: got %s %s, want %s %s", interval.Value, interval.Unit, tc.value, tc.unit)
+			}
+		})
+	}
+}
+
 func TestParseDescribeStatement(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -526,6 +573,11 @@ func TestParseStatementInvalidSQL(t *testing.T) {
 			sql:  "DROP VIEW",
 			want: "expected IDENT",
 		},
+		{
+			name: "interval with unknown unit",
+			sql:  "SELECT INTERVAL 5 fortnights",
+			want: "unsupported interval unit fortnights",
+		},
 	}
 
 	for _, tt := range tests {
@@ -554,3 +606,77 @@ func TestParseStatementInvalidSQL(t *testing.T) {
 		})
 	}
 }
+
+func TestTokenCountLimit(t *testing.T) {
+	var sb strings.Builder
+	sb.WriteString("SELECT 0")
+	for i := 0; i < sqlparser.MaxTokenCount; i++ {
+		sb.WriteString("+0")
+	}
+	l := lexer.New(sb.String())
+	p := sqlparser.New(l)
+	_ = p.ParseStatement()
+
+	found := false
+	for _, err := range p.Errors() {
+		if strings.Contains(err.Error(), "maximum token count exceeded") {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Fatalf("expected a token count error, got %v", p.Errors())
+	}
+}
+
+func TestErrorRecoveryReportsMultipleErrors(t *testing.T) {
+	tests := []struct {
+		sql     string
+		columns []int
+	}{
+		{
+			// Independent mistakes in WHERE, GROUP BY and ORDER BY should
+			// all be reported in one pass.
+			sql:     "SELECT * FROM logs WHERE = 1 GROUP BY = ORDER BY =",
+			columns: []int{26, 39, 50},
+		},
+		{
+			sql:     "SELECT a, FROM logs WHERE = 5 ORDER BY y DESC",
+			columns: []int{11, 27},
+		},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.sql)
+		p := sqlparser.New(l)
+		_ = p.ParseStatement()
+
+		errs := p.Errors()
+		if len(errs) != len(tt.columns) {
+			t.Fatalf("%q: expected %d errors, got %v", tt.sql, len(tt.columns), errs)
+		}
+		for i, col := range tt.columns {
+			syntaxErr, ok := errs[i].(*sqlparser.SyntaxError)
+			if !ok {
+				t.Fatalf("%q: expected SyntaxError, got %T", tt.sql, errs[i])
+			}
+			if syntaxErr.Pos.Column != col {
+				t.Fatalf("%q: expected error %d at column %d, got %+v", tt.sql, i, col, syntaxErr)
+			}
+		}
+	}
+}
+
+func TestUnpivotNotSupported(t *testing.T) {
+	l := lexer.New("SELECT * FROM logs UNPIVOT (x FOR y IN (a))")
+	p := sqlparser.New(l)
+	_ = p.ParseStatement()
+
+	errs := p.Errors()
+	if len(errs) == 0 {
+		t.Fatal("expected parse errors for UNPIVOT")
+	}
+	if !strings.Contains(errs[0].Error(), "UNPIVOT is not supported") {
+		t.Fatalf("expected UNPIVOT error, got %v", errs)
+	}
+}
@@ -3,8 +3,10 @@ package render
 import (
 	"fmt"
 	"strings"
+	"unicode"
 
 	"github.com/VictoriaMetrics/sql-to-logsql/lib/sql/ast"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/sql/token"
 )
 
 // Render produces a canonical SQL string for the supplied AST node.
@@ -100,6 +102,9 @@ func (r *renderer) renderSelect(stmt *ast.SelectStatement) {
 				r.write(", ")
 			}
 			r.renderExpr(item.Expr)
+			if item.Collation != "" {
+				r.write(" COLLATE " + item.Collation)
+			}
 			if item.Direction == ast.Descending {
 				r.write(" DESC")
 			} else {
@@ -240,6 +245,9 @@ func (r *renderer) renderCreateView(stmt *ast.CreateViewStatement) {
 	if stmt.OrReplace {
 		r.write("OR REPLACE ")
 	}
+	if stmt.Temporary {
+		r.write("TEMPORARY ")
+	}
 	if stmt.Materialized {
 		r.write("MATERIALIZED ")
 	}
@@ -258,6 +266,16 @@ func (r *renderer) renderCreateView(stmt *ast.CreateViewStatement) {
 		}
 		r.write(")")
 	}
+	if len(stmt.Parameters) > 0 {
+		r.write(" WITH PARAMETERS (")
+		for i, param := range stmt.Parameters {
+			if i > 0 {
+				r.write(", ")
+			}
+			r.renderIdentifier(param)
+		}
+		r.write(")")
+	}
 	r.write(" AS ")
 	if stmt.Select == nil {
 		r.errs = append(r.errs, fmt.Errorf("render: CREATE VIEW missing select"))
@@ -280,6 +298,67 @@ func (r *renderer) renderTable(tbl ast.TableExpr) {
 		if t.Alias != "" {
 			r.write(" AS ", t.Alias)
 		}
+	case *ast.PivotTable:
+		r.renderTable(t.Source)
+		r.write(" PIVOT (")
+		r.renderExpr(t.Agg)
+		r.write(" FOR ")
+		r.renderIdentifier(t.For)
+		r.write(" IN (")
+		for i, pv := range t.Values {
+			if i > 0 {
+				r.write(", ")
+			}
+			r.renderExpr(pv.Value)
+			if pv.Alias != "" {
+				r.write(" AS ", pv.Alias)
+			}
+		}
+		r.write("))")
+		if t.Alias != "" {
+			r.write(" AS ", t.Alias)
+		}
+	case *ast.ValuesTable:
+		r.write("(VALUES ")
+		for i, row := range t.Rows {
+			if i > 0 {
+				r.write(", ")
+			}
+			r.write("(")
+			for j, expr := range row {
+				if j > 0 {
+					r.write(", ")
+				}
+				r.renderExpr(expr)
+			}
+			r.write(")")
+		}
+		r.write(")")
+		if t.Alias != "" {
+			r.write(" AS ", t.Alias)
+			if len(t.Columns) > 0 {
+				r.write("(", strings.Join(t.Columns, ", "), ")")
+			}
+		}
+	case *ast.TableFunction:
+		r.renderIdentifier(t.Name)
+		r.write("(")
+		for i, arg := range t.Args {
+			if i > 0 {
+				r.write(", ")
+			}
+			r.renderExpr(arg)
+		}
+		r.write(")")
+		if t.Ordinality {
+			r.write(" WITH ORDINALITY")
+		}
+		if t.Alias != "" {
+			r.write(" AS ", t.Alias)
+			if len(t.Columns) > 0 {
+				r.write("(", strings.Join(t.Columns, ", "), ")")
+			}
+		}
 	case *ast.JoinExpr:
 		r.renderTable(t.Left)
 		r.write(" ")
@@ -312,11 +391,41 @@ func (r *renderer) renderJoinType(j ast.JoinType) {
 	}
 }
 
+// renderIdentifier writes id, double-quoting any part that would not survive
+// re-parsing as a single bare identifier, e.g. a field literally named
+// "l.duration" or one that collides with a keyword.
 func (r *renderer) renderIdentifier(id *ast.Identifier) {
 	if id == nil {
 		return
 	}
-	r.write(strings.Join(id.Parts, "."))
+	for i, part := range id.Parts {
+		if i > 0 {
+			r.write(".")
+		}
+		if bareIdentifier(part) {
+			r.write(part)
+		} else {
+			r.write(`"`, strings.ReplaceAll(part, `"`, `""`), `"`)
+		}
+	}
+}
+
+// bareIdentifier reports whether part lexes back into the same single
+// identifier token without quoting.
+func bareIdentifier(part string) bool {
+	if part == "" {
+		return false
+	}
+	for i, rn := range part {
+		if rn == '_' || unicode.IsLetter(rn) {
+			continue
+		}
+		if i > 0 && unicode.IsDigit(rn) {
+			continue
+		}
+		return false
+	}
+	return token.Lookup(strings.ToUpper(part)) == token.IDENT
 }
 
 func (r *renderer) renderExpr(expr ast.Expr) {
@@ -337,6 +446,8 @@ func (r *renderer) renderExpr(expr ast.Expr) {
 		r.write("NULL")
 	case *ast.Placeholder:
 		r.write(e.Symbol)
+	case *ast.IntervalExpr:
+		r.write("INTERVAL ", e.Value, " ", e.Unit)
 	case *ast.BinaryExpr:
 		r.write("(")
 		r.renderExpr(e.Left)
@@ -364,6 +475,28 @@ func (r *renderer) renderExpr(expr ast.Expr) {
 			r.renderExpr(arg)
 		}
 		r.write(")")
+		if e.Filter != nil {
+			r.write(" FILTER (WHERE ")
+			r.renderExpr(e.Filter)
+			r.write(")")
+		}
+	case *ast.CaseExpr:
+		r.write("CASE")
+		if e.Operand != nil {
+			r.write(" ")
+			r.renderExpr(e.Operand)
+		}
+		for _, when := range e.When {
+			r.write(" WHEN ")
+			r.renderExpr(when.Condition)
+			r.write(" THEN ")
+			r.renderExpr(when.Result)
+		}
+		if e.Else != nil {
+			r.write(" ELSE ")
+			r.renderExpr(e.Else)
+		}
+		r.write(" END")
 	case *ast.StarExpr:
 		if e.Table != nil {
 			r.renderIdentifier(e.Table)
@@ -402,7 +535,11 @@ func (r *renderer) renderExpr(expr ast.Expr) {
 		if e.Not {
 			r.write(" NOT")
 		}
-		r.write(" LIKE ")
+		if e.CaseInsensitive {
+			r.write(" ILIKE ")
+		} else {
+			r.write(" LIKE ")
+		}
 		r.renderExpr(e.Pattern)
 	case *ast.IsNullExpr:
 		r.renderExpr(e.Expr)
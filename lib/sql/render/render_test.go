@@ -51,6 +51,28 @@ func TestRenderSelectWithCTEAndUnion(t *testing.T) {
 	}
 }
 
+func TestRenderQuotedIdentifier(t *testing.T) {
+	stmt := &ast.SelectStatement{
+		Columns: []ast.SelectItem{{Expr: &ast.Identifier{Parts: []string{"l.duration"}}}},
+		From:    &ast.TableName{Name: &ast.Identifier{Parts: []string{"logs"}}, Alias: "l"},
+		Where: &ast.BinaryExpr{
+			Left:     &ast.Identifier{Parts: []string{"l", "level"}},
+			Operator: "=",
+			Right:    &ast.StringLiteral{Value: "error"},
+		},
+	}
+
+	out, err := Render(stmt)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+
+	expected := `SELECT "l.duration" FROM logs AS l WHERE (l.level = 'error')`
+	if out != expected {
+		t.Fatalf("unexpected render output:\nexpected: %s\nactual:   %s", expected, out)
+	}
+}
+
 func TestRenderInsertValues(t *testing.T) {
 	stmt := &ast.InsertStatement{
 		Table: &ast.TableName{Name: &ast.Identifier{Parts: []string{"accounts"}}},
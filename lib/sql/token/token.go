@@ -1,5 +1,7 @@
 package token
 
+import "sort"
+
 // Type identifies the lexical class of a token.
 type Type string
 
@@ -79,6 +81,21 @@ const (
 	RECURSIVE    Type = "RECURSIVE"
 	OVER         Type = "OVER"
 	PARTITION    Type = "PARTITION"
+	INTERVAL     Type = "INTERVAL"
+	CASE         Type = "CASE"
+	WHEN         Type = "WHEN"
+	THEN         Type = "THEN"
+	ELSE         Type = "ELSE"
+	END          Type = "END"
+	FILTER       Type = "FILTER"
+	FETCH        Type = "FETCH"
+	PIVOT        Type = "PIVOT"
+	UNPIVOT      Type = "UNPIVOT"
+	FOR          Type = "FOR"
+	TEMPORARY    Type = "TEMPORARY"
+	RESET        Type = "RESET"
+	USE          Type = "USE"
+	COLLATE      Type = "COLLATE"
 
 	JOIN  Type = "JOIN"
 	INNER Type = "INNER"
@@ -99,6 +116,7 @@ const (
 	EXISTS  Type = "EXISTS"
 	BETWEEN Type = "BETWEEN"
 	LIKE    Type = "LIKE"
+	ILIKE   Type = "ILIKE"
 	IS      Type = "IS"
 	DESC    Type = "DESC"
 	ASC     Type = "ASC"
@@ -142,6 +160,21 @@ var keywords = map[string]Type{
 	"RECURSIVE":    RECURSIVE,
 	"OVER":         OVER,
 	"PARTITION":    PARTITION,
+	"INTERVAL":     INTERVAL,
+	"CASE":         CASE,
+	"WHEN":         WHEN,
+	"THEN":         THEN,
+	"ELSE":         ELSE,
+	"END":          END,
+	"FILTER":       FILTER,
+	"FETCH":        FETCH,
+	"PIVOT":        PIVOT,
+	"UNPIVOT":      UNPIVOT,
+	"FOR":          FOR,
+	"TEMPORARY":    TEMPORARY,
+	"RESET":        RESET,
+	"USE":          USE,
+	"COLLATE":      COLLATE,
 	"JOIN":         JOIN,
 	"INNER":        INNER,
 	"LEFT":         LEFT,
@@ -160,6 +193,7 @@ var keywords = map[string]Type{
 	"EXISTS":       EXISTS,
 	"BETWEEN":      BETWEEN,
 	"LIKE":         LIKE,
+	"ILIKE":        ILIKE,
 	"IS":           IS,
 	"DESC":         DESC,
 	"ASC":          ASC,
@@ -176,3 +210,13 @@ func Lookup(ident string) Type {
 	}
 	return IDENT
 }
+
+// Keywords returns every reserved word, sorted, for editor autocompletion.
+func Keywords() []string {
+	names := make([]string, 0, len(keywords))
+	for name := range keywords {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,219 @@
+package sqlgen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// convertFilter translates the filter part of a LogsQL query to a SQL WHERE
+// condition. An empty string is returned for the match-all filter "*".
+func convertFilter(filter string) (string, error) {
+	if filter == "" || filter == "*" {
+		return "", nil
+	}
+	p := &filterParser{input: filter}
+	cond, err := p.parseExpr()
+	if err != nil {
+		return "", err
+	}
+	p.skipSpaces()
+	if p.pos < len(p.input) {
+		return "", fmt.Errorf("sqlgen: unexpected input at %q", p.input[p.pos:])
+	}
+	return cond, nil
+}
+
+// filterParser is a recursive-descent parser over the LogsQL filter grammar
+// subset: terms joined by AND/OR (AND is implicit between adjacent terms),
+// NOT / '-' negation, parenthesized groups and field:value filters.
+type filterParser struct {
+	input string
+	pos   int
+}
+
+func (p *filterParser) skipSpaces() {
+	for p.pos < len(p.input) && p.input[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func (p *filterParser) parseExpr() (string, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return "", err
+	}
+	for {
+		p.skipSpaces()
+		if p.pos >= len(p.input) || p.input[p.pos] == ')' {
+			return left, nil
+		}
+		op := "AND"
+		switch {
+		case p.hasKeyword("or"):
+			op = "OR"
+			p.pos += 2
+		case p.hasKeyword("and"):
+			p.pos += 3
+		}
+		right, err := p.parseTerm()
+		if err != nil {
+			return "", err
+		}
+		left = fmt.Sprintf("%s %s %s", left, op, right)
+	}
+}
+
+func (p *filterParser) parseTerm() (string, error) {
+	p.skipSpaces()
+	if p.pos >= len(p.input) {
+		return "", fmt.Errorf("sqlgen: unexpected end of filter")
+	}
+	switch {
+	case p.input[p.pos] == '(':
+		p.pos++
+		inner, err := p.parseExpr()
+		if err != nil {
+			return "", err
+		}
+		p.skipSpaces()
+		if p.pos >= len(p.input) || p.input[p.pos] != ')' {
+			return "", fmt.Errorf("sqlgen: missing closing parenthesis")
+		}
+		p.pos++
+		return "(" + inner + ")", nil
+	case p.input[p.pos] == '-':
+		p.pos++
+		inner, err := p.parseTerm()
+		if err != nil {
+			return "", err
+		}
+		return "NOT " + inner, nil
+	case p.hasKeyword("not"):
+		p.pos += 3
+		inner, err := p.parseTerm()
+		if err != nil {
+			return "", err
+		}
+		return "NOT " + inner, nil
+	}
+	return p.parseFieldFilter()
+}
+
+// hasKeyword reports whether a case-insensitive keyword starts at the
+// current position, followed by a space or parenthesis.
+func (p *filterParser) hasKeyword(kw string) bool {
+	end := p.pos + len(kw)
+	if end > len(p.input) {
+		return false
+	}
+	if !strings.EqualFold(p.input[p.pos:end], kw) {
+		return false
+	}
+	return end == len(p.input) || p.input[end] == ' ' || p.input[end] == '('
+}
+
+func (p *filterParser) parseFieldFilter() (string, error) {
+	start := p.pos
+	for p.pos < len(p.input) {
+		ch := p.input[p.pos]
+		if ch == ' ' || ch == '(' || ch == ')' {
+			break
+		}
+		if ch == ':' {
+			break
+		}
+		p.pos++
+	}
+	word := p.input[start:p.pos]
+	if p.pos >= len(p.input) || p.input[p.pos] != ':' {
+		// A bare word is a full-text match on the message field.
+		if word == "" {
+			return "", fmt.Errorf("sqlgen: empty filter term")
+		}
+		return fmt.Sprintf("_msg LIKE '%%%s%%'", escapeString(word)), nil
+	}
+	p.pos++ // skip ':'
+	value, err := p.parseValue()
+	if err != nil {
+		return "", err
+	}
+	return fieldCondition(word, value)
+}
+
+// parseValue reads the value part of a field:value filter, honoring quotes.
+func (p *filterParser) parseValue() (string, error) {
+	if p.pos < len(p.input) && (p.input[p.pos] == '>' || p.input[p.pos] == '<' || p.input[p.pos] == '~' || p.input[p.pos] == '=') {
+		op := string(p.input[p.pos])
+		p.pos++
+		if p.pos < len(p.input) && p.input[p.pos] == '=' && (op == ">" || op == "<") {
+			op += "="
+			p.pos++
+		}
+		rest, err := p.parseValue()
+		if err != nil {
+			return "", err
+		}
+		return op + "\x00" + rest, nil
+	}
+	if p.pos < len(p.input) && p.input[p.pos] == '"' {
+		end := p.pos + 1
+		for end < len(p.input) && p.input[end] != '"' {
+			if p.input[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end >= len(p.input) {
+			return "", fmt.Errorf("sqlgen: unterminated quoted value")
+		}
+		value := p.input[p.pos+1 : end]
+		p.pos = end + 1
+		return value, nil
+	}
+	start := p.pos
+	for p.pos < len(p.input) {
+		ch := p.input[p.pos]
+		if ch == ' ' || ch == ')' {
+			break
+		}
+		p.pos++
+	}
+	if p.pos == start {
+		return "", fmt.Errorf("sqlgen: empty filter value")
+	}
+	return p.input[start:p.pos], nil
+}
+
+// fieldCondition maps a single field filter to a SQL comparison.
+func fieldCondition(field, value string) (string, error) {
+	if op, rest, found := strings.Cut(value, "\x00"); found {
+		switch op {
+		case ">", ">=", "<", "<=":
+			return fmt.Sprintf("%s %s %s", field, op, sqlLiteral(rest)), nil
+		case "=":
+			return fmt.Sprintf("%s = %s", field, sqlLiteral(rest)), nil
+		case "~":
+			return fmt.Sprintf("%s REGEXP '%s'", field, escapeString(rest)), nil
+		}
+		return "", fmt.Errorf("sqlgen: unsupported comparison %q", op)
+	}
+	if strings.ContainsAny(value, "*") {
+		pattern := strings.ReplaceAll(escapeString(value), "*", "%")
+		return fmt.Sprintf("%s LIKE '%s'", field, pattern), nil
+	}
+	return fmt.Sprintf("%s = %s", field, sqlLiteral(value)), nil
+}
+
+// sqlLiteral renders value as a SQL literal: numbers stay bare, everything
+// else is single-quoted.
+func sqlLiteral(value string) string {
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return value
+	}
+	return "'" + escapeString(value) + "'"
+}
+
+func escapeString(s string) string {
+	return strings.ReplaceAll(s, "'", "''")
+}
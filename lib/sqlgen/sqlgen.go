@@ -0,0 +1,223 @@
+// Package sqlgen converts LogsQL queries back into SQL on a best-effort
+// basis, so teams migrating saved LogsQL queries into SQL-based tooling can
+// bootstrap automatically. The converter understands the filter part plus
+// the stats, fields, sort, limit and offset pipes; queries using other pipes
+// are rejected with an error naming the unsupported stage.
+package sqlgen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Options controls SQL generation.
+type Options struct {
+	// Table is the table name used in the FROM clause. Defaults to "logs".
+	Table string
+}
+
+// Convert translates a LogsQL query into an equivalent SQL statement.
+func Convert(logsQL string, opts Options) (string, error) {
+	table := opts.Table
+	if table == "" {
+		table = "logs"
+	}
+	stages := splitPipes(strings.TrimSpace(logsQL))
+	if len(stages) == 0 || strings.TrimSpace(stages[0]) == "" {
+		return "", fmt.Errorf("sqlgen: empty query")
+	}
+
+	where, err := convertFilter(strings.TrimSpace(stages[0]))
+	if err != nil {
+		return "", err
+	}
+
+	selectList := "*"
+	var groupBy, orderBy, limit, offset string
+	for _, stage := range stages[1:] {
+		stage = strings.TrimSpace(stage)
+		verb := stage
+		if cut := strings.IndexByte(stage, ' '); cut >= 0 {
+			verb = stage[:cut]
+		}
+		switch verb {
+		case "stats":
+			selectList, groupBy, err = convertStats(strings.TrimSpace(strings.TrimPrefix(stage, "stats")))
+			if err != nil {
+				return "", err
+			}
+		case "fields":
+			selectList = strings.TrimSpace(strings.TrimPrefix(stage, "fields"))
+		case "sort":
+			orderBy, err = convertSort(strings.TrimSpace(strings.TrimPrefix(stage, "sort")))
+			if err != nil {
+				return "", err
+			}
+		case "limit", "head":
+			limit = strings.TrimSpace(strings.TrimPrefix(stage, verb))
+			if _, err := strconv.Atoi(limit); err != nil {
+				return "", fmt.Errorf("sqlgen: invalid limit %q", limit)
+			}
+		case "offset", "skip":
+			offset = strings.TrimSpace(strings.TrimPrefix(stage, verb))
+			if _, err := strconv.Atoi(offset); err != nil {
+				return "", fmt.Errorf("sqlgen: invalid offset %q", offset)
+			}
+		default:
+			return "", fmt.Errorf("sqlgen: unsupported pipe %q", verb)
+		}
+	}
+
+	var builder strings.Builder
+	builder.WriteString("SELECT ")
+	builder.WriteString(selectList)
+	builder.WriteString(" FROM ")
+	builder.WriteString(table)
+	if where != "" {
+		builder.WriteString(" WHERE ")
+		builder.WriteString(where)
+	}
+	if groupBy != "" {
+		builder.WriteString(" GROUP BY ")
+		builder.WriteString(groupBy)
+	}
+	if orderBy != "" {
+		builder.WriteString(" ORDER BY ")
+		builder.WriteString(orderBy)
+	}
+	if limit != "" {
+		builder.WriteString(" LIMIT ")
+		builder.WriteString(limit)
+	}
+	if offset != "" {
+		builder.WriteString(" OFFSET ")
+		builder.WriteString(offset)
+	}
+	return builder.String(), nil
+}
+
+// convertStats maps a stats pipe body ("by (a, b) count(*) as total") to a
+// SELECT list and GROUP BY clause.
+func convertStats(body string) (string, string, error) {
+	var groupFields []string
+	if strings.HasPrefix(body, "by") {
+		rest := strings.TrimSpace(strings.TrimPrefix(body, "by"))
+		if !strings.HasPrefix(rest, "(") {
+			return "", "", fmt.Errorf("sqlgen: malformed stats by clause")
+		}
+		end := strings.Index(rest, ")")
+		if end < 0 {
+			return "", "", fmt.Errorf("sqlgen: unterminated stats by clause")
+		}
+		groupFields = splitList(rest[1:end])
+		body = strings.TrimSpace(rest[end+1:])
+	}
+	selectParts := append([]string{}, groupFields...)
+	for _, agg := range splitList(body) {
+		if agg == "" {
+			continue
+		}
+		idx := strings.LastIndex(agg, " as ")
+		if idx < 0 {
+			selectParts = append(selectParts, agg)
+			continue
+		}
+		selectParts = append(selectParts, fmt.Sprintf("%s AS %s",
+			strings.TrimSpace(agg[:idx]), strings.TrimSpace(agg[idx+4:])))
+	}
+	if len(selectParts) == 0 {
+		return "", "", fmt.Errorf("sqlgen: empty stats pipe")
+	}
+	return strings.Join(selectParts, ", "), strings.Join(groupFields, ", "), nil
+}
+
+// convertSort maps a sort pipe body ("by (total desc, level)") to an ORDER
+// BY clause.
+func convertSort(body string) (string, error) {
+	body = strings.TrimSpace(strings.TrimPrefix(body, "by"))
+	body = strings.TrimPrefix(body, "(")
+	body = strings.TrimSuffix(body, ")")
+	parts := splitList(body)
+	if len(parts) == 0 {
+		return "", fmt.Errorf("sqlgen: empty sort pipe")
+	}
+	orders := make([]string, 0, len(parts))
+	for _, part := range parts {
+		field := part
+		dir := ""
+		if strings.HasSuffix(part, " desc") {
+			field = strings.TrimSuffix(part, " desc")
+			dir = " DESC"
+		} else if strings.HasSuffix(part, " asc") {
+			field = strings.TrimSuffix(part, " asc")
+		}
+		orders = append(orders, strings.TrimSpace(field)+dir)
+	}
+	return strings.Join(orders, ", "), nil
+}
+
+func splitList(s string) []string {
+	parts := make([]string, 0)
+	depth := 0
+	start := 0
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				if p := strings.TrimSpace(s[start:i]); p != "" {
+					parts = append(parts, p)
+				}
+				start = i + 1
+			}
+		}
+	}
+	if p := strings.TrimSpace(s[start:]); p != "" {
+		parts = append(parts, p)
+	}
+	return parts
+}
+
+// splitPipes splits a LogsQL query on top-level '|' separators, ignoring
+// pipes inside quoted literals and parentheses.
+func splitPipes(query string) []string {
+	stages := make([]string, 0)
+	depth := 0
+	var quote rune
+	escaped := false
+	start := 0
+	for i, r := range query {
+		if quote != 0 {
+			if escaped {
+				escaped = false
+				continue
+			}
+			switch r {
+			case '\\':
+				escaped = true
+			case quote:
+				quote = 0
+			}
+			continue
+		}
+		switch r {
+		case '"', '\'', '`':
+			quote = r
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case '|':
+			if depth == 0 {
+				stages = append(stages, query[start:i])
+				start = i + 1
+			}
+		}
+	}
+	stages = append(stages, query[start:])
+	return stages
+}
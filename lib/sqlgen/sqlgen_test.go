@@ -0,0 +1,95 @@
+package sqlgen_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/sqlgen"
+)
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name   string
+		logsQL string
+		want   string
+	}{
+		{
+			name:   "match all",
+			logsQL: "*",
+			want:   "SELECT * FROM logs",
+		},
+		{
+			name:   "simple field filter",
+			logsQL: "level:error",
+			want:   "SELECT * FROM logs WHERE level = 'error'",
+		},
+		{
+			name:   "quoted phrase",
+			logsQL: `_msg:"connection refused"`,
+			want:   "SELECT * FROM logs WHERE _msg = 'connection refused'",
+		},
+		{
+			name:   "numeric comparison",
+			logsQL: "duration:>100",
+			want:   "SELECT * FROM logs WHERE duration > 100",
+		},
+		{
+			name:   "negation and or",
+			logsQL: "level:error or -level:debug",
+			want:   "SELECT * FROM logs WHERE level = 'error' OR NOT level = 'debug'",
+		},
+		{
+			name:   "implicit and with group",
+			logsQL: "level:error (app:web or app:api)",
+			want:   "SELECT * FROM logs WHERE level = 'error' AND (app = 'web' OR app = 'api')",
+		},
+		{
+			name:   "bare word full-text match",
+			logsQL: "timeout",
+			want:   "SELECT * FROM logs WHERE _msg LIKE '%timeout%'",
+		},
+		{
+			name:   "wildcard to like",
+			logsQL: "host:web-*",
+			want:   "SELECT * FROM logs WHERE host LIKE 'web-%'",
+		},
+		{
+			name:   "stats with group and sort",
+			logsQL: "level:error | stats by (level) count(*) as total | sort by (total desc) | limit 10",
+			want:   "SELECT level, count(*) AS total FROM logs WHERE level = 'error' GROUP BY level ORDER BY total DESC LIMIT 10",
+		},
+		{
+			name:   "fields projection with offset",
+			logsQL: "* | fields _time, _msg | limit 5 | offset 10",
+			want:   "SELECT _time, _msg FROM logs LIMIT 5 OFFSET 10",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := sqlgen.Convert(tt.logsQL, sqlgen.Options{})
+			if err != nil {
+				t.Fatalf("Convert(%q) error: %v", tt.logsQL, err)
+			}
+			if got != tt.want {
+				t.Fatalf("Convert(%q) = %q, want %q", tt.logsQL, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertCustomTable(t *testing.T) {
+	got, err := sqlgen.Convert("level:error", sqlgen.Options{Table: "events"})
+	if err != nil {
+		t.Fatalf("Convert error: %v", err)
+	}
+	if got != "SELECT * FROM events WHERE level = 'error'" {
+		t.Fatalf("unexpected SQL: %q", got)
+	}
+}
+
+func TestConvertUnsupportedPipe(t *testing.T) {
+	_, err := sqlgen.Convert("* | extract_regexp 'x(?P<y>.*)'", sqlgen.Options{})
+	if err == nil || !strings.Contains(err.Error(), "unsupported pipe") {
+		t.Fatalf("expected unsupported pipe error, got %v", err)
+	}
+}
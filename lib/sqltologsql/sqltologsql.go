@@ -0,0 +1,93 @@
+// Package sqltologsql exposes the SQL to LogsQL translator as an embeddable
+// API, so other Go services can translate queries without standing up the
+// HTTP server.
+package sqltologsql
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/logsql"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/sql/lexer"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/sql/parser"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/store"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/store/tablestore"
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/store/viewstore"
+)
+
+// Options configures a Translate call.
+type Options struct {
+	// Tables maps table names to LogsQL expressions. When empty, a single
+	// "logs" table matching all logs is assumed.
+	Tables map[string]string
+	// ViewsDir optionally points at a directory with stored .logsql views.
+	// Leave empty to translate without view support.
+	ViewsDir string
+	// Rewriters are applied to the parsed statement, in order, before
+	// translation. See logsql.Rewriter.
+	Rewriters []logsql.Rewriter
+	// PreserveComments carries leading SQL comments into the LogsQL query
+	// as a trailing comment. See logsql.AppendCommentAnnotation.
+	PreserveComments bool
+}
+
+// Warning describes a non-fatal issue detected during translation.
+type Warning struct {
+	Message string
+}
+
+// Translate parses the supplied SQL statement and returns the equivalent
+// LogsQL query together with any warnings. Statements that do not produce a
+// LogsQL query (for example SHOW TABLES) return an empty string and a
+// warning.
+func Translate(sql string, opts Options) (string, []Warning, error) {
+	tables := opts.Tables
+	if len(tables) == 0 {
+		tables = map[string]string{"logs": "*"}
+	}
+	if err := logsql.ValidateTableSpecs(tables); err != nil {
+		return "", nil, fmt.Errorf("sqltologsql: invalid table definition: %w", err)
+	}
+	ts, err := tablestore.NewTableStore(tables)
+	if err != nil {
+		return "", nil, fmt.Errorf("sqltologsql: %w", err)
+	}
+	vs, err := viewstore.NewViewStore(opts.ViewsDir)
+	if err != nil {
+		return "", nil, fmt.Errorf("sqltologsql: %w", err)
+	}
+	sp := store.NewStoreProvider(ts, vs)
+
+	l := lexer.New(sql)
+	p := parser.New(l)
+	stmt := p.ParseStatement()
+	if stmt == nil {
+		return "", nil, errors.New("sqltologsql: no statement parsed")
+	}
+	if perrs := p.Errors(); len(perrs) > 0 {
+		return "", nil, fmt.Errorf("sqltologsql: parse errors: %w", errors.Join(perrs...))
+	}
+
+	stmt, err = logsql.ApplyRewriters(stmt, opts.Rewriters)
+	if err != nil {
+		return "", nil, fmt.Errorf("sqltologsql: %w", err)
+	}
+
+	si, err := logsql.GetStatementInfo(stmt, sp)
+	if err != nil {
+		return "", nil, err
+	}
+	if opts.PreserveComments {
+		si.LogsQL = logsql.AppendCommentAnnotation(si.LogsQL, logsql.LeadingComments(sql))
+	}
+	var warnings []Warning
+	if si.LogsQL == "" {
+		warnings = append(warnings, Warning{
+			Message: fmt.Sprintf("statement of kind %q does not produce a LogsQL query", si.Kind),
+		})
+	}
+	for _, msg := range si.Warnings {
+		warnings = append(warnings, Warning{Message: msg})
+	}
+	return si.LogsQL, warnings, nil
+}
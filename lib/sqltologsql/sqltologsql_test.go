@@ -0,0 +1,83 @@
+package sqltologsql_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/sqltologsql"
+)
+
+func TestTranslate(t *testing.T) {
+	query, warnings, err := sqltologsql.Translate("SELECT * FROM logs WHERE level = 'error'", sqltologsql.Options{})
+	if err != nil {
+		t.Fatalf("Translate error: %v", err)
+	}
+	if len(warnings) != 0 {
+		t.Fatalf("unexpected warnings: %v", warnings)
+	}
+	if query != "level:error" {
+		t.Fatalf("unexpected LogsQL: %q", query)
+	}
+}
+
+func TestTranslateCustomTables(t *testing.T) {
+	query, _, err := sqltologsql.Translate("SELECT * FROM errors", sqltologsql.Options{
+		Tables: map[string]string{"errors": "level:ERROR"},
+	})
+	if err != nil {
+		t.Fatalf("Translate error: %v", err)
+	}
+	if query != "level:ERROR" {
+		t.Fatalf("unexpected LogsQL: %q", query)
+	}
+}
+
+func TestTranslateApproximationWarning(t *testing.T) {
+	query, warnings, err := sqltologsql.Translate("SELECT APPROX_COUNT_DISTINCT(ip) AS ips FROM logs", sqltologsql.Options{})
+	if err != nil {
+		t.Fatalf("Translate error: %v", err)
+	}
+	if query != "* | stats count_uniq(ip) ips" {
+		t.Fatalf("unexpected LogsQL: %q", query)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0].Message, "approximate") {
+		t.Fatalf("expected approximation warning, got %v", warnings)
+	}
+}
+
+func TestTranslatePreserveComments(t *testing.T) {
+	sql := "-- dashboard:latency-panel\nSELECT * FROM logs WHERE level = 'error'"
+	query, _, err := sqltologsql.Translate(sql, sqltologsql.Options{PreserveComments: true})
+	if err != nil {
+		t.Fatalf("Translate error: %v", err)
+	}
+	if query != "level:error # dashboard:latency-panel" {
+		t.Fatalf("unexpected LogsQL: %q", query)
+	}
+	query, _, err = sqltologsql.Translate(sql, sqltologsql.Options{})
+	if err != nil {
+		t.Fatalf("Translate error: %v", err)
+	}
+	if query != "level:error" {
+		t.Fatalf("expected comments dropped by default, got %q", query)
+	}
+}
+
+func TestTranslateParseError(t *testing.T) {
+	if _, _, err := sqltologsql.Translate("SELEC * FROM logs", sqltologsql.Options{}); err == nil {
+		t.Fatal("expected parse error, got nil")
+	}
+}
+
+func TestTranslateNoLogsQLStatement(t *testing.T) {
+	query, warnings, err := sqltologsql.Translate("SHOW TABLES", sqltologsql.Options{})
+	if err != nil {
+		t.Fatalf("Translate error: %v", err)
+	}
+	if query != "" {
+		t.Fatalf("expected empty LogsQL, got %q", query)
+	}
+	if len(warnings) != 1 {
+		t.Fatalf("expected one warning, got %v", warnings)
+	}
+}
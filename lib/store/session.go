@@ -0,0 +1,254 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SessionOptions holds per-session settings adjusted via SET and RESET.
+// Unset options fall back to their defaults.
+type SessionOptions struct {
+	mu     sync.RWMutex
+	values map[string]string
+}
+
+// sessionOptionValidators maps every known option name to its value check.
+var sessionOptionValidators = map[string]func(string) error{
+	"time_zone": func(v string) error {
+		if _, err := time.LoadLocation(v); err != nil {
+			return fmt.Errorf("unknown time zone %q", v)
+		}
+		return nil
+	},
+	"max_rows": func(v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return fmt.Errorf("max_rows expects a non-negative integer, got %q", v)
+		}
+		return nil
+	},
+	"case_insensitive": func(v string) error {
+		if _, err := strconv.ParseBool(v); err != nil {
+			return fmt.Errorf("case_insensitive expects a boolean, got %q", v)
+		}
+		return nil
+	},
+	"recursive_cte_depth": func(v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 100 {
+			return fmt.Errorf("recursive_cte_depth expects an integer between 1 and 100, got %q", v)
+		}
+		return nil
+	},
+	"case_sensitive_identifiers": func(v string) error {
+		if _, err := strconv.ParseBool(v); err != nil {
+			return fmt.Errorf("case_sensitive_identifiers expects a boolean, got %q", v)
+		}
+		return nil
+	},
+	"preserve_comments": func(v string) error {
+		if _, err := strconv.ParseBool(v); err != nil {
+			return fmt.Errorf("preserve_comments expects a boolean, got %q", v)
+		}
+		return nil
+	},
+	"strict_mode": func(v string) error {
+		if _, err := strconv.ParseBool(v); err != nil {
+			return fmt.Errorf("strict_mode expects a boolean, got %q", v)
+		}
+		return nil
+	},
+	"deterministic_aliases": func(v string) error {
+		if _, err := strconv.ParseBool(v); err != nil {
+			return fmt.Errorf("deterministic_aliases expects a boolean, got %q", v)
+		}
+		return nil
+	},
+	"schema": func(v string) error {
+		if strings.TrimSpace(v) == "" {
+			return fmt.Errorf("schema expects a non-empty name")
+		}
+		for _, r := range v {
+			if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') && r != '_' && r != '-' {
+				return fmt.Errorf("invalid character %q in schema name %q", r, v)
+			}
+		}
+		return nil
+	},
+	"dialect": func(v string) error {
+		switch strings.ToLower(v) {
+		case "mysql", "postgres", "trino":
+			return nil
+		}
+		return fmt.Errorf("unknown dialect %q (supported: mysql, postgres, trino)", v)
+	},
+}
+
+// NewSessionOptions returns an empty options set.
+func NewSessionOptions() *SessionOptions {
+	return &SessionOptions{values: make(map[string]string)}
+}
+
+// knownSessionOptions lists the supported option names, sorted for error
+// messages.
+func knownSessionOptions() []string {
+	names := make([]string, 0, len(sessionOptionValidators))
+	for name := range sessionOptionValidators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Set validates and stores an option value.
+func (o *SessionOptions) Set(name, value string) error {
+	if o == nil {
+		return fmt.Errorf("session options are not available on this frontend")
+	}
+	key := strings.ToLower(strings.TrimSpace(name))
+	validate, ok := sessionOptionValidators[key]
+	if !ok {
+		return fmt.Errorf("unknown session option %q (supported: %s)", name, strings.Join(knownSessionOptions(), ", "))
+	}
+	if err := validate(value); err != nil {
+		return err
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.values[key] = value
+	return nil
+}
+
+// Reset removes an option so its default applies again.
+func (o *SessionOptions) Reset(name string) error {
+	if o == nil {
+		return fmt.Errorf("session options are not available on this frontend")
+	}
+	key := strings.ToLower(strings.TrimSpace(name))
+	if _, ok := sessionOptionValidators[key]; !ok {
+		return fmt.Errorf("unknown session option %q (supported: %s)", name, strings.Join(knownSessionOptions(), ", "))
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	delete(o.values, key)
+	return nil
+}
+
+// Get returns the stored value for an option. A nil receiver never matches.
+func (o *SessionOptions) Get(name string) (string, bool) {
+	if o == nil {
+		return "", false
+	}
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	v, ok := o.values[strings.ToLower(name)]
+	return v, ok
+}
+
+// MaxRows returns the session row limit, if one is set.
+func (o *SessionOptions) MaxRows() (int, bool) {
+	v, ok := o.Get("max_rows")
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// CaseInsensitive reports whether string equality should translate to
+// case-insensitive filters.
+func (o *SessionOptions) CaseInsensitive() bool {
+	v, ok := o.Get("case_insensitive")
+	if !ok {
+		return false
+	}
+	b, err := strconv.ParseBool(v)
+	return err == nil && b
+}
+
+// RecursiveCTEDepth returns how many iterations a recursive CTE is unrolled
+// to, defaulting to 10.
+func (o *SessionOptions) RecursiveCTEDepth() int {
+	v, ok := o.Get("recursive_cte_depth")
+	if !ok {
+		return 10
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 {
+		return 10
+	}
+	return n
+}
+
+// CaseSensitiveIdentifiers reports whether table aliases, CTE names and
+// column names are matched with their exact spelling instead of the default
+// case-insensitive SQL behavior. VictoriaLogs field names are case-sensitive.
+func (o *SessionOptions) CaseSensitiveIdentifiers() bool {
+	v, ok := o.Get("case_sensitive_identifiers")
+	if !ok {
+		return false
+	}
+	b, err := strconv.ParseBool(v)
+	return err == nil && b
+}
+
+// PreserveComments reports whether leading SQL comments are carried into
+// the generated LogsQL as a trailing comment for query-log attribution.
+func (o *SessionOptions) PreserveComments() bool {
+	v, ok := o.Get("preserve_comments")
+	if !ok {
+		return false
+	}
+	b, err := strconv.ParseBool(v)
+	return err == nil && b
+}
+
+// StrictMode reports whether translations that cannot preserve exact SQL
+// semantics should be rejected instead of producing a warning.
+func (o *SessionOptions) StrictMode() bool {
+	v, ok := o.Get("strict_mode")
+	if !ok {
+		return false
+	}
+	b, err := strconv.ParseBool(v)
+	return err == nil && b
+}
+
+// DeterministicAliases reports whether auto-generated helper field names
+// should be derived from expression content instead of translation order.
+func (o *SessionOptions) DeterministicAliases() bool {
+	v, ok := o.Get("deterministic_aliases")
+	if !ok {
+		return false
+	}
+	b, err := strconv.ParseBool(v)
+	return err == nil && b
+}
+
+// Schema returns the session's default schema for unqualified table names,
+// set via USE or SET schema, or an empty string when unset.
+func (o *SessionOptions) Schema() string {
+	v, _ := o.Get("schema")
+	return strings.ToLower(v)
+}
+
+// TimeZone returns the session time zone, defaulting to UTC.
+func (o *SessionOptions) TimeZone() string {
+	if v, ok := o.Get("time_zone"); ok {
+		return v
+	}
+	return "UTC"
+}
+
+// Dialect returns the session SQL dialect, or an empty string when unset.
+func (o *SessionOptions) Dialect() string {
+	v, _ := o.Get("dialect")
+	return strings.ToLower(v)
+}
@@ -1,13 +1,20 @@
 package store
 
 import (
+	"strings"
+	"time"
+
 	"github.com/VictoriaMetrics/sql-to-logsql/lib/store/tablestore"
 	"github.com/VictoriaMetrics/sql-to-logsql/lib/store/viewstore"
 )
 
 type Provider struct {
-	tableStore *tablestore.TableStore
-	viewStore  *viewstore.ViewStore
+	tableStore     *tablestore.TableStore
+	viewStore      *viewstore.ViewStore
+	sessionViews   *viewstore.MemStore
+	sessionOptions *SessionOptions
+	timeZone       *time.Location
+	features       map[string]struct{}
 }
 
 func NewStoreProvider(tableStore *tablestore.TableStore, viewStore *viewstore.ViewStore) *Provider {
@@ -17,6 +24,14 @@ func NewStoreProvider(tableStore *tablestore.TableStore, viewStore *viewstore.Vi
 	}
 }
 
+// WithSessionViews returns a copy of the provider with the given in-memory
+// store attached for session-scoped temporary views.
+func (s *Provider) WithSessionViews(sessionViews *viewstore.MemStore) *Provider {
+	derived := *s
+	derived.sessionViews = sessionViews
+	return &derived
+}
+
 func (s *Provider) TableStore() *tablestore.TableStore {
 	return s.tableStore
 }
@@ -24,3 +39,66 @@ func (s *Provider) TableStore() *tablestore.TableStore {
 func (s *Provider) ViewStore() *viewstore.ViewStore {
 	return s.viewStore
 }
+
+// SessionViews returns the session-scoped temporary view store, or nil when
+// the current frontend has no session context.
+func (s *Provider) SessionViews() *viewstore.MemStore {
+	return s.sessionViews
+}
+
+// WithSessionOptions returns a copy of the provider with the given
+// session-scoped option set attached.
+func (s *Provider) WithSessionOptions(opts *SessionOptions) *Provider {
+	derived := *s
+	derived.sessionOptions = opts
+	return &derived
+}
+
+// SessionOptions returns the session-scoped options, or nil when the current
+// frontend has no session context.
+func (s *Provider) SessionOptions() *SessionOptions {
+	return s.sessionOptions
+}
+
+// WithTimeZone returns a copy of the provider with an explicit time zone for
+// interpreting naive timestamp literals. It overrides the session time_zone
+// option.
+func (s *Provider) WithTimeZone(loc *time.Location) *Provider {
+	derived := *s
+	derived.timeZone = loc
+	return &derived
+}
+
+// WithFeatures returns a copy of the provider with the given experimental
+// feature flags enabled. Flag semantics are defined by the translator; the
+// provider only carries them.
+func (s *Provider) WithFeatures(names []string) *Provider {
+	derived := *s
+	derived.features = make(map[string]struct{}, len(names))
+	for _, name := range names {
+		derived.features[strings.ToLower(strings.TrimSpace(name))] = struct{}{}
+	}
+	return &derived
+}
+
+// FeatureEnabled reports whether an experimental feature flag is enabled.
+func (s *Provider) FeatureEnabled(name string) bool {
+	_, ok := s.features[strings.ToLower(name)]
+	return ok
+}
+
+// TimeZone returns the zone for interpreting naive timestamp literals: the
+// explicit override if set, then the session time_zone option, then UTC.
+func (s *Provider) TimeZone() *time.Location {
+	if s.timeZone != nil {
+		return s.timeZone
+	}
+	if s.sessionOptions != nil {
+		if name, ok := s.sessionOptions.Get("time_zone"); ok {
+			if loc, err := time.LoadLocation(name); err == nil {
+				return loc
+			}
+		}
+	}
+	return time.UTC
+}
@@ -7,7 +7,15 @@ import (
 )
 
 type TableStore struct {
-	tables map[string]string
+	tables   map[string]string
+	backends map[string]Backend
+}
+
+// Backend identifies the upstream VictoriaLogs cluster serving a table, for
+// deployments that federate one SQL gateway over several clusters.
+type Backend struct {
+	Endpoint    string `json:"endpoint"`
+	BearerToken string `json:"bearerToken"`
 }
 
 func NewTableStore(tables map[string]string) (*TableStore, error) {
@@ -20,6 +28,36 @@ func NewTableStore(tables map[string]string) (*TableStore, error) {
 	}, nil
 }
 
+// SetBackends routes the named tables to their own upstream backends. Every
+// key must be a configured table; tables without an entry stay on the
+// default endpoint.
+func (s *TableStore) SetBackends(backends map[string]Backend) error {
+	normalized := make(map[string]Backend, len(backends))
+	for name, backend := range backends {
+		key := strings.ToLower(strings.TrimSpace(name))
+		if _, ok := s.tables[key]; !ok {
+			return fmt.Errorf("backend for unknown table %q", name)
+		}
+		if strings.TrimSpace(backend.Endpoint) == "" {
+			return fmt.Errorf("backend for table %q is missing an endpoint", name)
+		}
+		normalized[key] = backend
+	}
+	s.backends = normalized
+	return nil
+}
+
+// HasBackends reports whether any table routes to its own backend.
+func (s *TableStore) HasBackends() bool {
+	return len(s.backends) > 0
+}
+
+// GetTableBackend returns the backend serving a table, if one is configured.
+func (s *TableStore) GetTableBackend(name string) (Backend, bool) {
+	backend, ok := s.backends[strings.ToLower(name)]
+	return backend, ok
+}
+
 func (s *TableStore) GetTableQuery(name string) (string, bool) {
 	expr, ok := s.tables[name]
 	return expr, ok
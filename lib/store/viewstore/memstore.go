@@ -0,0 +1,96 @@
+package viewstore
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MemStore keeps session-scoped temporary views in memory. It mirrors the
+// ViewStore semantics (name sanitization, OR REPLACE / IF NOT EXISTS) but is
+// never persisted: each session gets its own instance and the views vanish
+// with it.
+type MemStore struct {
+	mu    sync.RWMutex
+	views map[string]string
+}
+
+// NewMemStore returns an empty in-memory view store.
+func NewMemStore() *MemStore {
+	return &MemStore{views: make(map[string]string)}
+}
+
+// Save stores query under the given view name.
+func (s *MemStore) Save(parts []string, query string, opts ViewOptions) error {
+	key, displayName, err := sanitizeViewFileName(parts)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.views[key]; exists {
+		if opts.IfNotExists {
+			return nil
+		}
+		if !opts.OrReplace {
+			return &StoreError{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("viewstore: temporary view %s already exists", displayName),
+			}
+		}
+	}
+	s.views[key] = query
+	return nil
+}
+
+// Load returns the stored query for the given view name. A nil MemStore
+// never matches.
+func (s *MemStore) Load(parts []string) (string, string, bool, error) {
+	if s == nil {
+		return "", strings.Join(parts, "."), false, nil
+	}
+	key, displayName, err := sanitizeViewFileName(parts)
+	if err != nil {
+		return "", displayName, false, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	query, ok := s.views[key]
+	return query, displayName, ok, nil
+}
+
+// Remove drops the named view and reports whether it existed. Missing views
+// are left to the caller so DROP VIEW can fall back to the persistent store.
+func (s *MemStore) Remove(parts []string) (bool, error) {
+	if s == nil {
+		return false, nil
+	}
+	key, _, err := sanitizeViewFileName(parts)
+	if err != nil {
+		return false, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.views[key]; !ok {
+		return false, nil
+	}
+	delete(s.views, key)
+	return true, nil
+}
+
+// ListViews returns the names of all temporary views in the session.
+func (s *MemStore) ListViews() []string {
+	if s == nil {
+		return nil
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	views := make([]string, 0, len(s.views))
+	for name := range s.views {
+		views = append(views, name)
+	}
+	sort.Strings(views)
+	return views
+}
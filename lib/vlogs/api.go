@@ -1,6 +1,7 @@
 package vlogs
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -16,51 +17,156 @@ import (
 type EndpointConfig struct {
 	Endpoint    string
 	BearerToken string
+	// ClientLabel identifies the SQL client that produced the query. It is
+	// sent upstream as the X-SQL2LogsQL-Client header so VictoriaLogs-side
+	// slow-query analysis can attribute queries to their source.
+	ClientLabel string
 }
 
 type API struct {
 	ec     EndpointConfig
 	limit  uint32
 	client *http.Client
+
+	insertEnabled     bool
+	insertStreamField string
+
+	postProcessMaxRows int
+
+	responseMemoryBytes int64
+	responseMaxBytes    int64
+
+	overridesDisabled bool
+	allowedEndpoints  map[string]struct{}
+
+	connCounters connCounters
 }
 
 func NewVLogsAPI(ec EndpointConfig, limit uint32) *API {
-	return &API{
+	a := &API{
 		ec:    ec,
 		limit: limit,
-		client: &http.Client{
-			Timeout: 60 * time.Second,
-		},
 	}
+	a.SetHTTPClient(&http.Client{
+		Timeout: 60 * time.Second,
+	})
+	return a
 }
 
-func (a *API) Execute(ctx context.Context, si *logsql.StatementInfo, customEC EndpointConfig) ([]byte, error) {
+// DisableEndpointOverrides rejects any per-request endpoint, pinning all
+// upstream traffic to the configured backend.
+func (a *API) DisableEndpointOverrides() {
+	a.overridesDisabled = true
+}
+
+// AllowEndpointOverrides restricts per-request endpoints to the given list.
+// Without an allowlist any caller could point the server at arbitrary
+// internal URLs, turning it into an SSRF proxy.
+func (a *API) AllowEndpointOverrides(endpoints []string) {
+	a.allowedEndpoints = make(map[string]struct{}, len(endpoints))
+	for _, endpoint := range endpoints {
+		a.allowedEndpoints[normalizeEndpoint(endpoint)] = struct{}{}
+	}
+}
+
+func normalizeEndpoint(endpoint string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(endpoint), "/"))
+}
+
+// ResolveEndpoint merges the per-request endpoint configuration with the
+// configured default. Setting the endpoint in both places is rejected so a
+// request cannot silently redirect traffic away from the configured backend,
+// and overrides are checked against the configured allowlist.
+func (a *API) ResolveEndpoint(customEC EndpointConfig) (EndpointConfig, error) {
 	if a.ec.Endpoint != "" && customEC.Endpoint != "" {
-		return nil, &APIError{
+		return EndpointConfig{}, &APIError{
 			Code:    http.StatusBadRequest,
 			Message: "endpoint can be set either in config or in request, not both",
 		}
 	}
-	recEC := customEC
-	if recEC.Endpoint == "" {
-		recEC.Endpoint = a.ec.Endpoint
-		recEC.BearerToken = a.ec.BearerToken
+	// The per-request client label wins over the configured default.
+	label := customEC.ClientLabel
+	if label == "" {
+		label = a.ec.ClientLabel
+	}
+	if customEC.Endpoint == "" {
+		ec := a.ec
+		ec.ClientLabel = label
+		return ec, nil
+	}
+	if a.overridesDisabled {
+		return EndpointConfig{}, &APIError{
+			Code:    http.StatusForbidden,
+			Message: "per-request endpoint overrides are disabled",
+		}
+	}
+	if a.allowedEndpoints != nil {
+		if _, ok := a.allowedEndpoints[normalizeEndpoint(customEC.Endpoint)]; !ok {
+			return EndpointConfig{}, &APIError{
+				Code:    http.StatusForbidden,
+				Message: fmt.Sprintf("endpoint %q is not in the allowed endpoints list", customEC.Endpoint),
+			}
+		}
+	}
+	customEC.ClientLabel = label
+	return customEC, nil
+}
+
+// setClientLabel attaches the resolved client label as the
+// X-SQL2LogsQL-Client request header. Control characters are dropped to
+// keep the value header-safe.
+func setClientLabel(req *http.Request, ec EndpointConfig) {
+	label := strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, ec.ClientLabel)
+	if label = strings.TrimSpace(label); label != "" {
+		req.Header.Set("X-SQL2LogsQL-Client", label)
+	}
+}
+
+func (a *API) Execute(ctx context.Context, si *logsql.StatementInfo, customEC EndpointConfig) ([]byte, error) {
+	recEC, err := a.resolveStatementEndpoint(si, customEC)
+	if err != nil {
+		return nil, err
 	}
 	switch si.Kind {
 	case logsql.StatementTypeSelect:
-		if a.ec.Endpoint == "" && customEC.Endpoint == "" {
+		if len(si.Fanout) > 0 {
+			return a.executeFanout(ctx, si, customEC)
+		}
+		if recEC.Endpoint == "" {
 			return nil, nil
 		}
+		if si.PostProcess != nil {
+			return a.executePostProcess(ctx, si, recEC)
+		}
 		return a.Query(ctx, si.LogsQL, recEC)
 	case logsql.StatementTypeDescribe:
-		if a.ec.Endpoint == "" && customEC.Endpoint == "" {
+		if recEC.Endpoint == "" {
 			return nil, nil
 		}
 		return a.GetFieldNames(ctx, si.LogsQL, recEC)
+	case logsql.StatementTypeInsert:
+		if !a.insertEnabled {
+			return nil, &APIError{
+				Code:    http.StatusForbidden,
+				Message: "vlogs: INSERT is disabled; enable it with the enableInsert config option",
+			}
+		}
+		rows, err := a.Query(ctx, si.LogsQL, recEC)
+		if err != nil {
+			return nil, err
+		}
+		return a.Insert(ctx, si.Target, rows, recEC)
 	case logsql.StatementTypeCreateView, logsql.StatementTypeDropView:
 		return []byte(si.Data), nil
 	case logsql.StatementTypeShowTables, logsql.StatementTypeShowViews:
 		return []byte(si.Data), nil
+	case logsql.StatementTypeSet, logsql.StatementTypeUse:
+		return []byte(si.Data), nil
 	default:
 		return nil, &APIError{
 			Code:    http.StatusBadRequest,
@@ -69,11 +175,104 @@ func (a *API) Execute(ctx context.Context, si *logsql.StatementInfo, customEC En
 	}
 }
 
+// executeFanout runs each branch of a cross-backend UNION ALL against its
+// own backend and concatenates the NDJSON result streams. A trailing ORDER
+// BY/LIMIT hoisted by the translator is applied to the merged rows, since no
+// single branch can order the whole union.
+func (a *API) executeFanout(ctx context.Context, si *logsql.StatementInfo, customEC EndpointConfig) ([]byte, error) {
+	merged := make([]byte, 0)
+	for _, branch := range si.Fanout {
+		branchInfo := &logsql.StatementInfo{Kind: logsql.StatementTypeSelect, Backend: branch.Backend}
+		recEC, err := a.resolveStatementEndpoint(branchInfo, customEC)
+		if err != nil {
+			return nil, err
+		}
+		if recEC.Endpoint == "" {
+			// Translation-only mode: without any endpoint there is nothing
+			// to merge, matching the plain SELECT behavior.
+			return nil, nil
+		}
+		data, err := a.Query(ctx, branch.LogsQL, recEC)
+		if err != nil {
+			return nil, err
+		}
+		merged = append(merged, data...)
+		if len(data) > 0 && data[len(data)-1] != '\n' {
+			merged = append(merged, '\n')
+		}
+	}
+	if si.PostProcess != nil {
+		return a.postProcessMerged(si, merged)
+	}
+	return merged, nil
+}
+
+// resolveStatementEndpoint routes the statement to its table's configured
+// backend when one is set, and otherwise falls back to the usual endpoint
+// resolution. Table backends come from server config, so they bypass the
+// per-request override allowlist.
+func (a *API) resolveStatementEndpoint(si *logsql.StatementInfo, customEC EndpointConfig) (EndpointConfig, error) {
+	if si.Backend == nil || si.Backend.Endpoint == "" {
+		return a.ResolveEndpoint(customEC)
+	}
+	if customEC.Endpoint != "" {
+		return EndpointConfig{}, &APIError{
+			Code:    http.StatusBadRequest,
+			Message: "per-request endpoint conflicts with the table's configured backend",
+		}
+	}
+	label := customEC.ClientLabel
+	if label == "" {
+		label = a.ec.ClientLabel
+	}
+	return EndpointConfig{
+		Endpoint:    si.Backend.Endpoint,
+		BearerToken: si.Backend.BearerToken,
+		ClientLabel: label,
+	}, nil
+}
+
+// SetHTTPClient swaps the upstream client. The transport is wrapped so
+// connection reuse keeps being counted; the caller's client is not mutated.
 func (a *API) SetHTTPClient(client *http.Client) {
-	a.client = client
+	wrapped := *client
+	base := wrapped.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if t, ok := base.(*connTracingTransport); !ok || t.counters != &a.connCounters {
+		wrapped.Transport = &connTracingTransport{base: base, counters: &a.connCounters}
+	}
+	a.client = &wrapped
+}
+
+// ConnStats reports the upstream connection counters gathered since startup.
+func (a *API) ConnStats() ConnStats {
+	return ConnStats{
+		Requests: a.connCounters.requests.Load(),
+		Reused:   a.connCounters.reused.Load(),
+		Dialed:   a.connCounters.dialed.Load(),
+		HTTP2:    a.connCounters.http2.Load(),
+	}
 }
 
+// Query runs a LogsQL query and returns the NDJSON rows, buffered under the
+// configured response limits. Callers that only relay the rows should go
+// through ExecuteStream instead, which never rebuilds a spilled result in
+// memory.
 func (a *API) Query(ctx context.Context, logsQL string, recEC EndpointConfig) ([]byte, error) {
+	stream, err := a.queryStream(ctx, logsQL, recEC)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+	return drainResponseStream(stream)
+}
+
+// queryStream runs a LogsQL query and returns the buffered result as a
+// stream. Unlike QueryStream it applies the row limit and the response size
+// limits; the upstream body is fully consumed before it returns.
+func (a *API) queryStream(ctx context.Context, logsQL string, recEC EndpointConfig) (io.ReadCloser, error) {
 	if recEC.Endpoint == "" {
 		return nil, &APIError{
 			Code:    http.StatusBadRequest,
@@ -104,6 +303,7 @@ func (a *API) Query(ctx context.Context, logsQL string, recEC EndpointConfig) ([
 	if recEC.BearerToken != "" {
 		req.Header.Set("Authorization", "Bearer "+recEC.BearerToken)
 	}
+	setClientLabel(req, recEC)
 
 	resp, err := a.client.Do(req)
 	if err != nil {
@@ -115,15 +315,93 @@ func (a *API) Query(ctx context.Context, logsQL string, recEC EndpointConfig) ([
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		msg := strings.TrimSpace(string(body))
+		if msg == "" {
+			msg = resp.Status
+		}
+		return nil, &APIError{
+			Code:    http.StatusBadGateway,
+			Message: fmt.Sprintf("status %d: %s", resp.StatusCode, msg),
+		}
+	}
+	return a.readResponseStream(resp.Body)
+}
+
+// ExecuteStream behaves like Execute but returns plain SELECT results as a
+// stream, so a response that spilled to disk is relayed to the client
+// without being rebuilt in memory. Statements that must buffer their rows
+// anyway (fanout, post-processing, inserts) fall back to Execute. The caller
+// must close the returned reader.
+func (a *API) ExecuteStream(ctx context.Context, si *logsql.StatementInfo, customEC EndpointConfig) (io.ReadCloser, error) {
+	if si.Kind == logsql.StatementTypeSelect && len(si.Fanout) == 0 && si.PostProcess == nil {
+		recEC, err := a.resolveStatementEndpoint(si, customEC)
+		if err != nil {
+			return nil, err
+		}
+		if recEC.Endpoint == "" {
+			return io.NopCloser(strings.NewReader("")), nil
+		}
+		return a.queryStream(ctx, si.LogsQL, recEC)
+	}
+	data, err := a.Execute(ctx, si, customEC)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// QueryStream runs a LogsQL query and returns the raw JSONL response body as
+// a stream. Unlike Query it sends no row limit and does not buffer the
+// response, so callers can export results that are far larger than an HTTP
+// response buffer should hold. The caller must close the returned reader.
+func (a *API) QueryStream(ctx context.Context, logsQL string, recEC EndpointConfig) (io.ReadCloser, error) {
+	if recEC.Endpoint == "" {
+		return nil, &APIError{
+			Code:    http.StatusBadRequest,
+			Message: "endpoint is required for this statement",
+		}
+	}
+	reqURL, err := url.Parse(recEC.Endpoint)
+	if err != nil {
+		return nil, &APIError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("invalid endpoint URL: %v", recEC.Endpoint),
+			Err:     err,
+		}
+	}
+	reqURL = reqURL.JoinPath("/select/logsql/query")
+	form := url.Values{}
+	form.Set("query", logsQL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, reqURL.String(), strings.NewReader(form.Encode()))
 	if err != nil {
 		return nil, &APIError{
 			Code:    http.StatusBadGateway,
-			Message: "failed to read response body",
+			Message: "failed to create request",
+			Err:     err,
+		}
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if recEC.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+recEC.BearerToken)
+	}
+	setClientLabel(req, recEC)
+
+	// The shared client enforces a timeout sized for interactive queries;
+	// exports run until the stream is drained or the context is canceled.
+	client := &http.Client{Transport: a.client.Transport}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, &APIError{
+			Code:    http.StatusBadGateway,
+			Message: "failed to execute request",
 			Err:     err,
 		}
 	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		resp.Body.Close()
 		msg := strings.TrimSpace(string(body))
 		if msg == "" {
 			msg = resp.Status
@@ -133,7 +411,7 @@ func (a *API) Query(ctx context.Context, logsQL string, recEC EndpointConfig) ([
 			Message: fmt.Sprintf("status %d: %s", resp.StatusCode, msg),
 		}
 	}
-	return body, nil
+	return resp.Body, nil
 }
 
 type FieldNamesResponse struct {
@@ -175,6 +453,7 @@ func (a *API) GetFieldNames(ctx context.Context, logsQL string, recEC EndpointCo
 	if recEC.BearerToken != "" {
 		req.Header.Set("Authorization", "Bearer "+recEC.BearerToken)
 	}
+	setClientLabel(req, recEC)
 
 	resp, err := a.client.Do(req)
 	if err != nil {
@@ -0,0 +1,123 @@
+package vlogs
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"sync/atomic"
+	"time"
+)
+
+// ClientOptions tunes the HTTP client used for upstream VictoriaLogs
+// requests. Zero values keep the default noted on each field, so deployments
+// only spell out what they change.
+type ClientOptions struct {
+	// TimeoutSeconds bounds a whole interactive request including reading
+	// the response body; defaults to 60. Streaming exports ignore it.
+	TimeoutSeconds int `json:"timeoutSeconds"`
+	// MaxIdleConnsPerHost sizes the connection pool towards one backend;
+	// defaults to the net/http default of 2, which is too small for heavy
+	// concurrent dashboards.
+	MaxIdleConnsPerHost int `json:"maxIdleConnsPerHost"`
+	// TLSHandshakeTimeoutSeconds defaults to 10.
+	TLSHandshakeTimeoutSeconds int `json:"tlsHandshakeTimeoutSeconds"`
+	// KeepAliveSeconds sets the TCP keep-alive interval; defaults to 30.
+	// A negative value disables keep-alives entirely.
+	KeepAliveSeconds int `json:"keepAliveSeconds"`
+	// IdleConnTimeoutSeconds closes idle pooled connections; defaults
+	// to 90.
+	IdleConnTimeoutSeconds int `json:"idleConnTimeoutSeconds"`
+	// MaxConnsPerHost caps concurrent connections to one backend; extra
+	// requests queue instead of dialing, which keeps a burst of dashboard
+	// panels from exhausting ephemeral ports. 0 (the default) is unlimited.
+	MaxConnsPerHost int `json:"maxConnsPerHost"`
+	// DisableHTTP2 forces HTTP/1.1 to the upstream. HTTP/2 is attempted by
+	// default because it multiplexes concurrent queries over one connection.
+	DisableHTTP2 bool `json:"disableHTTP2"`
+}
+
+// IsZero reports whether every option is left at its default.
+func (o ClientOptions) IsZero() bool {
+	return o == ClientOptions{}
+}
+
+func secondsOrDefault(value int, fallback time.Duration) time.Duration {
+	if value <= 0 {
+		return fallback
+	}
+	return time.Duration(value) * time.Second
+}
+
+// NewHTTPClient builds an upstream client from the options.
+func (o ClientOptions) NewHTTPClient() *http.Client {
+	keepAlive := secondsOrDefault(o.KeepAliveSeconds, 30*time.Second)
+	if o.KeepAliveSeconds < 0 {
+		keepAlive = -1
+	}
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: keepAlive,
+	}
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     !o.DisableHTTP2,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   o.MaxIdleConnsPerHost,
+		MaxConnsPerHost:       o.MaxConnsPerHost,
+		IdleConnTimeout:       secondsOrDefault(o.IdleConnTimeoutSeconds, 90*time.Second),
+		TLSHandshakeTimeout:   secondsOrDefault(o.TLSHandshakeTimeoutSeconds, 10*time.Second),
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+	return &http.Client{
+		Timeout:   secondsOrDefault(o.TimeoutSeconds, 60*time.Second),
+		Transport: transport,
+	}
+}
+
+// ConnStats snapshots how upstream connections are being used. A low reuse
+// ratio under load means the pool limits need tuning before the host runs
+// out of ephemeral ports.
+type ConnStats struct {
+	// Requests counts requests that obtained an upstream connection.
+	Requests uint64 `json:"requests"`
+	// Reused counts requests served over an already-established connection.
+	Reused uint64 `json:"reusedConns"`
+	// Dialed counts requests that needed a fresh connection.
+	Dialed uint64 `json:"dialedConns"`
+	// HTTP2 counts responses that arrived over HTTP/2.
+	HTTP2 uint64 `json:"http2Responses"`
+}
+
+type connCounters struct {
+	requests atomic.Uint64
+	reused   atomic.Uint64
+	dialed   atomic.Uint64
+	http2    atomic.Uint64
+}
+
+// connTracingTransport hooks httptrace into every upstream request to count
+// connection reuse; the counters feed API.ConnStats.
+type connTracingTransport struct {
+	base     http.RoundTripper
+	counters *connCounters
+}
+
+func (t *connTracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.counters.requests.Add(1)
+			if info.Reused {
+				t.counters.reused.Add(1)
+			} else {
+				t.counters.dialed.Add(1)
+			}
+		},
+	}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+	resp, err := t.base.RoundTrip(req)
+	if err == nil && resp.ProtoMajor == 2 {
+		t.counters.http2.Add(1)
+	}
+	return resp, err
+}
@@ -0,0 +1,126 @@
+package vlogs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// HitsEstimate summarizes how many log entries a filter matches and the
+// time span they cover, as reported by the VictoriaLogs hits API.
+type HitsEstimate struct {
+	Rows  uint64 `json:"rows"`
+	Start string `json:"start,omitempty"`
+	End   string `json:"end,omitempty"`
+}
+
+type hitsResponse struct {
+	Hits []struct {
+		Timestamps []string `json:"timestamps"`
+		Total      uint64   `json:"total"`
+	} `json:"hits"`
+}
+
+// GetHits runs logsQL against /select/logsql/hits and aggregates the result
+// into a row-count estimate. The query should be a plain filter without
+// pipes; hits are bucketed by day only to recover the matched time span.
+func (a *API) GetHits(ctx context.Context, logsQL string, customEC EndpointConfig) (*HitsEstimate, error) {
+	if a.ec.Endpoint != "" && customEC.Endpoint != "" {
+		return nil, &APIError{
+			Code:    http.StatusBadRequest,
+			Message: "endpoint can be set either in config or in request, not both",
+		}
+	}
+	recEC := customEC
+	if recEC.Endpoint == "" {
+		recEC.Endpoint = a.ec.Endpoint
+		recEC.BearerToken = a.ec.BearerToken
+	}
+	if recEC.Endpoint == "" {
+		return nil, &APIError{
+			Code:    http.StatusBadRequest,
+			Message: "endpoint is required for this statement",
+		}
+	}
+	reqURL, err := url.Parse(recEC.Endpoint)
+	if err != nil {
+		return nil, &APIError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("invalid endpoint URL: %v", recEC.Endpoint),
+			Err:     err,
+		}
+	}
+	reqURL = reqURL.JoinPath("/select/logsql/hits")
+	form := url.Values{}
+	form.Set("query", logsQL)
+	form.Set("step", "1d")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, reqURL.String(), strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, &APIError{
+			Code:    http.StatusBadGateway,
+			Message: "failed to create request",
+			Err:     err,
+		}
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if recEC.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+recEC.BearerToken)
+	}
+	setClientLabel(req, recEC)
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, &APIError{
+			Code:    http.StatusBadGateway,
+			Message: "failed to execute request",
+			Err:     err,
+		}
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &APIError{
+			Code:    http.StatusBadGateway,
+			Message: "failed to read response body",
+			Err:     err,
+		}
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg := strings.TrimSpace(string(body))
+		if msg == "" {
+			msg = resp.Status
+		}
+		return nil, &APIError{
+			Code:    http.StatusBadGateway,
+			Message: fmt.Sprintf("status %d: %s", resp.StatusCode, msg),
+		}
+	}
+
+	respData := hitsResponse{}
+	if err = json.Unmarshal(body, &respData); err != nil {
+		return nil, &APIError{
+			Code:    http.StatusBadGateway,
+			Message: "failed to parse response body",
+			Err:     err,
+		}
+	}
+
+	estimate := &HitsEstimate{}
+	for _, h := range respData.Hits {
+		estimate.Rows += h.Total
+		for _, ts := range h.Timestamps {
+			if estimate.Start == "" || ts < estimate.Start {
+				estimate.Start = ts
+			}
+			if ts > estimate.End {
+				estimate.End = ts
+			}
+		}
+	}
+	return estimate, nil
+}
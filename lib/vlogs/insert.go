@@ -0,0 +1,140 @@
+package vlogs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// defaultInsertStreamField is used when no stream field is configured for
+// INSERT ingestion.
+const defaultInsertStreamField = "stream"
+
+// EnableInsert turns on INSERT INTO ... SELECT execution. Result rows are
+// written back to VictoriaLogs via its JSON line ingestion API, with
+// streamField carrying the destination table name as the stream label.
+func (a *API) EnableInsert(streamField string) {
+	a.insertEnabled = true
+	a.insertStreamField = strings.TrimSpace(streamField)
+	if a.insertStreamField == "" {
+		a.insertStreamField = defaultInsertStreamField
+	}
+}
+
+// InsertResult reports how many rows an INSERT ... SELECT wrote.
+type InsertResult struct {
+	Inserted int    `json:"inserted"`
+	Stream   string `json:"stream"`
+}
+
+// Insert writes newline-delimited JSON rows to /insert/jsonline, labelling
+// every row with the configured stream field set to target.
+func (a *API) Insert(ctx context.Context, target string, rows []byte, recEC EndpointConfig) ([]byte, error) {
+	if recEC.Endpoint == "" {
+		return nil, &APIError{
+			Code:    http.StatusBadRequest,
+			Message: "endpoint is required for this statement",
+		}
+	}
+	reqURL, err := url.Parse(recEC.Endpoint)
+	if err != nil {
+		return nil, &APIError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("invalid endpoint URL: %v", recEC.Endpoint),
+			Err:     err,
+		}
+	}
+	reqURL = reqURL.JoinPath("/insert/jsonline")
+	q := reqURL.Query()
+	q.Set("_stream_fields", a.insertStreamField)
+	reqURL.RawQuery = q.Encode()
+
+	payload := bytes.Buffer{}
+	inserted := 0
+	for _, line := range bytes.Split(rows, []byte{'\n'}) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		row := map[string]any{}
+		if err := json.Unmarshal(line, &row); err != nil {
+			return nil, &APIError{
+				Code:    http.StatusBadGateway,
+				Message: "failed to parse result row",
+				Err:     err,
+			}
+		}
+		row[a.insertStreamField] = target
+		encoded, err := json.Marshal(row)
+		if err != nil {
+			return nil, &APIError{
+				Code:    http.StatusBadGateway,
+				Message: "failed to encode result row",
+				Err:     err,
+			}
+		}
+		payload.Write(encoded)
+		payload.WriteByte('\n')
+		inserted++
+	}
+
+	if inserted > 0 {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, reqURL.String(), &payload)
+		if err != nil {
+			return nil, &APIError{
+				Code:    http.StatusBadGateway,
+				Message: "failed to create request",
+				Err:     err,
+			}
+		}
+		req.Header.Set("Content-Type", "application/stream+json")
+		if recEC.BearerToken != "" {
+			req.Header.Set("Authorization", "Bearer "+recEC.BearerToken)
+		}
+		setClientLabel(req, recEC)
+
+		resp, err := a.client.Do(req)
+		if err != nil {
+			return nil, &APIError{
+				Code:    http.StatusBadGateway,
+				Message: "failed to execute request",
+				Err:     err,
+			}
+		}
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, &APIError{
+				Code:    http.StatusBadGateway,
+				Message: "failed to read response body",
+				Err:     err,
+			}
+		}
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			msg := strings.TrimSpace(string(body))
+			if msg == "" {
+				msg = resp.Status
+			}
+			return nil, &APIError{
+				Code:    http.StatusBadGateway,
+				Message: fmt.Sprintf("status %d: %s", resp.StatusCode, msg),
+			}
+		}
+	}
+
+	result, err := json.Marshal(InsertResult{Inserted: inserted, Stream: target})
+	if err != nil {
+		return nil, &APIError{
+			Code:    http.StatusBadGateway,
+			Message: "failed to marshal response body",
+			Err:     err,
+		}
+	}
+	return result, nil
+}
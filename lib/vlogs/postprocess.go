@@ -0,0 +1,95 @@
+package vlogs
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/VictoriaMetrics/sql-to-logsql/lib/logsql"
+)
+
+// defaultPostProcessMaxRows bounds how many result rows the server is
+// willing to buffer for client-side post-processing.
+const defaultPostProcessMaxRows = 10000
+
+// SetPostProcessLimit overrides the maximum number of result rows (across
+// the main query and any INTERSECT branches) that post-processing may
+// buffer. Larger results fail with an error instead of exhausting memory.
+func (a *API) SetPostProcessLimit(rows int) {
+	a.postProcessMaxRows = rows
+}
+
+// executePostProcess runs the translated query plus any INTERSECT branches,
+// then applies the operations the translator could not push into LogsQL to
+// the buffered rows.
+func (a *API) executePostProcess(ctx context.Context, si *logsql.StatementInfo, recEC EndpointConfig) ([]byte, error) {
+	main, err := a.Query(ctx, si.LogsQL, recEC)
+	if err != nil {
+		return nil, err
+	}
+	total := countNDJSONRows(main)
+	branches := make([][]byte, 0, len(si.PostProcess.Intersect))
+	for _, branch := range si.PostProcess.Intersect {
+		data, err := a.Query(ctx, branch, recEC)
+		if err != nil {
+			return nil, err
+		}
+		total += countNDJSONRows(data)
+		branches = append(branches, data)
+	}
+	maxRows := a.postProcessMaxRows
+	if maxRows <= 0 {
+		maxRows = defaultPostProcessMaxRows
+	}
+	if total > maxRows {
+		return nil, &APIError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("vlogs: post-processing would buffer %d rows, above the %d-row limit; narrow the query or rewrite it so it translates fully", total, maxRows),
+		}
+	}
+	result, err := si.PostProcess.Apply(main, branches)
+	if err != nil {
+		return nil, &APIError{
+			Code:    http.StatusBadRequest,
+			Message: "vlogs: post-process result rows",
+			Err:     err,
+		}
+	}
+	return result, nil
+}
+
+// postProcessMerged applies the operations hoisted off a fanned-out UNION
+// ALL to the merged branch rows, under the same buffering cap as the regular
+// post-processing path.
+func (a *API) postProcessMerged(si *logsql.StatementInfo, merged []byte) ([]byte, error) {
+	maxRows := a.postProcessMaxRows
+	if maxRows <= 0 {
+		maxRows = defaultPostProcessMaxRows
+	}
+	if total := countNDJSONRows(merged); total > maxRows {
+		return nil, &APIError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("vlogs: ordering the merged UNION would buffer %d rows, above the %d-row limit; narrow the branches or drop the trailing ORDER BY", total, maxRows),
+		}
+	}
+	result, err := si.PostProcess.Apply(merged, nil)
+	if err != nil {
+		return nil, &APIError{
+			Code:    http.StatusBadRequest,
+			Message: "vlogs: post-process merged rows",
+			Err:     err,
+		}
+	}
+	return result, nil
+}
+
+func countNDJSONRows(data []byte) int {
+	rows := 0
+	for _, line := range bytes.Split(data, []byte{'\n'}) {
+		if len(bytes.TrimSpace(line)) > 0 {
+			rows++
+		}
+	}
+	return rows
+}
@@ -0,0 +1,166 @@
+package vlogs
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+)
+
+const (
+	// defaultResponseMemoryBytes is how much of an upstream response is
+	// buffered in memory before the remainder spills to a temp file.
+	defaultResponseMemoryBytes = 16 << 20
+	// defaultResponseMaxBytes is the hard cap on upstream response size.
+	defaultResponseMaxBytes = 256 << 20
+)
+
+// SetResponseLimits bounds upstream response buffering. memoryBytes is the
+// most held in memory while a response is read; anything beyond it spills to
+// a temp file. maxBytes is a hard cap above which the query fails with a
+// 413. Zero keeps the built-in defaults; a negative maxBytes disables the
+// cap entirely.
+func (a *API) SetResponseLimits(memoryBytes, maxBytes int64) {
+	a.responseMemoryBytes = memoryBytes
+	a.responseMaxBytes = maxBytes
+}
+
+// ReadResult drains a result stream under the configured response limits.
+// It exists for callers that keep the whole result around anyway (e.g.
+// background jobs) but still need the hard-cap protection Query gets
+// internally.
+func (a *API) ReadResult(r io.Reader) ([]byte, error) {
+	stream, err := a.readResponseStream(r)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+	return drainResponseStream(stream)
+}
+
+// spillReader streams a buffered response back out: first the in-memory
+// head, then the spill file holding the tail. Closing it removes the file,
+// so the caller must fully relay the stream before closing.
+type spillReader struct {
+	head  *bytes.Reader
+	spill *os.File
+}
+
+func (r *spillReader) Read(p []byte) (int, error) {
+	if r.head.Len() > 0 {
+		return r.head.Read(p)
+	}
+	if r.spill == nil {
+		return 0, io.EOF
+	}
+	return r.spill.Read(p)
+}
+
+func (r *spillReader) Close() error {
+	if r.spill == nil {
+		return nil
+	}
+	err := r.spill.Close()
+	os.Remove(r.spill.Name())
+	r.spill = nil
+	return err
+}
+
+// readResponseStream replaces io.ReadAll for upstream responses: the head of
+// the body stays in memory, the tail spills to a temp file, and the total
+// size is checked against the hard cap. The returned stream serves the head
+// and then the spill file, so an oversized result is relayed to the client
+// without ever being rebuilt in memory. r is fully drained before returning,
+// so the caller may close the underlying body immediately.
+func (a *API) readResponseStream(r io.Reader) (io.ReadCloser, error) {
+	memLimit := a.responseMemoryBytes
+	if memLimit <= 0 {
+		memLimit = defaultResponseMemoryBytes
+	}
+	maxBytes := a.responseMaxBytes
+	if maxBytes == 0 {
+		maxBytes = defaultResponseMaxBytes
+	}
+	// Reading one byte past the cap distinguishes "exactly at the cap" from
+	// "over it" without draining the rest of an oversized body.
+	readLimit := memLimit
+	if maxBytes > 0 && maxBytes+1 < readLimit {
+		readLimit = maxBytes + 1
+	}
+	head := &bytes.Buffer{}
+	n, err := io.CopyN(head, r, readLimit)
+	if err == io.EOF {
+		return &spillReader{head: bytes.NewReader(head.Bytes())}, nil
+	}
+	if err != nil {
+		return nil, &APIError{
+			Code:    http.StatusBadGateway,
+			Message: "failed to read response body",
+			Err:     err,
+		}
+	}
+	if maxBytes > 0 && n > maxBytes {
+		return nil, &APIError{
+			Code:    http.StatusRequestEntityTooLarge,
+			Message: fmt.Sprintf("result exceeds the %d-byte limit; add filters or use the streaming export API", maxBytes),
+		}
+	}
+
+	spill, err := os.CreateTemp("", "sql-to-logsql-result-")
+	if err != nil {
+		return nil, &APIError{
+			Code:    http.StatusInternalServerError,
+			Message: "failed to create spill file",
+			Err:     err,
+		}
+	}
+	discard := func() {
+		spill.Close()
+		os.Remove(spill.Name())
+	}
+
+	rest := r
+	if maxBytes > 0 {
+		rest = io.LimitReader(r, maxBytes-n+1)
+	}
+	m, err := io.Copy(spill, rest)
+	if err != nil {
+		discard()
+		return nil, &APIError{
+			Code:    http.StatusBadGateway,
+			Message: "failed to read response body",
+			Err:     err,
+		}
+	}
+	if maxBytes > 0 && n+m > maxBytes {
+		discard()
+		return nil, &APIError{
+			Code:    http.StatusRequestEntityTooLarge,
+			Message: fmt.Sprintf("result exceeds the %d-byte limit; add filters or use the streaming export API", maxBytes),
+		}
+	}
+	if _, err := spill.Seek(0, io.SeekStart); err != nil {
+		discard()
+		return nil, &APIError{
+			Code:    http.StatusInternalServerError,
+			Message: "failed to rewind spill file",
+			Err:     err,
+		}
+	}
+	return &spillReader{head: bytes.NewReader(head.Bytes()), spill: spill}, nil
+}
+
+// drainResponseStream materializes a response stream for callers that parse
+// the rows. The hard cap in readResponseStream keeps the buffer bounded.
+func drainResponseStream(stream io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(stream)
+	if err != nil {
+		return nil, &APIError{
+			Code:    http.StatusInternalServerError,
+			Message: "failed to read spill file",
+			Err:     err,
+		}
+	}
+	return data, nil
+}